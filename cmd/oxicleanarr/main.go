@@ -14,9 +14,11 @@ import (
 	"github.com/ramonskie/oxicleanarr/internal/api/handlers"
 	"github.com/ramonskie/oxicleanarr/internal/cache"
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/metrics"
 	"github.com/ramonskie/oxicleanarr/internal/services"
 	"github.com/ramonskie/oxicleanarr/internal/services/rules"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/tracing"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
 	"github.com/rs/zerolog/log"
 )
@@ -49,6 +51,18 @@ func main() {
 		Str("config_path", configPathValue).
 		Msg("Configuration loaded")
 
+	// Initialize tracing (no-op unless server.tracing.enabled)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Server.Tracing)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	if cfg.Server.Tracing.Enabled {
+		log.Info().
+			Str("exporter", cfg.Server.Tracing.Exporter).
+			Str("endpoint", cfg.Server.Tracing.Endpoint).
+			Msg("Tracing enabled")
+	}
+
 	// Initialize JWT
 	jwtSecret := getEnv("JWT_SECRET", "")
 	jwtExpiry, _ := time.ParseDuration(getEnv("JWT_EXPIRATION", "24h"))
@@ -86,13 +100,62 @@ func main() {
 	}
 	log.Info().Int("jobs", len(jobsFile.GetAll())).Msg("Jobs loaded")
 
+	diskHistoryFile, err := storage.NewDiskHistoryFile(dataPath, 0)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize disk history storage")
+	}
+
+	deletionBatchesFile, err := storage.NewDeletionBatchesFile(dataPath, 0)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize deletion batches storage")
+	}
+
+	keepRequestsFile, err := storage.NewKeepRequestsFile(dataPath, 0)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize keep requests storage")
+	}
+
+	usersFile, err := storage.NewUsersFile(dataPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize users storage")
+	}
+
+	apiKeysFile, err := storage.NewAPIKeysFile(dataPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize API keys storage")
+	}
+
+	sessionsFile, err := storage.NewSessionsFile(dataPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize sessions storage")
+	}
+
+	auditFile, err := storage.NewAuditLogFile(dataPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize audit log storage")
+	}
+	auditLog := services.NewAuditLog(auditFile)
+
+	configHistoryFile, err := storage.NewConfigHistoryFile(dataPath, 0)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize config history storage")
+	}
+	configHistory := services.NewConfigHistory(configHistoryFile)
+	configHistory.RecordFile(config.GetPath(), storage.ConfigSourceStartup)
+
 	// Initialize cache
 	appCache := cache.New()
 	log.Info().Msg("Cache initialized")
 
 	// Initialize services
 	authService := services.NewAuthService(cfg)
-	log.Info().Msg("Authentication service initialized")
+	authService.SetUsers(usersFile)
+	authService.SetAPIKeys(apiKeysFile)
+	authService.SetSessions(sessionsFile)
+	if _, err := authService.MigrateAdmin(); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate admin account to the user store")
+	}
+	log.Info().Int("users", usersFile.Count()).Msg("Authentication service initialized")
 
 	// Initialize rules engine (nil diskMonitor = disk threshold feature disabled)
 	rulesEngine := rules.NewRulesEngine(exclusionsFile, nil)
@@ -100,6 +163,10 @@ func main() {
 
 	// Initialize sync engine
 	syncEngine := services.NewSyncEngine(cfg, appCache, jobsFile, exclusionsFile, manualLeavingSoonFile, rulesEngine)
+	syncEngine.SetDiskHistory(diskHistoryFile)
+	syncEngine.SetDeletionBatches(deletionBatchesFile)
+	syncEngine.SetKeepRequests(keepRequestsFile)
+	metrics.Registry.MustRegister(services.NewMetricsCollector(syncEngine))
 	log.Info().Msg("Sync engine initialized")
 
 	// Start sync engine scheduler
@@ -120,17 +187,25 @@ func main() {
 
 	// Create router with dependencies
 	router := api.NewRouter(&api.RouterDependencies{
-		AuthService: authService,
-		SyncEngine:  syncEngine,
-		JobsFile:    jobsFile,
-		ShutdownCh:  shutdownCh,
-		SPAHandler:  spaHandler,
+		AuthService:   authService,
+		SyncEngine:    syncEngine,
+		JobsFile:      jobsFile,
+		AuditLog:      auditLog,
+		ConfigHistory: configHistory,
+		ShutdownCh:    shutdownCh,
+		SPAHandler:    spaHandler,
 	})
 	log.Info().Msg("Router initialized")
 
+	// Announce every config reload (file watcher or API write) on the event stream
+	config.OnReload(func(*config.Config) {
+		syncEngine.Events().Publish(services.EventConfigReloaded, map[string]any{"path": config.GetPath()})
+	})
+
 	// Start config watcher for hot-reload
 	if err := config.StartWatcher(func() {
 		log.Info().Msg("Configuration reloaded, clearing cache and reapplying retention rules")
+		configHistory.RecordFile(config.GetPath(), storage.ConfigSourceFile)
 		appCache.Clear()
 		syncEngine.ReapplyRetentionRules()
 	}); err != nil {
@@ -185,6 +260,21 @@ func main() {
 		log.Error().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Write pending API key last-used times
+	if err := apiKeysFile.Flush(); err != nil {
+		log.Error().Err(err).Msg("Failed to save API key usage")
+	}
+
+	// Write pending session last-seen times
+	if err := sessionsFile.Flush(); err != nil {
+		log.Error().Err(err).Msg("Failed to save session activity")
+	}
+
+	// Flush buffered spans
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Server stopped")
 }
 
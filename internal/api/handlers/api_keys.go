@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// APIKeysHandler handles API key management requests
+type APIKeysHandler struct {
+	auditor
+	authService *services.AuthService
+}
+
+// NewAPIKeysHandler creates a new APIKeysHandler
+func NewAPIKeysHandler(authService *services.AuthService) *APIKeysHandler {
+	return &APIKeysHandler{
+		authService: authService,
+	}
+}
+
+// CreateAPIKeyRequest represents the body of a create API key request
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyResponse is an API key as returned by the API (never includes the hash)
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Expired    bool       `json:"expired"`
+}
+
+// CreateAPIKeyResponse includes the secret, which is only ever shown here
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func newAPIKeyResponse(key storage.APIKey, now time.Time) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		Expired:    key.Expired(now),
+	}
+}
+
+// ListAPIKeys handles GET /api/api-keys
+func (h *APIKeysHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.authService.ListAPIKeys()
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, newAPIKeyResponse(key, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_keys": resp,
+		"total":    len(resp),
+	})
+}
+
+// CreateAPIKey handles POST /api/api-keys
+func (h *APIKeysHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	key, secret, err := h.authService.CreateAPIKey(req.Name, req.Scopes, req.ExpiresAt, requestActor(r))
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	resp := newAPIKeyResponse(key, time.Now())
+	h.audit(r, "api_key.create", key.Name, nil, resp)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateAPIKeyResponse{
+		APIKeyResponse: resp,
+		Key:            secret,
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/api-keys/{id}
+func (h *APIKeysHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	// Keep the key as it was for the audit log; a miss is reported by the revoke
+	var before *APIKeyResponse
+	if keys, err := h.authService.ListAPIKeys(); err == nil {
+		for _, key := range keys {
+			if key.ID == id {
+				resp := newAPIKeyResponse(key, time.Now())
+				before = &resp
+				break
+			}
+		}
+	}
+
+	if err := h.authService.RevokeAPIKey(id); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	log.Info().Str("key_id", id).Str("by", requestActor(r)).Msg("API key revoked via API")
+	target := id
+	if before != nil {
+		target = before.Name
+	}
+	h.audit(r, "api_key.revoke", target, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "API key revoked"})
+}
+
+// writeAPIKeyError maps API key management errors to HTTP responses
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrAPIKeyName),
+		errors.Is(err, services.ErrAPIKeyScopes),
+		errors.Is(err, services.ErrAPIKeyExpiry):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrAPIKeysUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("API key operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
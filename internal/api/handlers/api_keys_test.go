@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAPIKeysRouter mounts the API key routes so chi URL params resolve
+func newAPIKeysRouter(handler *APIKeysHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/api/api-keys", handler.ListAPIKeys)
+	r.Post("/api/api-keys", handler.CreateAPIKey)
+	r.Delete("/api/api-keys/{id}", handler.RevokeAPIKey)
+	return r
+}
+
+func setupAPIKeysHandler(t *testing.T) (*APIKeysHandler, *services.AuthService) {
+	t.Helper()
+
+	authService := services.NewAuthService(&config.Config{})
+	keys, err := storage.NewAPIKeysFile(t.TempDir())
+	require.NoError(t, err)
+	authService.SetAPIKeys(keys)
+
+	return NewAPIKeysHandler(authService), authService
+}
+
+func TestAPIKeysHandler(t *testing.T) {
+	handler, authService := setupAPIKeysHandler(t)
+	router := newAPIKeysRouter(handler)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var created CreateAPIKeyResponse
+	t.Run("creates a key and shows the secret once", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/api-keys", `{"name":"jellyfin plugin","scopes":["leaving-soon:read"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+
+		assert.Equal(t, "jellyfin plugin", created.Name)
+		assert.Equal(t, []string{utils.ScopeLeavingSoonRead}, created.Scopes)
+		assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+
+		key, ok := authService.AuthenticateAPIKey(created.Key)
+		require.True(t, ok)
+		assert.Equal(t, created.ID, key.ID)
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/api-keys", `{"name":"","scopes":["media:read"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/api-keys", `{"name":"x","scopes":["users:write"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/api-keys", `{"name":"x","scopes":[]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/api-keys", `{"name":"x","scopes":["media:read"],"expires_at":"`+past+`"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/api-keys", `not json`).Code)
+	})
+
+	t.Run("lists keys without secrets or hashes", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/api-keys", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), created.Key)
+		assert.NotContains(t, w.Body.String(), `"hash"`)
+
+		var resp struct {
+			APIKeys []APIKeyResponse `json:"api_keys"`
+			Total   int              `json:"total"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Equal(t, 1, resp.Total)
+		assert.Equal(t, created.ID, resp.APIKeys[0].ID)
+		assert.NotNil(t, resp.APIKeys[0].LastUsedAt)
+	})
+
+	t.Run("revokes a key", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/api/api-keys/"+created.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/api-keys/"+created.ID, "").Code)
+
+		_, ok := authService.AuthenticateAPIKey(created.Key)
+		assert.False(t, ok)
+	})
+}
+
+func TestAPIKeysHandler_Unavailable(t *testing.T) {
+	handler := NewAPIKeysHandler(services.NewAuthService(&config.Config{}))
+
+	w := httptest.NewRecorder()
+	handler.ListAPIKeys(w, httptest.NewRequest(http.MethodGet, "/api/api-keys", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
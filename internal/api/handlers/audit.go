@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// auditor is embedded in handlers whose actions are recorded in the audit log
+type auditor struct {
+	auditLog *services.AuditLog
+}
+
+// SetAuditLog sets where the handler records its actions. Without one,
+// nothing is recorded.
+func (a *auditor) SetAuditLog(auditLog *services.AuditLog) {
+	a.auditLog = auditLog
+}
+
+// audit records an action taken by the caller of r
+func (a *auditor) audit(r *http.Request, action, target string, before, after any) {
+	a.auditNote(r, action, target, "", before, after)
+}
+
+// auditNote records an action like audit, with a message for what the
+// changed fields alone do not make obvious
+func (a *auditor) auditNote(r *http.Request, action, target, message string, before, after any) {
+	a.auditLog.Record(storage.AuditEntry{
+		Actor:   requestActor(r),
+		IP:      clientIP(r),
+		Action:  action,
+		Target:  target,
+		Message: message,
+	}, before, after)
+}
+
+// clientIP returns the caller's address without the port. For requests from
+// a trusted proxy, the RealIP middleware has already replaced RemoteAddr with
+// the client named in X-Forwarded-For/X-Real-IP.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// mediaTarget names a media item in audit entries
+func mediaTarget(id, title string) string {
+	if title == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", title, id)
+}
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// AuditHandler serves the audit log
+type AuditHandler struct {
+	auditLog *services.AuditLog
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditLog *services.AuditLog) *AuditHandler {
+	return &AuditHandler{
+		auditLog: auditLog,
+	}
+}
+
+// ListAudit handles GET /api/audit
+// Query params: actor, action (exact or prefix, e.g. "rule"), target
+// (substring), outcome, since and until (RFC 3339), limit (default 100, max
+// 1000) and offset. format=csv or format=ndjson downloads every matching
+// entry instead of a page.
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.AuditFilter{
+		Actor:   query.Get("actor"),
+		Action:  query.Get("action"),
+		Target:  query.Get("target"),
+		Outcome: query.Get("outcome"),
+	}
+	for param, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Invalid %s: use RFC 3339, e.g. 2025-01-31T00:00:00Z", param)})
+			return
+		}
+		*dst = t
+	}
+
+	entries := h.auditLog.Query(filter)
+
+	switch format := query.Get("format"); format {
+	case "csv":
+		writeAuditCSV(w, entries)
+		return
+	case "ndjson":
+		writeAuditNDJSON(w, entries)
+		return
+	case "", "json":
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid format: use json, csv or ndjson"})
+		return
+	}
+
+	limit := defaultAuditPageSize
+	if s := query.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			limit = min(n, maxAuditPageSize)
+		}
+	}
+	offset := 0
+	if s := query.Get("offset"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			offset = n
+		}
+	}
+
+	total := len(entries)
+	page := entries[min(offset, total):min(offset+limit, total)]
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": page,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// writeAuditCSV writes entries as a CSV download, with changes as JSON
+func writeAuditCSV(w http.ResponseWriter, entries []storage.AuditEntry) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"timestamp", "actor", "ip", "action", "target", "outcome", "message", "changes"})
+	for _, entry := range entries {
+		changes := ""
+		if len(entry.Changes) > 0 {
+			data, _ := json.Marshal(entry.Changes)
+			changes = string(data)
+		}
+		cw.Write([]string{
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			csvSafe(entry.Actor),
+			entry.IP,
+			entry.Action,
+			csvSafe(entry.Target),
+			entry.Outcome,
+			csvSafe(entry.Message),
+			changes,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Debug().Err(err).Msg("Failed to write audit CSV export")
+	}
+}
+
+// writeAuditNDJSON writes entries as newline-delimited JSON
+func writeAuditNDJSON(w http.ResponseWriter, entries []storage.AuditEntry) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			log.Debug().Err(err).Msg("Failed to write audit NDJSON export")
+			return
+		}
+	}
+}
+
+// csvSafe keeps user-controlled text (usernames, titles, rule names) from
+// being evaluated as a formula when the export is opened in a spreadsheet
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsAny(s[:1], "=+-@\t\r") {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuditLog returns an audit log backed by a temp directory
+func newTestAuditLog(t *testing.T) *services.AuditLog {
+	t.Helper()
+	file, err := storage.NewAuditLogFile(t.TempDir())
+	require.NoError(t, err)
+	return services.NewAuditLog(file)
+}
+
+// auditListResponse is the body of GET /api/audit
+type auditListResponse struct {
+	Entries []storage.AuditEntry `json:"entries"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+func seedAuditEntries(auditLog *services.AuditLog) {
+	auditLog.Record(storage.AuditEntry{Actor: "alice", Action: "rule.create", Target: "old-movies"}, nil, map[string]string{"name": "old-movies"})
+	auditLog.Record(storage.AuditEntry{Actor: "bob", Action: "media.exclude", Target: "=cmd (movie-1)"}, nil, nil)
+	auditLog.Record(storage.AuditEntry{Actor: "alice", Action: "rule.delete", Target: "old-movies"}, map[string]string{"name": "old-movies"}, nil)
+	auditLog.Record(storage.AuditEntry{Actor: "mallory", Action: "auth.login", Target: "mallory", Outcome: storage.AuditOutcomeFailure, Message: "invalid credentials"}, nil, nil)
+}
+
+func TestAuditHandler_ListAudit(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+	seedAuditEntries(auditLog)
+	handler := NewAuditHandler(auditLog)
+
+	list := func(t *testing.T, query string) auditListResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ListAudit(rec, httptest.NewRequest(http.MethodGet, "/api/audit?"+query, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp auditListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("returns newest first", func(t *testing.T) {
+		resp := list(t, "")
+		require.Equal(t, 4, resp.Total)
+		assert.Equal(t, "auth.login", resp.Entries[0].Action)
+		assert.Equal(t, "rule.create", resp.Entries[3].Action)
+		assert.Equal(t, defaultAuditPageSize, resp.Limit)
+	})
+
+	t.Run("filters by actor and action prefix", func(t *testing.T) {
+		resp := list(t, "actor=alice&action=rule")
+		require.Equal(t, 2, resp.Total)
+		for _, entry := range resp.Entries {
+			assert.Equal(t, "alice", entry.Actor)
+		}
+		assert.Equal(t, 1, list(t, "action=rule.delete").Total)
+		assert.Equal(t, 0, list(t, "action=rul").Total, "a prefix must end at a dot")
+	})
+
+	t.Run("filters by target and outcome", func(t *testing.T) {
+		assert.Equal(t, 1, list(t, "target=MOVIE-1").Total)
+		resp := list(t, "outcome=failure")
+		require.Equal(t, 1, resp.Total)
+		assert.Equal(t, "invalid credentials", resp.Entries[0].Message)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		resp := list(t, "limit=2&offset=3")
+		assert.Equal(t, 4, resp.Total)
+		assert.Len(t, resp.Entries, 1)
+		assert.Empty(t, list(t, "offset=10").Entries)
+	})
+
+	t.Run("rejects bad parameters", func(t *testing.T) {
+		for _, query := range []string{"since=yesterday", "until=2025-01-01", "format=xml"} {
+			rec := httptest.NewRecorder()
+			handler.ListAudit(rec, httptest.NewRequest(http.MethodGet, "/api/audit?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+}
+
+func TestAuditHandler_Export(t *testing.T) {
+	auditLog := newTestAuditLog(t)
+	seedAuditEntries(auditLog)
+	handler := NewAuditHandler(auditLog)
+
+	t.Run("csv", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ListAudit(rec, httptest.NewRequest(http.MethodGet, "/api/audit?format=csv&actor=bob", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/csv")
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "actor", records[0][1])
+		assert.Equal(t, "'=cmd (movie-1)", records[1][4], "formula-like targets must be escaped")
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ListAudit(rec, httptest.NewRequest(http.MethodGet, "/api/audit?format=ndjson&limit=1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		lines := 0
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var entry storage.AuditEntry
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			lines++
+		}
+		assert.Equal(t, 4, lines, "exports ignore paging")
+	})
+}
+
+func TestCSVSafe(t *testing.T) {
+	assert.Equal(t, "alice", csvSafe("alice"))
+	assert.Equal(t, "", csvSafe(""))
+	for _, s := range []string{"=1+1", "+1", "-1", "@SUM(A1)", "\tx"} {
+		assert.True(t, strings.HasPrefix(csvSafe(s), "'"), s)
+	}
+}
+
+func TestRulesHandler_ToggleRuleIsAudited(t *testing.T) {
+	loadTestConfig(t)
+	seedRules(t, []config.AdvancedRule{
+		{Name: "rule-a", Type: "tag", Enabled: true, Tag: "ta", Retention: "30d"},
+	})
+	auditLog := newTestAuditLog(t)
+	handler := NewRulesHandler()
+	handler.SetAuditLog(auditLog)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/rules/rule-a/toggle", strings.NewReader(`{"enabled":false}`))
+	req = withRuleName(req, "rule-a")
+	rec := httptest.NewRecorder()
+	handler.ToggleRule(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	entries := auditLog.Query(services.AuditFilter{})
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "rule.toggle", entry.Action)
+	assert.Equal(t, "rule-a", entry.Target)
+	assert.Equal(t, "api_key", entry.Actor)
+	assert.Equal(t, "192.0.2.1", entry.IP)
+	assert.Equal(t, storage.AuditOutcomeSuccess, entry.Outcome)
+	assert.Equal(t, []storage.AuditChange{{Field: "enabled", Before: true, After: false}}, entry.Changes)
+}
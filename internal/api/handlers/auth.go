@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
 	"github.com/rs/zerolog/log"
 )
@@ -19,6 +21,7 @@ const maxLoginBodyBytes = 1 << 20 // 1 MiB
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
+	auditor
 	authService *services.AuthService
 }
 
@@ -35,10 +38,39 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-// LoginResponse represents the login response
+// LoginResponse represents the login response. ExpiresIn is the lifetime
+// of Token in seconds; RefreshToken renews it through POST /api/auth/refresh
+// when sessions are enabled. RecoveryCodes is only set when the sign-in
+// completed a two-factor enrollment.
 type LoginResponse struct {
-	Token    string `json:"token"`
-	Username string `json:"username"`
+	Token         string   `json:"token"`
+	Username      string   `json:"username"`
+	Role          string   `json:"role,omitempty"`
+	ExpiresIn     int      `json:"expires_in,omitempty"`
+	RefreshToken  string   `json:"refresh_token,omitempty"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+func newLoginResponse(result services.LoginResult) LoginResponse {
+	return LoginResponse{
+		Token:         result.Token,
+		Username:      result.Username,
+		Role:          result.Role,
+		ExpiresIn:     int(time.Until(result.ExpiresAt).Seconds()),
+		RefreshToken:  result.RefreshToken,
+		RecoveryCodes: result.RecoveryCodes,
+	}
+}
+
+// TwoFactorChallengeResponse is returned instead of a token when the account
+// signs in with a second factor. The challenge is sent back with a code to
+// POST /api/auth/login/2fa. Enrollment is set when the account must enroll
+// first; the code then comes from the newly added authenticator entry.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool                     `json:"two_factor_required"`
+	Challenge         string                   `json:"challenge"`
+	Username          string                   `json:"username"`
+	Enrollment        *services.TOTPEnrollment `json:"enrollment,omitempty"`
 }
 
 // ErrorResponse represents an error response
@@ -68,42 +100,108 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.authService.Login(req.Username, req.Password)
+	result, err := h.authService.Login(r.Context(), req.Username, req.Password, clientInfo(r))
 	if err != nil {
-		if errors.Is(err, services.ErrInvalidCredentials) {
-			log.Warn().Str("username", req.Username).Msg("Failed login attempt")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid username or password"})
-			return
-		}
-		// Token generation failure is a server error, not a client error
-		log.Error().Err(err).Msg("Failed to generate token")
+		h.auditLogin(r, "password", req.Username, "", err)
+		writeLoginError(w, req.Username, err)
+		return
+	}
+
+	if result.Challenge != "" {
+		log.Info().Str("username", result.Username).Bool("enrolling", result.Enrollment != nil).Msg("Password accepted, waiting for second factor")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Internal server error"})
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			Challenge:         result.Challenge,
+			Username:          result.Username,
+			Enrollment:        result.Enrollment,
+		})
 		return
 	}
 
-	// Set httpOnly cookie for the web UI. The token stays out of JavaScript
-	// and localStorage, and EventSource connections pick it up automatically.
-	setAuthCookie(w, token)
+	h.auditLogin(r, "password", result.Username, result.Role, nil)
+	h.completeLogin(w, result)
+}
+
+// completeLogin sets the auth cookies and returns the tokens
+func (h *AuthHandler) completeLogin(w http.ResponseWriter, result services.LoginResult) {
+	// Set httpOnly cookies for the web UI. The tokens stay out of JavaScript
+	// and localStorage, and EventSource connections pick them up automatically.
+	setSessionCookies(w, result)
 
-	log.Info().Str("username", req.Username).Msg("Successful login")
+	log.Info().Str("username", result.Username).Str("role", result.Role).Msg("Successful login")
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(LoginResponse{Token: token, Username: req.Username})
+	json.NewEncoder(w).Encode(newLoginResponse(result))
+}
+
+// writeLoginError maps sign-in errors to HTTP responses. Lockouts carry a
+// Retry-After header.
+func writeLoginError(w http.ResponseWriter, username string, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	var locked *services.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		log.Warn().Str("username", username).Msg("Login refused while locked out")
+		w.Header().Set("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+		status = http.StatusTooManyRequests
+		message = "Too many failed sign-in attempts, try again later"
+	case errors.Is(err, services.ErrInvalidCredentials):
+		log.Warn().Str("username", username).Msg("Failed login attempt")
+		status = http.StatusUnauthorized
+		message = "Invalid username or password"
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		log.Warn().Str("username", username).Msg("Failed two-factor code")
+		status = http.StatusUnauthorized
+		message = "Invalid two-factor code"
+	case errors.Is(err, services.ErrLoginChallenge),
+		errors.Is(err, services.ErrTwoFactorNotEnrolling):
+		status = http.StatusUnauthorized
+		message = services.ErrLoginChallenge.Error()
+	case errors.Is(err, services.ErrLoginProviderUnavailable):
+		status = http.StatusServiceUnavailable
+		message = "Login provider is unavailable, try again later"
+	default:
+		// Token generation failure is a server error, not a client error
+		log.Error().Err(err).Msg("Failed to complete login")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+}
+
+// auditLogin records a sign-in attempt. The actor is the account signing in,
+// which is empty when a single sign-on attempt fails before it is known.
+func (h *AuthHandler) auditLogin(r *http.Request, method, username, role string, err error) {
+	entry := storage.AuditEntry{
+		Actor:  username,
+		IP:     clientIP(r),
+		Action: "auth.login",
+		Target: username,
+	}
+	after := map[string]string{"method": method}
+	if err != nil {
+		entry.Outcome = storage.AuditOutcomeFailure
+		entry.Message = err.Error()
+	} else {
+		after["role"] = role
+	}
+	h.auditLog.Record(entry, nil, after)
 }
 
 // Me handles GET /api/auth/me
-// Returns the authenticated username, or 401 when no valid session exists.
-// When authentication is disabled, the configured admin username is returned.
+// Returns the authenticated username and role, or 401 when no valid session
+// exists. When authentication is disabled, the configured admin username is
+// returned with the admin role.
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	cfg := config.Get()
 	if cfg != nil && cfg.Admin.DisableAuth {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(LoginResponse{Username: cfg.Admin.Username})
+		json.NewEncoder(w).Encode(LoginResponse{Username: cfg.Admin.Username, Role: utils.RoleAdmin})
 		return
 	}
 
@@ -116,7 +214,7 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	}
 
 	claims, err := h.authService.ValidateToken(token)
-	if err != nil {
+	if err != nil || claims.Role == "" {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid or expired token"})
@@ -125,34 +223,27 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(LoginResponse{Username: claims.Username})
+	json.NewEncoder(w).Encode(LoginResponse{Username: claims.Username, Role: claims.Role})
 }
 
 // Logout handles POST /api/auth/logout
-// Clears the auth cookie client-side.
+// Ends the server-side session, if any, and clears the auth cookies.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     middleware.AuthCookieName,
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   -1,
-		Expires:  time.Unix(1, 0),
-	})
+	var sessionID, refreshToken string
+	if token := middleware.GetTokenFromRequest(r); token != "" {
+		if claims, err := utils.ValidateToken(token); err == nil {
+			sessionID = claims.SessionID
+		}
+	}
+	if c, err := r.Cookie(refreshCookieName); err == nil {
+		refreshToken = c.Value
+	}
+	if err := h.authService.EndSession(sessionID, refreshToken); err != nil {
+		log.Warn().Err(err).Msg("Failed to end session on logout")
+	}
+
+	clearAuthCookies(w)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
 }
-
-// setAuthCookie writes the JWT as an httpOnly, SameSite=Lax cookie.
-func setAuthCookie(w http.ResponseWriter, token string) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     middleware.AuthCookieName,
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   int(utils.GetJWTExpiry().Seconds()),
-	})
-}
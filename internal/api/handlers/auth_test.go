@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -297,10 +298,11 @@ func TestAuthHandler_Login_RejectsOversizedBody(t *testing.T) {
 func TestAuthHandler_Me(t *testing.T) {
 	handler, _ := setupAuthHandler(t)
 
-	token, err := handler.authService.Login("admin", "testpassword")
+	result, err := handler.authService.Login(context.Background(), "admin", "testpassword", services.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
+	token := result.Token
 
 	t.Run("valid cookie returns username", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
@@ -317,6 +319,9 @@ func TestAuthHandler_Me(t *testing.T) {
 		if resp.Username != "admin" {
 			t.Errorf("Expected username admin, got %q", resp.Username)
 		}
+		if resp.Role != utils.RoleAdmin {
+			t.Errorf("Expected role admin, got %q", resp.Role)
+		}
 	})
 
 	t.Run("valid bearer header returns username", func(t *testing.T) {
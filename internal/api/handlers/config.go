@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"os"
@@ -14,7 +18,10 @@ import (
 
 // ConfigHandler handles configuration management requests
 type ConfigHandler struct {
-	syncEngine *services.SyncEngine
+	auditor
+	configWriter
+	syncEngine  *services.SyncEngine
+	authService *services.AuthService
 }
 
 // NewConfigHandler creates a new ConfigHandler
@@ -24,6 +31,12 @@ func NewConfigHandler(syncEngine *services.SyncEngine) *ConfigHandler {
 	}
 }
 
+// SetAuthService sets the service whose sessions are ended when a restore
+// changes the admin credentials. Without one, sessions are left alone.
+func (h *ConfigHandler) SetAuthService(authService *services.AuthService) {
+	h.authService = authService
+}
+
 // SanitizedConfig represents a sanitized version of the config (without passwords)
 type SanitizedConfig struct {
 	Admin         SanitizedAdminConfig        `json:"admin"`
@@ -55,28 +68,31 @@ type SanitizedIntegrationsConfig struct {
 
 // SanitizedBaseIntegrationConfig holds sanitized base integration config
 type SanitizedBaseIntegrationConfig struct {
-	Enabled   bool   `json:"enabled"`
-	URL       string `json:"url"`
-	HasAPIKey bool   `json:"has_api_key"`
-	Timeout   string `json:"timeout"`
+	Enabled      bool   `json:"enabled"`
+	URL          string `json:"url"`
+	HasAPIKey    bool   `json:"has_api_key"`
+	APIKeySource string `json:"api_key_source,omitempty"` // "file" or "env" when given as a secret reference
+	Timeout      string `json:"timeout"`
 }
 
 // SanitizedStreamystatsConfig holds sanitized Streamystats config (base + server_id)
 type SanitizedStreamystatsConfig struct {
-	Enabled     bool   `json:"enabled"`
-	URL         string `json:"url"`
-	HasAPIKey   bool   `json:"has_api_key"`
-	Timeout     string `json:"timeout"`
-	HasServerID bool   `json:"has_server_id"`
-	ServerID    string `json:"server_id"`
+	Enabled      bool   `json:"enabled"`
+	URL          string `json:"url"`
+	HasAPIKey    bool   `json:"has_api_key"`
+	APIKeySource string `json:"api_key_source,omitempty"`
+	Timeout      string `json:"timeout"`
+	HasServerID  bool   `json:"has_server_id"`
+	ServerID     string `json:"server_id"`
 }
 
 // SanitizedJellyfinConfig holds sanitized Jellyfin config
 type SanitizedJellyfinConfig struct {
-	Enabled   bool   `json:"enabled"`
-	URL       string `json:"url"`
-	HasAPIKey bool   `json:"has_api_key"`
-	Timeout   string `json:"timeout"`
+	Enabled      bool   `json:"enabled"`
+	URL          string `json:"url"`
+	HasAPIKey    bool   `json:"has_api_key"`
+	APIKeySource string `json:"api_key_source,omitempty"`
+	Timeout      string `json:"timeout"`
 }
 
 // GetConfig handles GET /api/config
@@ -90,8 +106,15 @@ func (h *ConfigHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Sanitize config (remove passwords, keep the admin API key for display)
-	sanitized := SanitizedConfig{
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sanitizeConfig(cfg))
+}
+
+// sanitizeConfig removes passwords and service API keys, keeping the admin
+// API key for display
+func sanitizeConfig(cfg *config.Config) SanitizedConfig {
+	return SanitizedConfig{
 		Admin: SanitizedAdminConfig{
 			Username:    cfg.Admin.Username,
 			DisableAuth: cfg.Admin.DisableAuth,
@@ -104,49 +127,111 @@ func (h *ConfigHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 		AdvancedRules: cfg.AdvancedRules,
 		Integrations: SanitizedIntegrationsConfig{
 			Jellyfin: SanitizedJellyfinConfig{
-				Enabled:   cfg.Integrations.Jellyfin.Enabled,
-				URL:       cfg.Integrations.Jellyfin.URL,
-				HasAPIKey: cfg.Integrations.Jellyfin.APIKey != "",
-				Timeout:   cfg.Integrations.Jellyfin.Timeout,
+				Enabled:      cfg.Integrations.Jellyfin.Enabled,
+				URL:          cfg.Integrations.Jellyfin.URL,
+				HasAPIKey:    cfg.Integrations.Jellyfin.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.jellyfin.api_key"),
+				Timeout:      cfg.Integrations.Jellyfin.Timeout,
 			},
 			Radarr: SanitizedBaseIntegrationConfig{
-				Enabled:   cfg.Integrations.Radarr.Enabled,
-				URL:       cfg.Integrations.Radarr.URL,
-				HasAPIKey: cfg.Integrations.Radarr.APIKey != "",
-				Timeout:   cfg.Integrations.Radarr.Timeout,
+				Enabled:      cfg.Integrations.Radarr.Enabled,
+				URL:          cfg.Integrations.Radarr.URL,
+				HasAPIKey:    cfg.Integrations.Radarr.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.radarr.api_key"),
+				Timeout:      cfg.Integrations.Radarr.Timeout,
 			},
 			Sonarr: SanitizedBaseIntegrationConfig{
-				Enabled:   cfg.Integrations.Sonarr.Enabled,
-				URL:       cfg.Integrations.Sonarr.URL,
-				HasAPIKey: cfg.Integrations.Sonarr.APIKey != "",
-				Timeout:   cfg.Integrations.Sonarr.Timeout,
+				Enabled:      cfg.Integrations.Sonarr.Enabled,
+				URL:          cfg.Integrations.Sonarr.URL,
+				HasAPIKey:    cfg.Integrations.Sonarr.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.sonarr.api_key"),
+				Timeout:      cfg.Integrations.Sonarr.Timeout,
 			},
 			Jellyseerr: SanitizedBaseIntegrationConfig{
-				Enabled:   cfg.Integrations.Jellyseerr.Enabled,
-				URL:       cfg.Integrations.Jellyseerr.URL,
-				HasAPIKey: cfg.Integrations.Jellyseerr.APIKey != "",
-				Timeout:   cfg.Integrations.Jellyseerr.Timeout,
+				Enabled:      cfg.Integrations.Jellyseerr.Enabled,
+				URL:          cfg.Integrations.Jellyseerr.URL,
+				HasAPIKey:    cfg.Integrations.Jellyseerr.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.jellyseerr.api_key"),
+				Timeout:      cfg.Integrations.Jellyseerr.Timeout,
 			},
 			Jellystat: SanitizedBaseIntegrationConfig{
-				Enabled:   cfg.Integrations.Jellystat.Enabled,
-				URL:       cfg.Integrations.Jellystat.URL,
-				HasAPIKey: cfg.Integrations.Jellystat.APIKey != "",
-				Timeout:   cfg.Integrations.Jellystat.Timeout,
+				Enabled:      cfg.Integrations.Jellystat.Enabled,
+				URL:          cfg.Integrations.Jellystat.URL,
+				HasAPIKey:    cfg.Integrations.Jellystat.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.jellystat.api_key"),
+				Timeout:      cfg.Integrations.Jellystat.Timeout,
 			},
 			Streamystats: SanitizedStreamystatsConfig{
-				Enabled:     cfg.Integrations.Streamystats.Enabled,
-				URL:         cfg.Integrations.Streamystats.URL,
-				HasAPIKey:   cfg.Integrations.Streamystats.APIKey != "",
-				Timeout:     cfg.Integrations.Streamystats.Timeout,
-				HasServerID: cfg.Integrations.Streamystats.ServerID != "",
-				ServerID:    cfg.Integrations.Streamystats.ServerID,
+				Enabled:      cfg.Integrations.Streamystats.Enabled,
+				URL:          cfg.Integrations.Streamystats.URL,
+				HasAPIKey:    cfg.Integrations.Streamystats.APIKey != "",
+				APIKeySource: config.SecretSource(cfg, "integrations.streamystats.api_key"),
+				Timeout:      cfg.Integrations.Streamystats.Timeout,
+				HasServerID:  cfg.Integrations.Streamystats.ServerID != "",
+				ServerID:     cfg.Integrations.Streamystats.ServerID,
 			},
 		},
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(sanitized)
+// configAuditView is the config as recorded in the audit log: the sanitized
+// config without the admin API key, plus a fingerprint per secret that
+// changes whenever the secret does without revealing it
+type configAuditView struct {
+	SanitizedConfig
+	Secrets map[string]string `json:"secrets,omitempty"`
+}
+
+// adminCredentialFields are the config secrets that sign in as the admin
+var adminCredentialFields = []string{"admin.password", "admin.api_key"}
+
+// changedAdminCredentials returns the admin credentials whose fingerprints
+// differ between two views
+func changedAdminCredentials(before, after configAuditView) []string {
+	var changed []string
+	for _, field := range adminCredentialFields {
+		if before.Secrets[field] != after.Secrets[field] {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+// auditFingerprintKey keys secret fingerprints. It is random per process, so
+// fingerprints in the audit log cannot be used to guess a secret offline;
+// both sides of a diff are always computed in the same process.
+var auditFingerprintKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+func newConfigAuditView(cfg *config.Config) configAuditView {
+	view := configAuditView{
+		SanitizedConfig: sanitizeConfig(cfg),
+		Secrets:         make(map[string]string),
+	}
+	view.Admin.APIKey = ""
+
+	secrets := map[string]string{
+		"admin.password":                    cfg.Admin.Password,
+		"admin.api_key":                     cfg.Admin.APIKey,
+		"integrations.jellyfin.api_key":     cfg.Integrations.Jellyfin.APIKey,
+		"integrations.radarr.api_key":       cfg.Integrations.Radarr.APIKey,
+		"integrations.sonarr.api_key":       cfg.Integrations.Sonarr.APIKey,
+		"integrations.jellyseerr.api_key":   cfg.Integrations.Jellyseerr.APIKey,
+		"integrations.jellystat.api_key":    cfg.Integrations.Jellystat.APIKey,
+		"integrations.streamystats.api_key": cfg.Integrations.Streamystats.APIKey,
+	}
+	for field, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, auditFingerprintKey)
+		mac.Write([]byte(secret))
+		view.Secrets[field] = "set:" + hex.EncodeToString(mac.Sum(nil))[:8]
+	}
+	return view
 }
 
 // UpdateConfigRequest represents a config update request
@@ -237,9 +322,12 @@ func (h *ConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	oldMovieRetention := cfg.Rules.MovieRetention
 	oldTVRetention := cfg.Rules.TVRetention
 
-	// Capture old sync interval values for scheduler restart detection
+	// Capture old sync schedule values for scheduler restart detection
 	oldFullInterval := cfg.Sync.FullInterval
 	oldIncrInterval := cfg.Sync.IncrementalInterval
+	oldFullCron := cfg.Sync.FullCron
+	oldIncrCron := cfg.Sync.IncrementalCron
+	oldDeletionCron := cfg.Sync.DeletionCron
 
 	// Update fields if provided
 	if req.Admin != nil {
@@ -381,7 +469,7 @@ func (h *ConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	log.Info().Int("leaving_soon_days", newCfg.App.LeavingSoonDays).Msg("About to write config to file")
 
 	// Write to config file
-	if err := writeConfigToFile(newCfg); err != nil {
+	if err := h.writeConfig(r, newCfg); err != nil {
 		log.Error().Err(err).Msg("Failed to write config to file")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -430,15 +518,20 @@ func (h *ConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		}()
 	}
 
-	// Restart sync scheduler if intervals changed
+	// Restart sync scheduler if intervals or cron schedules changed
 	if h.syncEngine != nil && req.Sync != nil {
-		if req.Sync.FullInterval != oldFullInterval || req.Sync.IncrementalInterval != oldIncrInterval {
+		if req.Sync.FullInterval != oldFullInterval || req.Sync.IncrementalInterval != oldIncrInterval ||
+			req.Sync.FullCron != oldFullCron || req.Sync.IncrementalCron != oldIncrCron ||
+			req.Sync.DeletionCron != oldDeletionCron {
 			log.Info().
 				Int("old_full_interval", oldFullInterval).
 				Int("new_full_interval", req.Sync.FullInterval).
 				Int("old_incr_interval", oldIncrInterval).
 				Int("new_incr_interval", req.Sync.IncrementalInterval).
-				Msg("Sync intervals changed, restarting scheduler")
+				Str("full_cron", req.Sync.FullCron).
+				Str("incr_cron", req.Sync.IncrementalCron).
+				Str("deletion_cron", req.Sync.DeletionCron).
+				Msg("Sync schedule changed, restarting scheduler")
 			go func() {
 				defer recoverPanic("restart sync scheduler")
 				if err := h.syncEngine.RestartScheduler(); err != nil {
@@ -449,13 +542,28 @@ func (h *ConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Msg("Configuration updated successfully")
+	h.audit(r, "config.update", "config", newConfigAuditView(cfg), newConfigAuditView(newCfg))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Configuration updated successfully"})
 }
 
-// writeConfigToFile writes the config to the YAML file
-func writeConfigToFile(cfg *config.Config) error {
+// marshalConfig renders cfg as config file content. Secrets that were
+// loaded from a file or ${VAR} reference are written as that reference.
+func marshalConfig(cfg *config.Config) ([]byte, error) {
+	data, err := yaml.Marshal(config.WithSecretReferences(cfg))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal config to YAML")
+		return nil, err
+	}
+
+	// Add a header comment
+	header := "# Prunarr Configuration\n# Generated by Prunarr Web UI\n\n"
+	return append([]byte(header), data...), nil
+}
+
+// writeConfigFile writes content to the config file
+func writeConfigFile(content []byte) error {
 	// Get the config file path from the loaded config
 	configPath := config.GetPath()
 	if configPath == "" {
@@ -465,17 +573,6 @@ func writeConfigToFile(cfg *config.Config) error {
 
 	log.Info().Str("path", configPath).Msg("Writing config to file")
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(cfg)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to marshal config to YAML")
-		return err
-	}
-
-	// Add a header comment
-	header := "# Prunarr Configuration\n# Generated by Prunarr Web UI\n\n"
-	content := header + string(data)
-
 	// Write to file (preserve original permissions or use 0600 for new files)
 	info, err := os.Stat(configPath)
 	perm := os.FileMode(0600)
@@ -490,7 +587,7 @@ func writeConfigToFile(cfg *config.Config) error {
 		return err
 	}
 
-	if err := os.WriteFile(configPath, []byte(content), perm); err != nil {
+	if err := os.WriteFile(configPath, content, perm); err != nil {
 		log.Error().Err(err).Str("path", configPath).Msg("Failed to write config file")
 		return err
 	}
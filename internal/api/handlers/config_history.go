@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// configWriter is embedded in handlers that write the config file. Every
+// write is recorded in the config history.
+type configWriter struct {
+	history *services.ConfigHistory
+}
+
+// SetConfigHistory sets where written config versions are recorded. Without
+// one, nothing is recorded.
+func (c *configWriter) SetConfigHistory(history *services.ConfigHistory) {
+	c.history = history
+}
+
+// writeConfig writes cfg to the config file on behalf of the caller of r
+func (c *configWriter) writeConfig(r *http.Request, cfg *config.Config) error {
+	content, err := marshalConfig(cfg)
+	if err != nil {
+		return err
+	}
+	return c.writeConfigContent(r, content, storage.ConfigVersion{Source: storage.ConfigSourceAPI})
+}
+
+// writeConfigContent writes content to the config file. The version is
+// recorded before writing, so the file watcher finds it already recorded
+// and does not attribute the change to an outside edit.
+func (c *configWriter) writeConfigContent(r *http.Request, content []byte, meta storage.ConfigVersion) error {
+	meta.Actor = requestActor(r)
+	version, recorded := c.history.Record(content, meta)
+	if err := writeConfigFile(content); err != nil {
+		if recorded {
+			c.history.Discard(version.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// ConfigVersionResponse describes a version in the config history
+type ConfigVersionResponse struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	Source       string    `json:"source"`
+	Actor        string    `json:"actor,omitempty"`
+	RestoredFrom string    `json:"restored_from,omitempty"`
+	Current      bool      `json:"current"` // the config file has this content now
+}
+
+func newConfigVersionResponse(version storage.ConfigVersion, currentHash string) ConfigVersionResponse {
+	return ConfigVersionResponse{
+		ID:           version.ID,
+		CreatedAt:    version.CreatedAt,
+		Source:       version.Source,
+		Actor:        version.Actor,
+		RestoredFrom: version.RestoredFrom,
+		Current:      version.Hash == currentHash,
+	}
+}
+
+// ListConfigHistory handles GET /api/config/history. Versions are listed most
+// recent first, without their content.
+func (h *ConfigHandler) ListConfigHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.history.List()
+	if err != nil {
+		writeConfigHistoryError(w, err)
+		return
+	}
+
+	currentHash := ""
+	if current, err := os.ReadFile(config.GetPath()); err == nil {
+		currentHash = services.ConfigHash(current)
+	}
+
+	resp := make([]ConfigVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		resp = append(resp, newConfigVersionResponse(version, currentHash))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"versions": resp,
+		"total":    len(resp),
+	})
+}
+
+// DiffConfigVersion handles GET /api/config/history/{id}/diff. It lists the
+// settings restoring the version would change: "before" is the current value
+// and "after" the value in the version. Secrets are shown as fingerprints.
+func (h *ConfigHandler) DiffConfigVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := h.history.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		writeConfigHistoryError(w, err)
+		return
+	}
+
+	current, err := os.ReadFile(config.GetPath())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read config file")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to read configuration"})
+		return
+	}
+
+	changes, err := h.history.Diff(version, current)
+	if err != nil {
+		writeConfigHistoryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"version": newConfigVersionResponse(version, services.ConfigHash(current)),
+		"changes": changes,
+		"total":   len(changes),
+	})
+}
+
+// RestoreConfigVersion handles POST /api/config/history/{id}/restore. The
+// version is validated before it is written back, then the config is
+// reloaded and retention rules are re-applied. If the admin password or API
+// key changes, the admin's sessions end and the audit entry says so.
+func (h *ConfigHandler) RestoreConfigVersion(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	version, err := h.history.Get(id)
+	if err != nil {
+		writeConfigHistoryError(w, err)
+		return
+	}
+
+	cfg := config.Get()
+	if cfg == nil {
+		log.Error().Msg("Config not initialized")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Config not initialized"})
+		return
+	}
+
+	restored, err := config.Parse([]byte(version.Content))
+	if err == nil {
+		err = config.Validate(restored)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("Config version failed validation")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	meta := storage.ConfigVersion{Source: storage.ConfigSourceRestore, RestoredFrom: id}
+	if err := h.writeConfigContent(r, []byte(version.Content), meta); err != nil {
+		log.Error().Err(err).Msg("Failed to write config to file")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to save configuration"})
+		return
+	}
+
+	if err := config.Reload(); err != nil {
+		log.Error().Err(err).Msg("Failed to reload config")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to reload configuration"})
+		return
+	}
+	newCfg := config.Get()
+
+	if h.syncEngine != nil {
+		log.Info().Str("id", id).Msg("Config version restored, re-applying retention rules")
+		go func() {
+			defer recoverPanic("reapply retention rules")
+			h.syncEngine.ReapplyRetentionRules()
+		}()
+
+		if syncScheduleChanged(cfg.Sync, newCfg.Sync) {
+			go func() {
+				defer recoverPanic("restart sync scheduler")
+				if err := h.syncEngine.RestartScheduler(); err != nil {
+					log.Error().Err(err).Msg("Failed to restart sync scheduler")
+				}
+			}()
+		}
+	}
+
+	before, after := newConfigAuditView(cfg), newConfigAuditView(newCfg)
+	var message string
+	if changed := changedAdminCredentials(before, after); len(changed) > 0 {
+		// The restored credential may be one that was replaced because it
+		// leaked; end the admin's sessions as a password change does
+		message = "admin credentials changed: " + strings.Join(changed, ", ")
+		log.Warn().Str("id", id).Strs("credentials", changed).Msg("Restored config changes admin credentials, ending admin sessions")
+		if h.authService != nil {
+			h.authService.RevokeUserSessions(cfg.Admin.Username)
+			if newCfg.Admin.Username != cfg.Admin.Username {
+				h.authService.RevokeUserSessions(newCfg.Admin.Username)
+			}
+		}
+	}
+	h.auditNote(r, "config.restore", "config", message, before, after)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Configuration restored successfully",
+		"id":      id,
+	})
+}
+
+// syncScheduleChanged reports whether the sync scheduler must be restarted
+// to apply next
+func syncScheduleChanged(prev, next config.SyncConfig) bool {
+	return prev.FullInterval != next.FullInterval ||
+		prev.IncrementalInterval != next.IncrementalInterval ||
+		prev.FullCron != next.FullCron ||
+		prev.IncrementalCron != next.IncrementalCron ||
+		prev.DeletionCron != next.DeletionCron
+}
+
+// writeConfigHistoryError maps config history errors to HTTP responses
+func writeConfigHistoryError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrConfigVersionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrConfigHistoryUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("Config history operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupConfigHistoryHandler loads a test config and returns a ConfigHandler
+// recording into a fresh history that starts with the loaded file
+func setupConfigHistoryHandler(t *testing.T) (*ConfigHandler, *services.ConfigHistory) {
+	t.Helper()
+	path := loadTestConfig(t)
+	file, err := storage.NewConfigHistoryFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	history := services.NewConfigHistory(file)
+	history.RecordFile(path, storage.ConfigSourceStartup)
+
+	handler := NewConfigHandler(nil)
+	handler.SetConfigHistory(history)
+	return handler, history
+}
+
+// withVersionID attaches a chi route context so URLParam("id") resolves
+func withVersionID(r *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestConfigHandler_ConfigHistory(t *testing.T) {
+	handler, _ := setupConfigHistoryHandler(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/config", strings.NewReader(`{"rules":{"movie_retention":"30d","tv_retention":"120d"}}`))
+	rec := httptest.NewRecorder()
+	handler.UpdateConfig(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ListConfigHistory(rec, httptest.NewRequest(http.MethodGet, "/api/config/history", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Versions []ConfigVersionResponse `json:"versions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Versions, 2)
+	assert.Equal(t, storage.ConfigSourceAPI, list.Versions[0].Source)
+	assert.True(t, list.Versions[0].Current)
+	startup := list.Versions[1]
+	assert.Equal(t, storage.ConfigSourceStartup, startup.Source)
+	assert.False(t, startup.Current)
+
+	// The diff shows what restoring the startup version would change
+	rec = httptest.NewRecorder()
+	handler.DiffConfigVersion(rec, withVersionID(httptest.NewRequest(http.MethodGet, "/", nil), startup.ID))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var diff struct {
+		Changes []storage.AuditChange `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &diff))
+	assert.Contains(t, diff.Changes, storage.AuditChange{Field: "rules.movie_retention", Before: "30d", After: "90d"})
+	assert.NotContains(t, rec.Body.String(), "adminpassword")
+
+	rec = httptest.NewRecorder()
+	handler.RestoreConfigVersion(rec, withVersionID(httptest.NewRequest(http.MethodPost, "/", nil), startup.ID))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "90d", config.Get().Rules.MovieRetention)
+
+	rec = httptest.NewRecorder()
+	handler.ListConfigHistory(rec, httptest.NewRequest(http.MethodGet, "/api/config/history", nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Versions, 3)
+	assert.Equal(t, storage.ConfigSourceRestore, list.Versions[0].Source)
+	assert.Equal(t, startup.ID, list.Versions[0].RestoredFrom)
+	assert.True(t, list.Versions[0].Current)
+}
+
+func TestConfigHandler_RestoreConfigVersion_Invalid(t *testing.T) {
+	handler, history := setupConfigHistoryHandler(t)
+	before, err := os.ReadFile(config.GetPath())
+	require.NoError(t, err)
+
+	// No integration enabled: the version fails validation
+	invalid, recorded := history.Record([]byte("admin:\n  username: admin\n  password: adminpassword\n"), storage.ConfigVersion{Source: storage.ConfigSourceFile})
+	require.True(t, recorded)
+
+	rec := httptest.NewRecorder()
+	handler.RestoreConfigVersion(rec, withVersionID(httptest.NewRequest(http.MethodPost, "/", nil), invalid.ID))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "integrations")
+
+	after, err := os.ReadFile(config.GetPath())
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "an invalid version must not be written")
+
+	rec = httptest.NewRecorder()
+	handler.RestoreConfigVersion(rec, withVersionID(httptest.NewRequest(http.MethodPost, "/", nil), "missing"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	NewConfigHandler(nil).ListConfigHistory(rec, httptest.NewRequest(http.MethodGet, "/api/config/history", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestConfigHandler_RestoreConfigVersion_AdminCredentials(t *testing.T) {
+	handler, history := setupConfigHistoryHandler(t)
+	auditLog := newTestAuditLog(t)
+	handler.SetAuditLog(auditLog)
+
+	require.NoError(t, utils.InitJWT("test-secret-key-for-testing-min-32-chars", time.Hour))
+	authService := services.NewAuthService(config.Get())
+	sessions, err := storage.NewSessionsFile(t.TempDir())
+	require.NoError(t, err)
+	authService.SetSessions(sessions)
+	handler.SetAuthService(authService)
+
+	login, err := authService.Login(t.Context(), "admin", "adminpassword", services.ClientInfo{})
+	require.NoError(t, err)
+
+	current, err := os.ReadFile(config.GetPath())
+	require.NoError(t, err)
+	old, recorded := history.Record([]byte(strings.Replace(string(current), "adminpassword", "leakedpassword", 1)), storage.ConfigVersion{Source: storage.ConfigSourceFile})
+	require.True(t, recorded)
+
+	rec := httptest.NewRecorder()
+	handler.RestoreConfigVersion(rec, withVersionID(httptest.NewRequest(http.MethodPost, "/", nil), old.ID))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	_, err = authService.ValidateToken(login.Token)
+	assert.ErrorIs(t, err, services.ErrSessionRevoked)
+
+	entries := auditLog.Query(services.AuditFilter{Action: "config.restore"})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "admin credentials changed: admin.password", entries[0].Message)
+}
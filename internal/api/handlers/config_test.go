@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -109,3 +111,38 @@ func TestConfigHandler_UpdateConfig_ConfigNil(t *testing.T) {
 	handler.UpdateConfig(rec, req)
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
+
+func TestConfigHandler_UpdateConfig_KeepsSecretReferences(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "jellyfin_api_key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("file-secret\n"), 0600))
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `admin:
+  username: admin
+  password: adminpassword
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://jellyfin:8096
+    api_key_file: ` + keyFile + `
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	_, err := config.Load(path)
+	require.NoError(t, err)
+	handler := NewConfigHandler(nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetConfig(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+	assert.Contains(t, rec.Body.String(), `"api_key_source":"file"`)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/config", strings.NewReader(`{"app":{"leaving_soon_days":21}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.UpdateConfig(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(written), "file-secret", "resolved secrets must not be written to the config file")
+	assert.Contains(t, string(written), "api_key_file: "+keyFile)
+	assert.Equal(t, "file-secret", config.Get().Integrations.Jellyfin.APIKey)
+}
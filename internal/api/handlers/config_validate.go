@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+// maxConfigBodyBytes caps the size of a config submitted for validation
+const maxConfigBodyBytes = 1 << 20
+
+// ConfigIssue is a validation error or warning for one config field
+type ConfigIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ConfigValidationResponse is the result of validating a config without
+// applying it
+type ConfigValidationResponse struct {
+	Valid    bool          `json:"valid"`
+	Errors   []ConfigIssue `json:"errors"`
+	Warnings []ConfigIssue `json:"warnings"`
+	// Connectivity holds the checks of integrations whose URL or API key
+	// differs from the running config
+	Connectivity []ServiceStatus `json:"connectivity"`
+	// Schedule previews what re-applying retention rules would change. It is
+	// absent when the config is invalid or no media library is available.
+	Schedule *services.SchedulePreview `json:"schedule,omitempty"`
+}
+
+// configValidationAudit is what the audit log records of a validation
+type configValidationAudit struct {
+	Valid bool `json:"valid"`
+	// Contacted maps each integration contacted to the URL it was reached at
+	Contacted map[string]string `json:"contacted,omitempty"`
+}
+
+// ValidateConfig handles POST /api/config/validate. The body is a full config
+// file, as YAML or as JSON when sent with a JSON content type. It is checked
+// the way it would be when applied, changed integrations are contacted, and
+// the deletion schedule changes are previewed. Nothing is written, but the
+// call and the integrations contacted are recorded in the audit log.
+func (h *ConfigHandler) ValidateConfig(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxConfigBodyBytes)
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(strings.TrimSpace(string(body))) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Request body must contain a config"})
+		return
+	}
+
+	resp := ConfigValidationResponse{
+		Errors:       []ConfigIssue{},
+		Warnings:     []ConfigIssue{},
+		Connectivity: []ServiceStatus{},
+	}
+
+	candidate, err := parseConfigBody(r, body)
+	if err != nil {
+		resp.Errors = append(resp.Errors, ConfigIssue{Message: err.Error()})
+		h.audit(r, "config.validate", "config", nil, configValidationAudit{})
+		writeConfigValidation(w, resp)
+		return
+	}
+
+	if err := config.Validate(candidate); err != nil {
+		var validationErrors config.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			validationErrors = config.ValidationErrors{{Message: err.Error()}}
+		}
+		resp.Errors = append(resp.Errors, configIssues(validationErrors)...)
+	}
+	resp.Valid = len(resp.Errors) == 0
+	resp.Warnings = append(resp.Warnings, configIssues(config.Warnings(candidate))...)
+
+	audited := configValidationAudit{Valid: resp.Valid, Contacted: make(map[string]string)}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	checked, statuses := checkChangedIntegrations(ctx, config.Get(), candidate)
+	for i, status := range statuses {
+		audited.Contacted[status.Name] = checked[i].url
+		if !status.Online {
+			resp.Warnings = append(resp.Warnings, ConfigIssue{
+				Field:   "integrations." + strings.ToLower(status.Name),
+				Message: fmt.Sprintf("%s is unreachable: %s", status.Name, status.Error),
+			})
+		}
+		resp.Connectivity = append(resp.Connectivity, status)
+	}
+
+	if resp.Valid && h.syncEngine != nil && config.Get() != nil {
+		preview := h.syncEngine.PreviewRetentionRules(r.Context(), candidate)
+		resp.Schedule = &preview
+	}
+
+	h.audit(r, "config.validate", "config", nil, audited)
+	writeConfigValidation(w, resp)
+}
+
+// parseConfigBody decodes a submitted config as JSON or YAML by content type.
+// Only the secret references the running config uses are resolved.
+func parseConfigBody(r *http.Request, body []byte) (*config.Config, error) {
+	format := "yaml"
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		format = "json"
+	}
+	return config.ParseSubmitted(body, format, config.Get())
+}
+
+// configIssues converts validation errors for the API response
+func configIssues(errs config.ValidationErrors) []ConfigIssue {
+	issues := make([]ConfigIssue, 0, len(errs))
+	for _, err := range errs {
+		issues = append(issues, ConfigIssue{Field: err.Field, Message: err.Message})
+	}
+	return issues
+}
+
+// checkChangedIntegrations pings the integrations enabled in next whose URL or
+// API key differs from prev, or that prev does not enable. Integrations whose
+// API key is an unresolved secret reference are skipped. It returns the
+// checks it ran with their results, in the order of buildServiceChecks.
+func checkChangedIntegrations(ctx context.Context, prev, next *config.Config) ([]serviceCheck, []ServiceStatus) {
+	var prevChecks map[string]serviceCheck
+	if prev != nil {
+		prevChecks = make(map[string]serviceCheck)
+		for _, check := range buildServiceChecks(prev) {
+			prevChecks[check.client] = check
+		}
+	}
+
+	var changed []serviceCheck
+	for _, check := range buildServiceChecks(next) {
+		if !check.enabled || check.url == "" || check.apiKey == "" {
+			continue
+		}
+		if config.UnresolvedSecret(next, "integrations."+check.client+".api_key") {
+			continue
+		}
+		if old, ok := prevChecks[check.client]; ok && old.enabled && old.url == check.url && old.apiKey == check.apiKey {
+			continue
+		}
+		changed = append(changed, check)
+	}
+
+	results := make([]ServiceStatus, len(changed))
+	var wg sync.WaitGroup
+	for i, check := range changed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := ServiceStatus{Name: check.name, Enabled: true}
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.Error().Str("service", check.name).Interface("panic", recovered).Msg("Service check panicked")
+					status.Error = "check failed"
+				}
+				results[i] = status
+			}()
+
+			start := time.Now()
+			if err := check.pinger(ctx); err != nil {
+				log.Warn().Str("service", check.name).Err(err).Msg("Service check for config validation failed")
+				status.Error = sanitizePingError(err)
+				return
+			}
+			status.Online = true
+			status.Latency = time.Since(start).String()
+		}()
+	}
+	wg.Wait()
+	return changed, results
+}
+
+// writeConfigValidation writes the validation result
+func writeConfigValidation(w http.ResponseWriter, resp ConfigValidationResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validateConfig posts body to ValidateConfig and decodes the result
+func validateConfig(t *testing.T, handler *ConfigHandler, contentType, body string) ConfigValidationResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/config/validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	handler.ValidateConfig(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ConfigValidationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestConfigHandler_ValidateConfig(t *testing.T) {
+	syncEngine := newTestSyncEngineForAPI(t)
+	path := loadTestConfig(t)
+	defer config.SetTestConfig(nil)
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	radarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"5.0.0"}`))
+	}))
+	defer radarr.Close()
+
+	handler := NewConfigHandler(syncEngine)
+
+	// Jellyfin is unchanged, so only the new Radarr integration is contacted
+	resp := validateConfig(t, handler, "application/yaml", `admin:
+  username: admin
+  password: adminpassword
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://jellyfin:8096
+    api_key: test-key
+  radarr:
+    enabled: true
+    url: `+radarr.URL+`
+    api_key: radarr-key
+rules:
+  movie_retention: 30d
+advanced_rules:
+  - name: requested
+    type: user
+    enabled: true
+    users:
+      - username: alice
+        retention: 7d
+`)
+	assert.True(t, resp.Valid, "%+v", resp.Errors)
+	assert.Empty(t, resp.Errors)
+	assert.Contains(t, resp.Warnings, ConfigIssue{
+		Field:   "advanced_rules[0]",
+		Message: "user rules match Jellyseerr requesters, but Jellyseerr is disabled; the rule matches nothing",
+	})
+	require.Len(t, resp.Connectivity, 1)
+	assert.Equal(t, "Radarr", resp.Connectivity[0].Name)
+	assert.True(t, resp.Connectivity[0].Online)
+	assert.NotNil(t, resp.Schedule)
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "validating must not write the config")
+	assert.Equal(t, "90d", config.Get().Rules.MovieRetention)
+}
+
+func TestConfigHandler_ValidateConfig_Invalid(t *testing.T) {
+	loadTestConfig(t)
+	defer config.SetTestConfig(nil)
+	handler := NewConfigHandler(nil)
+
+	resp := validateConfig(t, handler, "application/json", `{
+	"admin": {"username": "admin", "password": "adminpassword"},
+	"server": {"port": 70000},
+	"integrations": {"radarr": {"enabled": true, "url": "http://127.0.0.1:1", "api_key": "radarr-key"}}
+}`)
+	assert.False(t, resp.Valid)
+	assert.Contains(t, resp.Errors, ConfigIssue{Field: "server.port", Message: "must be between 1 and 65535 (got 70000)"})
+	assert.Nil(t, resp.Schedule, "no schedule preview for an invalid config")
+
+	require.Len(t, resp.Connectivity, 1)
+	assert.False(t, resp.Connectivity[0].Online)
+	assert.Contains(t, resp.Warnings, ConfigIssue{Field: "integrations.radarr", Message: "Radarr is unreachable: " + resp.Connectivity[0].Error})
+
+	resp = validateConfig(t, handler, "application/yaml", "admin: [unclosed\n")
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "failed to parse config")
+
+	rec := httptest.NewRecorder()
+	handler.ValidateConfig(rec, httptest.NewRequest(http.MethodPost, "/api/config/validate", strings.NewReader("  ")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestConfigHandler_ValidateConfig_SecretReferences(t *testing.T) {
+	loadTestConfig(t)
+	defer config.SetTestConfig(nil)
+	auditLog := newTestAuditLog(t)
+	handler := NewConfigHandler(nil)
+	handler.SetAuditLog(auditLog)
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("file-secret"), 0600))
+	t.Setenv("TEST_VALIDATE_SECRET", "env-secret")
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Api-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":"5.0.0"}`))
+	}))
+	defer server.Close()
+
+	// Secret references the running config does not use are not resolved,
+	// so nothing is sent to the new URLs
+	resp := validateConfig(t, handler, "application/yaml", `admin:
+  username: admin
+  password: adminpassword
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://jellyfin:8096
+    api_key: test-key
+  radarr:
+    enabled: true
+    url: `+server.URL+`
+    api_key_file: `+secretFile+`
+  sonarr:
+    enabled: true
+    url: `+server.URL+`
+    api_key: ${TEST_VALIDATE_SECRET}
+`)
+	assert.True(t, resp.Valid, "%+v", resp.Errors)
+	assert.Empty(t, resp.Connectivity)
+	assert.Empty(t, received)
+	var warned []string
+	for _, warning := range resp.Warnings {
+		warned = append(warned, warning.Field)
+	}
+	assert.Contains(t, warned, "integrations.radarr.api_key")
+	assert.Contains(t, warned, "integrations.sonarr.api_key")
+
+	// A key given directly is checked, and the validation is audited with
+	// the integrations contacted
+	resp = validateConfig(t, handler, "application/yaml", `admin:
+  username: admin
+  password: adminpassword
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://jellyfin:8096
+    api_key: test-key
+  radarr:
+    enabled: true
+    url: `+server.URL+`
+    api_key: radarr-key
+`)
+	require.Len(t, resp.Connectivity, 1)
+	assert.Equal(t, []string{"radarr-key"}, received)
+
+	entries := auditLog.Query(services.AuditFilter{Action: "config.validate"})
+	require.Len(t, entries, 2)
+	assert.Contains(t, entries[0].Changes, storage.AuditChange{Field: "contacted.Radarr", After: server.URL})
+	assert.Contains(t, entries[0].Changes, storage.AuditChange{Field: "valid", After: true})
+}
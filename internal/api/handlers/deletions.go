@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+// DeletionsHandler handles two-step deletion approval requests
+type DeletionsHandler struct {
+	auditor
+	syncEngine *services.SyncEngine
+}
+
+// NewDeletionsHandler creates a new DeletionsHandler
+func NewDeletionsHandler(syncEngine *services.SyncEngine) *DeletionsHandler {
+	return &DeletionsHandler{
+		syncEngine: syncEngine,
+	}
+}
+
+// BatchDecisionRequest selects which items of a batch to approve or reject.
+// An empty ItemIDs list applies the decision to every pending item.
+type BatchDecisionRequest struct {
+	ItemIDs []string `json:"item_ids,omitempty"` // media IDs
+}
+
+// ListBatches handles GET /api/deletions/batches
+func (h *DeletionsHandler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	batches, err := h.syncEngine.ListDeletionBatches()
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"batches": batches,
+		"total":   len(batches),
+	})
+}
+
+// GetBatch handles GET /api/deletions/batches/{id}
+func (h *DeletionsHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.syncEngine.GetDeletionBatch(chi.URLParam(r, "id"))
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// ApproveBatch handles POST /api/deletions/batches/{id}/approve
+func (h *DeletionsHandler) ApproveBatch(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBatchDecision(w, r)
+	if !ok {
+		return
+	}
+
+	batchID := chi.URLParam(r, "id")
+	log.Info().Str("batch_id", batchID).Int("selected_items", len(req.ItemIDs)).Msg("Deletion batch approval requested")
+
+	before, _ := h.syncEngine.GetDeletionBatch(batchID)
+	batch, err := h.syncEngine.ApproveDeletionBatch(r.Context(), batchID, req.ItemIDs, requestActor(r))
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+	h.audit(r, "deletions.batch.approve", batchID, before, batch)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// RejectBatch handles POST /api/deletions/batches/{id}/reject
+func (h *DeletionsHandler) RejectBatch(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBatchDecision(w, r)
+	if !ok {
+		return
+	}
+
+	batchID := chi.URLParam(r, "id")
+	before, _ := h.syncEngine.GetDeletionBatch(batchID)
+	batch, err := h.syncEngine.RejectDeletionBatch(batchID, req.ItemIDs, requestActor(r))
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+	h.audit(r, "deletions.batch.reject", batchID, before, batch)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// decodeBatchDecision parses the optional request body; an empty body
+// selects every pending item.
+func decodeBatchDecision(w http.ResponseWriter, r *http.Request) (BatchDecisionRequest, bool) {
+	var req BatchDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return req, false
+	}
+	return req, true
+}
+
+// writeBatchError maps deletion batch errors to HTTP responses
+func writeBatchError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrBatchNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrBatchItemNotPending):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrBatchNotPending),
+		errors.Is(err, services.ErrBatchExpired),
+		errors.Is(err, services.ErrDeletionDisabled),
+		errors.Is(err, services.ErrSyncInProgress):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrApprovalUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("Deletion batch operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
+
+// requestActor returns the username of the authenticated caller,
+// "api_key:<name>" for a named API key, or "api_key" for requests
+// authenticated with the static admin API key.
+func requestActor(r *http.Request) string {
+	if claims := middleware.GetUserFromContext(r.Context()); claims != nil {
+		return claims.Username
+	}
+	if key := middleware.GetAPIKeyFromContext(r.Context()); key != nil {
+		return "api_key:" + key.Name
+	}
+	if cfg := config.Get(); cfg != nil && cfg.Admin.DisableAuth {
+		return cfg.Admin.Username
+	}
+	return "api_key"
+}
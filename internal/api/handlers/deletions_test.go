@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeletionsRouter mounts the batch routes so chi URL params resolve
+func newDeletionsRouter(handler *DeletionsHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/api/deletions/batches", handler.ListBatches)
+	r.Get("/api/deletions/batches/{id}", handler.GetBatch)
+	r.Post("/api/deletions/batches/{id}/approve", handler.ApproveBatch)
+	r.Post("/api/deletions/batches/{id}/reject", handler.RejectBatch)
+	return r
+}
+
+func TestDeletionsHandler_NoStoreConfigured(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	router := newDeletionsRouter(NewDeletionsHandler(engine))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/deletions/batches", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestDeletionsHandler_Batches(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	batches, err := storage.NewDeletionBatchesFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	engine.SetDeletionBatches(batches)
+
+	require.NoError(t, batches.Add(storage.DeletionBatch{
+		ID:        "batch-1",
+		Status:    storage.BatchStatusPending,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+		Items: []storage.DeletionBatchItem{
+			{MediaID: "movie-1", Title: "Movie One", Status: storage.ItemStatusPending},
+			{MediaID: "movie-2", Title: "Movie Two", Status: storage.ItemStatusPending},
+		},
+	}))
+
+	router := newDeletionsRouter(NewDeletionsHandler(engine))
+
+	t.Run("lists batches", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/deletions/batches", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Batches []storage.DeletionBatch `json:"batches"`
+			Total   int                     `json:"total"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, 1, response.Total)
+		assert.Equal(t, "batch-1", response.Batches[0].ID)
+	})
+
+	t.Run("unknown batch returns 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/deletions/batches/nope", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("approve is refused while deletion is disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/deletions/batches/batch-1/approve", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("invalid body returns 400", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/deletions/batches/batch-1/reject", strings.NewReader("{"))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects selected items", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/deletions/batches/batch-1/reject", strings.NewReader(`{"item_ids":["movie-2"]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var batch storage.DeletionBatch
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&batch))
+		assert.Equal(t, storage.BatchStatusPending, batch.Status)
+		assert.Equal(t, storage.ItemStatusPending, batch.Items[0].Status)
+		assert.Equal(t, storage.ItemStatusRejected, batch.Items[1].Status)
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/services"
+)
+
+// eventsKeepaliveInterval is how often an idle event stream sends a comment
+// so proxies don't close the connection
+const eventsKeepaliveInterval = 15 * time.Second
+
+// EventsHandler streams engine events over SSE
+type EventsHandler struct {
+	syncEngine *services.SyncEngine
+}
+
+// NewEventsHandler creates a new EventsHandler
+func NewEventsHandler(syncEngine *services.SyncEngine) *EventsHandler {
+	return &EventsHandler{syncEngine: syncEngine}
+}
+
+// Stream handles GET /api/events
+//
+// Each event is sent with its ID, its type as the SSE event name, and the
+// JSON-encoded event as data. Clients resume with the Last-Event-ID header
+// (sent automatically by EventSource on reconnect) or the last_event_id query
+// param; missed events are replayed from a bounded buffer. If they are no
+// longer buffered a "reset" event is sent first and the client should reload
+// its state.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Streaming not supported"})
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid Last-Event-ID"})
+			return
+		}
+		lastID = id
+	}
+
+	// The stream outlives the server's write timeout; clear it for this response.
+	// Recorders in tests don't support deadlines, which is fine.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	replay, events, complete, unsubscribe := h.syncEngine.Events().Subscribe(lastID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx buffering
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	if !complete {
+		sendSSEEvent(w, flusher, "reset", `{"reason":"missed events are no longer available"}`)
+	}
+	for _, event := range replay {
+		writeEngineEvent(w, flusher, event)
+	}
+
+	ticker := time.NewTicker(eventsKeepaliveInterval)
+	defer ticker.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				return
+			}
+			writeEngineEvent(w, flusher, event)
+		case <-ticker.C:
+			fmt.Fprintf(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEngineEvent writes an engine event with its ID so clients can resume
+func writeEngineEvent(w http.ResponseWriter, flusher http.Flusher, event services.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\n", event.ID)
+	sendSSEEvent(w, flusher, string(event.Type), string(data))
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHandler_InvalidLastEventID(t *testing.T) {
+	handler := NewEventsHandler(newTestSyncEngineForAPI(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	w := httptest.NewRecorder()
+	handler.Stream(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestEventsHandler_Stream(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	handler := NewEventsHandler(engine)
+	bus := engine.Events()
+
+	bus.Publish(services.EventConfigReloaded, map[string]any{"path": "one"})
+	bus.Publish(services.EventConfigReloaded, map[string]any{"path": "two"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &lockedRecorder{rec: httptest.NewRecorder()}
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.Stream(rec, req)
+	}()
+
+	// Event 2 is replayed, event 1 is not
+	require.Eventually(t, func() bool {
+		return strings.Contains(rec.String(), "id: 2\nevent: config.reloaded\n")
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.NotContains(t, rec.String(), `"path":"one"`)
+	assert.NotContains(t, rec.String(), "event: reset")
+
+	// Live events follow the replay
+	bus.Publish(services.EventExclusionAdded, map[string]any{"media_id": "movie-1"})
+	require.Eventually(t, func() bool {
+		return strings.Contains(rec.String(), "id: 3\nevent: exclusion.added\n")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+	assert.Equal(t, "text/event-stream", rec.rec.Header().Get("Content-Type"))
+}
+
+func TestEventsHandler_ResetWhenResumeIsNotPossible(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	handler := NewEventsHandler(engine)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &lockedRecorder{rec: httptest.NewRecorder()}
+	// An ID the bus never issued, e.g. from before a restart
+	req := httptest.NewRequest(http.MethodGet, "/api/events?last_event_id=99", nil).WithContext(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.Stream(rec, req)
+	}()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(rec.String(), "event: reset")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+}
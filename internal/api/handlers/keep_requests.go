@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// KeepRequestsHandler handles requests to keep media from being deleted
+type KeepRequestsHandler struct {
+	auditor
+	syncEngine *services.SyncEngine
+}
+
+// NewKeepRequestsHandler creates a new KeepRequestsHandler
+func NewKeepRequestsHandler(syncEngine *services.SyncEngine) *KeepRequestsHandler {
+	return &KeepRequestsHandler{
+		syncEngine: syncEngine,
+	}
+}
+
+// KeepRequestBody is the optional body of a keep request submission
+type KeepRequestBody struct {
+	Reason string `json:"reason"`
+}
+
+// KeepDecisionBody is the optional body of an approve or deny request
+type KeepDecisionBody struct {
+	Note string `json:"note"`
+}
+
+// SubmitKeepRequest handles POST /api/media/{id}/keep-request
+func (h *KeepRequestsHandler) SubmitKeepRequest(w http.ResponseWriter, r *http.Request) {
+	var body KeepRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	requester := requesterName(r)
+	actor := requester
+	if actor == "" {
+		actor = requestActor(r)
+	}
+
+	request, err := h.syncEngine.SubmitKeepRequest(chi.URLParam(r, "id"), body.Reason, actor, requester != "")
+	if err != nil {
+		writeKeepRequestError(w, err)
+		return
+	}
+	h.audit(r, "keep_request.submit", mediaTarget(request.MediaID, request.Title), nil, request)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// ListKeepRequests handles GET /api/keep-requests
+// Requesters only see their own requests. Optional query param: status.
+func (h *KeepRequestsHandler) ListKeepRequests(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.syncEngine.ListKeepRequests(requesterName(r))
+	if err != nil {
+		writeKeepRequestError(w, err)
+		return
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		filtered := make([]storage.KeepRequest, 0, len(requests))
+		for _, request := range requests {
+			if string(request.Status) == status {
+				filtered = append(filtered, request)
+			}
+		}
+		requests = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"requests": requests,
+		"total":    len(requests),
+	})
+}
+
+// ApproveKeepRequest handles POST /api/keep-requests/{id}/approve
+// Approving excludes the item from deletion.
+func (h *KeepRequestsHandler) ApproveKeepRequest(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeKeepDecision(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.syncEngine.ApproveKeepRequest(r.Context(), chi.URLParam(r, "id"), body.Note, requestActor(r))
+	if err != nil {
+		writeKeepRequestError(w, err)
+		return
+	}
+	h.audit(r, "keep_request.approve", mediaTarget(request.MediaID, request.Title), keepRequestPending(request), request)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(request)
+}
+
+// DenyKeepRequest handles POST /api/keep-requests/{id}/deny
+func (h *KeepRequestsHandler) DenyKeepRequest(w http.ResponseWriter, r *http.Request) {
+	body, ok := decodeKeepDecision(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.syncEngine.DenyKeepRequest(chi.URLParam(r, "id"), body.Note, requestActor(r))
+	if err != nil {
+		writeKeepRequestError(w, err)
+		return
+	}
+	h.audit(r, "keep_request.deny", mediaTarget(request.MediaID, request.Title), keepRequestPending(request), request)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(request)
+}
+
+// keepRequestPending returns a decided request as it was while pending, so
+// the audit diff shows just the decision
+func keepRequestPending(request storage.KeepRequest) storage.KeepRequest {
+	request.Status = storage.KeepRequestPending
+	request.DecidedAt = nil
+	request.DecidedBy = ""
+	request.Note = ""
+	return request
+}
+
+// decodeKeepDecision parses the optional decision body
+func decodeKeepDecision(w http.ResponseWriter, r *http.Request) (KeepDecisionBody, bool) {
+	var body KeepDecisionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return body, false
+	}
+	return body, true
+}
+
+// writeKeepRequestError maps keep request errors to HTTP responses
+func writeKeepRequestError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrKeepRequestNotFound),
+		errors.Is(err, services.ErrMediaNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrKeepRequestNotPending),
+		errors.Is(err, services.ErrKeepRequestDuplicate),
+		errors.Is(err, services.ErrAlreadyExcluded):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrKeepRequestsUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("Keep request operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRequesterTestEngine seeds one item requested by "kid" and one that is not
+func newRequesterTestEngine(t *testing.T) *services.SyncEngine {
+	t.Helper()
+	require.NoError(t, utils.InitJWT("test-secret-key-for-testing-min-32-chars", 24*time.Hour))
+
+	engine := newTestSyncEngineForAPI(t)
+	keepRequests, err := storage.NewKeepRequestsFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	engine.SetKeepRequests(keepRequests)
+
+	kid := "Kid"
+	engine.GetMediaLibrary()["movie-1"] = models.Media{ID: "movie-1", Type: models.MediaTypeMovie, Title: "Requested Movie", RadarrID: 1, RequestedByUsername: &kid}
+	engine.GetMediaLibrary()["movie-2"] = models.Media{ID: "movie-2", Type: models.MediaTypeMovie, Title: "Other Movie", RadarrID: 2}
+	return engine
+}
+
+// newKeepRequestsRouter mounts the keep request and media read routes behind
+// Auth so handlers see the caller's role
+func newKeepRequestsRouter(engine *services.SyncEngine) *chi.Mux {
+	keepHandler := NewKeepRequestsHandler(engine)
+	mediaHandler := NewMediaHandler(engine)
+
+	r := chi.NewRouter()
+	r.Use(middleware.Auth)
+	r.Get("/api/media/movies", mediaHandler.ListMovies)
+	r.Get("/api/media/{id}", mediaHandler.GetMediaItem)
+	r.Post("/api/media/{id}/keep-request", keepHandler.SubmitKeepRequest)
+	r.Get("/api/keep-requests", keepHandler.ListKeepRequests)
+	r.Post("/api/keep-requests/{id}/approve", keepHandler.ApproveKeepRequest)
+	r.Post("/api/keep-requests/{id}/deny", keepHandler.DenyKeepRequest)
+	return r
+}
+
+func doAs(t *testing.T, router http.Handler, username, role, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	token, err := utils.GenerateToken(username, role)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMediaHandler_RequesterSeesOnlyOwnRequests(t *testing.T) {
+	engine := newRequesterTestEngine(t)
+	router := newKeepRequestsRouter(engine)
+
+	w := doAs(t, router, "kid", utils.RoleRequester, http.MethodGet, "/api/media/movies", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Items []models.Media `json:"items"`
+		Total int            `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Equal(t, 1, response.Total)
+	assert.Equal(t, "movie-1", response.Items[0].ID)
+
+	assert.Equal(t, http.StatusOK, doAs(t, router, "kid", utils.RoleRequester, http.MethodGet, "/api/media/movie-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, doAs(t, router, "kid", utils.RoleRequester, http.MethodGet, "/api/media/movie-2", "").Code)
+
+	// Viewers see the whole library
+	w = doAs(t, router, "someone", utils.RoleViewer, http.MethodGet, "/api/media/movies", "")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, 2, response.Total)
+}
+
+func TestKeepRequestsHandler(t *testing.T) {
+	engine := newRequesterTestEngine(t)
+	router := newKeepRequestsRouter(engine)
+
+	w := doAs(t, router, "kid", utils.RoleRequester, http.MethodPost, "/api/media/movie-1/keep-request", `{"reason":"still watching"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created storage.KeepRequest
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "kid", created.RequestedBy)
+
+	assert.Equal(t, http.StatusConflict, doAs(t, router, "kid", utils.RoleRequester, http.MethodPost, "/api/media/movie-1/keep-request", "").Code)
+	assert.Equal(t, http.StatusNotFound, doAs(t, router, "kid", utils.RoleRequester, http.MethodPost, "/api/media/movie-2/keep-request", "").Code)
+
+	// Another user's request is not visible to the requester
+	require.Equal(t, http.StatusCreated, doAs(t, router, "viewer", utils.RoleViewer, http.MethodPost, "/api/media/movie-2/keep-request", "").Code)
+
+	var list struct {
+		Requests []storage.KeepRequest `json:"requests"`
+		Total    int                   `json:"total"`
+	}
+	w = doAs(t, router, "kid", utils.RoleRequester, http.MethodGet, "/api/keep-requests", "")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	assert.Equal(t, 1, list.Total)
+
+	w = doAs(t, router, "op", utils.RoleOperator, http.MethodGet, "/api/keep-requests?status=pending", "")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	assert.Equal(t, 2, list.Total)
+
+	w = doAs(t, router, "op", utils.RoleOperator, http.MethodPost, "/api/keep-requests/"+created.ID+"/approve", `{"note":"ok"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	media, _ := engine.GetMediaByID("movie-1")
+	assert.True(t, media.IsExcluded)
+
+	assert.Equal(t, http.StatusConflict, doAs(t, router, "op", utils.RoleOperator, http.MethodPost, "/api/keep-requests/"+created.ID+"/deny", "").Code)
+	assert.Equal(t, http.StatusNotFound, doAs(t, router, "op", utils.RoleOperator, http.MethodPost, "/api/keep-requests/missing/deny", "").Code)
+}
@@ -1,21 +1,28 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
 	"github.com/rs/zerolog/log"
 )
 
 // MediaHandler handles media-related requests
 type MediaHandler struct {
+	auditor
 	syncEngine *services.SyncEngine
 }
 
@@ -32,6 +39,7 @@ func (h *MediaHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 	sortBy := r.URL.Query().Get("sort_by")      // e.g., "title", "added_at", "delete_after"
 	order := r.URL.Query().Get("order")         // "asc" or "desc"
 	filterStatus := r.URL.Query().Get("status") // "all", "leaving_soon", "excluded"
+	requester := requesterName(r)
 
 	media := h.syncEngine.GetMediaList()
 
@@ -39,6 +47,9 @@ func (h *MediaHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 	var movies []models.Media
 	for _, item := range media {
 		if item.Type == models.MediaTypeMovie {
+			if !visibleTo(item, requester) {
+				continue
+			}
 			// Apply status filter
 			if filterStatus == "leaving_soon" && item.DaysUntilDue <= 0 {
 				continue
@@ -53,9 +64,7 @@ func (h *MediaHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
 	// Sort movies
 	movies = sortMedia(movies, sortBy, order)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	h.writeMediaJSON(w, r, map[string]interface{}{
 		"items": movies,
 		"total": len(movies),
 	})
@@ -67,6 +76,7 @@ func (h *MediaHandler) ListShows(w http.ResponseWriter, r *http.Request) {
 	sortBy := r.URL.Query().Get("sort_by")
 	order := r.URL.Query().Get("order")
 	filterStatus := r.URL.Query().Get("status")
+	requester := requesterName(r)
 
 	media := h.syncEngine.GetMediaList()
 
@@ -74,6 +84,9 @@ func (h *MediaHandler) ListShows(w http.ResponseWriter, r *http.Request) {
 	var shows []models.Media
 	for _, item := range media {
 		if item.Type == models.MediaTypeTVShow {
+			if !visibleTo(item, requester) {
+				continue
+			}
 			// Apply status filter
 			if filterStatus == "leaving_soon" && item.DaysUntilDue <= 0 {
 				continue
@@ -88,9 +101,7 @@ func (h *MediaHandler) ListShows(w http.ResponseWriter, r *http.Request) {
 	// Sort shows
 	shows = sortMedia(shows, sortBy, order)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	h.writeMediaJSON(w, r, map[string]interface{}{
 		"items": shows,
 		"total": len(shows),
 	})
@@ -148,9 +159,7 @@ func (h *MediaHandler) ListLeavingSoon(w http.ResponseWriter, r *http.Request) {
 		return leavingSoon[i].Title < leavingSoon[j].Title
 	})
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(models.LeavingSoonResponse{
+	h.writeMediaJSON(w, r, models.LeavingSoonResponse{
 		Version: 1,
 		Items:   leavingSoon,
 	})
@@ -162,6 +171,7 @@ func (h *MediaHandler) ListLeavingSoon(w http.ResponseWriter, r *http.Request) {
 // plugin contract to machine clients.
 func (h *MediaHandler) ListLeavingSoonMedia(w http.ResponseWriter, r *http.Request) {
 	media := h.syncEngine.GetMediaList()
+	requester := requesterName(r)
 
 	cfg := config.Get()
 	leavingSoonDays := cfg.App.LeavingSoonDays
@@ -169,7 +179,7 @@ func (h *MediaHandler) ListLeavingSoonMedia(w http.ResponseWriter, r *http.Reque
 	// Filter leaving soon items (items within the leaving_soon_days threshold)
 	var leavingSoon []models.Media
 	for _, item := range media {
-		if item.DaysUntilDue > 0 && item.DaysUntilDue <= leavingSoonDays && !item.IsExcluded {
+		if item.DaysUntilDue > 0 && item.DaysUntilDue <= leavingSoonDays && !item.IsExcluded && visibleTo(item, requester) {
 			leavingSoon = append(leavingSoon, item)
 		}
 	}
@@ -177,9 +187,7 @@ func (h *MediaHandler) ListLeavingSoonMedia(w http.ResponseWriter, r *http.Reque
 	// Sort by deletion date (earliest first)
 	leavingSoon = sortMedia(leavingSoon, "delete_after", "asc")
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	h.writeMediaJSON(w, r, map[string]interface{}{
 		"items": leavingSoon,
 		"total": len(leavingSoon),
 	})
@@ -197,14 +205,69 @@ func (h *MediaHandler) GetMediaItem(w http.ResponseWriter, r *http.Request) {
 	id := parts[0]
 
 	media, found := h.syncEngine.GetMediaByID(id)
-	if !found {
+	if !found || !visibleTo(media, requesterName(r)) {
 		http.Error(w, "Media not found", http.StatusNotFound)
 		return
 	}
 
+	h.writeMediaJSON(w, r, media)
+}
+
+// requesterName returns the caller's username when they hold the restricted
+// requester role, or "" when they may see the whole library
+func requesterName(r *http.Request) string {
+	if middleware.GetRoleFromContext(r.Context()) != utils.RoleRequester {
+		return ""
+	}
+	if claims := middleware.GetUserFromContext(r.Context()); claims != nil {
+		return claims.Username
+	}
+	return ""
+}
+
+// visibleTo reports whether item may be shown to the caller. Requesters only
+// see items they requested; requester == "" sees everything.
+func visibleTo(item models.Media, requester string) bool {
+	return requester == "" || item.IsRequestedBy(requester)
+}
+
+// writeMediaJSON writes v as JSON with an ETag and answers 304 Not Modified
+// when the client already has it. The tag pairs the library generation with a
+// hash of the body, so it also changes with updates made between syncs
+// (exclusions, manual flags, re-applied rules) and with the query.
+func (h *MediaHandler) writeMediaJSON(w http.ResponseWriter, r *http.Request, v any) {
+	generation := h.syncEngine.LibraryGeneration()
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode media response")
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := fmt.Sprintf(`"%d-%s"`, generation, hex.EncodeToString(sum[:8]))
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(media)
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 specifies for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
 
 // AddExclusion handles POST /api/media/{id}/exclude
@@ -228,11 +291,16 @@ func (h *MediaHandler) AddExclusion(w http.ResponseWriter, r *http.Request) {
 		log.Debug().Err(err).Msg("No exclusion reason provided")
 	}
 
-	if err := h.syncEngine.AddExclusion(ctx, id, reqBody.Reason); err != nil {
+	before, _ := h.syncEngine.GetMediaByID(id)
+	if err := h.syncEngine.AddExclusion(ctx, id, reqBody.Reason, requestActor(r)); err != nil {
 		log.Error().Err(err).Str("media_id", id).Msg("Failed to add exclusion")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	after := newMediaAuditView(before)
+	after.Excluded = true
+	after.ExclusionReason = reqBody.Reason
+	h.audit(r, "media.exclude", mediaTarget(id, before.Title), newMediaAuditView(before), after)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -255,11 +323,14 @@ func (h *MediaHandler) RemoveExclusion(w http.ResponseWriter, r *http.Request) {
 	}
 	id := parts[0]
 
+	before, _ := h.syncEngine.GetMediaByID(id)
 	if err := h.syncEngine.RemoveExclusion(ctx, id); err != nil {
 		log.Error().Err(err).Str("media_id", id).Msg("Failed to remove exclusion")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	after, _ := h.syncEngine.GetMediaByID(id)
+	h.audit(r, "media.unexclude", mediaTarget(id, before.Title), newMediaAuditView(before), newMediaAuditView(after))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -285,11 +356,15 @@ func (h *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
 	// Check for dry run
 	dryRun := r.URL.Query().Get("dry_run") == "true"
 
+	before, _ := h.syncEngine.GetMediaByID(id)
 	if err := h.syncEngine.DeleteMedia(ctx, id, dryRun); err != nil {
 		log.Error().Err(err).Str("media_id", id).Bool("dry_run", dryRun).Msg("Failed to delete media")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if !dryRun {
+		h.audit(r, "media.delete", mediaTarget(id, before.Title), newMediaAuditView(before), nil)
+	}
 
 	message := "Media deleted successfully"
 	if dryRun {
@@ -321,7 +396,7 @@ func (h *MediaHandler) ProxyPoster(w http.ResponseWriter, r *http.Request) {
 
 	// Look up the media item to get its JellyfinID
 	media, found := h.syncEngine.GetMediaByID(id)
-	if !found {
+	if !found || !visibleTo(media, requesterName(r)) {
 		http.Error(w, "Media not found", http.StatusNotFound)
 		return
 	}
@@ -394,7 +469,8 @@ func (h *MediaHandler) AddManualLeavingSoon(w http.ResponseWriter, r *http.Reque
 	}
 	id := parts[0]
 
-	if err := h.syncEngine.AddManualLeavingSoon(ctx, id); err != nil {
+	before, _ := h.syncEngine.GetMediaByID(id)
+	if err := h.syncEngine.AddManualLeavingSoon(ctx, id, requestActor(r)); err != nil {
 		log.Error().Err(err).Str("media_id", id).Msg("Failed to add manual leaving soon flag")
 		// Return 409 Conflict when item is protected
 		if strings.HasPrefix(err.Error(), "conflict:") {
@@ -404,6 +480,8 @@ func (h *MediaHandler) AddManualLeavingSoon(w http.ResponseWriter, r *http.Reque
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	after, _ := h.syncEngine.GetMediaByID(id)
+	h.audit(r, "media.manual_leaving_soon.add", mediaTarget(id, before.Title), newMediaAuditView(before), newMediaAuditView(after))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -413,6 +491,27 @@ func (h *MediaHandler) AddManualLeavingSoon(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// mediaAuditView is the part of a media item that exclusions, manual
+// leaving-soon flags and deletes change, as recorded in the audit log
+type mediaAuditView struct {
+	Excluded          bool       `json:"excluded"`
+	ExclusionReason   string     `json:"exclusion_reason,omitempty"`
+	ManualLeavingSoon bool       `json:"manual_leaving_soon"`
+	DeleteAfter       *time.Time `json:"delete_after,omitempty"`
+}
+
+func newMediaAuditView(media models.Media) mediaAuditView {
+	view := mediaAuditView{
+		Excluded:          media.IsExcluded,
+		ManualLeavingSoon: media.IsManualLeavingSoon,
+	}
+	if !media.DeleteAfter.IsZero() {
+		deleteAfter := media.DeleteAfter
+		view.DeleteAfter = &deleteAfter
+	}
+	return view
+}
+
 // RemoveManualLeavingSoon handles DELETE /api/media/{id}/manual-leaving-soon
 func (h *MediaHandler) RemoveManualLeavingSoon(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -425,11 +524,14 @@ func (h *MediaHandler) RemoveManualLeavingSoon(w http.ResponseWriter, r *http.Re
 	}
 	id := parts[0]
 
+	before, _ := h.syncEngine.GetMediaByID(id)
 	if err := h.syncEngine.RemoveManualLeavingSoon(ctx, id); err != nil {
 		log.Error().Err(err).Str("media_id", id).Msg("Failed to remove manual leaving soon flag")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	after, _ := h.syncEngine.GetMediaByID(id)
+	h.audit(r, "media.manual_leaving_soon.remove", mediaTarget(id, before.Title), newMediaAuditView(before), newMediaAuditView(after))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -527,8 +629,7 @@ func (h *MediaHandler) ListUnmatched(w http.ResponseWriter, r *http.Request) {
 		Int("total", len(unmatched)).
 		Msg("Listing unmatched media items")
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	h.writeMediaJSON(w, r, map[string]interface{}{
 		"items": unmatched,
 		"total": len(unmatched),
 	})
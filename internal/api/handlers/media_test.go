@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -461,6 +462,45 @@ func TestMediaHandler_GetMediaItem(t *testing.T) {
 	})
 }
 
+func TestMediaHandler_ETag(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	handler := NewMediaHandler(engine)
+	engine.GetMediaLibrary()["movie-1"] = models.Media{ID: "movie-1", Type: models.MediaTypeMovie, Title: "Test Movie"}
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ListMovies(w, req)
+		return w
+	}
+
+	first := get("/api/media/movies", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Regexp(t, `^"0-[0-9a-f]{16}"$`, etag, "tag starts with the library generation")
+
+	t.Run("unchanged library is not modified", func(t *testing.T) {
+		w := get("/api/media/movies", etag)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusNotModified, get("/api/media/movies", `"other", W/`+etag).Code)
+	})
+
+	t.Run("changes between syncs change the tag", func(t *testing.T) {
+		require.NoError(t, engine.AddExclusion(context.Background(), "movie-1", "keep", ""))
+
+		w := get("/api/media/movies", etag)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
+
 func TestMediaHandler_AddExclusion(t *testing.T) {
 	t.Run("adds exclusion successfully", func(t *testing.T) {
 		engine := newTestSyncEngineForAPI(t)
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// oidcStateCookieName binds a sign-in to the browser that started it,
+	// so a callback URL cannot be replayed in someone else's browser
+	oidcStateCookieName = "oxicleanarr_oidc_state"
+	oidcCookiePath      = "/api/auth/oidc"
+)
+
+// ProvidersResponse lists the sign-in methods offered on the login page
+type ProvidersResponse struct {
+	Password bool `json:"password"`
+	Jellyfin bool `json:"jellyfin"`
+	OIDC     bool `json:"oidc"`
+}
+
+// Providers handles GET /api/auth/providers
+func (h *AuthHandler) Providers(w http.ResponseWriter, r *http.Request) {
+	response := ProvidersResponse{Password: true}
+	if cfg := config.Get(); cfg != nil {
+		response.Jellyfin = cfg.Admin.JellyfinLogin.Enabled
+		response.OIDC = cfg.Admin.OIDC.Enabled
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// OIDCLogin handles GET /api/auth/oidc/login
+// Redirects the browser to the OpenID provider.
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.authService.StartOIDCLogin(r.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Internal server error"
+		switch {
+		case errors.Is(err, services.ErrOIDCDisabled):
+			status, message = http.StatusNotFound, "Single sign-on is not enabled"
+		case errors.Is(err, services.ErrLoginProviderUnavailable):
+			status, message = http.StatusServiceUnavailable, "Login provider is unavailable, try again later"
+		default:
+			log.Error().Err(err).Msg("Failed to start OIDC login")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    state,
+		Path:     oidcCookiePath,
+		HttpOnly: true,
+		// Lax is sent on the provider's top-level redirect back to us
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int((10 * time.Minute).Seconds()),
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback handles GET /api/auth/oidc/callback
+// Completes the sign-in, sets the auth cookie and sends the browser to the
+// web UI. Failures go back to the login page with an sso_error message.
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+
+	cookie, cookieErr := r.Cookie(oidcStateCookieName)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     oidcCookiePath,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		log.Warn().Str("error", providerErr).Str("description", query.Get("error_description")).Msg("OIDC provider returned an error")
+		redirectLoginError(w, r, "Sign-in was cancelled or refused by the provider")
+		return
+	}
+	if cookieErr != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		redirectLoginError(w, r, "Sign-in request is invalid or has expired, please try again")
+		return
+	}
+
+	result, err := h.authService.CompleteOIDCLogin(r.Context(), state, query.Get("code"), clientInfo(r))
+	h.auditLogin(r, "oidc", result.Username, result.Role, err)
+	if err != nil {
+		message := "Sign-in failed"
+		switch {
+		case errors.Is(err, services.ErrOIDCNoRole):
+			message = "Your account is not allowed to use OxiCleanarr"
+		case errors.Is(err, services.ErrOIDCLocalName):
+			message = "Your username belongs to a local account, sign in with its password"
+		case errors.Is(err, services.ErrOIDCState):
+			message = "Sign-in request is invalid or has expired, please try again"
+		case errors.Is(err, services.ErrLoginProviderUnavailable):
+			message = "Login provider is unavailable, try again later"
+		case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrOIDCDisabled):
+		default:
+			log.Error().Err(err).Msg("Failed to complete OIDC login")
+		}
+		redirectLoginError(w, r, message)
+		return
+	}
+
+	setSessionCookies(w, result)
+	log.Info().Str("username", result.Username).Str("role", result.Role).Msg("Successful single sign-on")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// redirectLoginError sends the browser to the login page with a message
+func redirectLoginError(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, "/login?sso_error="+url.QueryEscape(message), http.StatusFound)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+func TestAuthHandler_Providers(t *testing.T) {
+	handler, cfg := setupAuthHandler(t)
+	cfg.Admin.OIDC.Enabled = true
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	w := httptest.NewRecorder()
+	handler.Providers(w, httptest.NewRequest(http.MethodGet, "/api/auth/providers", nil))
+
+	var response ProvidersResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Password || !response.OIDC || response.Jellyfin {
+		t.Errorf("Unexpected providers: %+v", response)
+	}
+}
+
+func TestAuthHandler_OIDCLoginDisabled(t *testing.T) {
+	handler, cfg := setupAuthHandler(t)
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	w := httptest.NewRecorder()
+	handler.OIDCLogin(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_OIDCCallbackRejects(t *testing.T) {
+	handler, cfg := setupAuthHandler(t)
+	cfg.Admin.OIDC.Enabled = true
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	tests := []struct {
+		name   string
+		query  string
+		cookie string
+	}{
+		{name: "missing state cookie", query: "state=abc&code=xyz"},
+		{name: "state does not match cookie", query: "state=abc&code=xyz", cookie: "other"},
+		{name: "provider error", query: "error=access_denied&state=abc", cookie: "abc"},
+		{name: "unknown state", query: "state=abc&code=xyz", cookie: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			handler.OIDCCallback(w, req)
+
+			if w.Code != http.StatusFound {
+				t.Fatalf("Expected status 302, got %d", w.Code)
+			}
+			location, _ := url.Parse(w.Header().Get("Location"))
+			if location.Path != "/login" || location.Query().Get("sso_error") == "" {
+				t.Errorf("Expected a redirect to the login page with an error, got %q", w.Header().Get("Location"))
+			}
+			for _, c := range w.Result().Cookies() {
+				if c.Name == middleware.AuthCookieName {
+					t.Error("Auth cookie must not be set on a failed sign-in")
+				}
+				if c.Name == oidcStateCookieName && c.MaxAge >= 0 {
+					t.Error("State cookie must be cleared")
+				}
+			}
+			if strings.Contains(w.Header().Get("Location"), "xyz") {
+				t.Error("Authorization code must not be echoed back")
+			}
+		})
+	}
+}
@@ -12,7 +12,10 @@ import (
 )
 
 // RulesHandler handles advanced rules management requests
-type RulesHandler struct{}
+type RulesHandler struct {
+	auditor
+	configWriter
+}
 
 // NewRulesHandler creates a new RulesHandler
 func NewRulesHandler() *RulesHandler {
@@ -53,6 +56,15 @@ type CreateRuleRequest struct {
 	MaxAge         string            `json:"max_age,omitempty"`
 	RequireWatched bool              `json:"require_watched,omitempty"`
 	Users          []config.UserRule `json:"users,omitempty"`
+
+	EpisodeDeleteStrategy string `json:"episode_delete_strategy,omitempty"`
+	RollingScope          string `json:"rolling_scope,omitempty"`
+	RollingBuffer         int    `json:"rolling_buffer,omitempty"`
+	OnlyWatchedEpisodes   bool   `json:"only_watched_episodes,omitempty"`
+	KeepNextNUnwatched    int    `json:"keep_next_n_unwatched,omitempty"`
+	WatchedRetention      string `json:"watched_retention,omitempty"`
+	UnmonitorDeleted      bool   `json:"unmonitor_deleted,omitempty"`
+	UnmonitorPastSeasons  bool   `json:"unmonitor_past_seasons,omitempty"`
 }
 
 // CreateRule handles POST /api/rules
@@ -77,6 +89,15 @@ func (h *RulesHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		MaxAge:         req.MaxAge,
 		RequireWatched: req.RequireWatched,
 		Users:          req.Users,
+
+		EpisodeDeleteStrategy: req.EpisodeDeleteStrategy,
+		RollingScope:          req.RollingScope,
+		RollingBuffer:         req.RollingBuffer,
+		OnlyWatchedEpisodes:   req.OnlyWatchedEpisodes,
+		KeepNextNUnwatched:    req.KeepNextNUnwatched,
+		WatchedRetention:      req.WatchedRetention,
+		UnmonitorDeleted:      req.UnmonitorDeleted,
+		UnmonitorPastSeasons:  req.UnmonitorPastSeasons,
 	}
 
 	if err := validateRule(&rule); err != nil {
@@ -126,7 +147,7 @@ func (h *RulesHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Write to config file
-	if err := writeConfigToFile(newCfg); err != nil {
+	if err := h.writeConfig(r, newCfg); err != nil {
 		log.Error().Err(err).Msg("Failed to write config to file")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -144,6 +165,7 @@ func (h *RulesHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Str("name", rule.Name).Msg("Rule created successfully")
+	h.audit(r, "rule.create", rule.Name, nil, rule)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(rule)
@@ -179,6 +201,15 @@ func (h *RulesHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 		MaxAge:         req.MaxAge,
 		RequireWatched: req.RequireWatched,
 		Users:          req.Users,
+
+		EpisodeDeleteStrategy: req.EpisodeDeleteStrategy,
+		RollingScope:          req.RollingScope,
+		RollingBuffer:         req.RollingBuffer,
+		OnlyWatchedEpisodes:   req.OnlyWatchedEpisodes,
+		KeepNextNUnwatched:    req.KeepNextNUnwatched,
+		WatchedRetention:      req.WatchedRetention,
+		UnmonitorDeleted:      req.UnmonitorDeleted,
+		UnmonitorPastSeasons:  req.UnmonitorPastSeasons,
 	}
 
 	// Serialize the read-modify-write so concurrent updates can't lose changes.
@@ -253,7 +284,7 @@ func (h *RulesHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Write to config file
-	if err := writeConfigToFile(newCfg); err != nil {
+	if err := h.writeConfig(r, newCfg); err != nil {
 		log.Error().Err(err).Msg("Failed to write config to file")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -271,6 +302,7 @@ func (h *RulesHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Str("name", updatedRule.Name).Msg("Rule updated successfully")
+	h.audit(r, "rule.update", ruleName, cfg.AdvancedRules[ruleIndex], updatedRule)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(updatedRule)
@@ -322,7 +354,7 @@ func (h *RulesHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	newCfg.AdvancedRules = append(newCfg.AdvancedRules[:ruleIndex], newCfg.AdvancedRules[ruleIndex+1:]...)
 
 	// Write to config file
-	if err := writeConfigToFile(newCfg); err != nil {
+	if err := h.writeConfig(r, newCfg); err != nil {
 		log.Error().Err(err).Msg("Failed to write config to file")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -340,6 +372,7 @@ func (h *RulesHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Str("name", ruleName).Msg("Rule deleted successfully")
+	h.audit(r, "rule.delete", ruleName, cfg.AdvancedRules[ruleIndex], nil)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "Rule deleted successfully"})
@@ -405,7 +438,7 @@ func (h *RulesHandler) ToggleRule(w http.ResponseWriter, r *http.Request) {
 	newCfg.AdvancedRules[ruleIndex].Enabled = req.Enabled
 
 	// Write to config file
-	if err := writeConfigToFile(newCfg); err != nil {
+	if err := h.writeConfig(r, newCfg); err != nil {
 		log.Error().Err(err).Msg("Failed to write config to file")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -423,6 +456,7 @@ func (h *RulesHandler) ToggleRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Str("name", ruleName).Bool("enabled", req.Enabled).Msg("Rule toggled successfully")
+	h.audit(r, "rule.toggle", ruleName, cfg.AdvancedRules[ruleIndex], newCfg.AdvancedRules[ruleIndex])
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(newCfg.AdvancedRules[ruleIndex])
@@ -454,8 +488,14 @@ func validateRule(rule *config.AdvancedRule) error {
 			return ErrInvalidInput{Field: "retention", Message: "Retention is required for tag-based rules"}
 		}
 	case "episode":
-		if rule.MaxEpisodes <= 0 && rule.MaxAge == "" {
-			return ErrInvalidInput{Field: "max_episodes", Message: "Either max_episodes or max_age is required for episode rules"}
+		if rule.MaxEpisodes <= 0 && rule.MaxAge == "" && rule.WatchedRetention == "" && rule.EpisodeDeleteStrategy != "rolling" {
+			return ErrInvalidInput{Field: "max_episodes", Message: "One of max_episodes, max_age or watched_retention is required for episode rules"}
+		}
+		if rule.RollingBuffer < 0 {
+			return ErrInvalidInput{Field: "rolling_buffer", Message: "rolling_buffer must not be negative"}
+		}
+		if rule.KeepNextNUnwatched < 0 {
+			return ErrInvalidInput{Field: "keep_next_n_unwatched", Message: "keep_next_n_unwatched must not be negative"}
 		}
 	case "user":
 		if len(rule.Users) == 0 {
@@ -497,12 +537,17 @@ func (e ErrInvalidInput) Error() string {
 }
 
 // cloneConfigWithRules returns a deep copy of cfg whose AdvancedRules slice
-// (including each rule's Users slice) is detached from the live config.
+// (including each rule's Users slice) and deletion blackout windows are
+// detached from the live config.
 // `newCfg := *cfg` is only a shallow copy, so appending/replacing/removing
 // elements (and toggle's element mutation) would otherwise write into the
 // shared backing array that concurrent readers see.
 func cloneConfigWithRules(cfg *config.Config) *config.Config {
 	clone := *cfg
+	if cfg.Sync.DeletionBlackouts != nil {
+		clone.Sync.DeletionBlackouts = make([]config.BlackoutWindow, len(cfg.Sync.DeletionBlackouts))
+		copy(clone.Sync.DeletionBlackouts, cfg.Sync.DeletionBlackouts)
+	}
 	if cfg.AdvancedRules != nil {
 		clone.AdvancedRules = make([]config.AdvancedRule, len(cfg.AdvancedRules))
 		for i, rule := range cfg.AdvancedRules {
@@ -18,7 +18,7 @@ import (
 
 // loadTestConfig creates a temp config file, loads it into the global config,
 // and returns its path. The config is valid so the rule/config handlers can
-// re-validate the written config and persist via writeConfigToFile + Reload.
+// re-validate the written config and persist via writeConfigFile + Reload.
 func loadTestConfig(t *testing.T) string {
 	t.Helper()
 	path := filepath.Join(t.TempDir(), "config.yaml")
@@ -55,7 +55,9 @@ func seedRules(t *testing.T, rules []config.AdvancedRule) {
 	require.NotNil(t, cfg, "config must be loaded before seeding rules")
 	newCfg := cloneConfigWithRules(cfg)
 	newCfg.AdvancedRules = rules
-	require.NoError(t, writeConfigToFile(newCfg))
+	content, err := marshalConfig(newCfg)
+	require.NoError(t, err)
+	require.NoError(t, writeConfigFile(content))
 	require.NoError(t, config.Reload())
 }
 
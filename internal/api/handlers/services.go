@@ -26,6 +26,9 @@ type ServiceStatusHandler struct {
 // serviceCheck bundles the metadata and pinger for one service.
 type serviceCheck struct {
 	name    string
+	client  string // integration name used by the client transport
+	url     string
+	apiKey  string
 	enabled bool
 	pinger  func(context.Context) error
 }
@@ -42,6 +45,9 @@ type ServiceStatus struct {
 	Online  bool   `json:"online"`
 	Error   string `json:"error,omitempty"`
 	Latency string `json:"latency,omitempty"`
+	// Transport holds request, retry and circuit breaker stats for the
+	// integration since startup (absent until a request has been made)
+	Transport *clients.TransportStats `json:"transport,omitempty"`
 }
 
 // ServiceStatusResponse represents the response for service status check
@@ -49,18 +55,19 @@ type ServiceStatusResponse struct {
 	Services []ServiceStatus `json:"services"`
 }
 
-// buildChecks derives the pinger list from the current config.
+// buildServiceChecks derives the pinger list from cfg.
 // Client instances are cheap to build (config struct + http.Client), but
 // rebuilding them on every request still churns allocations, so CheckStatus
 // caches them and only rebuilds when the config pointer changes (hot-reload).
-func (h *ServiceStatusHandler) buildChecks(cfg *config.Config) []serviceCheck {
+func buildServiceChecks(cfg *config.Config) []serviceCheck {
+	integrations := cfg.Integrations
 	return []serviceCheck{
-		{name: "Jellyfin", enabled: cfg.Integrations.Jellyfin.Enabled, pinger: clients.NewJellyfinClient(cfg.Integrations.Jellyfin).Ping},
-		{name: "Radarr", enabled: cfg.Integrations.Radarr.Enabled, pinger: clients.NewRadarrClient(cfg.Integrations.Radarr).Ping},
-		{name: "Sonarr", enabled: cfg.Integrations.Sonarr.Enabled, pinger: clients.NewSonarrClient(cfg.Integrations.Sonarr).Ping},
-		{name: "Jellyseerr", enabled: cfg.Integrations.Jellyseerr.Enabled, pinger: clients.NewJellyseerrClient(cfg.Integrations.Jellyseerr).Ping},
-		{name: "Jellystat", enabled: cfg.Integrations.Jellystat.Enabled, pinger: clients.NewJellystatClient(cfg.Integrations.Jellystat).Ping},
-		{name: "Streamystats", enabled: cfg.Integrations.Streamystats.Enabled, pinger: clients.NewStreamystatsClient(cfg.Integrations.Streamystats).Ping},
+		{name: "Jellyfin", client: "jellyfin", url: integrations.Jellyfin.URL, apiKey: integrations.Jellyfin.APIKey, enabled: integrations.Jellyfin.Enabled, pinger: clients.NewJellyfinClient(integrations.Jellyfin).Ping},
+		{name: "Radarr", client: "radarr", url: integrations.Radarr.URL, apiKey: integrations.Radarr.APIKey, enabled: integrations.Radarr.Enabled, pinger: clients.NewRadarrClient(integrations.Radarr).Ping},
+		{name: "Sonarr", client: "sonarr", url: integrations.Sonarr.URL, apiKey: integrations.Sonarr.APIKey, enabled: integrations.Sonarr.Enabled, pinger: clients.NewSonarrClient(integrations.Sonarr).Ping},
+		{name: "Jellyseerr", client: "jellyseerr", url: integrations.Jellyseerr.URL, apiKey: integrations.Jellyseerr.APIKey, enabled: integrations.Jellyseerr.Enabled, pinger: clients.NewJellyseerrClient(integrations.Jellyseerr).Ping},
+		{name: "Jellystat", client: "jellystat", url: integrations.Jellystat.URL, apiKey: integrations.Jellystat.APIKey, enabled: integrations.Jellystat.Enabled, pinger: clients.NewJellystatClient(integrations.Jellystat).Ping},
+		{name: "Streamystats", client: "streamystats", url: integrations.Streamystats.URL, apiKey: integrations.Streamystats.APIKey, enabled: integrations.Streamystats.Enabled, pinger: clients.NewStreamystatsClient(integrations.Streamystats).Ping},
 	}
 }
 
@@ -76,7 +83,7 @@ func (h *ServiceStatusHandler) CheckStatus(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Reuse cached client instances; rebuild only when config was reloaded.
-	// buildChecks runs outside the lock so a panic there (e.g. nil config)
+	// buildServiceChecks runs outside the lock so a panic there (e.g. nil config)
 	// cannot leave h.mu held forever and wedge every later request.
 	h.mu.Lock()
 	rebuild := h.lastCfg != cfg
@@ -84,7 +91,7 @@ func (h *ServiceStatusHandler) CheckStatus(w http.ResponseWriter, r *http.Reques
 	h.mu.Unlock()
 
 	if rebuild {
-		checks = h.buildChecks(cfg)
+		checks = buildServiceChecks(cfg)
 		h.mu.Lock()
 		h.lastCfg = cfg
 		h.checks = checks
@@ -122,6 +129,12 @@ func (h *ServiceStatusHandler) CheckStatus(w http.ResponseWriter, r *http.Reques
 			status.Online = true
 			status.Latency = time.Since(start).String()
 		}
+		if stats, ok := clients.GetTransportStats(check.client, check.url); ok {
+			if stats.LastError != "" {
+				stats.LastError = sanitizePingError(errors.New(stats.LastError))
+			}
+			status.Transport = &stats
+		}
 		resultsChan <- status
 	}
 
@@ -184,6 +197,10 @@ func sanitizePingError(err error) string {
 		err = uerr.Err
 	}
 
+	if errors.Is(err, clients.ErrCircuitOpen) {
+		return "circuit breaker open"
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Status-code errors from the clients are already safe (no URL embedded).
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"fmt"
 	"net/url"
 	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients"
 )
 
 func TestSanitizePingErrorScrubsHosts(t *testing.T) {
@@ -21,6 +24,7 @@ func TestSanitizePingErrorScrubsHosts(t *testing.T) {
 		{"single-label host in write error", errWith("write tcp jellyfin:8096->10.0.0.5:443: broken pipe"), "write tcp endpoint->endpoint: broken pipe"},
 		{"zone-qualified ipv6", errWith("write tcp [fe80::1%eth0]:8096->[fe80::2%eth0]:443: broken pipe"), "write tcp endpoint->endpoint: broken pipe"},
 		{"x509 cert hostname leak", errWith("x509: certificate is valid for *.media.local, not jellyfin.internal"), "certificate verification failed"},
+		{"circuit breaker open", fmt.Errorf("making request: %w", fmt.Errorf("radarr: %w until 2026-01-01T10:00:00Z", clients.ErrCircuitOpen)), "circuit breaker open"},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +31,39 @@ func TestServiceStatusHandler_CheckStatus_CompletesWithUnreachableService(t *tes
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
+func TestServiceStatusHandler_CheckStatus_IncludesTransportStats(t *testing.T) {
+	radarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer radarr.Close()
+
+	cfg := &config.Config{}
+	cfg.Integrations.Radarr.Enabled = true
+	cfg.Integrations.Radarr.URL = radarr.URL
+	cfg.Integrations.Radarr.APIKey = "key"
+
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	h := NewServiceStatusHandler()
+	rec := httptest.NewRecorder()
+	h.CheckStatus(rec, httptest.NewRequest(http.MethodGet, "/api/system/services", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response ServiceStatusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	for _, status := range response.Services {
+		if status.Name != "Radarr" {
+			assert.Nil(t, status.Transport, status.Name)
+			continue
+		}
+		assert.True(t, status.Online)
+		require.NotNil(t, status.Transport)
+		assert.Equal(t, int64(1), status.Transport.Requests)
+		assert.Equal(t, clients.CircuitClosed, status.Transport.CircuitState)
+	}
+}
+
 func TestRecoverPanic(t *testing.T) {
 	assert.NotPanics(t, func() {
 		func() {
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// refreshCookieName carries the refresh token for the web UI. It is only
+	// sent to the auth endpoints that refresh and end sessions.
+	refreshCookieName = "oxicleanarr_refresh"
+	refreshCookiePath = "/api/auth"
+)
+
+// RefreshRequest is the optional body of POST /api/auth/refresh. Browsers
+// send the refresh cookie instead.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SessionResponse describes a session in the session list
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	Method     string    `json:"method,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+func newSessionResponse(session storage.Session, currentID string) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		Username:   session.Username,
+		Method:     session.Method,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		Current:    session.ID == currentID,
+	}
+}
+
+// Refresh handles POST /api/auth/refresh. It exchanges the refresh token for
+// a new access token and a new refresh token, and sets both cookies. A
+// replaced refresh token that is used again ends the session.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLoginBodyBytes)
+
+	var req RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		if c, err := r.Cookie(refreshCookieName); err == nil {
+			req.RefreshToken = c.Value
+		}
+	}
+
+	result, err := h.authService.RefreshSession(req.RefreshToken, clientInfo(r))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			status = http.StatusUnauthorized
+			clearAuthCookies(w)
+		case errors.Is(err, services.ErrSessionsUnavailable):
+			status = http.StatusServiceUnavailable
+		default:
+			log.Error().Err(err).Msg("Failed to refresh session")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	setSessionCookies(w, result)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newLoginResponse(result))
+}
+
+// ListSessions handles GET /api/auth/sessions. Users see their own active
+// sessions; admins see everyone's with ?all=true.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionUser(w, r)
+	if !ok {
+		return
+	}
+
+	username := claims.Username
+	if r.URL.Query().Get("all") == "true" && utils.RoleAllows(claims.Role, utils.RoleAdmin) {
+		username = ""
+	}
+
+	sessions, err := h.authService.ListSessions(username)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, newSessionResponse(session, claims.SessionID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": resp,
+		"total":    len(resp),
+	})
+}
+
+// RevokeSession handles DELETE /api/auth/sessions/{id}. Users can end their
+// own sessions; admins can end anyone's. Ending the current session also
+// clears the cookies.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := sessionUser(w, r)
+	if !ok {
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	owner := claims.Username
+	if utils.RoleAllows(claims.Role, utils.RoleAdmin) {
+		owner = ""
+	}
+
+	session, err := h.authService.RevokeSession(id, owner)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	h.audit(r, "auth.session.revoke", session.Username, newSessionResponse(session, ""), nil)
+
+	if id == claims.SessionID {
+		clearAuthCookies(w)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Session revoked"})
+}
+
+// sessionUser returns the claims of the signed-in user. API keys and
+// disabled auth have no session, so they are refused.
+func sessionUser(w http.ResponseWriter, r *http.Request) (*utils.JWTClaims, bool) {
+	if claims := middleware.GetUserFromContext(r.Context()); claims != nil {
+		return claims, true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: "Sessions are only available to signed-in users"})
+	return nil, false
+}
+
+// writeSessionError maps session management errors to HTTP responses
+func writeSessionError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrSessionsUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("Session operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
+
+// clientInfo describes the caller for sign-in throttling and the session list
+func clientInfo(r *http.Request) services.ClientInfo {
+	return services.ClientInfo{IP: clientIP(r), UserAgent: r.UserAgent()}
+}
+
+// setSessionCookies writes the access token cookie and, when the sign-in
+// started a session, the refresh token cookie. Both are httpOnly and
+// SameSite=Lax.
+func setSessionCookies(w http.ResponseWriter, result services.LoginResult) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.AuthCookieName,
+		Value:    result.Token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   cookieMaxAge(result.ExpiresAt),
+	})
+	if result.RefreshToken == "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    result.RefreshToken,
+		Path:     refreshCookiePath,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   cookieMaxAge(result.RefreshExpiresAt),
+	})
+}
+
+// clearAuthCookies removes the access and refresh token cookies
+func clearAuthCookies(w http.ResponseWriter) {
+	for _, c := range []struct{ name, path string }{
+		{middleware.AuthCookieName, "/"},
+		{refreshCookieName, refreshCookiePath},
+	} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     c.name,
+			Value:    "",
+			Path:     c.path,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			MaxAge:   -1,
+			Expires:  time.Unix(1, 0),
+		})
+	}
+}
+
+// cookieMaxAge returns the seconds until expiresAt, at least one so the
+// cookie is not deleted outright
+func cookieMaxAge(expiresAt time.Time) int {
+	return max(int(time.Until(expiresAt).Seconds()), 1)
+}
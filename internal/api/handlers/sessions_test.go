@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+// setupSessionsHandler returns an AuthHandler with a user store and a
+// session store, with the auth middleware checking sessions
+func setupSessionsHandler(t *testing.T) *AuthHandler {
+	t.Helper()
+	handler, _ := setupTwoFactorHandler(t)
+	sessions, err := storage.NewSessionsFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionsFile failed: %v", err)
+	}
+	handler.authService.SetSessions(sessions)
+	middleware.SetSessionCheck(handler.authService.SessionActive)
+	t.Cleanup(func() { middleware.SetSessionCheck(nil) })
+	return handler
+}
+
+// sessionCookies returns the access and refresh cookies set on w
+func sessionCookies(w *httptest.ResponseRecorder) (access, refresh *http.Cookie) {
+	for _, c := range w.Result().Cookies() {
+		switch c.Name {
+		case middleware.AuthCookieName:
+			access = c
+		case refreshCookieName:
+			refresh = c
+		}
+	}
+	return access, refresh
+}
+
+func TestAuthHandler_SessionLifecycle(t *testing.T) {
+	handler := setupSessionsHandler(t)
+
+	login := httptest.NewRecorder()
+	req := postJSON(t, "/api/auth/login", LoginRequest{Username: "admin", Password: "testpassword"})
+	req.Header.Set("User-Agent", "session-test")
+	handler.Login(login, req)
+	if login.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", login.Code)
+	}
+	var resp LoginResponse
+	json.NewDecoder(login.Body).Decode(&resp)
+	if resp.RefreshToken == "" || resp.ExpiresIn <= 0 {
+		t.Errorf("Expected a refresh token and expiry, got %+v", resp)
+	}
+	access, refresh := sessionCookies(login)
+	if access == nil || refresh == nil || refresh.Path != refreshCookiePath || !refresh.HttpOnly {
+		t.Fatalf("Expected access and refresh cookies, got %v %v", access, refresh)
+	}
+
+	// Refresh with the cookie rotates both tokens
+	w := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
+	req.AddCookie(refresh)
+	handler.Refresh(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from refresh, got %d: %s", w.Code, w.Body.String())
+	}
+	newAccess, newRefresh := sessionCookies(w)
+	if newAccess == nil || newRefresh == nil || newRefresh.Value == refresh.Value {
+		t.Fatalf("Expected rotated cookies, got %v %v", newAccess, newRefresh)
+	}
+
+	// The session list marks the current session
+	w = withAuth(handler.ListSessions, httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil), newAccess.Value)
+	var list struct {
+		Sessions []SessionResponse `json:"sessions"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if w.Code != http.StatusOK || len(list.Sessions) != 1 || !list.Sessions[0].Current || list.Sessions[0].UserAgent != "session-test" {
+		t.Fatalf("Expected the current session, got %d %+v", w.Code, list.Sessions)
+	}
+
+	// Revoking it ends access right away and clears the cookies
+	req = httptest.NewRequest(http.MethodDelete, "/api/auth/sessions/"+list.Sessions[0].ID, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", list.Sessions[0].ID)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w = withAuth(handler.RevokeSession, req, newAccess.Value)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from revoke, got %d: %s", w.Code, w.Body.String())
+	}
+	if cleared, _ := sessionCookies(w); cleared == nil || cleared.MaxAge >= 0 {
+		t.Error("Expected revoking the current session to clear the cookie")
+	}
+
+	w = withAuth(handler.ListSessions, httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil), newAccess.Value)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after revoking, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
+	req.AddCookie(newRefresh)
+	handler.Refresh(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 refreshing a revoked session, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_LogoutEndsSession(t *testing.T) {
+	handler := setupSessionsHandler(t)
+
+	result, err := handler.authService.Login(t.Context(), "admin", "testpassword", services.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.AuthCookieName, Value: result.Token})
+	w := httptest.NewRecorder()
+	handler.Logout(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if _, err := handler.authService.ValidateToken(result.Token); err == nil {
+		t.Error("Expected the token to stop working after logout")
+	}
+}
+
+func TestAuthHandler_RefreshWithBody(t *testing.T) {
+	handler := setupSessionsHandler(t)
+
+	result, err := handler.authService.Login(t.Context(), "admin", "testpassword", services.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.Refresh(w, postJSON(t, "/api/auth/refresh", RefreshRequest{RefreshToken: result.RefreshToken}))
+	var resp LoginResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.Token == "" || resp.RefreshToken == "" {
+		t.Fatalf("Expected new tokens, got %d %+v", w.Code, resp)
+	}
+
+	w = httptest.NewRecorder()
+	handler.Refresh(w, postJSON(t, "/api/auth/refresh", RefreshRequest{RefreshToken: "bogus"}))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown refresh token, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_DemotionEndsSession(t *testing.T) {
+	handler := setupSessionsHandler(t)
+	if _, err := handler.authService.CreateUser("alice", "alicepassword", utils.RoleAdmin); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	result, err := handler.authService.Login(t.Context(), "alice", "alicepassword", services.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := handler.authService.UpdateUser("alice", "", utils.RoleViewer); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+
+	w := withAuth(handler.ListSessions, httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil), result.Token)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the demoted user's next request to get 401, got %d", w.Code)
+	}
+}
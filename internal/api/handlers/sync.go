@@ -4,15 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/ramonskie/oxicleanarr/internal/services"
 	"github.com/rs/zerolog/log"
 )
 
 // SyncHandler handles sync-related requests
 type SyncHandler struct {
+	auditor
 	syncEngine *services.SyncEngine
 }
 
@@ -27,21 +28,87 @@ func NewSyncHandler(syncEngine *services.SyncEngine) *SyncHandler {
 func (h *SyncHandler) TriggerFullSync(w http.ResponseWriter, r *http.Request) {
 	log.Info().Msg("Manual full sync triggered via API")
 
-	// Use background context for async operation, not request context
-	// which would be canceled when the response is sent
-	go func() {
-		defer recoverPanic("manual full sync")
-		ctx := context.Background()
-		if err := h.syncEngine.FullSync(ctx); err != nil {
-			log.Error().Err(err).Msg("Manual full sync failed")
-		}
-	}()
+	// The job is queued behind any running sync and runs in the background;
+	// its record shows phase progress and can be cancelled.
+	job, err := h.syncEngine.StartFullSync()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start full sync")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": "Failed to start full sync",
+		})
+		return
+	}
+	h.audit(r, "sync.full", job.ID, nil, nil)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
 		"message": "Full sync started",
+		"job_id":  job.ID,
+		"status":  job.Status,
+	})
+}
+
+// CancelJob handles POST /api/jobs/{id}/cancel
+func (h *SyncHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	err := h.syncEngine.CancelJob(jobID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrJobNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrJobNotActive):
+			status = http.StatusConflict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.audit(r, "job.cancel", jobID, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"job_id":  jobID,
+		"message": "Cancellation requested",
+	})
+}
+
+// RemonitorJob handles POST /api/jobs/{id}/remonitor, monitoring again in
+// Sonarr the episodes a deletion job unmonitored
+func (h *SyncHandler) RemonitorJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+
+	remonitored, err := h.syncEngine.RemonitorJobEpisodes(r.Context(), jobID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrJobNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrNothingToRemonitor):
+			status = http.StatusConflict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	h.audit(r, "job.remonitor", jobID, nil, map[string]int{"episodes_remonitored": remonitored})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":              true,
+		"job_id":               jobID,
+		"episodes_remonitored": remonitored,
 	})
 }
 
@@ -58,6 +125,7 @@ func (h *SyncHandler) TriggerIncrementalSync(w http.ResponseWriter, r *http.Requ
 			log.Error().Err(err).Msg("Manual incremental sync failed")
 		}
 	}()
+	h.audit(r, "sync.incremental", "", nil, nil)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -76,10 +144,10 @@ func (h *SyncHandler) GetSyncStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// ExecuteDeletions handles POST /api/deletions/execute
+// ExecuteDeletions handles POST /api/deletions/execute. With dry_run=true it
+// returns the current candidates; otherwise it starts a deletion job and
+// returns its ID (poll GET /api/jobs/{id} for per-item results).
 func (h *SyncHandler) ExecuteDeletions(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	// Check for dry-run query parameter
 	dryRun := r.URL.Query().Get("dry_run") == "true"
 
@@ -119,42 +187,36 @@ func (h *SyncHandler) ExecuteDeletions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute actual deletions
-	deletedCount, _, episodeFilesDeleted, protectedCount, failedCount, deletedItems, err := h.syncEngine.ExecuteDeletionsLocked(ctx, candidates)
-	if errors.Is(err, services.ErrSyncInProgress) {
+	// Queue a deletion job; it runs in the background once no sync is running
+	job, err := h.syncEngine.StartDeletionJob()
+	if errors.Is(err, services.ErrApprovalRequired) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"message": "A sync is currently in progress; retry once it finishes",
+			"message": err.Error(),
 		})
 		return
 	}
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute deletions")
+		log.Error().Err(err).Msg("Failed to start deletion job")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"message": "Failed to execute deletions",
+			"message": "Failed to start deletion job",
 		})
 		return
 	}
+	h.audit(r, "deletions.execute", job.ID, nil, map[string]int{"scheduled_count": scheduledCount})
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	message := "Deletion execution completed"
-	if failedCount > 0 {
-		message = fmt.Sprintf("Deletion execution completed with %d failure(s)", failedCount)
-	}
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":               failedCount == 0,
-		"scheduled_count":       scheduledCount,
-		"deleted_count":         deletedCount,
-		"episode_files_deleted": episodeFilesDeleted,
-		"protected_count":       protectedCount,
-		"failed_count":          failedCount,
-		"message":               message,
-		"deleted_items":         deletedItems,
+		"success":         true,
+		"job_id":          job.ID,
+		"status":          job.Status,
+		"scheduled_count": scheduledCount,
+		"message":         "Deletion job started",
 	})
 }
@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/ramonskie/oxicleanarr/internal/cache"
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/ramonskie/oxicleanarr/internal/services"
 	"github.com/ramonskie/oxicleanarr/internal/services/rules"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
@@ -73,6 +76,8 @@ func TestSyncHandler_TriggerFullSync(t *testing.T) {
 
 		assert.True(t, response["success"].(bool))
 		assert.Equal(t, "Full sync started", response["message"])
+		assert.NotEmpty(t, response["job_id"])
+		waitForIdleEngine(t, engine)
 	})
 
 	t.Run("accepts request with context", func(t *testing.T) {
@@ -86,6 +91,69 @@ func TestSyncHandler_TriggerFullSync(t *testing.T) {
 		handler.TriggerFullSync(w, req)
 
 		assert.Equal(t, http.StatusAccepted, w.Code)
+		waitForIdleEngine(t, engine)
+	})
+}
+
+// waitForIdleEngine waits until no job is queued or running, so background
+// jobs do not outlive the test's temp directory
+func waitForIdleEngine(t *testing.T, engine *services.SyncEngine) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		status := engine.GetStatus()
+		return status.RunningJobID == "" && status.QueuedJobs == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSyncHandler_CancelJob(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	router := chi.NewRouter()
+	router.Post("/api/jobs/{id}/cancel", NewSyncHandler(engine).CancelJob)
+
+	t.Run("unknown job returns 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/nope/cancel", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("finished job returns 409", func(t *testing.T) {
+		job, err := engine.StartFullSync()
+		require.NoError(t, err)
+		waitForIdleEngine(t, engine)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/"+job.ID+"/cancel", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestSyncHandler_RemonitorJob(t *testing.T) {
+	engine := newTestSyncEngineForAPI(t)
+	router := chi.NewRouter()
+	router.Post("/api/jobs/{id}/remonitor", NewSyncHandler(engine).RemonitorJob)
+
+	t.Run("unknown job returns 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/nope/remonitor", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("job without unmonitored episodes returns 409", func(t *testing.T) {
+		job, err := engine.StartFullSync()
+		require.NoError(t, err)
+		waitForIdleEngine(t, engine)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs/"+job.ID+"/remonitor", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
 	})
 }
 
@@ -208,43 +276,70 @@ func TestSyncHandler_ExecuteDeletions(t *testing.T) {
 		}
 	})
 
-	t.Run("executes deletions when dry_run=false", func(t *testing.T) {
+	t.Run("starts a deletion job when dry_run=false", func(t *testing.T) {
 		engine := newTestSyncEngineForAPI(t)
 		handler := NewSyncHandler(engine)
 
-		// Trigger a full sync to populate some data
-		ctx := context.Background()
-		err := engine.FullSync(ctx)
-		require.NoError(t, err)
+		// Make a movie overdue so there is something to delete
+		engine.GetMediaLibrary()["radarr-1"] = models.Media{
+			ID:      "radarr-1",
+			Type:    models.MediaTypeMovie,
+			Title:   "Overdue Movie",
+			AddedAt: time.Now().AddDate(0, 0, -400),
+		}
+		engine.ReapplyRetentionRules()
 
 		req := httptest.NewRequest(http.MethodPost, "/api/deletions/execute?dry_run=false", nil)
 		w := httptest.NewRecorder()
 
 		handler.ExecuteDeletions(w, req)
 
-		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusAccepted, w.Code)
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
 
 		var response map[string]interface{}
-		err = json.NewDecoder(w.Body).Decode(&response)
+		err := json.NewDecoder(w.Body).Decode(&response)
 		require.NoError(t, err)
 
 		assert.True(t, response["success"].(bool))
-		// Message depends on whether there are deletions or not
-		assert.NotEmpty(t, response["message"])
-
-		// Verify response includes expected fields
-		assert.NotNil(t, response["scheduled_count"])
-
-		// If there are scheduled deletions, should have deleted_count
-		scheduledCount := int(response["scheduled_count"].(float64))
-		if scheduledCount > 0 {
-			assert.NotNil(t, response["deleted_count"])
-			assert.NotNil(t, response["failed_count"])
-		} else {
-			// No scheduled deletions means no deleted_count field
-			assert.Nil(t, response["deleted_count"])
+		assert.Equal(t, float64(1), response["scheduled_count"])
+		jobID, _ := response["job_id"].(string)
+		require.NotEmpty(t, jobID)
+		assert.Nil(t, response["deleted_count"], "results are reported on the job, not the response")
+
+		// The job runs in the background; wait until it is recorded as done
+		require.Eventually(t, func() bool {
+			return !engine.GetStatus().LastDeletion.IsZero()
+		}, 5*time.Second, 10*time.Millisecond)
+		_, found := engine.GetMediaByID("radarr-1")
+		assert.False(t, found)
+	})
+
+	t.Run("refuses to delete in approval mode", func(t *testing.T) {
+		engine := newTestSyncEngineForAPI(t)
+		handler := NewSyncHandler(engine)
+
+		// The engine shares the global test config
+		cfg := config.Get()
+		cfg.App.EnableDeletion = true
+		cfg.App.Approval.Enabled = true
+
+		engine.GetMediaLibrary()["radarr-1"] = models.Media{
+			ID:      "radarr-1",
+			Type:    models.MediaTypeMovie,
+			Title:   "Overdue Movie",
+			AddedAt: time.Now().AddDate(0, 0, -400),
 		}
+		engine.ReapplyRetentionRules()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/deletions/execute", nil)
+		w := httptest.NewRecorder()
+
+		handler.ExecuteDeletions(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		_, found := engine.GetMediaByID("radarr-1")
+		assert.True(t, found, "nothing is deleted without an approved batch")
 	})
 
 	t.Run("defaults to actual execution when no query param", func(t *testing.T) {
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 // SystemHandler handles system-level operations
 type SystemHandler struct {
+	auditor
 	syncEngine   *services.SyncEngine
 	shutdownCh   chan struct{}
 	shutdownOnce sync.Once
@@ -80,6 +83,7 @@ func (h *SystemHandler) Restart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Info().Bool("force", req.Force).Msg("Application restart requested via API")
+	h.audit(r, "system.restart", "", nil, map[string]bool{"force": req.Force})
 
 	// Send success response before shutting down.
 	w.Header().Set("Content-Type", "application/json")
@@ -182,3 +186,46 @@ func (h *SystemHandler) GetDiskStatus(w http.ResponseWriter, r *http.Request) {
 		"check_source":       status.CheckSource,
 	})
 }
+
+// maxForecastDays bounds the forecast horizon accepted by GetDiskForecast.
+const maxForecastDays = 365
+
+// GetDiskForecast handles GET /api/system/disk/forecast
+//
+// Query params:
+//   - days: forecast horizon in days, default 90, max 365
+func (h *SystemHandler) GetDiskForecast(w http.ResponseWriter, r *http.Request) {
+	days := 90
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxForecastDays {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "days must be an integer between 1 and 365"})
+			return
+		}
+		days = n
+	}
+
+	forecast, err := h.syncEngine.ForecastDisk(days)
+	if errors.Is(err, services.ErrDiskForecastUnavailable) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": false,
+			"message": "Disk forecast requires disk threshold monitoring and at least one recorded reading",
+		})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build disk forecast")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to build disk forecast"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(forecast)
+}
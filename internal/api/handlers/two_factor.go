@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/rs/zerolog/log"
+)
+
+// TwoFactorLoginRequest is the body of POST /api/auth/login/2fa
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodesResponse lists recovery codes, which are only ever shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginTwoFactor handles POST /api/auth/login/2fa, the second sign-in step.
+// The code is a TOTP code or a recovery code; for a challenge that came with
+// an enrollment it must be a TOTP code, and the response includes the new
+// recovery codes.
+func (h *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLoginBodyBytes)
+
+	var req TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	result, err := h.authService.CompleteTwoFactorLogin(req.Challenge, req.Code, clientInfo(r))
+	h.auditLogin(r, "totp", result.Username, result.Role, err)
+	if err != nil {
+		writeLoginError(w, result.Username, err)
+		return
+	}
+
+	h.completeLogin(w, result)
+}
+
+// GetTwoFactor handles GET /api/auth/2fa
+func (h *AuthHandler) GetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.authService.TwoFactorStatus(username)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// EnrollTwoFactor handles POST /api/auth/2fa/enroll. It returns a new secret
+// and its otpauth:// URI; two-factor sign-in starts once a code is confirmed.
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.authService.BeginTOTPEnrollment(username)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmTwoFactor handles POST /api/auth/2fa/confirm
+func (h *AuthHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.authService.ConfirmTOTPEnrollment(username, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	h.audit(r, "auth.2fa.enable", username, map[string]bool{"enabled": false}, map[string]bool{"enabled": true})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableTwoFactor handles POST /api/auth/2fa/disable
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DisableTOTP(username, req.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	h.audit(r, "auth.2fa.disable", username, map[string]bool{"enabled": true}, map[string]bool{"enabled": false})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles POST /api/auth/2fa/recovery-codes. The
+// previous recovery codes stop working.
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	username, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(username, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	h.audit(r, "auth.2fa.recovery_codes", username, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// ResetTwoFactor handles DELETE /api/users/{username}/2fa, for users who lost
+// their authenticator and recovery codes
+func (h *UsersHandler) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	if err := h.authService.ResetTOTP(username); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	log.Info().Str("username", username).Str("by", requestActor(r)).Msg("Two-factor authentication reset")
+	h.audit(r, "user.2fa.reset", username, map[string]bool{"enabled": true}, map[string]bool{"enabled": false})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication reset"})
+}
+
+// twoFactorUser returns the signed-in local user. API keys, disabled auth
+// and Jellyfin or single sign-on sessions have no local account to protect,
+// so they are refused.
+func (h *AuthHandler) twoFactorUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if claims := middleware.GetUserFromContext(r.Context()); claims != nil && h.authService.LocalAccount(claims) {
+		return claims.Username, true
+	}
+	writeTwoFactorError(w, services.ErrTwoFactorUnavailable)
+	return "", false
+}
+
+// decodeTwoFactorCode reads a TwoFactorCodeRequest body
+func decodeTwoFactorCode(w http.ResponseWriter, r *http.Request) (TwoFactorCodeRequest, bool) {
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return req, false
+	}
+	return req, true
+}
+
+// writeTwoFactorError maps two-factor management errors to HTTP responses
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode),
+		errors.Is(err, services.ErrTwoFactorUnavailable):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrTwoFactorEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnrolling):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrUsersUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("Two-factor operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
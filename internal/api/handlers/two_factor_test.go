@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/api/middleware"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+// currentTOTPStep returns the current TOTP time step. Tests use codes from
+// the steps before and after it, which only all match during this step, so
+// it first waits for the next step when fewer than 10 seconds are left.
+func currentTOTPStep(t *testing.T) int64 {
+	t.Helper()
+	now := time.Now()
+	next := now.Truncate(utils.TOTPPeriod).Add(utils.TOTPPeriod)
+	if remaining := next.Sub(now); remaining < 10*time.Second {
+		time.Sleep(remaining)
+	}
+	return utils.TOTPStep(time.Now())
+}
+
+// setupTwoFactorHandler returns an AuthHandler backed by a users file with
+// the admin migrated into it
+func setupTwoFactorHandler(t *testing.T) (*AuthHandler, *config.Config) {
+	t.Helper()
+	handler, cfg := setupAuthHandler(t)
+	config.SetTestConfig(cfg)
+	t.Cleanup(func() { config.SetTestConfig(nil) })
+	users, err := storage.NewUsersFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUsersFile failed: %v", err)
+	}
+	handler.authService.SetUsers(users)
+	if _, err := handler.authService.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	return handler, cfg
+}
+
+func postJSON(t *testing.T, target string, body interface{}) *http.Request {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// withAuth runs handler behind the auth middleware with the given token
+func withAuth(handler http.HandlerFunc, req *http.Request, token string) *httptest.ResponseRecorder {
+	req.AddCookie(&http.Cookie{Name: middleware.AuthCookieName, Value: token})
+	w := httptest.NewRecorder()
+	middleware.Auth(handler).ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthHandler_TwoFactorLogin(t *testing.T) {
+	handler, _ := setupTwoFactorHandler(t)
+	step := currentTOTPStep(t)
+
+	// Enroll through the self-service endpoints with a first-factor session
+	login := httptest.NewRecorder()
+	handler.Login(login, postJSON(t, "/api/auth/login", LoginRequest{Username: "admin", Password: "testpassword"}))
+	var session LoginResponse
+	json.NewDecoder(login.Body).Decode(&session)
+	if login.Code != http.StatusOK || session.Token == "" {
+		t.Fatalf("Expected a token before enrolling, got %d", login.Code)
+	}
+
+	w := withAuth(handler.EnrollTwoFactor, httptest.NewRequest(http.MethodPost, "/api/auth/2fa/enroll", nil), session.Token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from enroll, got %d: %s", w.Code, w.Body.String())
+	}
+	var enrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+	json.NewDecoder(w.Body).Decode(&enrollment)
+
+	code, _ := utils.TOTPCode(enrollment.Secret, step-1)
+	w = withAuth(handler.ConfirmTwoFactor, postJSON(t, "/api/auth/2fa/confirm", TwoFactorCodeRequest{Code: code}), session.Token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from confirm, got %d: %s", w.Code, w.Body.String())
+	}
+	var codes RecoveryCodesResponse
+	json.NewDecoder(w.Body).Decode(&codes)
+	if len(codes.RecoveryCodes) == 0 {
+		t.Fatal("Expected recovery codes after confirming")
+	}
+
+	// The password step now returns a challenge and no cookie
+	login = httptest.NewRecorder()
+	handler.Login(login, postJSON(t, "/api/auth/login", LoginRequest{Username: "admin", Password: "testpassword"}))
+	if login.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", login.Code)
+	}
+	if len(login.Result().Cookies()) != 0 {
+		t.Error("Expected no cookie before the second step")
+	}
+	var challenge TwoFactorChallengeResponse
+	json.NewDecoder(login.Body).Decode(&challenge)
+	if !challenge.TwoFactorRequired || challenge.Challenge == "" {
+		t.Fatalf("Expected a two-factor challenge, got %+v", challenge)
+	}
+
+	w = httptest.NewRecorder()
+	handler.LoginTwoFactor(w, postJSON(t, "/api/auth/login/2fa", TwoFactorLoginRequest{Challenge: challenge.Challenge, Code: "000000"}))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong code, got %d", w.Code)
+	}
+
+	code, _ = utils.TOTPCode(enrollment.Secret, step)
+	w = httptest.NewRecorder()
+	handler.LoginTwoFactor(w, postJSON(t, "/api/auth/login/2fa", TwoFactorLoginRequest{Challenge: challenge.Challenge, Code: code}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from the second step, got %d: %s", w.Code, w.Body.String())
+	}
+	var found bool
+	for _, c := range w.Result().Cookies() {
+		found = found || (c.Name == middleware.AuthCookieName && c.Value != "")
+	}
+	if !found {
+		t.Error("Expected the auth cookie after the second step")
+	}
+
+	w = withAuth(handler.GetTwoFactor, httptest.NewRequest(http.MethodGet, "/api/auth/2fa", nil), session.Token)
+	var status struct {
+		Enabled bool `json:"enabled"`
+	}
+	json.NewDecoder(w.Body).Decode(&status)
+	if w.Code != http.StatusOK || !status.Enabled {
+		t.Errorf("Expected two-factor to be enabled, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandler_TwoFactorRequiresSession(t *testing.T) {
+	handler, _ := setupTwoFactorHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.GetTwoFactor(w, httptest.NewRequest(http.MethodGet, "/api/auth/2fa", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a signed-in user, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_TwoFactorRefusesExternalSession(t *testing.T) {
+	handler, _ := setupTwoFactorHandler(t)
+	sessions, err := storage.NewSessionsFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionsFile failed: %v", err)
+	}
+	handler.authService.SetSessions(sessions)
+
+	// A Jellyfin session carrying the local admin's name
+	session := storage.Session{
+		ID:        "jellyfin-session",
+		Username:  "admin",
+		Role:      utils.RoleAdmin,
+		Method:    "jellyfin",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := sessions.Put(session); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	token, err := utils.GenerateSessionToken(session.Username, session.Role, session.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateSessionToken failed: %v", err)
+	}
+
+	w := withAuth(handler.EnrollTwoFactor, httptest.NewRequest(http.MethodPost, "/api/auth/2fa/enroll", nil), token)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a Jellyfin session, got %d", w.Code)
+	}
+	if status, err := handler.authService.TwoFactorStatus("admin"); err != nil || status.Enrolling {
+		t.Errorf("Expected the local admin to be untouched, got %+v err=%v", status, err)
+	}
+}
+
+func TestAuthHandler_Login_LockedOut(t *testing.T) {
+	handler, cfg := setupAuthHandler(t)
+	cfg.Admin.LoginThrottle.MaxFailures = 2
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.Login(w, postJSON(t, "/api/auth/login", LoginRequest{Username: "admin", Password: "wrong"}))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Attempt %d: expected 401, got %d", i+1, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.Login(w, postJSON(t, "/api/auth/login", LoginRequest{Username: "admin", Password: "testpassword"}))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 while locked out, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// UsersHandler handles user management requests
+type UsersHandler struct {
+	auditor
+	authService *services.AuthService
+}
+
+// NewUsersHandler creates a new UsersHandler
+func NewUsersHandler(authService *services.AuthService) *UsersHandler {
+	return &UsersHandler{
+		authService: authService,
+	}
+}
+
+// UserRequest represents the body of a create or update user request.
+// On update, empty fields leave the stored value unchanged.
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// UserResponse is a user as returned by the API (never includes the hash)
+type UserResponse struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// TwoFactorEnabled reports whether the user signs in with a TOTP code
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+}
+
+func newUserResponse(user storage.User) UserResponse {
+	return UserResponse{
+		Username:         user.Username,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		TwoFactorEnabled: user.TOTPEnabled(),
+	}
+}
+
+// userAuditView is a user as recorded in the audit log. A password change is
+// recorded as a marker, never the password itself.
+type userAuditView struct {
+	UserResponse
+	Password string `json:"password,omitempty"`
+}
+
+// findUser returns the user with the given name, for the audit log's before state
+func (h *UsersHandler) findUser(username string) *UserResponse {
+	users, err := h.authService.ListUsers()
+	if err != nil {
+		return nil
+	}
+	for _, user := range users {
+		if user.Username == username {
+			resp := newUserResponse(user)
+			return &resp
+		}
+	}
+	return nil
+}
+
+// ListUsers handles GET /api/users
+func (h *UsersHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.authService.ListUsers()
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	resp := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, newUserResponse(user))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users": resp,
+		"total": len(resp),
+	})
+}
+
+// CreateUser handles POST /api/users
+func (h *UsersHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	user, err := h.authService.CreateUser(req.Username, req.Password, req.Role)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	log.Info().
+		Str("username", user.Username).
+		Str("role", user.Role).
+		Str("by", requestActor(r)).
+		Msg("User created")
+	h.audit(r, "user.create", user.Username, nil, newUserResponse(user))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newUserResponse(user))
+}
+
+// UpdateUser handles PUT /api/users/{username}
+func (h *UsersHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	var req UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	before := h.findUser(username)
+	user, err := h.authService.UpdateUser(username, req.Password, req.Role)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	log.Info().
+		Str("username", user.Username).
+		Str("role", user.Role).
+		Bool("password_changed", req.Password != "").
+		Str("by", requestActor(r)).
+		Msg("User updated")
+	after := userAuditView{UserResponse: newUserResponse(user)}
+	if req.Password != "" {
+		after.Password = "changed"
+	}
+	h.audit(r, "user.update", user.Username, before, after)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newUserResponse(user))
+}
+
+// DeleteUser handles DELETE /api/users/{username}
+func (h *UsersHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	before := h.findUser(username)
+	if err := h.authService.DeleteUser(username); err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	log.Info().Str("username", username).Str("by", requestActor(r)).Msg("User deleted")
+	h.audit(r, "user.delete", username, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "User deleted"})
+}
+
+// writeUserError maps user management errors to HTTP responses
+func writeUserError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrEmptyUsername),
+		errors.Is(err, services.ErrEmptyPassword),
+		errors.Is(err, services.ErrInvalidRole):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrUserExists),
+		errors.Is(err, services.ErrLastAdmin):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrUsersUnavailable):
+		status = http.StatusServiceUnavailable
+	default:
+		log.Error().Err(err).Msg("User operation failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUsersRouter mounts the user routes so chi URL params resolve
+func newUsersRouter(handler *UsersHandler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Get("/api/users", handler.ListUsers)
+	r.Post("/api/users", handler.CreateUser)
+	r.Put("/api/users/{username}", handler.UpdateUser)
+	r.Delete("/api/users/{username}", handler.DeleteUser)
+	return r
+}
+
+func setupUsersHandler(t *testing.T) *UsersHandler {
+	t.Helper()
+	require.NoError(t, utils.InitJWT("test-secret-key-for-testing-min-32-chars", 24*time.Hour))
+
+	authService := services.NewAuthService(&config.Config{
+		Admin: config.AdminConfig{Username: "admin", Password: "adminpassword"},
+	})
+	users, err := storage.NewUsersFile(t.TempDir())
+	require.NoError(t, err)
+	authService.SetUsers(users)
+	_, err = authService.MigrateAdmin()
+	require.NoError(t, err)
+
+	return NewUsersHandler(authService)
+}
+
+func TestUsersHandler(t *testing.T) {
+	handler := setupUsersHandler(t)
+	router := newUsersRouter(handler)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("creates a user", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/users", `{"username":"olivia","password":"pw","role":"operator"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var resp UserResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "olivia", resp.Username)
+		assert.Equal(t, utils.RoleOperator, resp.Role)
+		assert.NotContains(t, w.Body.String(), "password_hash")
+	})
+
+	t.Run("rejects duplicates and bad roles", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/users", `{"username":"olivia","password":"pw","role":"viewer"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/users", `{"username":"x","password":"pw","role":"root"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/users", `not json`).Code)
+	})
+
+	t.Run("lists users without hashes", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/users", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "$2")
+
+		var resp struct {
+			Users []UserResponse `json:"users"`
+			Total int            `json:"total"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 2, resp.Total)
+	})
+
+	t.Run("updates a role", func(t *testing.T) {
+		w := do(http.MethodPut, "/api/users/olivia", `{"role":"viewer"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp UserResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, utils.RoleViewer, resp.Role)
+
+		assert.Equal(t, http.StatusNotFound, do(http.MethodPut, "/api/users/nobody", `{"role":"viewer"}`).Code)
+	})
+
+	t.Run("protects the last admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, do(http.MethodPut, "/api/users/admin", `{"role":"viewer"}`).Code)
+		assert.Equal(t, http.StatusConflict, do(http.MethodDelete, "/api/users/admin", "").Code)
+	})
+
+	t.Run("deletes a user", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/api/users/olivia", "").Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/users/olivia", "").Code)
+	})
+}
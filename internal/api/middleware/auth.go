@@ -5,6 +5,7 @@ import (
 	"crypto/subtle"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
@@ -14,9 +15,65 @@ import (
 type contextKey string
 
 const (
-	userContextKey contextKey = "user"
+	userContextKey   contextKey = "user"
+	roleContextKey   contextKey = "role"
+	apiKeyContextKey contextKey = "api_key"
 )
 
+// APIKeyIdentity describes the named API key a request was made with
+type APIKeyIdentity struct {
+	ID     string
+	Name   string
+	Scopes []string
+}
+
+// APIKeyLookup resolves a bearer secret to a named API key
+type APIKeyLookup func(secret string) (APIKeyIdentity, bool)
+
+var (
+	apiKeyLookupMu sync.RWMutex
+	apiKeyLookup   APIKeyLookup
+)
+
+// SetAPIKeyLookup installs the resolver Auth uses for named API keys. With
+// none installed, only JWTs and the static admin.api_key are accepted.
+func SetAPIKeyLookup(lookup APIKeyLookup) {
+	apiKeyLookupMu.Lock()
+	defer apiKeyLookupMu.Unlock()
+	apiKeyLookup = lookup
+}
+
+func getAPIKeyLookup() APIKeyLookup {
+	apiKeyLookupMu.RLock()
+	defer apiKeyLookupMu.RUnlock()
+	return apiKeyLookup
+}
+
+// SessionCheck reports whether the server-side session a JWT belongs to is
+// still active
+type SessionCheck func(claims *utils.JWTClaims) bool
+
+var (
+	sessionCheckMu sync.RWMutex
+	sessionCheck   SessionCheck
+)
+
+// SetSessionCheck installs the check Auth runs on every JWT, so revoked
+// sessions are rejected before their tokens expire. With none installed, a
+// valid signature is enough.
+func SetSessionCheck(check SessionCheck) {
+	sessionCheckMu.Lock()
+	defer sessionCheckMu.Unlock()
+	sessionCheck = check
+}
+
+func sessionActive(claims *utils.JWTClaims) bool {
+	sessionCheckMu.RLock()
+	check := sessionCheck
+	sessionCheckMu.RUnlock()
+	return check == nil || check(claims)
+}
+
 // AuthCookieName is the name of the httpOnly cookie used to carry the JWT
 // for the web UI. The cookie cannot be read by JavaScript, which removes the
 // need to persist the token in localStorage.
@@ -42,15 +99,18 @@ func GetTokenFromRequest(r *http.Request) string {
 	return parts[1]
 }
 
-// Auth is a middleware that validates JWT tokens or the configured admin API key.
-// If admin.disable_auth is true in config, authentication is bypassed.
+// Auth is a middleware that validates JWT tokens, the configured admin API key
+// or a named API key. If admin.disable_auth is true in config, authentication
+// is bypassed. The caller's role is stored in the request context for
+// RequireRole: the JWT role claim, or admin for the static API key and when
+// auth is disabled. Named API keys get no role; Require checks their scopes.
 func Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if authentication is disabled
 		cfg := config.Get()
 		if cfg != nil && cfg.Admin.DisableAuth {
 			log.Debug().Msg("Authentication disabled, bypassing auth middleware")
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(withRole(r.Context(), utils.RoleAdmin)))
 			return
 		}
 
@@ -76,11 +136,13 @@ func Auth(next http.Handler) http.Handler {
 		}
 
 		for _, token := range candidates {
-			// Validate JWT (web UI / scripted login).
-			if claims, err := utils.ValidateToken(token); err == nil {
+			// Validate JWT (web UI / scripted login). Tokens issued before
+			// roles existed carry no role claim and must be renewed by logging
+			// in, as must tokens whose session was revoked.
+			if claims, err := utils.ValidateToken(token); err == nil && claims.Role != "" && sessionActive(claims) {
 				// Add claims to request context
 				ctx := context.WithValue(r.Context(), userContextKey, claims)
-				next.ServeHTTP(w, r.WithContext(ctx))
+				next.ServeHTTP(w, r.WithContext(withRole(ctx, claims.Role)))
 				return
 			}
 
@@ -88,9 +150,18 @@ func Auth(next http.Handler) http.Handler {
 			// NOTE: this path does not inject user claims into the request context
 			// (there is no user); GetUserFromContext returns nil for such requests.
 			if apiKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1 {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, r.WithContext(withRole(r.Context(), utils.RoleAdmin)))
 				return
 			}
+
+			// Named, scoped API keys
+			if lookup := getAPIKeyLookup(); lookup != nil {
+				if key, ok := lookup(token); ok {
+					ctx := context.WithValue(r.Context(), apiKeyContextKey, &key)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
 		}
 
 		if len(candidates) == 0 {
@@ -104,6 +175,68 @@ func Auth(next http.Handler) http.Handler {
 	})
 }
 
+// RequireRole returns a middleware that rejects callers whose role is below
+// the required one with 403. Named API keys are always rejected. It must run
+// after Auth.
+func RequireRole(required string) func(http.Handler) http.Handler {
+	return Require(required)
+}
+
+// Require returns a middleware that admits users whose role is at least
+// required and named API keys holding one of scopes; everyone else gets 403.
+// It must run after Auth.
+func Require(required string, scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := GetAPIKeyFromContext(r.Context()); key != nil {
+				if !utils.HasAnyScope(key.Scopes, scopes...) {
+					log.Debug().
+						Str("key", key.Name).
+						Strs("required", scopes).
+						Str("path", r.URL.Path).
+						Msg("API key lacks scope")
+					http.Error(w, `{"error": "API key lacks the required scope"}`, http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role := GetRoleFromContext(r.Context())
+			if !utils.RoleAllows(role, required) {
+				log.Debug().
+					Str("role", role).
+					Str("required", required).
+					Str("path", r.URL.Path).
+					Msg("Insufficient role")
+				http.Error(w, `{"error": "Insufficient permissions"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetRoleFromContext retrieves the caller's role from the request context,
+// or "" when the request did not pass through Auth.
+func GetRoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleContextKey).(string)
+	return role
+}
+
+func withRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleContextKey, role)
+}
+
+// GetAPIKeyFromContext retrieves the named API key from the request context,
+// or nil when the request was not made with one
+func GetAPIKeyFromContext(ctx context.Context) *APIKeyIdentity {
+	if key, ok := ctx.Value(apiKeyContextKey).(*APIKeyIdentity); ok {
+		return key
+	}
+	return nil
+}
+
 // GetUserFromContext retrieves the user claims from the request context
 func GetUserFromContext(ctx context.Context) *utils.JWTClaims {
 	if claims, ok := ctx.Value(userContextKey).(*utils.JWTClaims); ok {
@@ -33,7 +33,7 @@ func makeProtectedHandler() http.Handler {
 
 func TestAuth_AuthorizationHeader(t *testing.T) {
 	initTestJWT(t)
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -54,7 +54,7 @@ func TestAuth_AuthorizationHeader(t *testing.T) {
 
 func TestAuth_Cookie(t *testing.T) {
 	initTestJWT(t)
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -102,7 +102,7 @@ func TestAuth_InvalidToken(t *testing.T) {
 
 func TestAuth_MalformedHeader(t *testing.T) {
 	initTestJWT(t)
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -121,7 +121,7 @@ func TestAuth_MalformedHeader(t *testing.T) {
 
 func TestAuth_RejectsQueryParamToken(t *testing.T) {
 	initTestJWT(t)
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -156,7 +156,7 @@ func TestAuth_Disabled(t *testing.T) {
 
 func TestGetTokenFromRequest_Priority(t *testing.T) {
 	initTestJWT(t)
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -243,3 +243,173 @@ func TestAuth_APIKeyHeaderNotShadowedByStaleCookie(t *testing.T) {
 	}
 }
 
+func makeRoleHandler(required string) http.Handler {
+	return Auth(RequireRole(required)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(GetRoleFromContext(r.Context())))
+	})))
+}
+
+func TestRequireRole(t *testing.T) {
+	initTestJWT(t)
+	config.SetTestConfig(&config.Config{})
+	defer config.SetTestConfig(nil)
+
+	tests := []struct {
+		name     string
+		role     string
+		required string
+		wantCode int
+	}{
+		{"viewer reads", utils.RoleViewer, utils.RoleViewer, http.StatusOK},
+		{"viewer cannot operate", utils.RoleViewer, utils.RoleOperator, http.StatusForbidden},
+		{"operator operates", utils.RoleOperator, utils.RoleOperator, http.StatusOK},
+		{"operator cannot administer", utils.RoleOperator, utils.RoleAdmin, http.StatusForbidden},
+		{"admin administers", utils.RoleAdmin, utils.RoleAdmin, http.StatusOK},
+		{"unknown role denied", "superuser", utils.RoleViewer, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := utils.GenerateToken("someone", tt.role)
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/media", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			makeRoleHandler(tt.required).ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d", tt.wantCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuth_RejectsTokenWithoutRole(t *testing.T) {
+	initTestJWT(t)
+	config.SetTestConfig(&config.Config{})
+	defer config.SetTestConfig(nil)
+
+	token, err := utils.GenerateToken("admin", "")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/media", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	makeAuthHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a pre-role token, got %d", w.Code)
+	}
+}
+
+func TestAuth_RejectsRevokedSession(t *testing.T) {
+	initTestJWT(t)
+	config.SetTestConfig(&config.Config{})
+	defer config.SetTestConfig(nil)
+
+	SetSessionCheck(func(claims *utils.JWTClaims) bool { return claims.SessionID == "live" })
+	defer SetSessionCheck(nil)
+
+	for sessionID, want := range map[string]int{"live": http.StatusOK, "revoked": http.StatusUnauthorized} {
+		token, err := utils.GenerateSessionToken("admin", utils.RoleAdmin, sessionID, time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateSessionToken failed: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/media", nil)
+		req.AddCookie(&http.Cookie{Name: AuthCookieName, Value: token})
+		w := httptest.NewRecorder()
+
+		makeProtectedHandler().ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("Session %q: expected %d, got %d", sessionID, want, w.Code)
+		}
+	}
+}
+
+func TestRequireRole_APIKeyAndDisabledAuthAreAdmin(t *testing.T) {
+	initTestJWT(t)
+	defer config.SetTestConfig(nil)
+
+	config.SetTestConfig(&config.Config{Admin: config.AdminConfig{APIKey: "test-api-key"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/deletions/execute", nil)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	makeRoleHandler(utils.RoleAdmin).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected API key to have admin access, got %d", w.Code)
+	}
+
+	config.SetTestConfig(&config.Config{Admin: config.AdminConfig{DisableAuth: true}})
+	req = httptest.NewRequest(http.MethodPost, "/api/deletions/execute", nil)
+	w = httptest.NewRecorder()
+	makeRoleHandler(utils.RoleAdmin).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected admin access with auth disabled, got %d", w.Code)
+	}
+}
+
+func TestRequire_NamedAPIKeyScopes(t *testing.T) {
+	initTestJWT(t)
+	config.SetTestConfig(&config.Config{Admin: config.AdminConfig{APIKey: "test-api-key"}})
+	defer config.SetTestConfig(nil)
+
+	SetAPIKeyLookup(func(secret string) (APIKeyIdentity, bool) {
+		if secret != "oxi_plugin" {
+			return APIKeyIdentity{}, false
+		}
+		return APIKeyIdentity{ID: "k1", Name: "plugin", Scopes: []string{utils.ScopeLeavingSoonRead}}, true
+	})
+	defer SetAPIKeyLookup(nil)
+
+	makeHandler := func(required string, scopes ...string) http.Handler {
+		return Auth(Require(required, scopes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := GetAPIKeyFromContext(r.Context())
+			if key == nil {
+				w.Write([]byte("no key"))
+				return
+			}
+			w.Write([]byte(key.Name))
+		})))
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		required string
+		scopes   []string
+		wantCode int
+	}{
+		{name: "key with listed scope", token: "oxi_plugin", required: utils.RoleViewer, scopes: []string{utils.ScopeLeavingSoonRead, utils.ScopeMediaRead}, wantCode: http.StatusOK},
+		{name: "key without listed scope", token: "oxi_plugin", required: utils.RoleViewer, scopes: []string{utils.ScopeMediaRead}, wantCode: http.StatusForbidden},
+		{name: "key on role-only route", token: "oxi_plugin", required: utils.RoleRequester, wantCode: http.StatusForbidden},
+		{name: "unknown key", token: "oxi_other", required: utils.RoleRequester, scopes: utils.AllScopes, wantCode: http.StatusUnauthorized},
+		{name: "static admin key ignores scopes", token: "test-api-key", required: utils.RoleAdmin, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/media/leaving-soon", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			makeHandler(tt.required, tt.scopes...).ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected %d, got %d", tt.wantCode, w.Code)
+			}
+		})
+	}
+
+	// RequireRole never admits named keys, whatever their scopes
+	req := httptest.NewRequest(http.MethodGet, "/api/media/leaving-soon", nil)
+	req.Header.Set("Authorization", "Bearer oxi_plugin")
+	w := httptest.NewRecorder()
+	makeRoleHandler(utils.RoleRequester).ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected RequireRole to reject a named key, got %d", w.Code)
+	}
+}
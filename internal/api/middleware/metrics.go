@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+// MetricsAccess gates the Prometheus endpoint per server.metrics: it returns
+// 404 when metrics are disabled and, with require_api_key, accepts only the
+// admin API key as a Bearer token. The config is read per request so changes
+// apply without a restart.
+func MetricsAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.Get()
+		if cfg == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cfg.Server.Metrics.Disabled {
+			http.NotFound(w, r)
+			return
+		}
+
+		if cfg.Server.Metrics.RequireAPIKey {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || cfg.Admin.APIKey == "" ||
+				subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Admin.APIKey)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, `{"error": "Invalid or missing API key"}`, http.StatusUnauthorized)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
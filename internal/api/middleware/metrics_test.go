@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsAccess(t *testing.T) {
+	handler := MetricsAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(authHeader string) int {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cfg := &config.Config{Admin: config.AdminConfig{APIKey: "secret-key"}}
+	config.SetTestConfig(cfg)
+
+	t.Run("open by default", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(""))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg.Server.Metrics = config.MetricsConfig{Disabled: true}
+		defer func() { cfg.Server.Metrics = config.MetricsConfig{} }()
+		assert.Equal(t, http.StatusNotFound, serve("Bearer secret-key"))
+	})
+
+	t.Run("api key required", func(t *testing.T) {
+		cfg.Server.Metrics = config.MetricsConfig{RequireAPIKey: true}
+		defer func() { cfg.Server.Metrics = config.MetricsConfig{} }()
+		assert.Equal(t, http.StatusUnauthorized, serve(""))
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer wrong"))
+		assert.Equal(t, http.StatusOK, serve("Bearer secret-key"))
+	})
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+// RealIP sets RemoteAddr to the client's address when the request comes
+// through a proxy listed in server.trusted_proxies. X-Forwarded-For is read
+// from the right, skipping trusted proxies, so entries a client prepends are
+// ignored; X-Real-IP is used when there is no X-Forwarded-For. Requests from
+// any other peer keep their socket address, whatever headers they send, so
+// sign-in throttling and the audit log cannot be steered by a client.
+func RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := forwardedClientIP(r, trustedProxies()); ip != "" {
+			r.RemoteAddr = ip
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// trustedProxies returns the parsed server.trusted_proxies. Entries that do
+// not parse are skipped; config validation reports them.
+func trustedProxies() []netip.Prefix {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	prefixes := make([]netip.Prefix, 0, len(cfg.Server.TrustedProxies))
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if prefix, err := config.ParseTrustedProxy(proxy); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// forwardedClientIP returns the client address named by the forwarding
+// headers, or "" when the peer is not a trusted proxy or names no client
+func forwardedClientIP(r *http.Request, trusted []netip.Prefix) string {
+	if len(trusted) == 0 {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(peer, trusted) {
+		return ""
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap().String()
+		if !isTrustedProxy(addr, trusted) {
+			break
+		}
+	}
+	if client != "" {
+		return client
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+	return ""
+}
+
+// isTrustedProxy reports whether addr is in one of the trusted ranges
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
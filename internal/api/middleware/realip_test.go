@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealIP(t *testing.T) {
+	config.SetTestConfig(&config.Config{
+		Server: config.ServerConfig{TrustedProxies: []string{"10.0.0.0/8", "192.0.2.1"}},
+	})
+	defer config.SetTestConfig(nil)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "untrusted peer keeps its address", remoteAddr: "203.0.113.5:4000", forwarded: []string{"198.51.100.1"}, realIP: "198.51.100.2", want: "203.0.113.5:4000"},
+		{name: "trusted proxy names the client", remoteAddr: "10.1.2.3:4000", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "prepended entries are ignored", remoteAddr: "10.1.2.3:4000", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chained trusted proxies are skipped", remoteAddr: "10.1.2.3:4000", forwarded: []string{"1.2.3.4, 198.51.100.1", "192.0.2.1"}, want: "198.51.100.1"},
+		{name: "X-Real-IP without X-Forwarded-For", remoteAddr: "192.0.2.1:4000", realIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "trusted proxy without headers", remoteAddr: "10.1.2.3:4000", want: "10.1.2.3:4000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRealIP_NoTrustedProxies(t *testing.T) {
+	config.SetTestConfig(&config.Config{})
+	defer config.SetTestConfig(nil)
+
+	var got string
+	h := RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "127.0.0.1:4000", got)
+}
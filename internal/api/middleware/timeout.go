@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout cancels each request's context after d, except for the paths in
+// streams. Long-lived responses such as the SSE event stream would otherwise
+// be cut off every d and have their clients reconnect and replay.
+func Timeout(d time.Duration, streams ...string) func(http.Handler) http.Handler {
+	exempt := make(map[string]bool, len(streams))
+	for _, path := range streams {
+		exempt[path] = true
+	}
+	return func(next http.Handler) http.Handler {
+		timed := middleware.Timeout(d)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timed.ServeHTTP(w, r)
+		})
+	}
+}
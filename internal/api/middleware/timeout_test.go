@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(time.Minute, "/api/events")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			w.Header().Set("X-Deadline", "set")
+		}
+	}))
+
+	tests := []struct {
+		path         string
+		wantDeadline bool
+	}{
+		{path: "/api/media/movies", wantDeadline: true},
+		{path: "/api/events", wantDeadline: false},
+		{path: "/api/events/other", wantDeadline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantDeadline, w.Header().Get("X-Deadline") == "set")
+		})
+	}
+}
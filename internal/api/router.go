@@ -10,17 +10,21 @@ import (
 	"github.com/ramonskie/oxicleanarr/internal/api/handlers"
 	mw "github.com/ramonskie/oxicleanarr/internal/api/middleware"
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/metrics"
 	"github.com/ramonskie/oxicleanarr/internal/services"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
 )
 
 // RouterDependencies holds dependencies for the router
 type RouterDependencies struct {
-	AuthService *services.AuthService
-	SyncEngine  *services.SyncEngine
-	JobsFile    *storage.JobsFile
-	ShutdownCh  chan struct{} // Channel for signaling graceful shutdown
-	SPAHandler  http.Handler  // Optional: handler for serving the SPA frontend
+	AuthService   *services.AuthService
+	SyncEngine    *services.SyncEngine
+	JobsFile      *storage.JobsFile
+	AuditLog      *services.AuditLog      // Optional: records mutating actions
+	ConfigHistory *services.ConfigHistory // Optional: records config versions for diff and restore
+	ShutdownCh    chan struct{}           // Channel for signaling graceful shutdown
+	SPAHandler    http.Handler            // Optional: handler for serving the SPA frontend
 }
 
 // NewRouter creates and configures the HTTP router
@@ -29,10 +33,11 @@ func NewRouter(deps *RouterDependencies) *chi.Mux {
 
 	// Global middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(mw.RealIP)
 	r.Use(mw.Logger)
 	r.Use(mw.Recovery)
-	r.Use(middleware.Timeout(60 * time.Second))
+	// The SSE stream stays open for as long as the client listens
+	r.Use(mw.Timeout(60*time.Second, "/api/events"))
 
 	// CORS middleware - restricted to explicitly configured origins.
 	// Empty list = same-origin only (no CORS headers sent). Wildcard origins
@@ -55,80 +60,207 @@ func NewRouter(deps *RouterDependencies) *chi.Mux {
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler()
 	authHandler := handlers.NewAuthHandler(deps.AuthService)
+	usersHandler := handlers.NewUsersHandler(deps.AuthService)
 	mediaHandler := handlers.NewMediaHandler(deps.SyncEngine)
 	syncHandler := handlers.NewSyncHandler(deps.SyncEngine)
+	deletionsHandler := handlers.NewDeletionsHandler(deps.SyncEngine)
 	jobsHandler := handlers.NewJobsHandler(deps.JobsFile)
 	configHandler := handlers.NewConfigHandler(deps.SyncEngine)
 	rulesHandler := handlers.NewRulesHandler()
 	systemHandler := handlers.NewSystemHandler(deps.SyncEngine, deps.ShutdownCh)
 	servicesHandler := handlers.NewServiceStatusHandler()
 	logsHandler := handlers.NewLogsHandler()
+	eventsHandler := handlers.NewEventsHandler(deps.SyncEngine)
+	keepRequestsHandler := handlers.NewKeepRequestsHandler(deps.SyncEngine)
+	apiKeysHandler := handlers.NewAPIKeysHandler(deps.AuthService)
+	auditHandler := handlers.NewAuditHandler(deps.AuditLog)
+
+	// Handlers that change state record what they did in the audit log
+	authHandler.SetAuditLog(deps.AuditLog)
+	usersHandler.SetAuditLog(deps.AuditLog)
+	mediaHandler.SetAuditLog(deps.AuditLog)
+	syncHandler.SetAuditLog(deps.AuditLog)
+	deletionsHandler.SetAuditLog(deps.AuditLog)
+	configHandler.SetAuditLog(deps.AuditLog)
+	rulesHandler.SetAuditLog(deps.AuditLog)
+	systemHandler.SetAuditLog(deps.AuditLog)
+	keepRequestsHandler.SetAuditLog(deps.AuditLog)
+	apiKeysHandler.SetAuditLog(deps.AuditLog)
+
+	// Handlers that write the config file record each version
+	configHandler.SetConfigHistory(deps.ConfigHistory)
+	rulesHandler.SetConfigHistory(deps.ConfigHistory)
+
+	// Restoring old admin credentials ends the admin's sessions
+	configHandler.SetAuthService(deps.AuthService)
+
+	// Named API keys are resolved by the auth service
+	if deps.AuthService != nil {
+		authService := deps.AuthService
+		mw.SetAPIKeyLookup(func(secret string) (mw.APIKeyIdentity, bool) {
+			key, ok := authService.AuthenticateAPIKey(secret)
+			if !ok {
+				return mw.APIKeyIdentity{}, false
+			}
+			return mw.APIKeyIdentity{ID: key.ID, Name: key.Name, Scopes: key.Scopes}, true
+		})
+		// Revoked sessions are rejected before their access tokens expire
+		mw.SetSessionCheck(authService.SessionActive)
+	}
 
 	// Public routes
 	r.Get("/health", healthHandler.Handle)
 
+	// Prometheus metrics (server.metrics controls access)
+	r.With(mw.MetricsAccess).Get("/metrics", metrics.Handler().ServeHTTP)
+
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		// Public API routes
 		r.Post("/auth/login", authHandler.Login)
+		r.Post("/auth/login/2fa", authHandler.LoginTwoFactor)
+		r.Post("/auth/refresh", authHandler.Refresh)
 		r.Post("/auth/logout", authHandler.Logout)
 		r.Get("/auth/me", authHandler.Me)
+		r.Get("/auth/providers", authHandler.Providers)
+		r.Get("/auth/oidc/login", authHandler.OIDCLogin)
+		r.Get("/auth/oidc/callback", authHandler.OIDCCallback)
 
-		// Protected API routes (JWT or the static admin.api_key)
+		// Protected API routes (JWT, the static admin.api_key or a named API
+		// key). Requesters may only browse the media they requested and ask to
+		// keep it; every other route needs at least the viewer role, and
+		// mutating routes require operator or admin. The static API key and
+		// disabled auth act as admin. Named API keys have no role and only reach
+		// routes whose guard lists one of their scopes, so every route in this
+		// group carries its own guard.
 		r.Group(func(r chi.Router) {
 			r.Use(mw.Auth)
+			r.Use(mw.Require(utils.RoleRequester, utils.AllScopes...))
+
+			requester := mw.RequireRole(utils.RoleRequester)
+			viewer := mw.RequireRole(utils.RoleViewer)
+			operator := mw.RequireRole(utils.RoleOperator)
+			admin := mw.RequireRole(utils.RoleAdmin)
+
+			mediaRead := mw.Require(utils.RoleRequester, utils.ScopeMediaRead)
+			leavingSoonRead := mw.Require(utils.RoleViewer, utils.ScopeLeavingSoonRead, utils.ScopeMediaRead)
+			syncStatus := mw.Require(utils.RoleViewer, utils.ScopeSyncTrigger)
+			syncTrigger := mw.Require(utils.RoleOperator, utils.ScopeSyncTrigger)
+			deletionsRead := mw.Require(utils.RoleViewer, utils.ScopeDeletionsExecute)
+			deletionsExecute := mw.Require(utils.RoleAdmin, utils.ScopeDeletionsExecute)
+			configRead := mw.Require(utils.RoleViewer, utils.ScopeConfigWrite)
+			configWrite := mw.Require(utils.RoleAdmin, utils.ScopeConfigWrite)
+
+			// Two-factor self-service (signed-in local accounts only)
+			r.Route("/auth/2fa", func(r chi.Router) {
+				r.Use(requester)
+				r.Get("/", authHandler.GetTwoFactor)
+				r.Post("/enroll", authHandler.EnrollTwoFactor)
+				r.Post("/confirm", authHandler.ConfirmTwoFactor)
+				r.Post("/disable", authHandler.DisableTwoFactor)
+				r.Post("/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			})
+
+			// Sessions of the signed-in user (admins can see and end all)
+			r.Route("/auth/sessions", func(r chi.Router) {
+				r.Use(requester)
+				r.Get("/", authHandler.ListSessions)
+				r.Delete("/{id}", authHandler.RevokeSession)
+			})
 
 			// Media routes - specific endpoints before parameterized {id}
 			r.Route("/media", func(r chi.Router) {
-				r.Get("/movies", mediaHandler.ListMovies)
-				r.Get("/shows", mediaHandler.ListShows)
-				r.Get("/leaving-soon", mediaHandler.ListLeavingSoon)
-				r.Get("/leaving-soon/list", mediaHandler.ListLeavingSoonMedia)
-				r.Get("/unmatched", mediaHandler.ListUnmatched)
+				r.With(mediaRead).Get("/movies", mediaHandler.ListMovies)
+				r.With(mediaRead).Get("/shows", mediaHandler.ListShows)
+				r.With(leavingSoonRead).Get("/leaving-soon", mediaHandler.ListLeavingSoon)
+				r.With(mediaRead).Get("/leaving-soon/list", mediaHandler.ListLeavingSoonMedia)
+				r.With(mw.Require(utils.RoleViewer, utils.ScopeMediaRead)).Get("/unmatched", mediaHandler.ListUnmatched)
 
 				// Parameterized routes must come last
-				r.Get("/{id}", mediaHandler.GetMediaItem)
-				r.Get("/{id}/poster", mediaHandler.ProxyPoster)
-				r.Post("/{id}/exclude", mediaHandler.AddExclusion)
-				r.Delete("/{id}/exclude", mediaHandler.RemoveExclusion)
-				r.Post("/{id}/manual-leaving-soon", mediaHandler.AddManualLeavingSoon)
-				r.Delete("/{id}/manual-leaving-soon", mediaHandler.RemoveManualLeavingSoon)
-				r.Delete("/{id}", mediaHandler.DeleteMedia)
+				r.With(mediaRead).Get("/{id}", mediaHandler.GetMediaItem)
+				r.With(mediaRead).Get("/{id}/poster", mediaHandler.ProxyPoster)
+				r.With(requester).Post("/{id}/keep-request", keepRequestsHandler.SubmitKeepRequest)
+				r.With(operator).Post("/{id}/exclude", mediaHandler.AddExclusion)
+				r.With(operator).Delete("/{id}/exclude", mediaHandler.RemoveExclusion)
+				r.With(operator).Post("/{id}/manual-leaving-soon", mediaHandler.AddManualLeavingSoon)
+				r.With(operator).Delete("/{id}/manual-leaving-soon", mediaHandler.RemoveManualLeavingSoon)
+				r.With(admin).Delete("/{id}", mediaHandler.DeleteMedia)
 			})
 
+			// Keep request routes (requesters only see their own)
+			r.With(requester).Get("/keep-requests", keepRequestsHandler.ListKeepRequests)
+			r.With(operator).Post("/keep-requests/{id}/approve", keepRequestsHandler.ApproveKeepRequest)
+			r.With(operator).Post("/keep-requests/{id}/deny", keepRequestsHandler.DenyKeepRequest)
+
 			// Sync routes
-			r.Post("/sync/full", syncHandler.TriggerFullSync)
-			r.Post("/sync/incremental", syncHandler.TriggerIncrementalSync)
-			r.Get("/sync/status", syncHandler.GetSyncStatus)
+			r.With(syncTrigger).Post("/sync/full", syncHandler.TriggerFullSync)
+			r.With(syncTrigger).Post("/sync/incremental", syncHandler.TriggerIncrementalSync)
+			r.With(syncStatus).Get("/sync/status", syncHandler.GetSyncStatus)
 
 			// Deletion routes
-			r.Post("/deletions/execute", syncHandler.ExecuteDeletions)
+			r.With(deletionsExecute).Post("/deletions/execute", syncHandler.ExecuteDeletions)
+			r.With(deletionsRead).Get("/deletions/batches", deletionsHandler.ListBatches)
+			r.With(deletionsRead).Get("/deletions/batches/{id}", deletionsHandler.GetBatch)
+			r.With(deletionsExecute).Post("/deletions/batches/{id}/approve", deletionsHandler.ApproveBatch)
+			r.With(deletionsExecute).Post("/deletions/batches/{id}/reject", deletionsHandler.RejectBatch)
 
-			// Jobs routes
-			r.Get("/jobs", jobsHandler.ListJobs)
-			r.Get("/jobs/latest", jobsHandler.GetLatestJob)
-			r.Get("/jobs/{id}", jobsHandler.GetJob)
+			// Jobs routes (keys that start syncs or deletions can follow them)
+			jobsRead := mw.Require(utils.RoleViewer, utils.ScopeSyncTrigger, utils.ScopeDeletionsExecute)
+			r.With(jobsRead).Get("/jobs", jobsHandler.ListJobs)
+			r.With(jobsRead).Get("/jobs/latest", jobsHandler.GetLatestJob)
+			r.With(jobsRead).Get("/jobs/{id}", jobsHandler.GetJob)
+			r.With(operator).Post("/jobs/{id}/cancel", syncHandler.CancelJob)
+			r.With(admin).Post("/jobs/{id}/remonitor", syncHandler.RemonitorJob)
 
-			// Config routes
-			r.Get("/config", configHandler.GetConfig)
-			r.Put("/config", configHandler.UpdateConfig)
+			// Config routes (the config holds service API keys, so reads are admin-only too)
+			r.With(configWrite).Get("/config", configHandler.GetConfig)
+			r.With(configWrite).Put("/config", configHandler.UpdateConfig)
+			r.With(configWrite).Post("/config/validate", configHandler.ValidateConfig)
+			r.With(configWrite).Get("/config/history", configHandler.ListConfigHistory)
+			r.With(configWrite).Get("/config/history/{id}/diff", configHandler.DiffConfigVersion)
+			r.With(configWrite).Post("/config/history/{id}/restore", configHandler.RestoreConfigVersion)
 
 			// Rules routes
-			r.Get("/rules", rulesHandler.ListRules)
-			r.Post("/rules", rulesHandler.CreateRule)
-			r.Put("/rules/{name}", rulesHandler.UpdateRule)
-			r.Delete("/rules/{name}", rulesHandler.DeleteRule)
-			r.Patch("/rules/{name}/toggle", rulesHandler.ToggleRule)
+			r.With(configRead).Get("/rules", rulesHandler.ListRules)
+			r.With(configWrite).Post("/rules", rulesHandler.CreateRule)
+			r.With(configWrite).Put("/rules/{name}", rulesHandler.UpdateRule)
+			r.With(configWrite).Delete("/rules/{name}", rulesHandler.DeleteRule)
+			r.With(configWrite).Patch("/rules/{name}/toggle", rulesHandler.ToggleRule)
+
+			// User management routes
+			r.Route("/users", func(r chi.Router) {
+				r.Use(admin)
+				r.Get("/", usersHandler.ListUsers)
+				r.Post("/", usersHandler.CreateUser)
+				r.Put("/{username}", usersHandler.UpdateUser)
+				r.Delete("/{username}", usersHandler.DeleteUser)
+				r.Delete("/{username}/2fa", usersHandler.ResetTwoFactor)
+			})
+
+			// API key management routes (users only; keys cannot mint keys)
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Use(admin)
+				r.Get("/", apiKeysHandler.ListAPIKeys)
+				r.Post("/", apiKeysHandler.CreateAPIKey)
+				r.Delete("/{id}", apiKeysHandler.RevokeAPIKey)
+			})
+
+			// Audit log (users only; it records what every key did)
+			r.With(admin).Get("/audit", auditHandler.ListAudit)
 
 			// Logs routes
-			r.Get("/logs", logsHandler.GetLogs)
+			r.With(operator).Get("/logs", logsHandler.GetLogs)
+
+			// Live engine events (SSE)
+			r.With(viewer).Get("/events", eventsHandler.Stream)
 
 			// System routes
-			r.Post("/system/restart", systemHandler.Restart)
-			r.Get("/system/health", systemHandler.HealthCheck)
-			r.Get("/system/info", systemHandler.GetInfo)
-			r.Get("/system/disk", systemHandler.GetDiskStatus)
-			r.Get("/system/services", servicesHandler.CheckStatus)
+			r.With(admin).Post("/system/restart", systemHandler.Restart)
+			r.With(viewer).Get("/system/health", systemHandler.HealthCheck)
+			r.With(viewer).Get("/system/info", systemHandler.GetInfo)
+			r.With(viewer).Get("/system/disk", systemHandler.GetDiskStatus)
+			r.With(viewer).Get("/system/disk/forecast", systemHandler.GetDiskForecast)
+			r.With(viewer).Get("/system/services", servicesHandler.CheckStatus)
 		})
 	})
 
@@ -3,11 +3,14 @@ package api
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/services"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
 )
 
@@ -74,7 +77,7 @@ func TestRouter_ProtectedRouteWithCookie(t *testing.T) {
 	}
 	router := setupRouter(t, cfg)
 
-	token, err := utils.GenerateToken("admin")
+	token, err := utils.GenerateToken("admin", utils.RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -132,3 +135,121 @@ func TestRouter_CORSWithConfiguredOrigins(t *testing.T) {
 		t.Error("Disallowed origin must not be echoed in CORS header")
 	}
 }
+
+func TestRouter_RoleEnforcement(t *testing.T) {
+	cfg := &config.Config{
+		Admin: config.AdminConfig{Username: "admin", Password: "testpassword"},
+	}
+	router := setupRouter(t, cfg)
+
+	tests := []struct {
+		name      string
+		role      string
+		method    string
+		path      string
+		forbidden bool
+	}{
+		{"viewer cannot trigger sync", utils.RoleViewer, http.MethodPost, "/api/sync/full", true},
+		{"viewer cannot exclude", utils.RoleViewer, http.MethodPost, "/api/media/m1/exclude", true},
+		{"viewer cannot read logs", utils.RoleViewer, http.MethodGet, "/api/logs", true},
+		{"operator cannot execute deletions", utils.RoleOperator, http.MethodPost, "/api/deletions/execute", true},
+		{"operator cannot change rules", utils.RoleOperator, http.MethodPost, "/api/rules", true},
+		{"operator cannot change config", utils.RoleOperator, http.MethodPut, "/api/config", true},
+		{"operator cannot manage users", utils.RoleOperator, http.MethodGet, "/api/users", true},
+		{"admin manages users", utils.RoleAdmin, http.MethodGet, "/api/users", false},
+		{"requester lists movies", utils.RoleRequester, http.MethodGet, "/api/media/movies", false},
+		{"requester lists own keep requests", utils.RoleRequester, http.MethodGet, "/api/keep-requests", false},
+		{"requester cannot read sync status", utils.RoleRequester, http.MethodGet, "/api/sync/status", true},
+		{"requester cannot read the plugin leaving-soon feed", utils.RoleRequester, http.MethodGet, "/api/media/leaving-soon", true},
+		{"requester cannot approve keep requests", utils.RoleRequester, http.MethodPost, "/api/keep-requests/k1/approve", true},
+		{"viewer cannot approve keep requests", utils.RoleViewer, http.MethodPost, "/api/keep-requests/k1/deny", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := utils.GenerateToken("someone", tt.role)
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Code == http.StatusForbidden; got != tt.forbidden {
+				t.Errorf("%s %s as %s: got status %d, forbidden=%v", tt.method, tt.path, tt.role, w.Code, tt.forbidden)
+			}
+		})
+	}
+}
+
+func TestRouter_APIKeyScopes(t *testing.T) {
+	if err := utils.InitJWT("router-test-secret-at-least-32-chars-long!!", 24*time.Hour); err != nil {
+		t.Fatalf("InitJWT failed: %v", err)
+	}
+	cfg := &config.Config{
+		Admin: config.AdminConfig{Username: "admin", Password: "testpassword"},
+	}
+	config.SetTestConfig(cfg)
+
+	authService := services.NewAuthService(cfg)
+	keys, err := storage.NewAPIKeysFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewAPIKeysFile failed: %v", err)
+	}
+	authService.SetAPIKeys(keys)
+	_, secret, err := authService.CreateAPIKey("plugin", []string{utils.ScopeLeavingSoonRead}, nil, "admin")
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+
+	router := NewRouter(&RouterDependencies{
+		AuthService: authService,
+		ShutdownCh:  make(chan struct{}),
+	})
+
+	// A leaving-soon:read key reaches the plugin feed and nothing else; walking
+	// every route catches protected routes registered without a guard.
+	allowed := map[string]bool{"GET /api/media/leaving-soon": true}
+	checked := 0
+	err = chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/api/") || strings.HasPrefix(route, "/api/auth/") {
+			return nil
+		}
+		path := strings.NewReplacer("{id}", "x1", "{name}", "x1", "{username}", "x1").Replace(route)
+		path = strings.TrimSuffix(path, "/")
+
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		checked++
+		want := allowed[method+" "+route]
+		if got := w.Code != http.StatusForbidden; got != want {
+			t.Errorf("%s %s with a leaving-soon:read key: got status %d, allowed=%v", method, route, w.Code, want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if checked < 40 {
+		t.Fatalf("Expected to check every protected route, only checked %d", checked)
+	}
+
+	// Revoked keys are rejected outright
+	all, err := authService.ListAPIKeys()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("ListAPIKeys: %v, %d keys", err, len(all))
+	}
+	if err := authService.RevokeAPIKey(all[0].ID); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/media/leaving-soon", nil)
+	req.Header.Set("Authorization", "Bearer "+secret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a revoked key, got %d", w.Code)
+	}
+}
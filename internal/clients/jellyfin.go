@@ -1,8 +1,10 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +14,14 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ErrJellyfinUnauthorized is returned by AuthenticateByName when Jellyfin
+// rejects the username or password
+var ErrJellyfinUnauthorized = errors.New("jellyfin rejected the credentials")
+
+// jellyfinAuthHeader identifies OxiCleanarr to Jellyfin when signing a user in.
+// Jellyfin requires client and device fields on AuthenticateByName.
+const jellyfinAuthHeader = `MediaBrowser Client="OxiCleanarr", Device="OxiCleanarr", DeviceId="oxicleanarr-login", Version="1.0.0"`
+
 // JellyfinClient handles communication with Jellyfin API
 type JellyfinClient struct {
 	baseURL string
@@ -31,9 +41,7 @@ func NewJellyfinClient(cfg config.JellyfinConfig) *JellyfinClient {
 	return &JellyfinClient{
 		baseURL: cfg.URL,
 		apiKey:  cfg.APIKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  newHTTPClient("jellyfin", cfg.URL, timeout),
 	}
 }
 
@@ -83,6 +91,139 @@ func (c *JellyfinClient) getItems(ctx context.Context, itemType string) ([]Jelly
 	return result.Items, nil
 }
 
+// GetEpisodes fetches the episodes of a series. When userID is set, the
+// played state in UserData is that user's.
+func (c *JellyfinClient) GetEpisodes(ctx context.Context, seriesID, userID string) ([]JellyfinItem, error) {
+	url := fmt.Sprintf("%s/Shows/%s/Episodes?Fields=ProviderIds", c.baseURL, seriesID)
+	if userID != "" {
+		url += "&UserId=" + userID
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Emby-Token", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result JellyfinItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	log.Debug().
+		Str("series_id", seriesID).
+		Int("count", len(result.Items)).
+		Msg("Fetched episodes from Jellyfin")
+
+	return result.Items, nil
+}
+
+// GetUsers fetches all Jellyfin user accounts
+func (c *JellyfinClient) GetUsers(ctx context.Context) ([]JellyfinUser, error) {
+	url := fmt.Sprintf("%s/Users", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Emby-Token", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var users []JellyfinUser
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return users, nil
+}
+
+// AuthenticateByName checks a user's Jellyfin credentials and returns the
+// signed-in user with its policy. The session Jellyfin opens for the login is
+// closed again right away; OxiCleanarr only needs the identity.
+func (c *JellyfinClient) AuthenticateByName(ctx context.Context, username, password string) (*JellyfinAuthResult, error) {
+	url := fmt.Sprintf("%s/Users/AuthenticateByName", c.baseURL)
+
+	body, err := json.Marshal(map[string]string{"Username": username, "Pw": password})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", jellyfinAuthHeader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, ErrJellyfinUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result JellyfinAuthResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if result.AccessToken != "" {
+		c.logoutSession(ctx, result.AccessToken)
+	}
+
+	return &result, nil
+}
+
+// logoutSession ends the Jellyfin session opened by AuthenticateByName.
+// Failures are only logged: the login itself already succeeded.
+func (c *JellyfinClient) logoutSession(ctx context.Context, accessToken string) {
+	url := fmt.Sprintf("%s/Sessions/Logout", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("X-Emby-Token", accessToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to close Jellyfin login session")
+		return
+	}
+	resp.Body.Close()
+}
+
 // GetUserData fetches user-specific data for an item
 func (c *JellyfinClient) GetUserData(ctx context.Context, userID, itemID string) (*JellyfinUserData, error) {
 	url := fmt.Sprintf("%s/Users/%s/Items/%s",
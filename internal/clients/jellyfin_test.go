@@ -2,7 +2,11 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -320,3 +324,45 @@ func TestJellyfinClient_Unit(t *testing.T) {
 		assert.Equal(t, 45*time.Second, client.client.Timeout, "Should use custom timeout")
 	})
 }
+
+func TestJellyfinClient_AuthenticateByName(t *testing.T) {
+	var loggedOut atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Users/AuthenticateByName":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Contains(t, r.Header.Get("Authorization"), `Client="OxiCleanarr"`)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["Username"] != "alice" || body["Pw"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"User":        map[string]any{"Id": "u1", "Name": "alice", "Policy": map[string]any{"IsAdministrator": true}},
+				"AccessToken": "session-token",
+			})
+		case "/Sessions/Logout":
+			assert.Equal(t, "session-token", r.Header.Get("X-Emby-Token"))
+			loggedOut.Store(true)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewJellyfinClient(config.JellyfinConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: server.URL, APIKey: "test-api-key"},
+	})
+
+	result, err := client.AuthenticateByName(context.Background(), "alice", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", result.User.Name)
+	assert.True(t, result.User.Policy.IsAdministrator)
+	assert.True(t, loggedOut.Load(), "login session should be closed")
+
+	_, err = client.AuthenticateByName(context.Background(), "alice", "wrong")
+	assert.ErrorIs(t, err, ErrJellyfinUnauthorized)
+}
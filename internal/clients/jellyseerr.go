@@ -30,9 +30,7 @@ func NewJellyseerrClient(cfg config.JellyseerrConfig) *JellyseerrClient {
 	return &JellyseerrClient{
 		baseURL: cfg.URL,
 		apiKey:  cfg.APIKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  newHTTPClient("jellyseerr", cfg.URL, timeout),
 	}
 }
 
@@ -30,9 +30,7 @@ func NewJellystatClient(cfg config.JellystatConfig) *JellystatClient {
 	return &JellystatClient{
 		baseURL: cfg.URL,
 		apiKey:  cfg.APIKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  newHTTPClient("jellystat", cfg.URL, timeout),
 	}
 }
 
@@ -95,6 +93,8 @@ func (c *JellystatClient) GetHistory(ctx context.Context, _ []string) ([]StatsHi
 	for _, h := range rawHistory {
 		items = append(items, StatsHistoryItem{
 			JellyfinItemID:  h.NowPlayingItemID,
+			EpisodeID:       h.EpisodeID,
+			UserID:          h.UserID,
 			WatchedAt:       h.ActivityDateInserted,
 			PlaybackSeconds: h.PlaybackDuration,
 		})
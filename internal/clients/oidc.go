@@ -0,0 +1,365 @@
+package clients
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+// ErrOIDCTokenInvalid is returned when an ID token fails verification
+var ErrOIDCTokenInvalid = errors.New("invalid ID token")
+
+const (
+	// oidcDiscoveryTTL is how long a discovery document is reused
+	oidcDiscoveryTTL = time.Hour
+	// oidcKeysMinRefresh limits JWKS refetches triggered by unknown key IDs
+	oidcKeysMinRefresh = time.Minute
+	// oidcClockSkew is the leeway allowed on ID token timestamps
+	oidcClockSkew = time.Minute
+)
+
+// oidcSigningMethods are the ID token algorithms we accept. "none" and the
+// HMAC family are never accepted.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCDiscovery is the subset of an OpenID provider's discovery document
+// used for the authorization code flow
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcTokenResponse is the token endpoint response
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// jsonWebKey is one key from a JWKS document
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// OIDCClient talks to an OpenID Connect provider: discovery, the token
+// endpoint and ID token verification against the provider's JWKS. Discovery
+// and keys are cached.
+type OIDCClient struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+
+	mu            sync.Mutex
+	discovery     *OIDCDiscovery
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewOIDCClient creates a new OpenID Connect client
+func NewOIDCClient(cfg config.OIDCConfig) *OIDCClient {
+	return &OIDCClient{
+		issuer:       strings.TrimSuffix(cfg.IssuerURL, "/"),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		client:       newHTTPClient("oidc", cfg.IssuerURL, 15*time.Second),
+	}
+}
+
+// Discover fetches (or returns the cached) discovery document. The document's
+// issuer must match the configured issuer.
+func (c *OIDCClient) Discover(ctx context.Context) (*OIDCDiscovery, error) {
+	c.mu.Lock()
+	if c.discovery != nil && time.Since(c.discoveredAt) < oidcDiscoveryTTL {
+		discovery := c.discovery
+		c.mu.Unlock()
+		return discovery, nil
+	}
+	c.mu.Unlock()
+
+	var discovery OIDCDiscovery
+	if err := c.getJSON(ctx, c.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("fetching discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != c.issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match configured issuer %q", discovery.Issuer, c.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing an endpoint")
+	}
+
+	c.mu.Lock()
+	c.discovery = &discovery
+	c.discoveredAt = time.Now()
+	c.mu.Unlock()
+	return &discovery, nil
+}
+
+// AuthCodeURL builds the authorization request URL with a PKCE S256
+// challenge, state and nonce
+func (c *OIDCClient) AuthCodeURL(ctx context.Context, scopes []string, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parsing authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.clientID)
+	query.Set("redirect_uri", c.redirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the raw ID token
+func (c *OIDCClient) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if c.clientSecret == "" {
+		// Public client: identify by client_id only
+		form.Set("client_id", c.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.clientSecret != "" {
+		// client_secret_basic: credentials are form-encoded before base64 (RFC 6749 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+
+	var token oidcTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("decoding token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+// VerifyIDToken checks the ID token signature against the provider's keys,
+// its issuer, audience, expiry and nonce, and returns its claims
+func (c *OIDCClient) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.publicKey(ctx, discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(c.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCTokenInvalid, err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCTokenInvalid)
+	}
+	// With several audiences, the authorized party must be us
+	if azp, ok := claims["azp"].(string); ok && azp != c.clientID {
+		return nil, fmt.Errorf("%w: authorized party %q is not this client", ErrOIDCTokenInvalid, azp)
+	}
+	return claims, nil
+}
+
+// publicKey returns the signing key with the given ID, refetching the JWKS
+// when the key is unknown (the provider may have rotated keys)
+func (c *OIDCClient) publicKey(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	key, ok := c.lookupKey(kid)
+	stale := c.keys == nil || time.Since(c.keysFetchedAt) >= oidcKeysMinRefresh
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if parsed, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = parsed
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = keys
+	c.keysFetchedAt = time.Now()
+	if key, ok := c.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a cached key. A token without a kid matches only when the
+// provider publishes exactly one key. Callers hold mu.
+func (c *OIDCClient) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// getJSON fetches endpoint and decodes the JSON response into out
+func (c *OIDCClient) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// publicKey converts an RSA or EC JWK to a public key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent out of range")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decoding x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decoding y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, fmt.Errorf("coordinate too long")
+		}
+		// Uncompressed point: 0x04 || X || Y, each left-padded to the curve size
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,171 @@
+package clients
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ramonskie/oxicleanarr/internal/clients/oidctest"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+// pkceChallenge returns the S256 code challenge for verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestOIDCClient_AuthorizationCodeFlow(t *testing.T) {
+	provider := oidctest.New()
+	defer provider.Close()
+	client := NewOIDCClient(config.OIDCConfig{
+		IssuerURL:    provider.Issuer() + "/",
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "https://app.example.com/api/auth/oidc/callback",
+	})
+	ctx := context.Background()
+
+	authURL, err := client.AuthCodeURL(ctx, []string{"openid", "groups"}, "state-1", "nonce-1", pkceChallenge("verifier-1"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if parsed.Path != "/authorize" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != pkceChallenge("verifier-1") ||
+		query.Get("state") != "state-1" || query.Get("nonce") != "nonce-1" || query.Get("scope") != "openid groups" {
+		t.Errorf("unexpected authorization URL: %s", authURL)
+	}
+
+	code, err := provider.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+
+	// The provider only redeems the code with the matching verifier
+	if _, err := client.Exchange(ctx, code, "verifier-2"); err == nil {
+		t.Error("expected error for a wrong code verifier")
+	}
+	code, err = provider.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	idToken, err := client.Exchange(ctx, code, "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+
+	claims, err := client.VerifyIDToken(ctx, idToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken failed: %v", err)
+	}
+	if claims["preferred_username"] != "alice" {
+		t.Errorf("expected alice, got %v", claims["preferred_username"])
+	}
+
+	// Codes are single-use
+	if _, err := client.Exchange(ctx, code, "verifier-1"); err == nil {
+		t.Error("expected error for a redeemed code")
+	}
+}
+
+func TestOIDCClient_VerifyIDTokenRejects(t *testing.T) {
+	provider := oidctest.New()
+	defer provider.Close()
+	client := NewOIDCClient(config.OIDCConfig{IssuerURL: provider.Issuer(), ClientID: oidctest.ClientID})
+	ctx := context.Background()
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, provider.Claims("n"))
+	forged.Header["kid"] = oidctest.KeyID
+	forgedToken, _ := forged.SignedString(otherKey)
+
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, provider.Claims("n")).SignedString([]byte("secret"))
+
+	tests := []struct {
+		name   string
+		token  string
+		mutate func(jwt.MapClaims)
+		nonce  string
+	}{
+		{name: "wrong nonce", nonce: "other"},
+		{name: "wrong audience", nonce: "n", mutate: func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{name: "wrong issuer", nonce: "n", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", nonce: "n", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "foreign azp", nonce: "n", mutate: func(c jwt.MapClaims) { c["azp"] = "other-client" }},
+		{name: "bad signature", nonce: "n", token: forgedToken},
+		{name: "hmac algorithm", nonce: "n", token: hmacToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			if token == "" {
+				claims := provider.Claims("n")
+				if tt.mutate != nil {
+					tt.mutate(claims)
+				}
+				var err error
+				if token, err = provider.Sign(claims); err != nil {
+					t.Fatalf("signing token: %v", err)
+				}
+			}
+			_, err := client.VerifyIDToken(ctx, token, tt.nonce)
+			if !errors.Is(err, ErrOIDCTokenInvalid) {
+				t.Errorf("expected ErrOIDCTokenInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOIDCClient_DiscoveryIssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:                "https://other.example.com",
+			AuthorizationEndpoint: "https://other.example.com/authorize",
+			TokenEndpoint:         "https://other.example.com/token",
+			JWKSURI:               "https://other.example.com/jwks",
+		})
+	}))
+	defer server.Close()
+
+	client := NewOIDCClient(config.OIDCConfig{IssuerURL: server.URL, ClientID: "oxicleanarr"})
+	if _, err := client.Discover(context.Background()); err == nil {
+		t.Error("expected issuer mismatch error")
+	}
+}
+
+func TestJSONWebKey_ECPublicKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	jwk := jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+	parsed, err := jwk.publicKey()
+	if err != nil {
+		t.Fatalf("publicKey failed: %v", err)
+	}
+	if !key.PublicKey.Equal(parsed) {
+		t.Error("parsed EC key does not match")
+	}
+
+	if _, err := (jsonWebKey{Kty: "oct"}).publicKey(); err == nil {
+		t.Error("expected error for symmetric key")
+	}
+}
@@ -0,0 +1,276 @@
+// Package oidctest provides a mock OpenID Connect provider for tests of the
+// single sign-on flow.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClientID and ClientSecret are the client credentials the provider accepts
+const (
+	ClientID     = "oxicleanarr"
+	ClientSecret = "mock-oidc-secret"
+)
+
+// KeyID is the key ID of the provider's signing key
+const KeyID = "mock-key"
+
+// Provider simulates an OpenID Connect provider (Authelia, Authentik,
+// Keycloak) for the authorization code flow with PKCE. /authorize approves
+// every request immediately as the configured user, and /token only redeems
+// a code with the client credentials and the matching code verifier.
+type Provider struct {
+	Server *httptest.Server
+	key    *rsa.PrivateKey
+	issuer string
+
+	mu       sync.Mutex
+	username string
+	groups   []string
+	codes    map[string]authorization
+}
+
+// authorization is what an issued code was granted for
+type authorization struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+}
+
+// New starts a provider on a loopback address. Its issuer is its URL.
+func New() *Provider {
+	p := newProvider()
+	p.Server = httptest.NewServer(p.handler())
+	p.issuer = p.Server.URL
+	return p
+}
+
+// NewOnListener starts a provider serving listener with the given issuer
+// URL, for when the client reaches the provider at another address than the
+// test does (e.g. from a container).
+func NewOnListener(listener net.Listener, issuer string) *Provider {
+	p := newProvider()
+	p.Server = httptest.NewUnstartedServer(p.handler())
+	p.Server.Listener = listener
+	p.Server.Start()
+	p.issuer = strings.TrimSuffix(issuer, "/")
+	return p
+}
+
+func newProvider() *Provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("oidctest: failed to generate signing key: " + err.Error())
+	}
+	return &Provider{
+		key:      key,
+		username: "alice",
+		codes:    make(map[string]authorization),
+	}
+}
+
+func (p *Provider) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/jwks", p.handleJWKS)
+	mux.HandleFunc("/authorize", p.handleAuthorize)
+	mux.HandleFunc("/token", p.handleToken)
+	return mux
+}
+
+// Close shuts down the provider
+func (p *Provider) Close() {
+	p.Server.Close()
+}
+
+// URL returns the base URL the test reaches the provider at
+func (p *Provider) URL() string {
+	return p.Server.URL
+}
+
+// Issuer returns the issuer URL
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// SetUser sets the account that /authorize signs in
+func (p *Provider) SetUser(username string, groups []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.username = username
+	p.groups = groups
+}
+
+// Authorize plays the browser's part for an authorization URL built against
+// the issuer: it sends the request to the provider and returns the code
+// from the redirect back to the client.
+func (p *Provider) Authorize(authURL string) (string, error) {
+	target := strings.Replace(authURL, p.issuer, p.URL(), 1)
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(target)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("authorization request refused with status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+	return location.Query().Get("code"), nil
+}
+
+// Claims returns valid ID token claims for the current user
+func (p *Provider) Claims(nonce string) jwt.MapClaims {
+	p.mu.Lock()
+	username, groups := p.username, p.groups
+	p.mu.Unlock()
+
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":                p.issuer,
+		"aud":                ClientID,
+		"sub":                "mock-" + username,
+		"preferred_username": username,
+		"groups":             groups,
+		"nonce":              nonce,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// Sign signs claims as an ID token with the provider's key
+func (p *Provider) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	return token.SignedString(p.key)
+}
+
+// handleDiscovery responds to /.well-known/openid-configuration
+func (p *Provider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+// handleJWKS publishes the signing key
+func (p *Provider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": KeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// handleAuthorize approves the request and redirects back with a code
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != ClientID ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		redirectURI: query.Get("redirect_uri"),
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+	}
+	p.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// handleToken redeems a code after checking client credentials and PKCE
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request"})
+		return
+	}
+	if clientID, secret, ok := r.BasicAuth(); !ok || clientID != ClientID || secret != ClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	grant, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := p.Sign(p.Claims(grant.nonce))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "server_error"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// randomString returns a random URL-safe string for codes and tokens
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
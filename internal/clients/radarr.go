@@ -30,9 +30,7 @@ func NewRadarrClient(cfg config.RadarrConfig) *RadarrClient {
 	return &RadarrClient{
 		baseURL: cfg.URL,
 		apiKey:  cfg.APIKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  newHTTPClient("radarr", cfg.URL, timeout),
 	}
 }
 
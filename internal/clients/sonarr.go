@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -30,9 +31,7 @@ func NewSonarrClient(cfg config.SonarrConfig) *SonarrClient {
 	return &SonarrClient{
 		baseURL: cfg.URL,
 		apiKey:  cfg.APIKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:  newHTTPClient("sonarr", cfg.URL, timeout),
 	}
 }
 
@@ -148,6 +147,45 @@ func (c *SonarrClient) DeleteEpisodeFile(ctx context.Context, episodeFileID int)
 	return nil
 }
 
+// SetEpisodesMonitored sets the monitored flag of the given episodes, so that
+// unmonitored episodes are not searched for and grabbed again
+func (c *SonarrClient) SetEpisodesMonitored(ctx context.Context, episodeIDs []int, monitored bool) error {
+	if len(episodeIDs) == 0 {
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/api/v3/episode/monitor", c.baseURL)
+
+	body, err := json.Marshal(SonarrEpisodesMonitoredRequest{EpisodeIDs: episodeIDs, Monitored: monitored})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	log.Info().
+		Ints("episode_ids", episodeIDs).
+		Bool("monitored", monitored).
+		Msg("Updated episode monitoring in Sonarr")
+	return nil
+}
+
 // GetEpisodes fetches episodes for a series
 func (c *SonarrClient) GetEpisodes(ctx context.Context, seriesID int) ([]SonarrEpisode, error) {
 	url := fmt.Sprintf("%s/api/v3/episode?seriesId=%d", c.baseURL, seriesID)
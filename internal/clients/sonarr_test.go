@@ -2,6 +2,9 @@ package clients
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -318,4 +321,22 @@ func TestSonarrClient_Unit(t *testing.T) {
 
 		assert.Equal(t, 2*time.Minute, client.client.Timeout, "Should use custom timeout")
 	})
+	t.Run("SetEpisodesMonitored", func(t *testing.T) {
+		var got SonarrEpisodesMonitoredRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "/api/v3/episode/monitor", r.URL.Path)
+			assert.Equal(t, "test-api-key", r.Header.Get("X-Api-Key"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		client := NewSonarrClient(config.SonarrConfig{
+			BaseIntegrationConfig: config.BaseIntegrationConfig{URL: server.URL, APIKey: "test-api-key"},
+		})
+
+		require.NoError(t, client.SetEpisodesMonitored(context.Background(), []int{3, 4}, false))
+		assert.Equal(t, SonarrEpisodesMonitoredRequest{EpisodeIDs: []int{3, 4}, Monitored: false}, got)
+	})
 }
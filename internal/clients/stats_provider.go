@@ -8,6 +8,8 @@ import (
 // StatsHistoryItem is the normalised watch history record shared by all stats providers.
 type StatsHistoryItem struct {
 	JellyfinItemID  string
+	EpisodeID       string // Jellyfin ID of the episode played; empty for movies
+	UserID          string // Jellyfin ID of the user who played it
 	WatchedAt       time.Time
 	PlaybackSeconds int
 }
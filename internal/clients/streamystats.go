@@ -36,9 +36,7 @@ func NewStreamystatsClient(cfg config.StreamystatsConfig) *StreamystatsClient {
 		baseURL:  cfg.URL,
 		apiKey:   cfg.APIKey,
 		serverID: cfg.ServerID,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client:   newHTTPClient("streamystats", cfg.URL, timeout),
 	}
 }
 
@@ -50,6 +48,7 @@ type streamystatsItemDetailsResponse struct {
 // streamystatsSession represents a single play session returned by Streamystats.
 type streamystatsSession struct {
 	UserID           string    `json:"userId"`
+	ItemID           string    `json:"itemId"` // the episode played, for series`
 	LastActivityDate time.Time `json:"lastActivityDate"`
 	PlayDuration     int       `json:"playDuration"` // seconds
 	Completed        bool      `json:"completed"`
@@ -152,11 +151,16 @@ func (c *StreamystatsClient) fetchItemHistory(ctx context.Context, jellyfinID st
 
 	items := make([]StatsHistoryItem, 0, len(details.WatchHistory))
 	for _, s := range details.WatchHistory {
-		items = append(items, StatsHistoryItem{
+		item := StatsHistoryItem{
 			JellyfinItemID:  jellyfinID,
+			UserID:          s.UserID,
 			WatchedAt:       s.LastActivityDate,
 			PlaybackSeconds: s.PlayDuration,
-		})
+		}
+		if s.ItemID != jellyfinID {
+			item.EpisodeID = s.ItemID
+		}
+		items = append(items, item)
 	}
 
 	return items, nil
@@ -43,6 +43,32 @@ func TestStreamystatsGetHistory(t *testing.T) {
 		assert.True(t, ids["item-2"])
 	})
 
+	t.Run("carries the episode played for series", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"watchHistory":[
+				{"userId":"u1","itemId":"episode-1","lastActivityDate":"2024-01-01T00:00:00Z","playDuration":1200},
+				{"userId":"u1","itemId":"series-1","lastActivityDate":"2024-01-02T00:00:00Z","playDuration":60}
+			]}`))
+		}))
+		defer server.Close()
+
+		client := NewStreamystatsClient(config.StreamystatsConfig{
+			BaseIntegrationConfig: config.BaseIntegrationConfig{
+				URL:    server.URL,
+				APIKey: "key",
+			},
+			ServerID: "srv",
+		})
+
+		history, err := client.GetHistory(context.Background(), []string{"series-1"})
+
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "episode-1", history[0].EpisodeID)
+		assert.Empty(t, history[1].EpisodeID, "sessions of the item itself carry no episode")
+	})
+
 	t.Run("returns empty when no items requested", func(t *testing.T) {
 		client := NewStreamystatsClient(config.StreamystatsConfig{})
 		history, err := client.GetHistory(context.Background(), nil)
@@ -0,0 +1,468 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/metrics"
+	"github.com/ramonskie/oxicleanarr/internal/tracing"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCircuitOpen is returned without contacting the integration while its
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// transportOptions tunes retries, the circuit breaker and concurrency for one
+// integration
+type transportOptions struct {
+	// MaxRetries is how many times an idempotent request is retried after a
+	// network error, a 5xx or a 429
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// FailureThreshold consecutive failed requests open the breaker for
+	// OpenDuration; then a single probe request decides whether it closes.
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	// MaxConcurrent bounds in-flight requests to the integration
+	MaxConcurrent int
+}
+
+var defaultTransportOptions = transportOptions{
+	MaxRetries:       3,
+	RetryBaseDelay:   500 * time.Millisecond,
+	RetryMaxDelay:    5 * time.Second,
+	FailureThreshold: 5,
+	OpenDuration:     30 * time.Second,
+	MaxConcurrent:    8,
+}
+
+// Circuit breaker states reported in TransportStats
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// TransportStats summarises requests to one integration since startup
+type TransportStats struct {
+	Requests            int64      `json:"requests"`
+	Failures            int64      `json:"failures"`
+	Retries             int64      `json:"retries"`
+	Rejected            int64      `json:"rejected"` // short-circuited while the breaker was open
+	InFlight            int        `json:"in_flight"`
+	CircuitState        string     `json:"circuit_state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"` // when an open breaker lets a probe through
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// integrationState is shared by every client for the same integration and
+// URL, so the breaker, concurrency limit and stats survive config reloads and
+// cover both the sync engine's clients and the status checks.
+type integrationState struct {
+	name  string
+	opts  transportOptions
+	slots chan struct{}
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+	stats               TransportStats
+}
+
+var (
+	integrationsMu sync.Mutex
+	integrations   = make(map[string]*integrationState)
+)
+
+// integrationKey identifies an integration instance by name and base URL
+func integrationKey(name, baseURL string) string {
+	return name + " " + strings.TrimRight(baseURL, "/")
+}
+
+// integrationFor returns the shared state for an integration, creating it
+// with the default options on first use
+func integrationFor(name, baseURL string) *integrationState {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+
+	key := integrationKey(name, baseURL)
+	state, ok := integrations[key]
+	if !ok {
+		state = newIntegrationState(name, defaultTransportOptions)
+		integrations[key] = state
+	}
+	return state
+}
+
+func newIntegrationState(name string, opts transportOptions) *integrationState {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 1
+	}
+	return &integrationState{
+		name:  name,
+		opts:  opts,
+		slots: make(chan struct{}, opts.MaxConcurrent),
+		state: CircuitClosed,
+	}
+}
+
+// GetTransportStats returns request stats for the integration at baseURL, or
+// false if no request has been made to it yet
+func GetTransportStats(name, baseURL string) (TransportStats, bool) {
+	integrationsMu.Lock()
+	state, ok := integrations[integrationKey(name, baseURL)]
+	integrationsMu.Unlock()
+	if !ok {
+		return TransportStats{}, false
+	}
+	return state.snapshot(), true
+}
+
+// newHTTPClient returns the HTTP client an integration client uses. Requests
+// are retried, rate limited and short-circuited per integration (see
+// resilientTransport); name labels them in logs, metrics and trace spans.
+// timeout bounds the whole request, retries included.
+func newHTTPClient(name, baseURL string, timeout time.Duration) *http.Client {
+	next := metrics.InstrumentTransport(name, tracing.InstrumentTransport(name, http.DefaultTransport))
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &resilientTransport{
+			state: integrationFor(name, baseURL),
+			next:  next,
+		},
+	}
+}
+
+// resilientTransport wraps an integration's requests with a circuit breaker,
+// a concurrency limit, redacted debug logging and retries with exponential
+// backoff and full jitter. Retries stop when the request's context ends, so
+// they stay within the client timeout. Only idempotent methods (GET, HEAD,
+// OPTIONS) are retried; a DELETE or POST is sent at most once because a lost
+// response doesn't tell us whether it was applied.
+type resilientTransport struct {
+	state *integrationState
+	next  http.RoundTripper
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s := t.state
+	if err := s.allow(); err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	retryable := isRetryableRequest(req)
+	for attempt := 1; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+
+		if !retryable || attempt > s.opts.MaxRetries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			s.record(ctx, resp, err)
+			return resp, err
+		}
+
+		delay := retryDelay(s.opts, attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		s.addRetry()
+		log.Debug().
+			Str("client", s.name).
+			Str("method", req.Method).
+			Str("url", redactURL(req.URL)).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Msg("Retrying upstream request")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			s.record(ctx, nil, ctx.Err())
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends one try of req, holding a concurrency slot until the
+// response body is closed
+func (t *resilientTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	s := t.state
+	if err := s.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+
+	try := req
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			s.release()
+			return nil, err
+		}
+		try = req.Clone(req.Context())
+		try.Body = body
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(try)
+	logExchange(s.name, try, attempt, resp, err, time.Since(start))
+	if err != nil {
+		s.release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: s.release}
+	return resp, nil
+}
+
+// releaseOnClose frees an attempt's concurrency slot once the caller is done
+// with the body
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// isRetryableRequest reports whether req can safely be sent again
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry reports whether an attempt failed in a way worth retrying:
+// network errors, timeouts, 5xx and 429
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryDelay returns the backoff before the next attempt: a random delay up
+// to base*2^(attempt-1), capped at the max, or the server's Retry-After (in
+// seconds) when that is longer and still within the cap
+func retryDelay(opts transportOptions, attempt int, resp *http.Response) time.Duration {
+	ceiling := opts.RetryBaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > opts.RetryMaxDelay {
+		ceiling = opts.RetryMaxDelay
+	}
+	delay := time.Duration(0)
+	if ceiling > 0 {
+		delay = rand.N(ceiling + 1)
+	}
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			if after := time.Duration(seconds) * time.Second; after > delay {
+				delay = min(after, opts.RetryMaxDelay)
+			}
+		}
+	}
+	return delay
+}
+
+// acquire takes a concurrency slot, waiting until one frees up or ctx ends
+func (s *integrationState) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *integrationState) release() {
+	<-s.slots
+}
+
+// allow reports whether the breaker lets a request through. After
+// OpenDuration an open breaker admits one probe; the rest are rejected until
+// the probe's outcome closes or reopens it.
+func (s *integrationState) allow() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case CircuitOpen:
+		retryAt := s.openedAt.Add(s.opts.OpenDuration)
+		if time.Now().Before(retryAt) {
+			s.stats.Rejected++
+			return fmt.Errorf("%s: %w until %s", s.name, ErrCircuitOpen, retryAt.Format(time.RFC3339))
+		}
+		s.state = CircuitHalfOpen
+		s.probing = true
+	case CircuitHalfOpen:
+		if s.probing {
+			s.stats.Rejected++
+			return fmt.Errorf("%s: %w, waiting for probe request", s.name, ErrCircuitOpen)
+		}
+		s.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker and stats with a request's final outcome. 5xx
+// responses and transport errors count as failures; a request abandoned by
+// its caller counts as neither.
+func (s *integrationState) record(ctx context.Context, resp *http.Response, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Requests++
+	s.probing = false
+
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	if err == nil && resp.StatusCode < 500 {
+		s.consecutiveFailures = 0
+		s.state = CircuitClosed
+		return
+	}
+
+	now := time.Now()
+	s.stats.Failures++
+	s.stats.LastFailureAt = &now
+	if err != nil {
+		s.stats.LastError = err.Error()
+	} else {
+		s.stats.LastError = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	s.consecutiveFailures++
+	if s.state == CircuitHalfOpen || s.consecutiveFailures >= s.opts.FailureThreshold {
+		if s.state != CircuitOpen {
+			log.Warn().
+				Str("client", s.name).
+				Int("consecutive_failures", s.consecutiveFailures).
+				Dur("open_for", s.opts.OpenDuration).
+				Msg("Circuit breaker opened, requests will fail fast")
+		}
+		s.state = CircuitOpen
+		s.openedAt = now
+	}
+}
+
+func (s *integrationState) addRetry() {
+	s.mu.Lock()
+	s.stats.Retries++
+	s.mu.Unlock()
+}
+
+func (s *integrationState) snapshot() TransportStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.InFlight = len(s.slots)
+	stats.CircuitState = s.state
+	stats.ConsecutiveFailures = s.consecutiveFailures
+	if s.state == CircuitOpen {
+		retryAt := s.openedAt.Add(s.opts.OpenDuration)
+		stats.RetryAt = &retryAt
+	}
+	return stats
+}
+
+// sensitiveParams are query parameters and headers whose values never reach
+// the logs (compared lower-case)
+var sensitiveParams = map[string]bool{
+	"apikey":               true,
+	"api_key":              true,
+	"x-api-key":            true,
+	"x-emby-token":         true,
+	"x-mediabrowser-token": true,
+	"authorization":        true,
+	"token":                true,
+	"access_token":         true,
+	"cookie":               true,
+	"set-cookie":           true,
+}
+
+const redacted = "REDACTED"
+
+// redactURL renders u with credentials and sensitive query values masked
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	masked := *u
+	if masked.User != nil {
+		masked.User = url.User(redacted)
+	}
+	if masked.RawQuery != "" {
+		query := masked.Query()
+		for key := range query {
+			if sensitiveParams[strings.ToLower(key)] {
+				query.Set(key, redacted)
+			}
+		}
+		masked.RawQuery = query.Encode()
+	}
+	return masked.String()
+}
+
+// redactHeaders flattens h for logging with sensitive values masked
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		if sensitiveParams[strings.ToLower(key)] {
+			out[key] = redacted
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// logExchange logs one attempt at debug level with secrets redacted
+func logExchange(client string, req *http.Request, attempt int, resp *http.Response, err error, duration time.Duration) {
+	event := log.Debug()
+	if !event.Enabled() {
+		return
+	}
+	event = event.
+		Str("client", client).
+		Str("method", req.Method).
+		Str("url", redactURL(req.URL)).
+		Int("attempt", attempt).
+		Interface("request_headers", redactHeaders(req.Header)).
+		Dur("duration", duration)
+	if err != nil {
+		event.Err(err).Msg("Upstream request failed")
+		return
+	}
+	event.
+		Int("status", resp.StatusCode).
+		Int64("content_length", resp.ContentLength).
+		Interface("response_headers", redactHeaders(resp.Header)).
+		Msg("Upstream request")
+}
@@ -14,6 +14,10 @@ type JellyfinItem struct {
 	Path           string            `json:"Path"`
 	UserData       JellyfinUserData  `json:"UserData"`
 	ProviderIds    map[string]string `json:"ProviderIds"`
+
+	// Episode position, set on episode items only
+	IndexNumber       int `json:"IndexNumber"`
+	ParentIndexNumber int `json:"ParentIndexNumber"`
 }
 
 // JellyfinUserData represents user-specific data for a Jellyfin item
@@ -23,6 +27,25 @@ type JellyfinUserData struct {
 	Played         bool      `json:"Played"`
 }
 
+// JellyfinUser represents a Jellyfin user account
+type JellyfinUser struct {
+	ID     string             `json:"Id"`
+	Name   string             `json:"Name"`
+	Policy JellyfinUserPolicy `json:"Policy"`
+}
+
+// JellyfinUserPolicy holds the permission flags of a Jellyfin user
+type JellyfinUserPolicy struct {
+	IsAdministrator bool `json:"IsAdministrator"`
+	IsDisabled      bool `json:"IsDisabled"`
+}
+
+// JellyfinAuthResult represents the response from /Users/AuthenticateByName
+type JellyfinAuthResult struct {
+	User        JellyfinUser `json:"User"`
+	AccessToken string       `json:"AccessToken"`
+}
+
 // JellyfinItemsResponse represents the response from Jellyfin items endpoint
 type JellyfinItemsResponse struct {
 	Items            []JellyfinItem `json:"Items"`
@@ -120,6 +143,12 @@ type SonarrEpisode struct {
 	EpisodeFile   *SonarrEpisodeFile `json:"episodeFile,omitempty"`
 }
 
+// SonarrEpisodesMonitoredRequest is the body of PUT /api/v3/episode/monitor
+type SonarrEpisodesMonitoredRequest struct {
+	EpisodeIDs []int `json:"episodeIds"`
+	Monitored  bool  `json:"monitored"`
+}
+
 // SonarrEpisodeFile represents an episode file
 type SonarrEpisodeFile struct {
 	ID        int       `json:"id"`
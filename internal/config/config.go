@@ -1,11 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/rs/zerolog/log"
@@ -15,11 +17,22 @@ import (
 var (
 	globalConfig atomic.Pointer[Config]
 	configPath   string
+
+	reloadHooksMu sync.Mutex
+	reloadHooks   []func(*Config)
 )
 
+// OnReload registers fn to be called with the new config after every
+// successful Reload, whether triggered by the file watcher or an API write
+func OnReload(fn func(*Config)) {
+	reloadHooksMu.Lock()
+	defer reloadHooksMu.Unlock()
+	reloadHooks = append(reloadHooks, fn)
+}
+
 // Load loads configuration from file and environment variables
 func Load(path string) (*Config, error) {
-	v := viper.New()
+	v := newViper()
 
 	// Set config file path
 	if path == "" {
@@ -28,12 +41,6 @@ func Load(path string) (*Config, error) {
 	configPath = path
 
 	v.SetConfigFile(path)
-	v.SetConfigType("yaml")
-
-	// Environment variable support
-	v.SetEnvPrefix("OXICLEANARR")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
 
 	// Read config file
 	if err := v.ReadInConfig(); err != nil {
@@ -44,28 +51,15 @@ func Load(path string) (*Config, error) {
 		}
 	}
 
-	// Start with defaults
-	cfg := DefaultConfig()
-
-	// Unmarshal into config struct
-	if err := v.Unmarshal(cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	cfg, err := decode(v, resolveSecrets)
+	if err != nil {
+		return nil, err
 	}
 
-	// Debug: Log admin config after unmarshaling
-	log.Debug().
-		Str("username", cfg.Admin.Username).
-		Bool("has_password", cfg.Admin.Password != "").
-		Bool("disable_auth", cfg.Admin.DisableAuth).
-		Msg("Admin config after unmarshaling from YAML")
-
-	// Apply defaults for any missing values
-	SetDefaults(cfg)
-
 	// Auto-generate an admin API key on first start so machine clients (e.g.
 	// jellyfin-plugin-leaving-soon) don't require manual key setup. The key is
 	// persisted to the config file so it stays stable across restarts.
-	if cfg.Admin.APIKey == "" {
+	if _, isRef := cfg.secretRefs["admin.api_key"]; cfg.Admin.APIKey == "" && !isRef && cfg.Admin.APIKeyFile == "" {
 		key, err := generateAPIKey()
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate admin API key: %w", err)
@@ -97,6 +91,80 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// Parse decodes config file content the way Load does, including
+// environment overrides, defaults and secret references, without validating
+// it or changing the global config
+func Parse(data []byte) (*Config, error) {
+	return parse(data, "yaml", resolveSecrets)
+}
+
+// ParseJSON is Parse for a config written as JSON, with the same keys as the
+// YAML file
+func ParseJSON(data []byte) (*Config, error) {
+	return parse(data, "json", resolveSecrets)
+}
+
+// ParseSubmitted is Parse for a config submitted to be checked rather than
+// loaded, as "yaml" or "json". A *_file or ${VAR} secret reference is only
+// resolved when running uses the same reference, with running's value, so a
+// submitted config cannot read files or environment variables the server
+// does not already use. Other references are left unresolved; Warnings
+// reports them and UnresolvedSecret tells them apart.
+func ParseSubmitted(data []byte, format string, running *Config) (*Config, error) {
+	return parse(data, format, func(cfg *Config) ValidationErrors {
+		return resolveKnownSecrets(cfg, running)
+	})
+}
+
+func parse(data []byte, format string, resolve func(*Config) ValidationErrors) (*Config, error) {
+	v := newViper()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return decode(v, resolve)
+}
+
+// newViper returns a viper instance reading YAML with OXICLEANARR_*
+// environment overrides
+func newViper() *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	// Environment variable support
+	v.SetEnvPrefix("OXICLEANARR")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	return v
+}
+
+// decode unmarshals the settings read by v over the defaults and resolves
+// secret references with resolve. Problems resolving secrets are reported by
+// Validate.
+func decode(v *viper.Viper, resolve func(*Config) ValidationErrors) (*Config, error) {
+	// Start with defaults
+	cfg := DefaultConfig()
+
+	// Unmarshal into config struct
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// Debug: Log admin config after unmarshaling
+	log.Debug().
+		Str("username", cfg.Admin.Username).
+		Bool("has_password", cfg.Admin.Password != "").
+		Bool("disable_auth", cfg.Admin.DisableAuth).
+		Msg("Admin config after unmarshaling from YAML")
+
+	// Resolve ${VAR} and *_file secret references
+	cfg.secretErrors = resolve(cfg)
+
+	// Apply defaults for any missing values
+	SetDefaults(cfg)
+	return cfg, nil
+}
+
 // Get returns the global config instance
 func Get() *Config {
 	return globalConfig.Load()
@@ -126,6 +194,13 @@ func Reload() error {
 		Str("tv_retention", cfg.Rules.TVRetention).
 		Bool("dry_run", cfg.App.DryRun).
 		Msg("Configuration reloaded successfully")
+
+	reloadHooksMu.Lock()
+	hooks := append([]func(*Config){}, reloadHooks...)
+	reloadHooksMu.Unlock()
+	for _, hook := range hooks {
+		hook(cfg)
+	}
 	return nil
 }
 
@@ -7,6 +7,9 @@ func DefaultConfig() *Config {
 			DryRun:          true,
 			EnableDeletion:  false,
 			LeavingSoonDays: 14,
+			Approval: ApprovalConfig{
+				Expiry: "7d",
+			},
 		},
 		Sync: SyncConfig{
 			FullInterval:        60, // 60 minutes (1 hour)
@@ -32,6 +35,9 @@ func SetDefaults(cfg *Config) {
 	if cfg.App.LeavingSoonDays == 0 {
 		cfg.App.LeavingSoonDays = defaults.App.LeavingSoonDays
 	}
+	if cfg.App.Approval.Expiry == "" {
+		cfg.App.Approval.Expiry = defaults.App.Approval.Expiry
+	}
 
 	// Sync defaults
 	if cfg.Sync.FullInterval == 0 {
@@ -200,3 +200,31 @@ integrations:
 		t.Fatalf("Expected the API key to be stable across reloads, got %q then %q", cfg.Admin.APIKey, cfg2.Admin.APIKey)
 	}
 }
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := ParseJSON([]byte(`{
+	"admin": {"username": "admin", "password": "changeme"},
+	"rules": {"movie_retention": "30d"},
+	"integrations": {"radarr": {"enabled": true, "url": "http://radarr:7878", "api_key": "test-key"}}
+}`))
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	if cfg.Rules.MovieRetention != "30d" {
+		t.Errorf("Expected movie_retention 30d, got %q", cfg.Rules.MovieRetention)
+	}
+	if cfg.Rules.TVRetention == "" {
+		t.Errorf("Expected defaults to be applied to tv_retention")
+	}
+	if !cfg.Integrations.Radarr.Enabled || cfg.Integrations.Radarr.URL != "http://radarr:7878" {
+		t.Errorf("Expected the Radarr integration to be parsed, got %+v", cfg.Integrations.Radarr)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Expected the parsed config to be valid: %v", err)
+	}
+
+	if _, err := ParseJSON([]byte(`{"rules": `)); err == nil {
+		t.Errorf("Expected an error for malformed JSON")
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Secret values can be given directly, as ${VAR} references to environment
+// variables, or through a <field>_file variant naming a file that holds the
+// value (Docker and Kubernetes secrets). Load resolves them; the references
+// are kept so the config can be written back without the resolved values.
+
+// Secret sources reported by SecretSource
+const (
+	SecretSourceFile = "file"
+	SecretSourceEnv  = "env"
+)
+
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// secretRef records how a secret was given in the config file
+type secretRef struct {
+	// raw is the value as written: a ${VAR} reference, or empty when the
+	// value came from a file
+	raw      string
+	resolved string
+}
+
+// secretField is a config value that may be a secret reference
+type secretField struct {
+	path  string
+	value *string
+	file  *string
+}
+
+// secretFields returns the secret values of cfg with their config paths
+func secretFields(cfg *Config) []secretField {
+	integrations := []struct {
+		name string
+		base *BaseIntegrationConfig
+	}{
+		{"jellyfin", &cfg.Integrations.Jellyfin.BaseIntegrationConfig},
+		{"radarr", &cfg.Integrations.Radarr.BaseIntegrationConfig},
+		{"sonarr", &cfg.Integrations.Sonarr.BaseIntegrationConfig},
+		{"jellyseerr", &cfg.Integrations.Jellyseerr.BaseIntegrationConfig},
+		{"jellystat", &cfg.Integrations.Jellystat.BaseIntegrationConfig},
+		{"streamystats", &cfg.Integrations.Streamystats.BaseIntegrationConfig},
+	}
+
+	fields := []secretField{
+		{"admin.password", &cfg.Admin.Password, &cfg.Admin.PasswordFile},
+		{"admin.api_key", &cfg.Admin.APIKey, &cfg.Admin.APIKeyFile},
+		{"admin.oidc.client_secret", &cfg.Admin.OIDC.ClientSecret, &cfg.Admin.OIDC.ClientSecretFile},
+	}
+	for _, integration := range integrations {
+		fields = append(fields, secretField{
+			path:  "integrations." + integration.name + ".api_key",
+			value: &integration.base.APIKey,
+			file:  &integration.base.APIKeyFile,
+		})
+	}
+	return fields
+}
+
+// resolveSecrets replaces secret references in cfg with their values and
+// remembers the references for WithSecretReferences
+func resolveSecrets(cfg *Config) ValidationErrors {
+	var errors ValidationErrors
+	refs := make(map[string]secretRef)
+
+	for _, field := range secretFields(cfg) {
+		switch {
+		case *field.file != "":
+			if *field.value != "" {
+				errors = append(errors, ValidationError{
+					Field:   field.path,
+					Message: fmt.Sprintf("set either %s or %s_file, not both", field.path, field.path),
+				})
+				continue
+			}
+			data, err := os.ReadFile(*field.file)
+			if err != nil {
+				errors = append(errors, ValidationError{
+					Field:   field.path + "_file",
+					Message: fmt.Sprintf("cannot read secret file: %v", err),
+				})
+				continue
+			}
+			*field.value = strings.TrimRight(string(data), "\r\n")
+			refs[field.path] = secretRef{resolved: *field.value}
+
+		case envReferenceRegex.MatchString(*field.value):
+			raw := *field.value
+			resolved, missing := expandEnvReferences(raw)
+			if len(missing) > 0 {
+				errors = append(errors, ValidationError{
+					Field:   field.path,
+					Message: fmt.Sprintf("environment variable %s is not set", strings.Join(missing, ", ")),
+				})
+			}
+			*field.value = resolved
+			refs[field.path] = secretRef{raw: raw, resolved: resolved}
+		}
+	}
+
+	cfg.secretRefs = refs
+	return errors
+}
+
+// resolveKnownSecrets resolves the secret references of cfg that running
+// uses too, taking running's values without reading files or the
+// environment. Other references are left in place of the value and recorded
+// as unresolved.
+func resolveKnownSecrets(cfg, running *Config) ValidationErrors {
+	var errors ValidationErrors
+	refs := make(map[string]secretRef)
+	var unresolved []string
+
+	runningFiles := make(map[string]string)
+	var runningRefs map[string]secretRef
+	if running != nil {
+		for _, field := range secretFields(running) {
+			runningFiles[field.path] = *field.file
+		}
+		runningRefs = running.secretRefs
+	}
+
+	for _, field := range secretFields(cfg) {
+		known, isKnown := runningRefs[field.path]
+		switch {
+		case *field.file != "":
+			if *field.value != "" {
+				errors = append(errors, ValidationError{
+					Field:   field.path,
+					Message: fmt.Sprintf("set either %s or %s_file, not both", field.path, field.path),
+				})
+				continue
+			}
+			if isKnown && known.raw == "" && runningFiles[field.path] == *field.file {
+				*field.value = known.resolved
+				refs[field.path] = known
+				continue
+			}
+			// Stand in for the secret so it does not count as missing
+			*field.value = *field.file
+			unresolved = append(unresolved, field.path)
+
+		case envReferenceRegex.MatchString(*field.value):
+			if isKnown && known.raw == *field.value {
+				*field.value = known.resolved
+				refs[field.path] = known
+				continue
+			}
+			unresolved = append(unresolved, field.path)
+		}
+	}
+
+	cfg.secretRefs = refs
+	cfg.unresolvedSecrets = unresolved
+	return errors
+}
+
+// UnresolvedSecret reports whether ParseSubmitted left the secret at path
+// unresolved, so its value is a reference rather than the secret
+func UnresolvedSecret(cfg *Config, path string) bool {
+	return contains(cfg.unresolvedSecrets, path)
+}
+
+// expandEnvReferences replaces ${VAR} references in s and returns the names
+// of variables that are not set
+func expandEnvReferences(s string) (string, []string) {
+	var missing []string
+	expanded := envReferenceRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		value, ok := os.LookupEnv(name)
+		if !ok && !contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	return expanded, missing
+}
+
+// WithSecretReferences returns a copy of cfg to write to the config file.
+// Secrets loaded from a file or ${VAR} reference are replaced by that
+// reference again; a secret that has been changed since it was loaded is
+// written as the new value instead.
+func WithSecretReferences(cfg *Config) *Config {
+	out := *cfg
+	for _, field := range secretFields(&out) {
+		ref, ok := cfg.secretRefs[field.path]
+		if !ok {
+			continue
+		}
+		if *field.value == ref.resolved {
+			*field.value = ref.raw
+		} else if ref.raw == "" {
+			*field.file = ""
+		}
+	}
+	return &out
+}
+
+// SecretSource reports where the secret at path (e.g.
+// "integrations.radarr.api_key") came from: SecretSourceFile,
+// SecretSourceEnv, or "" when it was given directly or is unset
+func SecretSource(cfg *Config, path string) string {
+	ref, ok := cfg.secretRefs[path]
+	switch {
+	case !ok:
+		return ""
+	case ref.raw == "":
+		return SecretSourceFile
+	default:
+		return SecretSourceEnv
+	}
+}
+
+// RedactSecrets returns a copy of cfg with every secret value other than a
+// ${VAR} reference replaced by redact(value). Empty values are kept.
+func RedactSecrets(cfg *Config, redact func(value string) string) *Config {
+	out := *cfg
+	for _, field := range secretFields(&out) {
+		if *field.value != "" && !isEnvReference(*field.value) {
+			*field.value = redact(*field.value)
+		}
+	}
+	return &out
+}
+
+// isEnvReference reports whether s is exactly one ${VAR} reference
+func isEnvReference(s string) bool {
+	loc := envReferenceRegex.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
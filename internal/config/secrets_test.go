@@ -0,0 +1,243 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file in a temp dir and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_ResolvesSecretReferences(t *testing.T) {
+	secretDir := t.TempDir()
+	radarrKeyFile := filepath.Join(secretDir, "radarr_api_key")
+	passwordFile := filepath.Join(secretDir, "admin_password")
+	if err := os.WriteFile(radarrKeyFile, []byte("radarr-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(passwordFile, []byte("hunter2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_JELLYFIN_KEY", "jellyfin-secret")
+
+	path := writeConfig(t, `
+admin:
+  username: admin
+  password_file: `+passwordFile+`
+  api_key: static-admin-key
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://localhost:8096
+    api_key: ${TEST_JELLYFIN_KEY}
+  radarr:
+    enabled: true
+    url: http://localhost:7878
+    api_key_file: `+radarrKeyFile+`
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Admin.Password != "hunter2" {
+		t.Errorf("Expected the password from the file, got %q", cfg.Admin.Password)
+	}
+	if cfg.Integrations.Radarr.APIKey != "radarr-secret" {
+		t.Errorf("Expected the trailing newline to be trimmed, got %q", cfg.Integrations.Radarr.APIKey)
+	}
+	if cfg.Integrations.Jellyfin.APIKey != "jellyfin-secret" {
+		t.Errorf("Expected the key from the environment, got %q", cfg.Integrations.Jellyfin.APIKey)
+	}
+
+	for path, want := range map[string]string{
+		"integrations.radarr.api_key":   SecretSourceFile,
+		"integrations.jellyfin.api_key": SecretSourceEnv,
+		"admin.api_key":                 "",
+	} {
+		if got := SecretSource(cfg, path); got != want {
+			t.Errorf("SecretSource(%s) = %q, want %q", path, got, want)
+		}
+	}
+
+	// Writing back keeps the references, not the resolved values
+	out := WithSecretReferences(cfg)
+	if out.Integrations.Jellyfin.APIKey != "${TEST_JELLYFIN_KEY}" {
+		t.Errorf("Expected the env reference to be kept, got %q", out.Integrations.Jellyfin.APIKey)
+	}
+	if out.Integrations.Radarr.APIKey != "" || out.Integrations.Radarr.APIKeyFile != radarrKeyFile {
+		t.Errorf("Expected the file reference to be kept, got %+v", out.Integrations.Radarr.BaseIntegrationConfig)
+	}
+	if out.Admin.Password != "" || out.Admin.APIKey != "static-admin-key" {
+		t.Errorf("Expected only references to be replaced, got %+v", out.Admin)
+	}
+	if cfg.Integrations.Radarr.APIKey != "radarr-secret" {
+		t.Error("WithSecretReferences must not modify the loaded config")
+	}
+
+	// A changed secret replaces its reference
+	changed := *cfg
+	changed.Integrations.Radarr.APIKey = "new-radarr-key"
+	changed.Integrations.Jellyfin.APIKey = "new-jellyfin-key"
+	out = WithSecretReferences(&changed)
+	if out.Integrations.Radarr.APIKey != "new-radarr-key" || out.Integrations.Radarr.APIKeyFile != "" {
+		t.Errorf("Expected the new key to replace the file reference, got %+v", out.Integrations.Radarr.BaseIntegrationConfig)
+	}
+	if out.Integrations.Jellyfin.APIKey != "new-jellyfin-key" {
+		t.Errorf("Expected the new key to replace the env reference, got %q", out.Integrations.Jellyfin.APIKey)
+	}
+}
+
+func TestLoad_ReportsSecretErrors(t *testing.T) {
+	secretDir := t.TempDir()
+	missingFile := filepath.Join(secretDir, "missing")
+	bothFile := filepath.Join(secretDir, "sonarr_api_key")
+	if err := os.WriteFile(bothFile, []byte("sonarr-secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeConfig(t, `
+admin:
+  username: admin
+  password: changeme
+  api_key: ${TEST_UNSET_ADMIN_KEY}
+integrations:
+  radarr:
+    enabled: true
+    url: http://localhost:7878
+    api_key_file: `+missingFile+`
+  sonarr:
+    enabled: true
+    url: http://localhost:8989
+    api_key: inline-key
+    api_key_file: `+bothFile+`
+`)
+	_, err := Load(path)
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+
+	fields := make(map[string]string)
+	for _, validationError := range validationErrors {
+		fields[validationError.Field] = validationError.Message
+	}
+	if msg := fields["integrations.radarr.api_key_file"]; !strings.Contains(msg, "cannot read secret file") || !strings.Contains(msg, missingFile) {
+		t.Errorf("Expected an unreadable file error naming the file, got %q", msg)
+	}
+	if _, ok := fields["integrations.radarr.api_key"]; ok {
+		t.Error("Expected the unreadable key not to be reported as missing too")
+	}
+	if msg := fields["admin.api_key"]; !strings.Contains(msg, "TEST_UNSET_ADMIN_KEY is not set") {
+		t.Errorf("Expected an unset variable error, got %q", msg)
+	}
+	if msg := fields["integrations.sonarr.api_key"]; !strings.Contains(msg, "not both") {
+		t.Errorf("Expected an error for setting both, got %q", msg)
+	}
+
+	// An unset admin key reference must not be replaced by a generated key
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "${TEST_UNSET_ADMIN_KEY}") {
+		t.Errorf("Expected the config file to keep the reference, got:\n%s", data)
+	}
+}
+
+func TestParseSubmitted_ResolvesOnlyKnownReferences(t *testing.T) {
+	secretDir := t.TempDir()
+	radarrKeyFile := filepath.Join(secretDir, "radarr_api_key")
+	otherFile := filepath.Join(secretDir, "other")
+	if err := os.WriteFile(radarrKeyFile, []byte("radarr-secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(otherFile, []byte("other-secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_JELLYFIN_KEY", "jellyfin-secret")
+	t.Setenv("TEST_OTHER_SECRET", "env-secret")
+
+	running, err := Load(writeConfig(t, `
+admin:
+  username: admin
+  password: changeme
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://localhost:8096
+    api_key: ${TEST_JELLYFIN_KEY}
+  radarr:
+    enabled: true
+    url: http://localhost:7878
+    api_key_file: `+radarrKeyFile+`
+`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	defer SetTestConfig(nil)
+
+	cfg, err := ParseSubmitted([]byte(`
+admin:
+  username: admin
+  password: ${TEST_OTHER_SECRET}
+integrations:
+  jellyfin:
+    enabled: true
+    url: http://elsewhere:8096
+    api_key: ${TEST_JELLYFIN_KEY}
+  radarr:
+    enabled: true
+    url: http://elsewhere:7878
+    api_key_file: `+radarrKeyFile+`
+  sonarr:
+    enabled: true
+    url: http://elsewhere:8989
+    api_key_file: `+otherFile+`
+`), "yaml", running)
+	if err != nil {
+		t.Fatalf("ParseSubmitted failed: %v", err)
+	}
+
+	// References the running config uses resolve to its values
+	if cfg.Integrations.Jellyfin.APIKey != "jellyfin-secret" || cfg.Integrations.Radarr.APIKey != "radarr-secret" {
+		t.Errorf("Expected the running config's secrets, got %q and %q", cfg.Integrations.Jellyfin.APIKey, cfg.Integrations.Radarr.APIKey)
+	}
+
+	// New references are not read
+	if strings.Contains(cfg.Admin.Password, "env-secret") || strings.Contains(cfg.Integrations.Sonarr.APIKey, "other-secret") {
+		t.Errorf("Expected new references to stay unresolved, got %q and %q", cfg.Admin.Password, cfg.Integrations.Sonarr.APIKey)
+	}
+	for path, want := range map[string]bool{
+		"admin.password":                true,
+		"integrations.sonarr.api_key":   true,
+		"integrations.radarr.api_key":   false,
+		"integrations.jellyfin.api_key": false,
+	} {
+		if got := UnresolvedSecret(cfg, path); got != want {
+			t.Errorf("UnresolvedSecret(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	// Unresolved secrets do not count as missing, but are warned about
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Expected the config to validate, got %v", err)
+	}
+	warned := make(map[string]bool)
+	for _, warning := range Warnings(cfg) {
+		warned[warning.Field] = true
+	}
+	if !warned["admin.password"] || !warned["integrations.sonarr.api_key"] || warned["integrations.radarr.api_key"] {
+		t.Errorf("Expected warnings for the unresolved secrets only, got %v", warned)
+	}
+}
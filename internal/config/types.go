@@ -9,6 +9,14 @@ type Config struct {
 	Server        ServerConfig       `mapstructure:"server" yaml:"server" json:"server"`
 	Integrations  IntegrationsConfig `mapstructure:"integrations" yaml:"integrations" json:"integrations"`
 	AdvancedRules []AdvancedRule     `mapstructure:"advanced_rules" yaml:"advanced_rules,omitempty" json:"advanced_rules,omitempty"`
+
+	// secretRefs records the file and ${VAR} references secrets were loaded
+	// from, keyed by config path; secretErrors the ones that could not be
+	// resolved, which Validate reports. unresolvedSecrets lists the paths
+	// ParseSubmitted left unresolved.
+	secretRefs        map[string]secretRef
+	secretErrors      ValidationErrors
+	unresolvedSecrets []string
 }
 
 // AdminConfig holds admin user credentials
@@ -16,10 +24,91 @@ type AdminConfig struct {
 	Username    string `mapstructure:"username" yaml:"username" json:"username"`
 	Password    string `mapstructure:"password" yaml:"password" json:"password"`
 	DisableAuth bool   `mapstructure:"disable_auth" yaml:"disable_auth" json:"disable_auth"`
+	// PasswordFile reads Password from a file, e.g. a Docker secret.
+	PasswordFile string `mapstructure:"password_file" yaml:"password_file,omitempty" json:"password_file,omitempty"`
 	// APIKey is a static Bearer key accepted on every protected endpoint as an
 	// alternative to a JWT (e.g. machine clients like jellyfin-plugin-leaving-soon).
 	// Empty disables the key path.
 	APIKey string `mapstructure:"api_key" yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	// APIKeyFile reads APIKey from a file.
+	APIKeyFile string `mapstructure:"api_key_file" yaml:"api_key_file,omitempty" json:"api_key_file,omitempty"`
+	// JellyfinLogin lets Jellyfin accounts sign in with their Jellyfin
+	// credentials, checked against the configured Jellyfin server.
+	JellyfinLogin JellyfinLoginConfig `mapstructure:"jellyfin_login" yaml:"jellyfin_login,omitempty" json:"jellyfin_login,omitempty"`
+	// OIDC enables single sign-on through an OpenID Connect provider.
+	OIDC OIDCConfig `mapstructure:"oidc" yaml:"oidc,omitempty" json:"oidc,omitempty"`
+	// TwoFactor configures TOTP two-factor sign-in for local accounts.
+	TwoFactor TwoFactorConfig `mapstructure:"two_factor" yaml:"two_factor,omitempty" json:"two_factor,omitempty"`
+	// LoginThrottle locks out a username or client IP after repeated failed
+	// sign-in attempts.
+	LoginThrottle LoginThrottleConfig `mapstructure:"login_throttle" yaml:"login_throttle,omitempty" json:"login_throttle,omitempty"`
+	// Sessions configures server-side sessions and token refresh.
+	Sessions SessionsConfig `mapstructure:"sessions" yaml:"sessions,omitempty" json:"sessions,omitempty"`
+}
+
+// TwoFactorConfig holds settings for TOTP two-factor sign-in. Any local
+// account can enroll; accounts with a role in RequiredRoles must, and are
+// walked through enrollment at their next sign-in.
+type TwoFactorConfig struct {
+	RequiredRoles []string `mapstructure:"required_roles" yaml:"required_roles,omitempty" json:"required_roles,omitempty"`
+	// Issuer is the name authenticator apps show for the account (default "OxiCleanarr").
+	Issuer string `mapstructure:"issuer" yaml:"issuer,omitempty" json:"issuer,omitempty"`
+}
+
+// LoginThrottleConfig limits failed sign-ins. After MaxFailures failures
+// within Window for the same username or client IP, further attempts from it
+// are refused until Lockout has passed.
+type LoginThrottleConfig struct {
+	Disabled    bool   `mapstructure:"disabled" yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	MaxFailures int    `mapstructure:"max_failures" yaml:"max_failures,omitempty" json:"max_failures,omitempty"` // default 5
+	Window      string `mapstructure:"window" yaml:"window,omitempty" json:"window,omitempty"`                   // default "15m"
+	Lockout     string `mapstructure:"lockout" yaml:"lockout,omitempty" json:"lockout,omitempty"`                // default "15m"
+}
+
+// SessionsConfig holds settings for server-side sessions. A session lasts
+// JWT_EXPIRATION from sign-in; the access tokens it hands out expire after
+// AccessTokenTTL and are renewed with the session's refresh token.
+type SessionsConfig struct {
+	AccessTokenTTL string `mapstructure:"access_token_ttl" yaml:"access_token_ttl,omitempty" json:"access_token_ttl,omitempty"` // default "15m"
+}
+
+// JellyfinLoginConfig holds settings for signing in with Jellyfin accounts.
+// Jellyfin administrators get the admin role; everyone else gets UserRole.
+type JellyfinLoginConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// UserRole is the role for non-administrator Jellyfin users: "requester"
+	// (default; only sees media they requested), "viewer" or "operator".
+	UserRole string `mapstructure:"user_role" yaml:"user_role,omitempty" json:"user_role,omitempty"`
+}
+
+// OIDCConfig holds settings for OpenID Connect sign-in (authorization code
+// flow with PKCE). Roles come from the groups claim via GroupRoles; users in
+// no mapped group get DefaultRole, or are refused when it is empty.
+type OIDCConfig struct {
+	Enabled      bool   `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	IssuerURL    string `mapstructure:"issuer_url" yaml:"issuer_url,omitempty" json:"issuer_url,omitempty"`
+	ClientID     string `mapstructure:"client_id" yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	ClientSecret string `mapstructure:"client_secret" yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	// ClientSecretFile reads ClientSecret from a file.
+	ClientSecretFile string `mapstructure:"client_secret_file" yaml:"client_secret_file,omitempty" json:"client_secret_file,omitempty"`
+	// RedirectURL is the callback registered with the provider, e.g.
+	// https://oxicleanarr.example.com/api/auth/oidc/callback
+	RedirectURL string `mapstructure:"redirect_url" yaml:"redirect_url,omitempty" json:"redirect_url,omitempty"`
+	// Scopes defaults to openid, profile, email and groups.
+	Scopes []string `mapstructure:"scopes" yaml:"scopes,omitempty" json:"scopes,omitempty"`
+	// UsernameClaim defaults to "preferred_username".
+	UsernameClaim string `mapstructure:"username_claim" yaml:"username_claim,omitempty" json:"username_claim,omitempty"`
+	// GroupsClaim defaults to "groups".
+	GroupsClaim string          `mapstructure:"groups_claim" yaml:"groups_claim,omitempty" json:"groups_claim,omitempty"`
+	GroupRoles  []OIDCGroupRole `mapstructure:"group_roles" yaml:"group_roles,omitempty" json:"group_roles,omitempty"`
+	DefaultRole string          `mapstructure:"default_role" yaml:"default_role,omitempty" json:"default_role,omitempty"`
+}
+
+// OIDCGroupRole grants Role to members of Group. A user in several mapped
+// groups gets the highest role.
+type OIDCGroupRole struct {
+	Group string `mapstructure:"group" yaml:"group" json:"group"`
+	Role  string `mapstructure:"role" yaml:"role" json:"role"`
 }
 
 // AppConfig holds general application settings
@@ -28,6 +117,16 @@ type AppConfig struct {
 	EnableDeletion  bool                `mapstructure:"enable_deletion" yaml:"enable_deletion" json:"enable_deletion"`
 	LeavingSoonDays int                 `mapstructure:"leaving_soon_days" yaml:"leaving_soon_days" json:"leaving_soon_days"`
 	DiskThreshold   DiskThresholdConfig `mapstructure:"disk_threshold" yaml:"disk_threshold,omitempty" json:"disk_threshold,omitempty"`
+	Approval        ApprovalConfig      `mapstructure:"approval" yaml:"approval,omitempty" json:"approval,omitempty"`
+}
+
+// ApprovalConfig holds settings for two-step deletion approval. When enabled
+// (together with enable_deletion and dry_run=false), full syncs propose
+// deletions as a pending batch instead of deleting, and nothing is removed
+// until an admin approves the batch.
+type ApprovalConfig struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Expiry  string `mapstructure:"expiry" yaml:"expiry,omitempty" json:"expiry,omitempty"` // how long a batch stays approvable, e.g. "7d" (default)
 }
 
 // DiskThresholdConfig holds disk-space threshold settings for conditional rule activation
@@ -42,6 +141,26 @@ type SyncConfig struct {
 	FullInterval        int  `mapstructure:"full_interval" yaml:"full_interval" json:"full_interval"`
 	IncrementalInterval int  `mapstructure:"incremental_interval" yaml:"incremental_interval" json:"incremental_interval"`
 	AutoStart           bool `mapstructure:"auto_start" yaml:"auto_start" json:"auto_start"`
+
+	// Cron expressions (standard 5-field or descriptors like "@daily") override
+	// the matching interval when set.
+	FullCron        string `mapstructure:"full_cron" yaml:"full_cron,omitempty" json:"full_cron,omitempty"`
+	IncrementalCron string `mapstructure:"incremental_cron" yaml:"incremental_cron,omitempty" json:"incremental_cron,omitempty"`
+	// DeletionCron runs the deletion pass on its own schedule. Empty = deletions
+	// run at the end of each full sync.
+	DeletionCron string `mapstructure:"deletion_cron" yaml:"deletion_cron,omitempty" json:"deletion_cron,omitempty"`
+	// DeletionBlackouts are windows in which automatic deletions never run;
+	// deletions due during a blackout are deferred until it ends.
+	DeletionBlackouts []BlackoutWindow `mapstructure:"deletion_blackouts" yaml:"deletion_blackouts,omitempty" json:"deletion_blackouts,omitempty"`
+}
+
+// BlackoutWindow is a recurring window in server local time. Start and End are
+// either "HH:MM" (every day) or "<weekday> HH:MM" (weekly, e.g. "fri 18:00").
+// Windows may wrap past midnight or the end of the week.
+type BlackoutWindow struct {
+	Name  string `mapstructure:"name" yaml:"name,omitempty" json:"name,omitempty"`
+	Start string `mapstructure:"start" yaml:"start" json:"start"`
+	End   string `mapstructure:"end" yaml:"end" json:"end"`
 }
 
 // RulesConfig holds simple retention rules
@@ -55,9 +174,40 @@ type RulesConfig struct {
 
 // ServerConfig holds HTTP server settings
 type ServerConfig struct {
-	Host        string   `mapstructure:"host" yaml:"host" json:"host"`
-	Port        int      `mapstructure:"port" yaml:"port" json:"port"`
-	CorsOrigins []string `mapstructure:"cors_origins" yaml:"cors_origins,omitempty" json:"cors_origins,omitempty"` // Allowed CORS origins (empty = same-origin only)
+	Host        string        `mapstructure:"host" yaml:"host" json:"host"`
+	Port        int           `mapstructure:"port" yaml:"port" json:"port"`
+	CorsOrigins []string      `mapstructure:"cors_origins" yaml:"cors_origins,omitempty" json:"cors_origins,omitempty"` // Allowed CORS origins (empty = same-origin only)
+	Metrics     MetricsConfig `mapstructure:"metrics" yaml:"metrics,omitempty" json:"metrics"`
+	Tracing     TracingConfig `mapstructure:"tracing" yaml:"tracing,omitempty" json:"tracing"`
+	Audit       AuditConfig   `mapstructure:"audit" yaml:"audit,omitempty" json:"audit"`
+
+	// TrustedProxies lists the reverse proxies (IPs or CIDRs) whose
+	// X-Forwarded-For and X-Real-IP headers name the client. Empty trusts none.
+	TrustedProxies []string `mapstructure:"trusted_proxies" yaml:"trusted_proxies,omitempty" json:"trusted_proxies,omitempty"`
+}
+
+// AuditConfig bounds the audit log of mutating actions. Entries older than
+// Retention or beyond the newest MaxEntries are dropped as new ones arrive.
+type AuditConfig struct {
+	Retention  string `mapstructure:"retention" yaml:"retention,omitempty" json:"retention,omitempty"`       // e.g. "90d" (default)
+	MaxEntries int    `mapstructure:"max_entries" yaml:"max_entries,omitempty" json:"max_entries,omitempty"` // default 10000
+}
+
+// MetricsConfig controls the Prometheus endpoint at GET /metrics
+type MetricsConfig struct {
+	Disabled bool `mapstructure:"disabled" yaml:"disabled,omitempty" json:"disabled"`
+	// RequireAPIKey requires admin.api_key as a Bearer token on scrapes
+	RequireAPIKey bool `mapstructure:"require_api_key" yaml:"require_api_key,omitempty" json:"require_api_key"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Read once at startup; changes
+// require a restart.
+type TracingConfig struct {
+	Enabled  bool   `mapstructure:"enabled" yaml:"enabled,omitempty" json:"enabled"`
+	Exporter string `mapstructure:"exporter" yaml:"exporter,omitempty" json:"exporter,omitempty"` // "otlp" (default), "stdout"
+	// Endpoint is the OTLP/HTTP traces URL, e.g. "http://otel-collector:4318".
+	// Empty uses the OTEL_EXPORTER_OTLP_* environment variables, or localhost:4318.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
 }
 
 // IntegrationsConfig holds all integration settings
@@ -70,12 +220,14 @@ type IntegrationsConfig struct {
 	Streamystats StreamystatsConfig `mapstructure:"streamystats" yaml:"streamystats" json:"streamystats"`
 }
 
-// BaseIntegrationConfig holds common integration settings
+// BaseIntegrationConfig holds common integration settings. The API key can
+// also be a ${VAR} reference, or be read from APIKeyFile.
 type BaseIntegrationConfig struct {
-	Enabled bool   `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
-	URL     string `mapstructure:"url" yaml:"url" json:"url"`
-	APIKey  string `mapstructure:"api_key" yaml:"api_key" json:"api_key"`
-	Timeout string `mapstructure:"timeout" yaml:"timeout" json:"timeout"`
+	Enabled    bool   `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	URL        string `mapstructure:"url" yaml:"url" json:"url"`
+	APIKey     string `mapstructure:"api_key" yaml:"api_key" json:"api_key"`
+	APIKeyFile string `mapstructure:"api_key_file" yaml:"api_key_file,omitempty" json:"api_key_file,omitempty"`
+	Timeout    string `mapstructure:"timeout" yaml:"timeout" json:"timeout"`
 }
 
 // JellyfinConfig holds Jellyfin integration settings
@@ -130,7 +282,16 @@ type AdvancedRule struct {
 	SeasonNumbers           []int  `mapstructure:"season_numbers" yaml:"season_numbers,omitempty" json:"season_numbers,omitempty"`
 	ExcludeContinuingSeries bool   `mapstructure:"exclude_continuing_series" yaml:"exclude_continuing_series,omitempty" json:"exclude_continuing_series,omitempty"`
 	KeepLatestSeason        bool   `mapstructure:"keep_latest_season" yaml:"keep_latest_season,omitempty" json:"keep_latest_season,omitempty"`
-	EpisodeDeleteStrategy   string `mapstructure:"episode_delete_strategy" yaml:"episode_delete_strategy,omitempty" json:"episode_delete_strategy,omitempty"` // "oldest_first", "by_age", "by_season_age"
+	EpisodeDeleteStrategy   string `mapstructure:"episode_delete_strategy" yaml:"episode_delete_strategy,omitempty" json:"episode_delete_strategy,omitempty"` // "oldest_first", "by_age", "by_season_age", "rolling"
+	RollingScope            string `mapstructure:"rolling_scope" yaml:"rolling_scope,omitempty" json:"rolling_scope,omitempty"`                               // rolling strategy: "household" (default) or "user"
+	RollingBuffer           int    `mapstructure:"rolling_buffer" yaml:"rolling_buffer,omitempty" json:"rolling_buffer,omitempty"`                            // rolling strategy: watched episodes kept behind the furthest watched one
+	UnmonitorDeleted        bool   `mapstructure:"unmonitor_deleted" yaml:"unmonitor_deleted,omitempty" json:"unmonitor_deleted,omitempty"`                   // unmonitor episodes in Sonarr after deleting their files (always on for rolling)
+	UnmonitorPastSeasons    bool   `mapstructure:"unmonitor_past_seasons" yaml:"unmonitor_past_seasons,omitempty" json:"unmonitor_past_seasons,omitempty"`    // also unmonitor every episode of seasons before the latest one
+
+	// Episode watch awareness (only valid when Type="episode")
+	OnlyWatchedEpisodes bool   `mapstructure:"only_watched_episodes" yaml:"only_watched_episodes,omitempty" json:"only_watched_episodes,omitempty"` // only delete episodes that have been watched
+	KeepNextNUnwatched  int    `mapstructure:"keep_next_n_unwatched" yaml:"keep_next_n_unwatched,omitempty" json:"keep_next_n_unwatched,omitempty"` // never delete the next N unwatched episodes after the furthest watched one
+	WatchedRetention    string `mapstructure:"watched_retention" yaml:"watched_retention,omitempty" json:"watched_retention,omitempty"`             // only delete episodes last watched at least this long ago
 }
 
 // UserRule represents a user-based cleanup rule
@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/ramonskie/oxicleanarr/internal/utils"
 )
 
 var durationRegex = regexp.MustCompile(`^\d+[dhms]$`)
@@ -51,6 +55,60 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	// Validate Jellyfin login (needs the Jellyfin integration to check credentials)
+	if cfg.Admin.JellyfinLogin.Enabled {
+		if !cfg.Integrations.Jellyfin.Enabled || cfg.Integrations.Jellyfin.URL == "" {
+			errors = append(errors, ValidationError{
+				Field:   "admin.jellyfin_login.enabled",
+				Message: "requires the Jellyfin integration to be enabled with a URL",
+			})
+		}
+	}
+	switch cfg.Admin.JellyfinLogin.UserRole {
+	case "", "requester", "viewer", "operator":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "admin.jellyfin_login.user_role",
+			Message: fmt.Sprintf("invalid role %q (must be 'requester', 'viewer' or 'operator')", cfg.Admin.JellyfinLogin.UserRole),
+		})
+	}
+
+	errors = append(errors, validateOIDC(cfg.Admin.OIDC)...)
+
+	// Validate two-factor enforcement and login throttling
+	for i, role := range cfg.Admin.TwoFactor.RequiredRoles {
+		if !utils.IsValidRole(role) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("admin.two_factor.required_roles[%d]", i),
+				Message: fmt.Sprintf("invalid role %q (must be 'admin', 'operator', 'viewer' or 'requester')", role),
+			})
+		}
+	}
+	if cfg.Admin.LoginThrottle.MaxFailures < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "admin.login_throttle.max_failures",
+			Message: fmt.Sprintf("must not be negative (got %d)", cfg.Admin.LoginThrottle.MaxFailures),
+		})
+	}
+	for _, field := range []struct{ name, value string }{
+		{"window", cfg.Admin.LoginThrottle.Window},
+		{"lockout", cfg.Admin.LoginThrottle.Lockout},
+	} {
+		if field.value != "" && !isPositiveDuration(field.value) {
+			errors = append(errors, ValidationError{
+				Field:   "admin.login_throttle." + field.name,
+				Message: fmt.Sprintf("invalid duration format %q (use a positive duration like '15m', '1h')", field.value),
+			})
+		}
+	}
+
+	if ttl := cfg.Admin.Sessions.AccessTokenTTL; ttl != "" && !isPositiveDuration(ttl) {
+		errors = append(errors, ValidationError{
+			Field:   "admin.sessions.access_token_ttl",
+			Message: fmt.Sprintf("invalid duration format %q (use a positive duration like '15m', '1h')", ttl),
+		})
+	}
+
 	// Validate at least one integration enabled
 	hasIntegration := cfg.Integrations.Jellyfin.Enabled ||
 		cfg.Integrations.Radarr.Enabled ||
@@ -198,15 +256,35 @@ func Validate(cfg *Config) error {
 				}
 
 				// At least one targeting criterion required
-				if rule.MaxEpisodes == 0 && rule.MaxAge == "" && len(rule.SeasonNumbers) == 0 {
+				if rule.MaxEpisodes == 0 && rule.MaxAge == "" && len(rule.SeasonNumbers) == 0 && rule.WatchedRetention == "" && rule.EpisodeDeleteStrategy != "rolling" {
+					errors = append(errors, ValidationError{
+						Field:   prefix,
+						Message: "episode rule must specify at least one of: max_episodes, max_age, season_numbers, watched_retention",
+					})
+				}
+
+				// Validate watch awareness options
+				if rule.WatchedRetention != "" && !isValidDuration(rule.WatchedRetention) {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.watched_retention", prefix),
+						Message: fmt.Sprintf("invalid duration format %q", rule.WatchedRetention),
+					})
+				}
+				if rule.KeepNextNUnwatched < 0 {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.keep_next_n_unwatched", prefix),
+						Message: "must not be negative",
+					})
+				}
+				if (rule.OnlyWatchedEpisodes || rule.KeepNextNUnwatched > 0 || rule.WatchedRetention != "") && !cfg.Integrations.Jellyfin.Enabled {
 					errors = append(errors, ValidationError{
 						Field:   prefix,
-						Message: "episode rule must specify at least one of: max_episodes, max_age, season_numbers",
+						Message: "only_watched_episodes, keep_next_n_unwatched and watched_retention require Jellyfin integration to be enabled",
 					})
 				}
 
 				// Validate episode_delete_strategy
-				validStrategies := []string{"oldest_first", "by_age", "by_season_age"}
+				validStrategies := []string{"oldest_first", "by_age", "by_season_age", "rolling"}
 				if rule.EpisodeDeleteStrategy != "" && !contains(validStrategies, rule.EpisodeDeleteStrategy) {
 					errors = append(errors, ValidationError{
 						Field:   fmt.Sprintf("%s.episode_delete_strategy", prefix),
@@ -214,6 +292,39 @@ func Validate(cfg *Config) error {
 					})
 				}
 
+				// Validate rolling strategy options
+				validRollingScopes := []string{"household", "user"}
+				if rule.RollingScope != "" && !contains(validRollingScopes, rule.RollingScope) {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.rolling_scope", prefix),
+						Message: fmt.Sprintf("must be one of: %v", validRollingScopes),
+					})
+				}
+				if rule.RollingBuffer < 0 {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.rolling_buffer", prefix),
+						Message: "must not be negative",
+					})
+				}
+				if (rule.RollingScope != "" || rule.RollingBuffer > 0) && rule.EpisodeDeleteStrategy != "rolling" {
+					errors = append(errors, ValidationError{
+						Field:   prefix,
+						Message: "rolling_scope and rolling_buffer require episode_delete_strategy: rolling",
+					})
+				}
+				if rule.UnmonitorPastSeasons && !rule.UnmonitorDeleted && rule.EpisodeDeleteStrategy != "rolling" {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.unmonitor_past_seasons", prefix),
+						Message: "unmonitor_past_seasons requires unmonitor_deleted or episode_delete_strategy: rolling",
+					})
+				}
+				if rule.EpisodeDeleteStrategy == "rolling" && !cfg.Integrations.Jellyfin.Enabled {
+					errors = append(errors, ValidationError{
+						Field:   fmt.Sprintf("%s.episode_delete_strategy", prefix),
+						Message: "the rolling strategy requires Jellyfin integration to be enabled",
+					})
+				}
+
 				// Episode rules require Sonarr integration
 				if !cfg.Integrations.Sonarr.Enabled {
 					errors = append(errors, ValidationError{
@@ -283,6 +394,85 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	// Validate cron schedules
+	cronFields := []struct{ field, expr string }{
+		{"sync.full_cron", cfg.Sync.FullCron},
+		{"sync.incremental_cron", cfg.Sync.IncrementalCron},
+		{"sync.deletion_cron", cfg.Sync.DeletionCron},
+	}
+	for _, f := range cronFields {
+		if f.expr == "" {
+			continue
+		}
+		if _, err := utils.ParseCron(f.expr); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   f.field,
+				Message: fmt.Sprintf("invalid cron expression %q: %v", f.expr, err),
+			})
+		}
+	}
+
+	// Validate deletion blackout windows
+	for i, window := range cfg.Sync.DeletionBlackouts {
+		if _, err := utils.ParseTimeWindow(window.Start, window.End); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("sync.deletion_blackouts[%d]", i),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	// Validate approval expiry (must be a positive duration; "never"/"0d" would
+	// leave batches approvable forever against an increasingly stale library)
+	if cfg.App.Approval.Expiry != "" && !isPositiveDuration(cfg.App.Approval.Expiry) {
+		errors = append(errors, ValidationError{
+			Field:   "app.approval.expiry",
+			Message: fmt.Sprintf("invalid duration format %q (use a positive duration like '7d', '48h')", cfg.App.Approval.Expiry),
+		})
+	}
+
+	// Validate tracing
+	if cfg.Server.Tracing.Enabled {
+		validExporters := []string{"otlp", "stdout"}
+		if cfg.Server.Tracing.Exporter != "" && !contains(validExporters, cfg.Server.Tracing.Exporter) {
+			errors = append(errors, ValidationError{
+				Field:   "server.tracing.exporter",
+				Message: fmt.Sprintf("must be one of: %v", validExporters),
+			})
+		}
+		if endpoint := cfg.Server.Tracing.Endpoint; endpoint != "" {
+			if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errors = append(errors, ValidationError{
+					Field:   "server.tracing.endpoint",
+					Message: fmt.Sprintf("invalid URL %q (use e.g. 'http://otel-collector:4318')", endpoint),
+				})
+			}
+		}
+	}
+
+	// Validate audit log retention
+	if cfg.Server.Audit.Retention != "" && !isPositiveDuration(cfg.Server.Audit.Retention) {
+		errors = append(errors, ValidationError{
+			Field:   "server.audit.retention",
+			Message: fmt.Sprintf("invalid duration format %q (use a positive duration like '90d', '720h')", cfg.Server.Audit.Retention),
+		})
+	}
+	if cfg.Server.Audit.MaxEntries < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "server.audit.max_entries",
+			Message: fmt.Sprintf("must not be negative (got %d)", cfg.Server.Audit.MaxEntries),
+		})
+	}
+
+	for i, proxy := range cfg.Server.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("server.trusted_proxies[%d]", i),
+				Message: fmt.Sprintf("invalid address %q (use an IP like '172.18.0.2' or a CIDR like '172.18.0.0/16')", proxy),
+			})
+		}
+	}
+
 	// Validate port range
 	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
 		errors = append(errors, ValidationError{
@@ -291,12 +481,85 @@ func Validate(cfg *Config) error {
 		})
 	}
 
+	errors = withSecretErrors(errors, cfg.secretErrors)
+
 	if len(errors) > 0 {
 		return errors
 	}
 	return nil
 }
 
+// Warnings returns settings that are valid but probably not what was meant,
+// such as rules that depend on a disabled integration. Unlike Validate's
+// errors, they do not stop the config from being applied.
+func Warnings(cfg *Config) ValidationErrors {
+	var warnings ValidationErrors
+
+	if cfg.Admin.DisableAuth {
+		warnings = append(warnings, ValidationError{
+			Field:   "admin.disable_auth",
+			Message: "authentication is disabled; anyone who can reach the server has full access",
+		})
+	}
+
+	for _, path := range cfg.unresolvedSecrets {
+		warnings = append(warnings, ValidationError{
+			Field:   path,
+			Message: "the secret reference differs from the running config and was not resolved; it is checked when the config is applied",
+		})
+	}
+
+	hasWatchData := cfg.Integrations.Jellyfin.Enabled || cfg.Integrations.Jellystat.Enabled || cfg.Integrations.Streamystats.Enabled
+	if cfg.Rules.RetentionBase == "last_watched" && !hasWatchData {
+		warnings = append(warnings, ValidationError{
+			Field:   "rules.retention_base",
+			Message: "last_watched needs watch history from Jellyfin or a stats provider, but none is enabled",
+		})
+	}
+
+	for i, rule := range cfg.AdvancedRules {
+		if !rule.Enabled {
+			continue
+		}
+		prefix := fmt.Sprintf("advanced_rules[%d]", i)
+		switch rule.Type {
+		case "user":
+			if !cfg.Integrations.Jellyseerr.Enabled {
+				warnings = append(warnings, ValidationError{
+					Field:   prefix,
+					Message: "user rules match Jellyseerr requesters, but Jellyseerr is disabled; the rule matches nothing",
+				})
+			}
+		case "tag":
+			if !cfg.Integrations.Radarr.Enabled && !cfg.Integrations.Sonarr.Enabled {
+				warnings = append(warnings, ValidationError{
+					Field:   prefix,
+					Message: "tag rules match Radarr and Sonarr tags, but neither is enabled; the rule matches nothing",
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// withSecretErrors puts the errors from resolving secret references first. A
+// field whose secret could not be resolved is not also reported as missing.
+func withSecretErrors(errors, secretErrors ValidationErrors) ValidationErrors {
+	if len(secretErrors) == 0 {
+		return errors
+	}
+	all := append(ValidationErrors{}, secretErrors...)
+	for _, err := range errors {
+		if !slices.ContainsFunc(secretErrors, func(secretErr ValidationError) bool {
+			return strings.TrimSuffix(secretErr.Field, "_file") == err.Field
+		}) {
+			all = append(all, err)
+		}
+	}
+	return all
+}
+
 // validateIntegration validates URL and API key for an integration
 func validateIntegration(errors ValidationErrors, prefix, urlStr, apiKey string) ValidationErrors {
 	if urlStr == "" {
@@ -324,6 +587,68 @@ func validateIntegration(errors ValidationErrors, prefix, urlStr, apiKey string)
 	return errors
 }
 
+// validateOIDC validates the OpenID Connect sign-in settings
+func validateOIDC(oidc OIDCConfig) ValidationErrors {
+	var errors ValidationErrors
+	if !oidc.Enabled {
+		return errors
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"issuer_url", oidc.IssuerURL},
+		{"redirect_url", oidc.RedirectURL},
+	} {
+		if field.value == "" {
+			errors = append(errors, ValidationError{
+				Field:   "admin.oidc." + field.name,
+				Message: "required when enabled=true",
+			})
+			continue
+		}
+		if u, err := url.Parse(field.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errors = append(errors, ValidationError{
+				Field:   "admin.oidc." + field.name,
+				Message: fmt.Sprintf("must be an absolute http(s) URL (got: %q)", field.value),
+			})
+		}
+	}
+	if oidc.ClientID == "" {
+		errors = append(errors, ValidationError{
+			Field:   "admin.oidc.client_id",
+			Message: "required when enabled=true",
+		})
+	}
+
+	for i, mapping := range oidc.GroupRoles {
+		if mapping.Group == "" {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("admin.oidc.group_roles[%d].group", i),
+				Message: "must not be empty",
+			})
+		}
+		if !utils.IsValidRole(mapping.Role) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("admin.oidc.group_roles[%d].role", i),
+				Message: fmt.Sprintf("invalid role %q (must be 'admin', 'operator', 'viewer' or 'requester')", mapping.Role),
+			})
+		}
+	}
+	if oidc.DefaultRole != "" && !utils.IsValidRole(oidc.DefaultRole) {
+		errors = append(errors, ValidationError{
+			Field:   "admin.oidc.default_role",
+			Message: fmt.Sprintf("invalid role %q (must be 'admin', 'operator', 'viewer' or 'requester')", oidc.DefaultRole),
+		})
+	}
+	if len(oidc.GroupRoles) == 0 && oidc.DefaultRole == "" {
+		errors = append(errors, ValidationError{
+			Field:   "admin.oidc.group_roles",
+			Message: "map at least one group to a role, or set default_role",
+		})
+	}
+
+	return errors
+}
+
 // isValidDuration checks if a duration string is valid (e.g., "30d", "1h", "90d")
 // Special values "never" and "0d" are allowed to disable retention rules
 func isValidDuration(duration string) bool {
@@ -334,6 +659,31 @@ func isValidDuration(duration string) bool {
 	return durationRegex.MatchString(duration)
 }
 
+// isPositiveDuration checks if a duration string is valid and non-zero,
+// rejecting the special "never"/"0d" values accepted by isValidDuration
+func isPositiveDuration(duration string) bool {
+	if !durationRegex.MatchString(duration) {
+		return false
+	}
+	return strings.Trim(duration[:len(duration)-1], "0") != ""
+}
+
+// ParseTrustedProxy parses a server.trusted_proxies entry, an IP address or
+// a CIDR range
+func ParseTrustedProxy(proxy string) (netip.Prefix, error) {
+	proxy = strings.TrimSpace(proxy)
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 // contains checks if a string slice contains a given value
 func contains(slice []string, value string) bool {
 	for _, s := range slice {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -497,3 +498,558 @@ func TestValidate_DisabledRetention_AdvancedRules(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_ApprovalExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		expiry      string
+		shouldError bool
+	}{
+		{name: "empty uses default - should pass", expiry: "", shouldError: false},
+		{name: "days - should pass", expiry: "7d", shouldError: false},
+		{name: "hours - should pass", expiry: "48h", shouldError: false},
+		{name: "zero - should fail", expiry: "0d", shouldError: true},
+		{name: "zero hours - should fail", expiry: "00h", shouldError: true},
+		{name: "never - should fail", expiry: "never", shouldError: true},
+		{name: "garbage - should fail", expiry: "soon", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				App: AppConfig{
+					Approval: ApprovalConfig{Enabled: true, Expiry: tt.expiry},
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host: "0.0.0.0",
+					Port: 9709,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_SyncSchedules(t *testing.T) {
+	tests := []struct {
+		name        string
+		sync        SyncConfig
+		shouldError bool
+	}{
+		{name: "no cron - should pass", sync: SyncConfig{}, shouldError: false},
+		{name: "standard cron - should pass", sync: SyncConfig{FullCron: "0 3 * * *", IncrementalCron: "*/15 * * * *"}, shouldError: false},
+		{name: "descriptor - should pass", sync: SyncConfig{DeletionCron: "@daily"}, shouldError: false},
+		{name: "invalid full cron - should fail", sync: SyncConfig{FullCron: "every night"}, shouldError: true},
+		{name: "invalid deletion cron - should fail", sync: SyncConfig{DeletionCron: "0 25 * * *"}, shouldError: true},
+		{
+			name:        "daily blackout - should pass",
+			sync:        SyncConfig{DeletionBlackouts: []BlackoutWindow{{Start: "22:00", End: "06:00"}}},
+			shouldError: false,
+		},
+		{
+			name:        "weekly blackout - should pass",
+			sync:        SyncConfig{DeletionBlackouts: []BlackoutWindow{{Name: "weekend", Start: "fri 18:00", End: "sun 23:00"}}},
+			shouldError: false,
+		},
+		{
+			name:        "mixed blackout forms - should fail",
+			sync:        SyncConfig{DeletionBlackouts: []BlackoutWindow{{Start: "fri 18:00", End: "23:00"}}},
+			shouldError: true,
+		},
+		{
+			name:        "invalid blackout time - should fail",
+			sync:        SyncConfig{DeletionBlackouts: []BlackoutWindow{{Start: "24:00", End: "06:00"}}},
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				Sync: tt.sync,
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host: "0.0.0.0",
+					Port: 9709,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	tests := []struct {
+		name        string
+		tracing     TracingConfig
+		shouldError bool
+	}{
+		{name: "disabled ignores bad values", tracing: TracingConfig{Exporter: "zipkin", Endpoint: "nope"}, shouldError: false},
+		{name: "otlp default exporter", tracing: TracingConfig{Enabled: true}, shouldError: false},
+		{name: "otlp with endpoint", tracing: TracingConfig{Enabled: true, Exporter: "otlp", Endpoint: "http://otel-collector:4318"}, shouldError: false},
+		{name: "stdout", tracing: TracingConfig{Enabled: true, Exporter: "stdout"}, shouldError: false},
+		{name: "unknown exporter", tracing: TracingConfig{Enabled: true, Exporter: "zipkin"}, shouldError: true},
+		{name: "endpoint without scheme", tracing: TracingConfig{Enabled: true, Endpoint: "otel-collector:4318"}, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host:    "0.0.0.0",
+					Port:    9709,
+					Tracing: tt.tracing,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_TrustedProxies(t *testing.T) {
+	tests := []struct {
+		name        string
+		proxies     []string
+		shouldError bool
+	}{
+		{name: "none", proxies: nil, shouldError: false},
+		{name: "addresses and ranges", proxies: []string{"172.18.0.2", "10.0.0.0/8", "fd00::/8", "::1"}, shouldError: false},
+		{name: "hostname", proxies: []string{"traefik"}, shouldError: true},
+		{name: "bad range", proxies: []string{"10.0.0.0/33"}, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host:           "0.0.0.0",
+					Port:           9709,
+					TrustedProxies: tt.proxies,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_EpisodeWatchOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		rule            AdvancedRule
+		jellyfinEnabled bool
+		shouldError     bool
+	}{
+		{name: "watched_retention alone", rule: AdvancedRule{WatchedRetention: "30d"}, jellyfinEnabled: true, shouldError: false},
+		{name: "all options", rule: AdvancedRule{MaxEpisodes: 10, OnlyWatchedEpisodes: true, KeepNextNUnwatched: 2, WatchedRetention: "14d"}, jellyfinEnabled: true, shouldError: false},
+		{name: "invalid watched_retention", rule: AdvancedRule{WatchedRetention: "soon"}, jellyfinEnabled: true, shouldError: true},
+		{name: "negative keep_next_n_unwatched", rule: AdvancedRule{MaxEpisodes: 10, KeepNextNUnwatched: -1}, jellyfinEnabled: true, shouldError: true},
+		{name: "requires jellyfin", rule: AdvancedRule{MaxEpisodes: 10, OnlyWatchedEpisodes: true}, jellyfinEnabled: false, shouldError: true},
+		{name: "rolling alone", rule: AdvancedRule{EpisodeDeleteStrategy: "rolling"}, jellyfinEnabled: true, shouldError: false},
+		{name: "rolling per user with buffer", rule: AdvancedRule{EpisodeDeleteStrategy: "rolling", RollingScope: "user", RollingBuffer: 2}, jellyfinEnabled: true, shouldError: false},
+		{name: "unknown rolling scope", rule: AdvancedRule{EpisodeDeleteStrategy: "rolling", RollingScope: "family"}, jellyfinEnabled: true, shouldError: true},
+		{name: "rolling options without rolling", rule: AdvancedRule{MaxEpisodes: 10, RollingBuffer: 2}, jellyfinEnabled: true, shouldError: true},
+		{name: "rolling requires jellyfin", rule: AdvancedRule{EpisodeDeleteStrategy: "rolling"}, jellyfinEnabled: false, shouldError: true},
+		{name: "unmonitor past seasons", rule: AdvancedRule{MaxEpisodes: 10, UnmonitorDeleted: true, UnmonitorPastSeasons: true}, jellyfinEnabled: false, shouldError: false},
+		{name: "unmonitor past seasons alone", rule: AdvancedRule{MaxEpisodes: 10, UnmonitorPastSeasons: true}, jellyfinEnabled: false, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			rule.Name = "Episodes"
+			rule.Type = "episode"
+			rule.Enabled = true
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host: "0.0.0.0",
+					Port: 9709,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: tt.jellyfinEnabled,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+					Sonarr: SonarrConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://sonarr:8989",
+							APIKey:  "test-key",
+						},
+					},
+				},
+				AdvancedRules: []AdvancedRule{rule},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_JellyfinLogin(t *testing.T) {
+	tests := []struct {
+		name            string
+		login           JellyfinLoginConfig
+		jellyfinEnabled bool
+		shouldError     bool
+	}{
+		{name: "disabled - should pass", login: JellyfinLoginConfig{}, jellyfinEnabled: false, shouldError: false},
+		{name: "enabled with jellyfin - should pass", login: JellyfinLoginConfig{Enabled: true}, jellyfinEnabled: true, shouldError: false},
+		{name: "viewer role - should pass", login: JellyfinLoginConfig{Enabled: true, UserRole: "viewer"}, jellyfinEnabled: true, shouldError: false},
+		{name: "enabled without jellyfin - should fail", login: JellyfinLoginConfig{Enabled: true}, jellyfinEnabled: false, shouldError: true},
+		{name: "admin role - should fail", login: JellyfinLoginConfig{Enabled: true, UserRole: "admin"}, jellyfinEnabled: true, shouldError: true},
+		{name: "unknown role - should fail", login: JellyfinLoginConfig{Enabled: true, UserRole: "guest"}, jellyfinEnabled: true, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username:      "admin",
+					Password:      "pass",
+					JellyfinLogin: tt.login,
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host: "0.0.0.0",
+					Port: 9709,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: tt.jellyfinEnabled,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+					Radarr: RadarrConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://radarr:7878",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_OIDC(t *testing.T) {
+	valid := OIDCConfig{
+		Enabled:     true,
+		IssuerURL:   "https://auth.example.com",
+		ClientID:    "oxicleanarr",
+		RedirectURL: "https://oxicleanarr.example.com/api/auth/oidc/callback",
+		GroupRoles:  []OIDCGroupRole{{Group: "media-admins", Role: "admin"}},
+	}
+	with := func(mutate func(*OIDCConfig)) OIDCConfig {
+		oidc := valid
+		mutate(&oidc)
+		return oidc
+	}
+
+	tests := []struct {
+		name        string
+		oidc        OIDCConfig
+		shouldError bool
+	}{
+		{name: "disabled - should pass", oidc: OIDCConfig{}, shouldError: false},
+		{name: "valid - should pass", oidc: valid, shouldError: false},
+		{name: "default role only - should pass", oidc: with(func(o *OIDCConfig) { o.GroupRoles = nil; o.DefaultRole = "requester" }), shouldError: false},
+		{name: "missing issuer - should fail", oidc: with(func(o *OIDCConfig) { o.IssuerURL = "" }), shouldError: true},
+		{name: "relative redirect - should fail", oidc: with(func(o *OIDCConfig) { o.RedirectURL = "/api/auth/oidc/callback" }), shouldError: true},
+		{name: "missing client id - should fail", oidc: with(func(o *OIDCConfig) { o.ClientID = "" }), shouldError: true},
+		{name: "unknown group role - should fail", oidc: with(func(o *OIDCConfig) { o.GroupRoles = []OIDCGroupRole{{Group: "x", Role: "root"}} }), shouldError: true},
+		{name: "unknown default role - should fail", oidc: with(func(o *OIDCConfig) { o.DefaultRole = "guest" }), shouldError: true},
+		{name: "no mapping and no default - should fail", oidc: with(func(o *OIDCConfig) { o.GroupRoles = nil }), shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateOIDC(tt.oidc)
+			if tt.shouldError && len(errs) == 0 {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && len(errs) > 0 {
+				t.Errorf("unexpected validation error: %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidate_Audit(t *testing.T) {
+	tests := []struct {
+		name        string
+		audit       AuditConfig
+		shouldError bool
+	}{
+		{name: "defaults", audit: AuditConfig{}, shouldError: false},
+		{name: "days and entries", audit: AuditConfig{Retention: "30d", MaxEntries: 500}, shouldError: false},
+		{name: "zero retention", audit: AuditConfig{Retention: "0d"}, shouldError: true},
+		{name: "bad retention", audit: AuditConfig{Retention: "forever"}, shouldError: true},
+		{name: "negative entries", audit: AuditConfig{MaxEntries: -1}, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username: "admin",
+					Password: "pass",
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host:  "0.0.0.0",
+					Port:  9709,
+					Audit: tt.audit,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_SignInSecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		twoFactor   TwoFactorConfig
+		throttle    LoginThrottleConfig
+		sessions    SessionsConfig
+		shouldError bool
+	}{
+		{name: "defaults", shouldError: false},
+		{name: "required roles", twoFactor: TwoFactorConfig{RequiredRoles: []string{"admin", "operator"}}, shouldError: false},
+		{name: "unknown role", twoFactor: TwoFactorConfig{RequiredRoles: []string{"root"}}, shouldError: true},
+		{name: "custom throttle", throttle: LoginThrottleConfig{MaxFailures: 10, Window: "1h", Lockout: "30m"}, shouldError: false},
+		{name: "negative failures", throttle: LoginThrottleConfig{MaxFailures: -1}, shouldError: true},
+		{name: "bad window", throttle: LoginThrottleConfig{Window: "soon"}, shouldError: true},
+		{name: "zero lockout", throttle: LoginThrottleConfig{Lockout: "0m"}, shouldError: true},
+		{name: "access token ttl", sessions: SessionsConfig{AccessTokenTTL: "5m"}, shouldError: false},
+		{name: "bad access token ttl", sessions: SessionsConfig{AccessTokenTTL: "-5m"}, shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Admin: AdminConfig{
+					Username:      "admin",
+					Password:      "pass",
+					TwoFactor:     tt.twoFactor,
+					LoginThrottle: tt.throttle,
+					Sessions:      tt.sessions,
+				},
+				Rules: RulesConfig{
+					MovieRetention: "90d",
+					TVRetention:    "120d",
+				},
+				Server: ServerConfig{
+					Host: "0.0.0.0",
+					Port: 9709,
+				},
+				Integrations: IntegrationsConfig{
+					Jellyfin: JellyfinConfig{
+						BaseIntegrationConfig: BaseIntegrationConfig{
+							Enabled: true,
+							URL:     "http://jellyfin:8096",
+							APIKey:  "test-key",
+						},
+					},
+				},
+			}
+
+			err := Validate(cfg)
+			if tt.shouldError && err == nil {
+				t.Errorf("expected validation error but got none")
+			}
+			if !tt.shouldError && err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	jellyfin := JellyfinConfig{BaseIntegrationConfig: BaseIntegrationConfig{Enabled: true, URL: "http://jellyfin:8096", APIKey: "test-key"}}
+	tests := []struct {
+		name   string
+		cfg    Config
+		fields []string
+	}{
+		{
+			name: "no warnings",
+			cfg:  Config{Integrations: IntegrationsConfig{Jellyfin: jellyfin}, Rules: RulesConfig{RetentionBase: "last_watched"}},
+		},
+		{
+			name:   "auth disabled",
+			cfg:    Config{Admin: AdminConfig{DisableAuth: true}, Integrations: IntegrationsConfig{Jellyfin: jellyfin}},
+			fields: []string{"admin.disable_auth"},
+		},
+		{
+			name: "rules need disabled integrations",
+			cfg: Config{
+				Integrations: IntegrationsConfig{Jellyseerr: JellyseerrConfig{BaseIntegrationConfig: BaseIntegrationConfig{Enabled: false}}},
+				Rules:        RulesConfig{RetentionBase: "last_watched"},
+				AdvancedRules: []AdvancedRule{
+					{Name: "requested", Type: "user", Enabled: true},
+					{Name: "kids", Type: "tag", Enabled: true},
+					{Name: "off", Type: "user", Enabled: false},
+				},
+			},
+			fields: []string{"rules.retention_base", "advanced_rules[0]", "advanced_rules[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, warning := range Warnings(&tt.cfg) {
+				fields = append(fields, warning.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("expected warnings for %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
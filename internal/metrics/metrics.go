@@ -0,0 +1,117 @@
+// Package metrics defines the Prometheus metrics served on GET /metrics.
+//
+// Counters and histograms are updated where the work happens. Gauges that
+// describe current state (library size, schedule, disk) are computed at scrape
+// time by collectors registered in main.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "oxicleanarr"
+
+// Registry holds all OxiCleanarr metrics plus the Go runtime and process
+// collectors. A dedicated registry keeps tests free of global state.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// ReclaimedBytes counts disk space freed by whole-item deletions
+	ReclaimedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reclaimed_bytes_total",
+		Help:      "Bytes freed by deleted media items.",
+	}, []string{"type"})
+
+	// Deletions counts deletion job outcomes per item
+	Deletions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deletions_total",
+		Help:      "Items processed by deletion jobs, by scheduling rule and outcome.",
+	}, []string{"rule", "outcome"})
+
+	// JobDuration observes how long full syncs and deletion jobs take
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of finished jobs, by job type and final status.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600},
+	}, []string{"type", "status"})
+
+	// SyncPhaseDuration observes how long each job phase takes
+	SyncPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_phase_duration_seconds",
+		Help:      "Duration of job phases (radarr, sonarr, jellyfin, stats, jellyseerr, rules, deletions).",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"phase", "status"})
+
+	// UpstreamRequests counts HTTP requests to integrations. code is the HTTP
+	// status, or "error" when no response was received.
+	UpstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "HTTP requests made to upstream integrations.",
+	}, []string{"client", "method", "code"})
+
+	// UpstreamRequestDuration observes upstream request latency
+	UpstreamRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of HTTP requests to upstream integrations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"client", "method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ReclaimedBytes,
+		Deletions,
+		JobDuration,
+		SyncPhaseDuration,
+		UpstreamRequests,
+		UpstreamRequestDuration,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// instrumentedTransport records upstream request counts and latencies
+type instrumentedTransport struct {
+	client string
+	next   http.RoundTripper
+}
+
+// InstrumentTransport wraps next so every request is recorded under the
+// given client label (radarr, sonarr, jellyfin, ...). A nil next uses
+// http.DefaultTransport.
+func InstrumentTransport(client string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{client: client, next: next}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	UpstreamRequestDuration.WithLabelValues(t.client, req.Method).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	UpstreamRequests.WithLabelValues(t.client, req.Method, code).Inc()
+	return resp, err
+}
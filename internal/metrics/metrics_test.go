@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	client := &http.Client{Transport: InstrumentTransport("test-client", nil)}
+
+	for _, path := range []string{"/ok", "/ok", "/missing"} {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// A closed server yields a transport error
+	srv.Close()
+	_, err := client.Get(srv.URL + "/ok")
+	require.Error(t, err)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(UpstreamRequests.WithLabelValues("test-client", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(UpstreamRequests.WithLabelValues("test-client", "GET", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(UpstreamRequests.WithLabelValues("test-client", "GET", "error")))
+}
+
+func TestHandler_ServesTextFormat(t *testing.T) {
+	Deletions.WithLabelValues("standard_retention", "deleted").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.True(t, strings.Contains(body, `oxicleanarr_deletions_total{outcome="deleted",rule="standard_retention"}`))
+	assert.True(t, strings.Contains(body, "go_goroutines"))
+}
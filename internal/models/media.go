@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -44,6 +45,14 @@ type Media struct {
 	// Empty = whole-item deletion (standard behavior).
 	EpisodeFileIDs []int `json:"episode_file_ids,omitempty"`
 
+	// SeriesStatus is Sonarr's status for TV shows ("continuing", "ended", "upcoming")
+	SeriesStatus string `json:"series_status,omitempty"`
+
+	// Episodes is the show's episode snapshot from the last full sync, which
+	// episode rules evaluate instead of querying Sonarr. Nil when it was not
+	// fetched (no episode rule matches the show, or the fetch failed).
+	Episodes []Episode `json:"-"`
+
 	// Source system IDs
 	JellyfinID string `json:"jellyfin_id,omitempty"`
 	RadarrID   int    `json:"radarr_id,omitempty"`
@@ -59,6 +68,35 @@ type Media struct {
 	JellyfinMismatchInfo string `json:"jellyfin_mismatch_info,omitempty"` // Details about the mismatch
 }
 
+// Episode is a TV episode as captured from Sonarr during a full sync, with
+// watch data from Jellyfin and the stats provider when those were fetched
+type Episode struct {
+	ID            int       `json:"id"`
+	EpisodeFileID int       `json:"episode_file_id,omitempty"`
+	SeasonNumber  int       `json:"season_number"`
+	EpisodeNumber int       `json:"episode_number"`
+	AirDate       time.Time `json:"air_date,omitempty"` // zero when Sonarr has no air date
+	HasFile       bool      `json:"has_file"`
+	Monitored     bool      `json:"monitored"`
+
+	JellyfinID  string    `json:"jellyfin_id,omitempty"`
+	Played      bool      `json:"played,omitempty"`
+	WatchCount  int       `json:"watch_count,omitempty"`
+	LastWatched time.Time `json:"last_watched,omitempty"`
+	WatchedBy   []string  `json:"watched_by,omitempty"` // Jellyfin user IDs that played the episode
+}
+
+// Watched reports whether the episode has been played or has watch history
+func (ep Episode) Watched() bool {
+	return ep.Played || ep.WatchCount > 0 || !ep.LastWatched.IsZero() || len(ep.WatchedBy) > 0
+}
+
+// IsRequestedBy reports whether the item was requested (via Jellyseerr) by
+// the given username, compared case-insensitively
+func (m Media) IsRequestedBy(username string) bool {
+	return username != "" && m.RequestedByUsername != nil && strings.EqualFold(*m.RequestedByUsername, username)
+}
+
 // MediaList represents a list of media items with metadata
 type MediaList struct {
 	Items      []Media `json:"items"`
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrAPIKeysUnavailable is returned when no API key store is configured.
+	ErrAPIKeysUnavailable = errors.New("API key storage is not configured")
+	// ErrAPIKeyNotFound is returned when no key has the requested ID.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrAPIKeyName is returned for an empty key name.
+	ErrAPIKeyName = errors.New("API key name must not be empty")
+	// ErrAPIKeyScopes is returned when a key has no scopes or an unknown one.
+	ErrAPIKeyScopes = errors.New("scopes must be one or more of leaving-soon:read, media:read, sync:trigger, deletions:execute, config:write")
+	// ErrAPIKeyExpiry is returned for an expiry that is not in the future.
+	ErrAPIKeyExpiry = errors.New("expires_at must be in the future")
+)
+
+const (
+	// apiKeySecretPrefix marks OxiCleanarr API keys, e.g. in secret scanners
+	apiKeySecretPrefix = "oxi_"
+	// apiKeyDisplayPrefixLen is how much of the secret is kept for display
+	apiKeyDisplayPrefixLen = len(apiKeySecretPrefix) + 6
+)
+
+// SetAPIKeys attaches the API key store. Without one, only the static
+// admin.api_key is accepted.
+func (s *AuthService) SetAPIKeys(keys *storage.APIKeysFile) {
+	s.apiKeys = keys
+}
+
+// CreateAPIKey creates a named key with the given scopes and optional expiry.
+// Returns the stored key and the secret, which is not retrievable later.
+func (s *AuthService) CreateAPIKey(name string, scopes []string, expiresAt *time.Time, createdBy string) (storage.APIKey, string, error) {
+	if s.apiKeys == nil {
+		return storage.APIKey{}, "", ErrAPIKeysUnavailable
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return storage.APIKey{}, "", ErrAPIKeyName
+	}
+	if len(scopes) == 0 {
+		return storage.APIKey{}, "", ErrAPIKeyScopes
+	}
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !utils.IsValidScope(scope) {
+			return storage.APIKey{}, "", ErrAPIKeyScopes
+		}
+		if !utils.HasAnyScope(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return storage.APIKey{}, "", ErrAPIKeyExpiry
+	}
+
+	random, err := randomToken()
+	if err != nil {
+		return storage.APIKey{}, "", err
+	}
+	secret := apiKeySecretPrefix + random
+
+	key := storage.APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    secret[:apiKeyDisplayPrefixLen],
+		Hash:      hashAPIKey(secret),
+		Scopes:    unique,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeys.Put(key); err != nil {
+		return storage.APIKey{}, "", fmt.Errorf("storing API key: %w", err)
+	}
+
+	log.Info().
+		Str("key_id", key.ID).
+		Str("name", key.Name).
+		Strs("scopes", key.Scopes).
+		Str("created_by", createdBy).
+		Msg("API key created")
+	return key, secret, nil
+}
+
+// ListAPIKeys returns all keys, oldest first
+func (s *AuthService) ListAPIKeys() ([]storage.APIKey, error) {
+	if s.apiKeys == nil {
+		return nil, ErrAPIKeysUnavailable
+	}
+	return s.apiKeys.GetAll(), nil
+}
+
+// RevokeAPIKey deletes a key; requests using it are rejected immediately
+func (s *AuthService) RevokeAPIKey(id string) error {
+	if s.apiKeys == nil {
+		return ErrAPIKeysUnavailable
+	}
+
+	key, _ := s.apiKeys.Get(id)
+	removed, err := s.apiKeys.Remove(id)
+	if err != nil {
+		return fmt.Errorf("removing API key: %w", err)
+	}
+	if !removed {
+		return ErrAPIKeyNotFound
+	}
+
+	log.Info().Str("key_id", id).Str("name", key.Name).Msg("API key revoked")
+	return nil
+}
+
+// AuthenticateAPIKey looks up a named key by its secret and records its use.
+// Expired and unknown keys are rejected.
+func (s *AuthService) AuthenticateAPIKey(secret string) (storage.APIKey, bool) {
+	if s.apiKeys == nil || !strings.HasPrefix(secret, apiKeySecretPrefix) {
+		return storage.APIKey{}, false
+	}
+
+	key, ok := s.apiKeys.GetByHash(hashAPIKey(secret))
+	if !ok {
+		return storage.APIKey{}, false
+	}
+	now := time.Now()
+	if key.Expired(now) {
+		log.Debug().Str("key_id", key.ID).Str("name", key.Name).Msg("Rejected expired API key")
+		return storage.APIKey{}, false
+	}
+
+	if err := s.apiKeys.MarkUsed(key.ID, now); err != nil {
+		log.Warn().Err(err).Str("key_id", key.ID).Msg("Failed to record API key use")
+	}
+	return key, true
+}
+
+// hashAPIKey returns the hex SHA-256 of a key secret. Secrets are 256-bit
+// random values, so a fast hash is enough to make the stored form useless.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
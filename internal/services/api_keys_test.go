@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+func setupAuthServiceWithAPIKeys(t *testing.T) (*AuthService, *storage.APIKeysFile) {
+	t.Helper()
+	svc := setupAuthService(t, "adminpassword")
+	keys, err := storage.NewAPIKeysFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewAPIKeysFile failed: %v", err)
+	}
+	svc.SetAPIKeys(keys)
+	return svc, keys
+}
+
+func TestAPIKeys_CreateAuthenticateRevoke(t *testing.T) {
+	svc, keys := setupAuthServiceWithAPIKeys(t)
+
+	key, secret, err := svc.CreateAPIKey(" plugin ", []string{utils.ScopeLeavingSoonRead, utils.ScopeLeavingSoonRead}, nil, "admin")
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if key.Name != "plugin" || len(key.Scopes) != 1 || key.CreatedBy != "admin" {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+	if !strings.HasPrefix(secret, "oxi_") || !strings.HasPrefix(secret, key.Prefix) {
+		t.Errorf("Expected an oxi_ secret starting with the display prefix, got %q / %q", secret, key.Prefix)
+	}
+	if strings.Contains(key.Hash, secret) || key.Hash == "" {
+		t.Error("Expected only a hash of the secret to be stored")
+	}
+
+	authenticated, ok := svc.AuthenticateAPIKey(secret)
+	if !ok || authenticated.ID != key.ID {
+		t.Fatalf("Expected the secret to authenticate")
+	}
+	stored, _ := keys.Get(key.ID)
+	if stored.LastUsedAt == nil {
+		t.Error("Expected last use to be recorded")
+	}
+	if _, ok := svc.AuthenticateAPIKey(secret + "x"); ok {
+		t.Error("Expected a wrong secret to be rejected")
+	}
+
+	if err := svc.RevokeAPIKey(key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if _, ok := svc.AuthenticateAPIKey(secret); ok {
+		t.Error("Expected a revoked key to be rejected")
+	}
+	if err := svc.RevokeAPIKey(key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+	}
+}
+
+func TestAPIKeys_Expiry(t *testing.T) {
+	svc, keys := setupAuthServiceWithAPIKeys(t)
+
+	past := time.Now().Add(-time.Minute)
+	if _, _, err := svc.CreateAPIKey("old", []string{utils.ScopeMediaRead}, &past, "admin"); !errors.Is(err, ErrAPIKeyExpiry) {
+		t.Errorf("Expected ErrAPIKeyExpiry, got %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	key, secret, err := svc.CreateAPIKey("temp", []string{utils.ScopeMediaRead}, &future, "admin")
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if _, ok := svc.AuthenticateAPIKey(secret); !ok {
+		t.Fatal("Expected an unexpired key to authenticate")
+	}
+
+	// Let the key expire
+	key.ExpiresAt = &past
+	if err := keys.Put(key); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := svc.AuthenticateAPIKey(secret); ok {
+		t.Error("Expected an expired key to be rejected")
+	}
+}
+
+func TestAPIKeys_Validation(t *testing.T) {
+	svc, _ := setupAuthServiceWithAPIKeys(t)
+
+	if _, _, err := svc.CreateAPIKey("", []string{utils.ScopeMediaRead}, nil, "admin"); !errors.Is(err, ErrAPIKeyName) {
+		t.Errorf("Expected ErrAPIKeyName, got %v", err)
+	}
+	if _, _, err := svc.CreateAPIKey("k", nil, nil, "admin"); !errors.Is(err, ErrAPIKeyScopes) {
+		t.Errorf("Expected ErrAPIKeyScopes for no scopes, got %v", err)
+	}
+	if _, _, err := svc.CreateAPIKey("k", []string{"admin:*"}, nil, "admin"); !errors.Is(err, ErrAPIKeyScopes) {
+		t.Errorf("Expected ErrAPIKeyScopes for an unknown scope, got %v", err)
+	}
+
+	unconfigured := setupAuthService(t, "adminpassword")
+	if _, err := unconfigured.ListAPIKeys(); !errors.Is(err, ErrAPIKeysUnavailable) {
+		t.Errorf("Expected ErrAPIKeysUnavailable, got %v", err)
+	}
+	if _, ok := unconfigured.AuthenticateAPIKey("oxi_anything"); ok {
+		t.Error("Expected no key to authenticate without a store")
+	}
+}
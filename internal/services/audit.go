@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Defaults used when server.audit.retention or max_entries is unset or invalid
+const (
+	defaultAuditRetention  = 90 * 24 * time.Hour
+	defaultAuditMaxEntries = 10000
+)
+
+// AuditLog records mutating actions for GET /api/audit. Its methods are safe
+// to call on a nil *AuditLog, which records nothing.
+type AuditLog struct {
+	file *storage.AuditLogFile
+}
+
+// NewAuditLog creates an audit log backed by file
+// Entries past the configured limits are dropped right away, so those left in
+// the file since the last compaction do not reappear.
+func NewAuditLog(file *storage.AuditLogFile) *AuditLog {
+	if file != nil {
+		retention, maxEntries := auditLimits()
+		if err := file.Prune(time.Now().Add(-retention), maxEntries); err != nil {
+			log.Warn().Err(err).Msg("Failed to prune audit log")
+		}
+	}
+	return &AuditLog{file: file}
+}
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	Actor   string    // exact username or "api_key:<name>"
+	Action  string    // exact action, or a prefix such as "rule" for "rule.*"
+	Target  string    // case-insensitive substring of the target
+	Outcome string    // "success" or "failure"
+	Since   time.Time // inclusive
+	Until   time.Time // exclusive
+}
+
+// Record stores entry, filling in its ID and timestamp, with the changes
+// between before and after (either may be nil for creations and deletions).
+// Failures are logged, not returned: an action that already happened must not
+// be reported as failed because auditing it did.
+func (a *AuditLog) Record(entry storage.AuditEntry, before, after any) {
+	if a == nil || a.file == nil {
+		return
+	}
+
+	entry.ID = uuid.New().String()
+	entry.Timestamp = time.Now()
+	if entry.Outcome == "" {
+		entry.Outcome = storage.AuditOutcomeSuccess
+	}
+	changes, err := AuditDiff(before, after)
+	if err != nil {
+		log.Warn().Err(err).Str("action", entry.Action).Msg("Failed to compute audit diff")
+	}
+	entry.Changes = changes
+
+	retention, maxEntries := auditLimits()
+	if err := a.file.Add(entry, entry.Timestamp.Add(-retention), maxEntries); err != nil {
+		log.Error().Err(err).Str("action", entry.Action).Str("actor", entry.Actor).Msg("Failed to write audit log entry")
+	}
+}
+
+// Query returns the entries matching filter, most recent first
+func (a *AuditLog) Query(filter AuditFilter) []storage.AuditEntry {
+	if a == nil || a.file == nil {
+		return []storage.AuditEntry{}
+	}
+
+	target := strings.ToLower(filter.Target)
+	entries := make([]storage.AuditEntry, 0)
+	for _, entry := range a.file.GetAll() {
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action && !strings.HasPrefix(entry.Action, filter.Action+".") {
+			continue
+		}
+		if target != "" && !strings.Contains(strings.ToLower(entry.Target), target) {
+			continue
+		}
+		if filter.Outcome != "" && entry.Outcome != filter.Outcome {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !entry.Timestamp.Before(filter.Until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// auditLimits returns the configured retention and entry cap
+func auditLimits() (time.Duration, int) {
+	retention, maxEntries := defaultAuditRetention, defaultAuditMaxEntries
+	if cfg := config.Get(); cfg != nil {
+		if d, err := rules.ParseDuration(cfg.Server.Audit.Retention); err == nil && d > 0 {
+			retention = d
+		}
+		if cfg.Server.Audit.MaxEntries > 0 {
+			maxEntries = cfg.Server.Audit.MaxEntries
+		}
+	}
+	return retention, maxEntries
+}
+
+// AuditDiff returns the fields that differ between the JSON forms of before
+// and after, sorted by path. Nested objects are flattened to dotted paths and
+// array elements to [i]; empty objects and arrays contribute no fields.
+func AuditDiff(before, after any) ([]storage.AuditChange, error) {
+	beforeFields, err := flattenForAudit(before)
+	if err != nil {
+		return nil, fmt.Errorf("flattening before: %w", err)
+	}
+	afterFields, err := flattenForAudit(after)
+	if err != nil {
+		return nil, fmt.Errorf("flattening after: %w", err)
+	}
+
+	paths := make([]string, 0, len(beforeFields)+len(afterFields))
+	for path := range beforeFields {
+		paths = append(paths, path)
+	}
+	for path := range afterFields {
+		if _, seen := beforeFields[path]; !seen {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []storage.AuditChange
+	for _, path := range paths {
+		oldValue, hadOld := beforeFields[path]
+		newValue, hasNew := afterFields[path]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, storage.AuditChange{Field: path, Before: oldValue, After: newValue})
+	}
+	return changes, nil
+}
+
+// flattenForAudit maps each leaf of v's JSON form to its path
+func flattenForAudit(v any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch typed := value.(type) {
+		case map[string]any:
+			for key, child := range typed {
+				if prefix == "" {
+					walk(key, child)
+				} else {
+					walk(prefix+"."+key, child)
+				}
+			}
+		case []any:
+			for i, child := range typed {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		case nil:
+		default:
+			if prefix == "" {
+				prefix = "value"
+			}
+			fields[prefix] = typed
+		}
+	}
+	walk("", generic)
+	return fields, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDiff(t *testing.T) {
+	type rule struct {
+		Name    string   `json:"name"`
+		Enabled bool     `json:"enabled"`
+		Tags    []string `json:"tags,omitempty"`
+	}
+
+	t.Run("reports changed, added and removed fields", func(t *testing.T) {
+		changes, err := AuditDiff(
+			rule{Name: "old", Enabled: true, Tags: []string{"a", "b"}},
+			rule{Name: "old", Enabled: false, Tags: []string{"a"}},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []storage.AuditChange{
+			{Field: "enabled", Before: true, After: false},
+			{Field: "tags[1]", Before: "b"},
+		}, changes)
+	})
+
+	t.Run("creation lists every field", func(t *testing.T) {
+		var before *rule
+		changes, err := AuditDiff(before, rule{Name: "new"})
+		require.NoError(t, err)
+		assert.Equal(t, []storage.AuditChange{
+			{Field: "enabled", After: false},
+			{Field: "name", After: "new"},
+		}, changes)
+	})
+
+	t.Run("identical values have no changes", func(t *testing.T) {
+		changes, err := AuditDiff(rule{Name: "x"}, rule{Name: "x"})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	defer config.SetTestConfig(nil)
+	config.SetTestConfig(&config.Config{Server: config.ServerConfig{Audit: config.AuditConfig{MaxEntries: 3}}})
+
+	file, err := storage.NewAuditLogFile(t.TempDir())
+	require.NoError(t, err)
+	audit := NewAuditLog(file)
+
+	audit.Record(storage.AuditEntry{Actor: "alice", Action: "rule.create", Target: "Old Movies"}, nil, map[string]bool{"enabled": true})
+	audit.Record(storage.AuditEntry{Actor: "api_key:plugin", Action: "deletions.execute"}, nil, nil)
+	audit.Record(storage.AuditEntry{Actor: "bob", Action: "auth.login", Target: "bob", Outcome: storage.AuditOutcomeFailure}, nil, nil)
+
+	t.Run("fills in ID, timestamp, outcome and changes", func(t *testing.T) {
+		entries := audit.Query(AuditFilter{Action: "rule.create"})
+		require.Len(t, entries, 1)
+		assert.NotEmpty(t, entries[0].ID)
+		assert.False(t, entries[0].Timestamp.IsZero())
+		assert.Equal(t, storage.AuditOutcomeSuccess, entries[0].Outcome)
+		assert.Equal(t, []storage.AuditChange{{Field: "enabled", After: true}}, entries[0].Changes)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Len(t, audit.Query(AuditFilter{}), 3)
+		assert.Len(t, audit.Query(AuditFilter{Action: "rule"}), 1)
+		assert.Len(t, audit.Query(AuditFilter{Action: "rul"}), 0)
+		assert.Len(t, audit.Query(AuditFilter{Actor: "api_key:plugin"}), 1)
+		assert.Len(t, audit.Query(AuditFilter{Target: "old movies"}), 1)
+		assert.Len(t, audit.Query(AuditFilter{Outcome: storage.AuditOutcomeFailure}), 1)
+		assert.Len(t, audit.Query(AuditFilter{Since: time.Now().Add(time.Minute)}), 0)
+		assert.Len(t, audit.Query(AuditFilter{Until: time.Now().Add(time.Minute)}), 3)
+	})
+
+	t.Run("keeps at most max_entries", func(t *testing.T) {
+		audit.Record(storage.AuditEntry{Actor: "alice", Action: "system.restart"}, nil, nil)
+		entries := audit.Query(AuditFilter{})
+		require.Len(t, entries, 3)
+		assert.Equal(t, "system.restart", entries[0].Action)
+	})
+
+	t.Run("nil audit log records nothing", func(t *testing.T) {
+		var nilAudit *AuditLog
+		nilAudit.Record(storage.AuditEntry{Action: "x"}, nil, nil)
+		assert.Empty(t, nilAudit.Query(AuditFilter{}))
+	})
+}
@@ -1,20 +1,87 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid username or password")
 	ErrEmptyPassword      = errors.New("password must not be empty")
+	ErrEmptyUsername      = errors.New("username must not be empty")
+	ErrInvalidRole        = errors.New("role must be one of admin, operator, viewer, requester")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrLastAdmin          = errors.New("at least one admin user is required")
+	ErrUsersUnavailable   = errors.New("user store is not configured")
+	// ErrLoginProviderUnavailable is returned when an external login provider
+	// (Jellyfin) could not be reached to check the credentials.
+	ErrLoginProviderUnavailable = errors.New("login provider is unavailable")
+
+	// errUnknownUser marks credentials for an account that does not exist
+	// locally, so Login can try the external providers.
+	errUnknownUser = errors.New("unknown user")
 )
 
 // AuthService handles authentication operations
 type AuthService struct {
-	cfg *config.Config
+	cfg     *config.Config
+	users   *storage.UsersFile
+	apiKeys *storage.APIKeysFile
+
+	// sessions holds server-side sign-ins; sessionsMu serializes refresh
+	// token rotation
+	sessions   *storage.SessionsFile
+	sessionsMu sync.Mutex
+
+	// usersMu serializes user mutations so the last-admin check and the
+	// write it guards cannot interleave with another request.
+	usersMu sync.Mutex
+
+	// oidcMu guards the single sign-on state below
+	oidcMu        sync.Mutex
+	oidcPending   map[string]oidcPendingLogin
+	oidcClient    *clients.OIDCClient
+	oidcClientKey string
+
+	// throttle counts failed sign-ins per username and client IP
+	throttle loginThrottle
+
+	// twoFactorMu guards sign-ins waiting for their second factor
+	twoFactorMu      sync.Mutex
+	twoFactorPending map[string]pendingTwoFactorLogin
+}
+
+// LoginResult is the outcome of a sign-in that passed the password check.
+// Either Token is set, or the account needs a second factor: Challenge is
+// then passed to CompleteTwoFactorLogin with a code, and Enrollment is set
+// when the account has to enroll first.
+type LoginResult struct {
+	Token      string
+	Username   string
+	Role       string
+	Challenge  string
+	Enrollment *TOTPEnrollment
+	// RecoveryCodes is set when the second step completed an enrollment
+	RecoveryCodes []string
+
+	// ExpiresAt is when Token expires
+	ExpiresAt time.Time
+	// SessionID, RefreshToken and RefreshExpiresAt are set when a session
+	// store is configured
+	SessionID        string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
 
 // NewAuthService creates a new AuthService
@@ -24,33 +91,216 @@ func NewAuthService(cfg *config.Config) *AuthService {
 	}
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *AuthService) Login(username, password string) (string, error) {
-	// Check username
-	if username != s.cfg.Admin.Username {
-		return "", ErrInvalidCredentials
+// SetUsers attaches the user store. Without one, only the admin account from
+// the config file can sign in.
+func (s *AuthService) SetUsers(users *storage.UsersFile) {
+	s.users = users
+}
+
+// MigrateAdmin turns the admin account from the config file into the first
+// admin user. It only runs while the user store is empty, so accounts managed
+// through the API are never overwritten. Legacy plaintext passwords are
+// hashed on the way in. Returns true when a user was created.
+func (s *AuthService) MigrateAdmin() (bool, error) {
+	if s.users == nil || s.users.Count() > 0 || s.cfg.Admin.Username == "" || s.cfg.Admin.Password == "" {
+		return false, nil
+	}
+
+	hash := s.cfg.Admin.Password
+	if !utils.IsBcryptHash(hash) {
+		var err error
+		if hash, err = utils.HashPassword(hash); err != nil {
+			return false, err
+		}
+	}
+
+	now := time.Now()
+	if err := s.users.Put(storage.User{
+		Username:     s.cfg.Admin.Username,
+		PasswordHash: hash,
+		Role:         utils.RoleAdmin,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}); err != nil {
+		return false, err
+	}
+
+	log.Info().Str("username", s.cfg.Admin.Username).Msg("Migrated config admin to the user store")
+	return true, nil
+}
+
+// Login authenticates a user coming from client. Local
+// accounts are checked first; a username without a local account is tried
+// against Jellyfin when admin.jellyfin_login is enabled. Local accounts with
+// two-factor authentication enabled (or required for their role) get a
+// challenge instead of a token. Repeated failures for the same username or
+// IP return a *LoginLockedError until the lockout has passed.
+func (s *AuthService) Login(ctx context.Context, username, password string, client ClientInfo) (LoginResult, error) {
+	limits := newLoginThrottleLimits(s.currentConfig().Admin.LoginThrottle)
+	keys := loginThrottleKeys(username, client.IP)
+	if err := s.throttle.check(limits, time.Now(), keys...); err != nil {
+		log.Warn().Str("username", username).Str("ip", client.IP).Msg("Sign-in refused: locked out after failed attempts")
+		return LoginResult{}, err
+	}
+
+	name, role, err := s.authenticateLocal(username, password)
+	local := err == nil
+	method := loginMethodPassword
+	if local {
+		username = name
+	}
+	if errors.Is(err, errUnknownUser) {
+		method = loginMethodJellyfin
+		username, role, err = s.authenticateJellyfin(ctx, username, password)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			s.throttle.fail(limits, time.Now(), keys...)
+		}
+		return LoginResult{}, err
+	}
+
+	if local && s.hasUsers() {
+		if user, ok := s.users.Get(username); ok && (user.TOTPEnabled() || s.twoFactorRequired(user.Role)) {
+			return s.startTwoFactorLogin(user)
+		}
+	}
+
+	s.throttle.reset(keys[0])
+	return s.issueToken(username, role, method, client)
+}
+
+// issueToken returns a completed sign-in. With a session store this starts
+// a session; otherwise the result is a single JWT valid for JWT_EXPIRATION.
+func (s *AuthService) issueToken(username, role, method string, client ClientInfo) (LoginResult, error) {
+	if s.sessions != nil {
+		return s.startSession(username, role, method, client)
+	}
+
+	token, err := utils.GenerateToken(username, role)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return LoginResult{
+		Token:     token,
+		Username:  username,
+		Role:      role,
+		ExpiresAt: time.Now().Add(utils.GetJWTExpiry()),
+	}, nil
+}
+
+// authenticateLocal checks the credentials against the user store (or the
+// config admin before the store exists) and returns the account's stored
+// username and role. Usernames match case-insensitively. Returns
+// errUnknownUser when there is no such local account.
+func (s *AuthService) authenticateLocal(username, password string) (string, string, error) {
+	if s.hasUsers() {
+		user, ok := s.findUser(username)
+		if !ok {
+			return "", "", errUnknownUser
+		}
+		if !utils.CheckPassword(user.PasswordHash, password) {
+			return "", "", ErrInvalidCredentials
+		}
+		return user.Username, user.Role, nil
+	}
+
+	// No user store yet: fall back to the config admin
+	if !strings.EqualFold(username, s.cfg.Admin.Username) {
+		return "", "", errUnknownUser
 	}
 
 	// Check password (bcrypt hash or legacy plaintext)
 	if !utils.CheckPassword(s.cfg.Admin.Password, password) {
-		return "", ErrInvalidCredentials
+		return "", "", ErrInvalidCredentials
 	}
+	return s.cfg.Admin.Username, utils.RoleAdmin, nil
+}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(username)
+// findUser returns the local account named username. An exact match wins;
+// otherwise names are compared case-insensitively.
+func (s *AuthService) findUser(username string) (storage.User, bool) {
+	if user, ok := s.users.Get(username); ok {
+		return user, true
+	}
+	for _, user := range s.users.GetAll() {
+		if strings.EqualFold(user.Username, username) {
+			return user, true
+		}
+	}
+	return storage.User{}, false
+}
+
+// isLocalName reports whether username names a local account (or the config
+// admin before the user store exists), ignoring case. External sign-ins with
+// such a name are refused so they cannot act as the local account.
+func (s *AuthService) isLocalName(username string) bool {
+	if s.hasUsers() {
+		_, ok := s.findUser(username)
+		return ok
+	}
+	return strings.EqualFold(username, s.cfg.Admin.Username)
+}
+
+// authenticateJellyfin checks the credentials against the configured Jellyfin
+// server and returns the canonical Jellyfin username and the mapped role:
+// Jellyfin administrators are admins, everyone else gets
+// admin.jellyfin_login.user_role (requester by default). Jellyfin accounts
+// whose name matches a local account are refused.
+func (s *AuthService) authenticateJellyfin(ctx context.Context, username, password string) (string, string, error) {
+	cfg := s.currentConfig()
+	if !cfg.Admin.JellyfinLogin.Enabled || !cfg.Integrations.Jellyfin.Enabled {
+		return "", "", ErrInvalidCredentials
+	}
+	// Jellyfin allows accounts without a password; never let those in here.
+	if username == "" || password == "" {
+		return "", "", ErrInvalidCredentials
+	}
+
+	client := clients.NewJellyfinClient(cfg.Integrations.Jellyfin)
+	result, err := client.AuthenticateByName(ctx, username, password)
 	if err != nil {
-		return "", err
+		if errors.Is(err, clients.ErrJellyfinUnauthorized) {
+			return "", "", ErrInvalidCredentials
+		}
+		log.Warn().Err(err).Str("username", username).Msg("Jellyfin login check failed")
+		return "", "", fmt.Errorf("%w: %v", ErrLoginProviderUnavailable, err)
+	}
+	if result.User.Policy.IsDisabled || result.User.Name == "" {
+		return "", "", ErrInvalidCredentials
+	}
+	if s.isLocalName(result.User.Name) {
+		log.Warn().Str("username", result.User.Name).Msg("Jellyfin sign-in refused: the name belongs to a local account")
+		return "", "", ErrInvalidCredentials
 	}
 
-	return token, nil
+	role := cfg.Admin.JellyfinLogin.UserRole
+	if role == "" {
+		role = utils.RoleRequester
+	}
+	if result.User.Policy.IsAdministrator {
+		role = utils.RoleAdmin
+	}
+
+	log.Debug().
+		Str("username", result.User.Name).
+		Str("role", role).
+		Msg("Authenticated with Jellyfin")
+
+	return result.User.Name, role, nil
 }
 
-// ChangePassword changes the admin password.
-// The new password is stored as a bcrypt hash in memory.
-func (s *AuthService) ChangePassword(currentPassword, newPassword string) error {
-	// Verify current password
-	if !utils.CheckPassword(s.cfg.Admin.Password, currentPassword) {
-		return ErrInvalidCredentials
+// ChangePassword changes a user's password after verifying the current one.
+// The new password is stored as a bcrypt hash, and the user's sessions are
+// revoked.
+func (s *AuthService) ChangePassword(username, currentPassword, newPassword string) error {
+	// Verify current password. Only local accounts have a password here;
+	// Jellyfin users change theirs in Jellyfin.
+	if _, _, err := s.authenticateLocal(username, currentPassword); err != nil {
+		if errors.Is(err, errUnknownUser) {
+			return ErrInvalidCredentials
+		}
+		return err
 	}
 
 	if newPassword == "" {
@@ -62,15 +312,185 @@ func (s *AuthService) ChangePassword(currentPassword, newPassword string) error
 	if err != nil {
 		return err
 	}
-	s.cfg.Admin.Password = hash
 
-	// Note: In a complete implementation, you'd want to persist this to the config file
-	// This would require access to the config file writer
+	if !s.hasUsers() {
+		s.cfg.Admin.Password = hash
+		return nil
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
 
+	user, ok := s.users.Get(username)
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.PasswordHash = hash
+	user.UpdatedAt = time.Now()
+	if err := s.users.Put(user); err != nil {
+		return err
+	}
+	s.RevokeUserSessions(username)
 	return nil
 }
 
-// ValidateToken validates a JWT token
+// ListUsers returns all users
+func (s *AuthService) ListUsers() ([]storage.User, error) {
+	if s.users == nil {
+		return nil, ErrUsersUnavailable
+	}
+	return s.users.GetAll(), nil
+}
+
+// CreateUser adds a new user with the given role
+func (s *AuthService) CreateUser(username, password, role string) (storage.User, error) {
+	if s.users == nil {
+		return storage.User{}, ErrUsersUnavailable
+	}
+
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return storage.User{}, ErrEmptyUsername
+	}
+	if password == "" {
+		return storage.User{}, ErrEmptyPassword
+	}
+	if !utils.IsValidRole(role) {
+		return storage.User{}, ErrInvalidRole
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return storage.User{}, err
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	if _, exists := s.findUser(username); exists {
+		return storage.User{}, ErrUserExists
+	}
+
+	now := time.Now()
+	user := storage.User{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.users.Put(user); err != nil {
+		return storage.User{}, err
+	}
+	return user, nil
+}
+
+// UpdateUser changes a user's role and/or password. Empty values leave the
+// corresponding field unchanged. Demoting the last admin is rejected. A new
+// password or role revokes the user's sessions, so the change applies from
+// their next sign-in.
+func (s *AuthService) UpdateUser(username, password, role string) (storage.User, error) {
+	if s.users == nil {
+		return storage.User{}, ErrUsersUnavailable
+	}
+	if role != "" && !utils.IsValidRole(role) {
+		return storage.User{}, ErrInvalidRole
+	}
+
+	var hash string
+	if password != "" {
+		var err error
+		if hash, err = utils.HashPassword(password); err != nil {
+			return storage.User{}, err
+		}
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, ok := s.users.Get(username)
+	if !ok {
+		return storage.User{}, ErrUserNotFound
+	}
+	roleChanged := role != "" && role != user.Role
+	if roleChanged {
+		if user.Role == utils.RoleAdmin && s.adminCount() == 1 {
+			return storage.User{}, ErrLastAdmin
+		}
+		user.Role = role
+	}
+	if hash != "" {
+		user.PasswordHash = hash
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.users.Put(user); err != nil {
+		return storage.User{}, err
+	}
+	if hash != "" || roleChanged {
+		s.RevokeUserSessions(username)
+	}
+	return user, nil
+}
+
+// DeleteUser removes a user and ends their sessions. Deleting the last
+// admin is rejected.
+func (s *AuthService) DeleteUser(username string) error {
+	if s.users == nil {
+		return ErrUsersUnavailable
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, ok := s.users.Get(username)
+	if !ok {
+		return ErrUserNotFound
+	}
+	if user.Role == utils.RoleAdmin && s.adminCount() == 1 {
+		return ErrLastAdmin
+	}
+	if err := s.users.Remove(username); err != nil {
+		return err
+	}
+	s.RevokeUserSessions(username)
+	return nil
+}
+
+// ValidateToken validates a JWT token and, with a session store, that its
+// session is still active
 func (s *AuthService) ValidateToken(token string) (*utils.JWTClaims, error) {
-	return utils.ValidateToken(token)
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !s.SessionActive(claims) {
+		return nil, ErrSessionRevoked
+	}
+	return claims, nil
+}
+
+// currentConfig returns the live config so provider settings follow reloads,
+// falling back to the config the service was created with.
+func (s *AuthService) currentConfig() *config.Config {
+	if cfg := config.Get(); cfg != nil {
+		return cfg
+	}
+	return s.cfg
+}
+
+// hasUsers reports whether logins are served from the user store
+func (s *AuthService) hasUsers() bool {
+	return s.users != nil && s.users.Count() > 0
+}
+
+// adminCount returns the number of admin users. Callers hold usersMu.
+func (s *AuthService) adminCount() int {
+	count := 0
+	for _, user := range s.users.GetAll() {
+		if user.Role == utils.RoleAdmin {
+			count++
+		}
+	}
+	return count
 }
@@ -1,10 +1,17 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
 	"github.com/ramonskie/oxicleanarr/internal/utils"
 )
 
@@ -25,13 +32,17 @@ func setupAuthService(t *testing.T, password string) *AuthService {
 func TestLogin_Success(t *testing.T) {
 	svc := setupAuthService(t, "testpassword")
 
-	token, err := svc.Login("admin", "testpassword")
+	result, err := svc.Login(context.Background(), "admin", "testpassword", ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
+	token, role := result.Token, result.Role
 	if token == "" {
 		t.Error("Expected non-empty token")
 	}
+	if role != utils.RoleAdmin {
+		t.Errorf("Expected config admin to get role admin, got %q", role)
+	}
 
 	claims, err := svc.ValidateToken(token)
 	if err != nil {
@@ -40,6 +51,9 @@ func TestLogin_Success(t *testing.T) {
 	if claims.Username != "admin" {
 		t.Errorf("Expected username admin, got %q", claims.Username)
 	}
+	if claims.Role != utils.RoleAdmin {
+		t.Errorf("Expected role claim admin, got %q", claims.Role)
+	}
 }
 
 func TestLogin_WithBcryptStoredPassword(t *testing.T) {
@@ -49,10 +63,10 @@ func TestLogin_WithBcryptStoredPassword(t *testing.T) {
 	}
 	svc := setupAuthService(t, hash)
 
-	if _, err := svc.Login("admin", "bcryptpassword"); err != nil {
+	if _, err := svc.Login(context.Background(), "admin", "bcryptpassword", ClientInfo{}); err != nil {
 		t.Errorf("Login with bcrypt-stored password failed: %v", err)
 	}
-	if _, err := svc.Login("admin", "wrongpassword"); err != ErrInvalidCredentials {
+	if _, err := svc.Login(context.Background(), "admin", "wrongpassword", ClientInfo{}); err != ErrInvalidCredentials {
 		t.Errorf("Expected ErrInvalidCredentials for wrong password, got %v", err)
 	}
 }
@@ -72,7 +86,7 @@ func TestLogin_WrongCredentials(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if _, err := svc.Login(tt.username, tt.password); err != ErrInvalidCredentials {
+			if _, err := svc.Login(context.Background(), tt.username, tt.password, ClientInfo{}); err != ErrInvalidCredentials {
 				t.Errorf("Expected ErrInvalidCredentials, got %v", err)
 			}
 		})
@@ -82,16 +96,16 @@ func TestLogin_WrongCredentials(t *testing.T) {
 func TestChangePassword_HashesNewPassword(t *testing.T) {
 	svc := setupAuthService(t, "oldpassword")
 
-	if err := svc.ChangePassword("oldpassword", "newpassword"); err != nil {
+	if err := svc.ChangePassword("admin", "oldpassword", "newpassword"); err != nil {
 		t.Fatalf("ChangePassword failed: %v", err)
 	}
 
 	// New password must work with the service
-	if _, err := svc.Login("admin", "newpassword"); err != nil {
+	if _, err := svc.Login(context.Background(), "admin", "newpassword", ClientInfo{}); err != nil {
 		t.Errorf("Login with new password failed: %v", err)
 	}
 	// Old password must no longer work
-	if _, err := svc.Login("admin", "oldpassword"); err != ErrInvalidCredentials {
+	if _, err := svc.Login(context.Background(), "admin", "oldpassword", ClientInfo{}); err != ErrInvalidCredentials {
 		t.Errorf("Expected old password to be rejected, got %v", err)
 	}
 	// Stored value must be a bcrypt hash, not plaintext
@@ -106,7 +120,7 @@ func TestChangePassword_HashesNewPassword(t *testing.T) {
 func TestChangePassword_WrongCurrent(t *testing.T) {
 	svc := setupAuthService(t, "oldpassword")
 
-	if err := svc.ChangePassword("wrongcurrent", "newpassword"); err != ErrInvalidCredentials {
+	if err := svc.ChangePassword("admin", "wrongcurrent", "newpassword"); err != ErrInvalidCredentials {
 		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
 	}
 }
@@ -114,7 +128,283 @@ func TestChangePassword_WrongCurrent(t *testing.T) {
 func TestChangePassword_EmptyNewPassword(t *testing.T) {
 	svc := setupAuthService(t, "oldpassword")
 
-	if err := svc.ChangePassword("oldpassword", ""); err != ErrEmptyPassword {
+	if err := svc.ChangePassword("admin", "oldpassword", ""); err != ErrEmptyPassword {
 		t.Errorf("Expected ErrEmptyPassword, got %v", err)
 	}
 }
+
+func setupAuthServiceWithUsers(t *testing.T, password string) *AuthService {
+	t.Helper()
+	svc := setupAuthService(t, password)
+	users, err := storage.NewUsersFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUsersFile failed: %v", err)
+	}
+	svc.SetUsers(users)
+	return svc
+}
+
+func TestMigrateAdmin(t *testing.T) {
+	svc := setupAuthServiceWithUsers(t, "plainpassword")
+
+	migrated, err := svc.MigrateAdmin()
+	if err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	if !migrated {
+		t.Fatal("Expected the config admin to be migrated into an empty store")
+	}
+
+	user, ok := svc.users.Get("admin")
+	if !ok {
+		t.Fatal("Expected admin user in store")
+	}
+	if user.Role != utils.RoleAdmin {
+		t.Errorf("Expected role admin, got %q", user.Role)
+	}
+	if !utils.IsBcryptHash(user.PasswordHash) {
+		t.Error("Migrated plaintext password must be stored as a bcrypt hash")
+	}
+
+	if result, err := svc.Login(context.Background(), "admin", "plainpassword", ClientInfo{}); err != nil || result.Role != utils.RoleAdmin {
+		t.Errorf("Expected migrated admin to log in as admin, got role=%q err=%v", result.Role, err)
+	}
+
+	// A second run must not touch the now non-empty store
+	migrated, err = svc.MigrateAdmin()
+	if err != nil || migrated {
+		t.Errorf("Expected second migration to be a no-op, got migrated=%v err=%v", migrated, err)
+	}
+}
+
+func TestUserManagement(t *testing.T) {
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+
+	if _, err := svc.CreateUser("viewer1", "viewerpassword", utils.RoleViewer); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if _, err := svc.CreateUser("viewer1", "other", utils.RoleViewer); err != ErrUserExists {
+		t.Errorf("Expected ErrUserExists, got %v", err)
+	}
+	if _, err := svc.CreateUser("Viewer1", "other", utils.RoleViewer); err != ErrUserExists {
+		t.Errorf("Expected ErrUserExists for a name differing only in case, got %v", err)
+	}
+	if _, err := svc.CreateUser("x", "pw", "superuser"); err != ErrInvalidRole {
+		t.Errorf("Expected ErrInvalidRole, got %v", err)
+	}
+	if _, err := svc.CreateUser("  ", "pw", utils.RoleViewer); err != ErrEmptyUsername {
+		t.Errorf("Expected ErrEmptyUsername, got %v", err)
+	}
+
+	result, err := svc.Login(context.Background(), "viewer1", "viewerpassword", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login as viewer failed: %v", err)
+	}
+	token, role := result.Token, result.Role
+	if role != utils.RoleViewer {
+		t.Errorf("Expected role viewer, got %q", role)
+	}
+	claims, err := svc.ValidateToken(token)
+	if err != nil || claims.Role != utils.RoleViewer {
+		t.Errorf("Expected viewer role claim, got claims=%+v err=%v", claims, err)
+	}
+
+	// The config admin no longer bypasses the store once users exist
+	svc.cfg.Admin.Password = "configpassword"
+	if _, err := svc.Login(context.Background(), "admin", "configpassword", ClientInfo{}); err != ErrInvalidCredentials {
+		t.Errorf("Expected config password to be ignored once users exist, got %v", err)
+	}
+
+	user, err := svc.UpdateUser("viewer1", "newpassword", utils.RoleOperator)
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if user.Role != utils.RoleOperator {
+		t.Errorf("Expected role operator, got %q", user.Role)
+	}
+	if _, err := svc.Login(context.Background(), "viewer1", "newpassword", ClientInfo{}); err != nil {
+		t.Errorf("Login with updated password failed: %v", err)
+	}
+
+	if err := svc.ChangePassword("viewer1", "newpassword", "changed"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	if _, err := svc.Login(context.Background(), "viewer1", "changed", ClientInfo{}); err != nil {
+		t.Errorf("Login with changed password failed: %v", err)
+	}
+
+	if _, err := svc.UpdateUser("nobody", "", utils.RoleViewer); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if err := svc.DeleteUser("nobody"); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserManagement_KeepsLastAdmin(t *testing.T) {
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+
+	if _, err := svc.UpdateUser("admin", "", utils.RoleViewer); err != ErrLastAdmin {
+		t.Errorf("Expected ErrLastAdmin when demoting the last admin, got %v", err)
+	}
+	if err := svc.DeleteUser("admin"); err != ErrLastAdmin {
+		t.Errorf("Expected ErrLastAdmin when deleting the last admin, got %v", err)
+	}
+
+	if _, err := svc.CreateUser("admin2", "pw", utils.RoleAdmin); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if err := svc.DeleteUser("admin"); err != nil {
+		t.Errorf("Expected deleting one of two admins to succeed, got %v", err)
+	}
+}
+
+func TestUserManagement_WithoutStore(t *testing.T) {
+	svc := setupAuthService(t, "adminpassword")
+
+	if _, err := svc.ListUsers(); err != ErrUsersUnavailable {
+		t.Errorf("Expected ErrUsersUnavailable, got %v", err)
+	}
+	if _, err := svc.CreateUser("u", "p", utils.RoleViewer); err != ErrUsersUnavailable {
+		t.Errorf("Expected ErrUsersUnavailable, got %v", err)
+	}
+}
+
+func newJellyfinLoginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/Sessions/Logout" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path != "/Users/AuthenticateByName" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		users := map[string]map[string]any{
+			"jelly-admin": {"Id": "1", "Name": "Jelly-Admin", "Policy": map[string]any{"IsAdministrator": true}},
+			"kid":         {"Id": "2", "Name": "Kid", "Policy": map[string]any{}},
+			"gone":        {"Id": "3", "Name": "Gone", "Policy": map[string]any{"IsDisabled": true}},
+			// Signs in with another name but is called like a local account
+			"ally": {"Id": "4", "Name": "ALICE", "Policy": map[string]any{}},
+		}
+		user, ok := users[strings.ToLower(body["Username"])]
+		if !ok || body["Pw"] != "jellypw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"User": user, "AccessToken": "tok"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLogin_Jellyfin(t *testing.T) {
+	server := newJellyfinLoginServer(t)
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	if _, err := svc.CreateUser("alice", "alicepassword", utils.RoleViewer); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	cfg := &config.Config{
+		Admin: config.AdminConfig{
+			Username:      "admin",
+			JellyfinLogin: config.JellyfinLoginConfig{Enabled: true},
+		},
+		Integrations: config.IntegrationsConfig{
+			Jellyfin: config.JellyfinConfig{
+				BaseIntegrationConfig: config.BaseIntegrationConfig{Enabled: true, URL: server.URL, APIKey: "key"},
+			},
+		},
+	}
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	t.Run("administrator maps to admin", func(t *testing.T) {
+		result, err := svc.Login(context.Background(), "jelly-admin", "jellypw", ClientInfo{})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		token, role := result.Token, result.Role
+		if role != utils.RoleAdmin {
+			t.Errorf("Expected role admin, got %q", role)
+		}
+		claims, err := svc.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("ValidateToken failed: %v", err)
+		}
+		if claims.Username != "Jelly-Admin" {
+			t.Errorf("Expected the canonical Jellyfin name in the token, got %q", claims.Username)
+		}
+	})
+
+	t.Run("regular user maps to requester", func(t *testing.T) {
+		result, err := svc.Login(context.Background(), "kid", "jellypw", ClientInfo{})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if result.Role != utils.RoleRequester {
+			t.Errorf("Expected role requester, got %q", result.Role)
+		}
+	})
+
+	t.Run("configured user role", func(t *testing.T) {
+		cfg.Admin.JellyfinLogin.UserRole = utils.RoleViewer
+		defer func() { cfg.Admin.JellyfinLogin.UserRole = "" }()
+		if result, err := svc.Login(context.Background(), "kid", "jellypw", ClientInfo{}); err != nil || result.Role != utils.RoleViewer {
+			t.Errorf("Expected role viewer, got role=%q err=%v", result.Role, err)
+		}
+	})
+
+	t.Run("rejections", func(t *testing.T) {
+		for _, tc := range []struct{ username, password string }{
+			{"kid", "wrong"},
+			{"kid", ""},
+			{"gone", "jellypw"},
+			{"admin", "jellypw"}, // local account wins; Jellyfin is not consulted
+			{"ALICE", "jellypw"}, // local names match regardless of case
+			{"ally", "jellypw"},  // Jellyfin name collides with a local account
+		} {
+			if _, err := svc.Login(context.Background(), tc.username, tc.password, ClientInfo{}); err != ErrInvalidCredentials {
+				t.Errorf("Login(%q, %q): expected ErrInvalidCredentials, got %v", tc.username, tc.password, err)
+			}
+		}
+	})
+
+	t.Run("local name in another case", func(t *testing.T) {
+		result, err := svc.Login(context.Background(), "ALICE", "alicepassword", ClientInfo{})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if result.Username != "alice" || result.Role != utils.RoleViewer {
+			t.Errorf("Expected the local account, got %q with role %q", result.Username, result.Role)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg.Admin.JellyfinLogin.Enabled = false
+		defer func() { cfg.Admin.JellyfinLogin.Enabled = true }()
+		if _, err := svc.Login(context.Background(), "kid", "jellypw", ClientInfo{}); err != ErrInvalidCredentials {
+			t.Errorf("Expected ErrInvalidCredentials with Jellyfin login disabled, got %v", err)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		cfg.Integrations.Jellyfin.URL = "http://127.0.0.1:1"
+		defer func() { cfg.Integrations.Jellyfin.URL = server.URL }()
+		if _, err := svc.Login(context.Background(), "kid", "jellypw", ClientInfo{}); !errors.Is(err, ErrLoginProviderUnavailable) {
+			t.Errorf("Expected ErrLoginProviderUnavailable, got %v", err)
+		}
+	})
+}
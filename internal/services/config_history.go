@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrConfigHistoryUnavailable is returned when no config history is configured.
+	ErrConfigHistoryUnavailable = errors.New("config history is not configured")
+	// ErrConfigVersionNotFound is returned for an unknown or expired version ID.
+	ErrConfigVersionNotFound = errors.New("config version not found")
+)
+
+// ConfigHistory keeps earlier versions of the config file so they can be
+// compared and restored. Record, RecordFile and Discard are safe to call on a
+// nil *ConfigHistory, which records nothing.
+type ConfigHistory struct {
+	mu   sync.Mutex // serializes Record so each change is compared with the latest version
+	file *storage.ConfigHistoryFile
+	// fingerprintKey keys the secret fingerprints shown in diffs. It is random
+	// per process, so fingerprints cannot be used to guess a secret offline.
+	fingerprintKey []byte
+}
+
+// NewConfigHistory creates a config history backed by file
+func NewConfigHistory(file *storage.ConfigHistoryFile) *ConfigHistory {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &ConfigHistory{file: file, fingerprintKey: key}
+}
+
+// ConfigHash returns the hash identifying config file content
+func ConfigHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record stores content as a new version with the source, actor and
+// restored-from ID of meta, unless it matches the latest version. It returns
+// the version and whether it was added. Failures are logged, not returned:
+// losing a history entry must not stop the config from being saved.
+func (h *ConfigHistory) Record(content []byte, meta storage.ConfigVersion) (storage.ConfigVersion, bool) {
+	if h == nil || h.file == nil {
+		return storage.ConfigVersion{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hash := ConfigHash(content)
+	if latest, ok := h.file.Latest(); ok && latest.Hash == hash {
+		return latest, false
+	}
+
+	version := meta
+	version.ID = uuid.New().String()
+	version.CreatedAt = time.Now()
+	version.Hash = hash
+	version.Content = string(content)
+	if err := h.file.Add(version); err != nil {
+		log.Error().Err(err).Str("source", version.Source).Msg("Failed to record config version")
+		return storage.ConfigVersion{}, false
+	}
+
+	log.Debug().Str("id", version.ID).Str("source", version.Source).Msg("Recorded config version")
+	return version, true
+}
+
+// RecordFile records the current content of the config file at path
+func (h *ConfigHistory) RecordFile(path, source string) {
+	if h == nil || h.file == nil {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to read config file for history")
+		return
+	}
+	h.Record(content, storage.ConfigVersion{Source: source})
+}
+
+// Discard removes a version recorded for a write that then failed
+func (h *ConfigHistory) Discard(id string) {
+	if h == nil || h.file == nil {
+		return
+	}
+	if _, err := h.file.Remove(id); err != nil {
+		log.Warn().Err(err).Str("id", id).Msg("Failed to discard config version")
+	}
+}
+
+// List returns the recorded versions, most recent first
+func (h *ConfigHistory) List() ([]storage.ConfigVersion, error) {
+	if h == nil || h.file == nil {
+		return nil, ErrConfigHistoryUnavailable
+	}
+
+	versions := h.file.GetAll()
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return versions, nil
+}
+
+// Get returns the version with the given ID
+func (h *ConfigHistory) Get(id string) (storage.ConfigVersion, error) {
+	if h == nil || h.file == nil {
+		return storage.ConfigVersion{}, ErrConfigHistoryUnavailable
+	}
+
+	version, ok := h.file.Get(id)
+	if !ok {
+		return storage.ConfigVersion{}, ErrConfigVersionNotFound
+	}
+	return version, nil
+}
+
+// Diff returns the settings that restoring version would change in the
+// current config file content, sorted by path: Before is the current value
+// and After the value in version. Both are compared after defaults are
+// applied, with secrets shown as fingerprints and references left as written.
+func (h *ConfigHistory) Diff(version storage.ConfigVersion, current []byte) ([]storage.AuditChange, error) {
+	currentCfg, err := config.Parse(current)
+	if err != nil {
+		return nil, fmt.Errorf("parsing current config: %w", err)
+	}
+	versionCfg, err := config.Parse([]byte(version.Content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing config version: %w", err)
+	}
+
+	changes, err := AuditDiff(h.diffView(currentCfg), h.diffView(versionCfg))
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []storage.AuditChange{}
+	}
+	return changes, nil
+}
+
+// diffView returns cfg as written to the config file, with secret values
+// replaced by fingerprints
+func (h *ConfigHistory) diffView(cfg *config.Config) *config.Config {
+	return config.RedactSecrets(config.WithSecretReferences(cfg), func(secret string) string {
+		mac := hmac.New(sha256.New, h.fingerprintKey)
+		mac.Write([]byte(secret))
+		return "set:" + hex.EncodeToString(mac.Sum(nil))[:8]
+	})
+}
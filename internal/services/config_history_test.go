@@ -0,0 +1,109 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfigHistory(t *testing.T) *ConfigHistory {
+	t.Helper()
+	file, err := storage.NewConfigHistoryFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	return NewConfigHistory(file)
+}
+
+func TestConfigHistory_Record(t *testing.T) {
+	history := newTestConfigHistory(t)
+
+	first, added := history.Record([]byte("app:\n  dry_run: true\n"), storage.ConfigVersion{Source: storage.ConfigSourceStartup})
+	require.True(t, added)
+	assert.NotEmpty(t, first.ID)
+	assert.Equal(t, ConfigHash([]byte("app:\n  dry_run: true\n")), first.Hash)
+
+	// The file watcher sees API writes too; the same content is recorded once
+	_, added = history.Record([]byte("app:\n  dry_run: true\n"), storage.ConfigVersion{Source: storage.ConfigSourceFile})
+	assert.False(t, added)
+
+	second, added := history.Record([]byte("app:\n  dry_run: false\n"), storage.ConfigVersion{Source: storage.ConfigSourceAPI, Actor: "admin"})
+	require.True(t, added)
+
+	versions, err := history.List()
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, second.ID, versions[0].ID, "most recent first")
+	assert.Equal(t, "admin", versions[0].Actor)
+
+	history.Discard(second.ID)
+	_, err = history.Get(second.ID)
+	assert.ErrorIs(t, err, ErrConfigVersionNotFound)
+
+	var unavailable *ConfigHistory
+	unavailable.Record([]byte("app: {}\n"), storage.ConfigVersion{})
+	_, err = unavailable.List()
+	assert.ErrorIs(t, err, ErrConfigHistoryUnavailable)
+}
+
+func TestConfigHistory_Diff(t *testing.T) {
+	history := newTestConfigHistory(t)
+	keyFile := filepath.Join(t.TempDir(), "radarr_api_key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("radarr-secret"), 0600))
+
+	version, _ := history.Record([]byte(`admin:
+  username: admin
+  password: old-password
+rules:
+  movie_retention: 30d
+integrations:
+  radarr:
+    enabled: true
+    url: http://radarr:7878
+    api_key_file: `+keyFile+`
+`), storage.ConfigVersion{Source: storage.ConfigSourceAPI})
+
+	current := []byte(`admin:
+  username: admin
+  password: new-password
+rules:
+  movie_retention: 90d
+  tv_retention: 120d
+integrations:
+  radarr:
+    enabled: true
+    url: http://radarr:7878
+    api_key: ${RADARR_API_KEY}
+`)
+	t.Setenv("RADARR_API_KEY", "radarr-secret")
+
+	changes, err := history.Diff(version, current)
+	require.NoError(t, err)
+
+	byField := make(map[string]storage.AuditChange)
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+	assert.Equal(t, storage.AuditChange{Field: "rules.movie_retention", Before: "90d", After: "30d"}, byField["rules.movie_retention"])
+	assert.NotContains(t, byField, "rules.tv_retention", "defaults are applied before comparing")
+	assert.Equal(t, storage.AuditChange{Field: "integrations.radarr.api_key", Before: "${RADARR_API_KEY}", After: ""}, byField["integrations.radarr.api_key"])
+	assert.Equal(t, storage.AuditChange{Field: "integrations.radarr.api_key_file", After: keyFile}, byField["integrations.radarr.api_key_file"])
+
+	password := byField["admin.password"]
+	assert.Contains(t, password.Before, "set:")
+	assert.Contains(t, password.After, "set:")
+	assert.NotEqual(t, password.Before, password.After)
+	for _, change := range changes {
+		for _, value := range []any{change.Before, change.After} {
+			if s, ok := value.(string); ok {
+				assert.NotContains(t, s, "password", "secrets must not appear in diffs")
+			}
+		}
+	}
+
+	changes, err = history.Diff(version, []byte(version.Content))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultApprovalExpiry is used when app.approval.expiry is unset or invalid.
+const defaultApprovalExpiry = 7 * 24 * time.Hour
+
+var (
+	// ErrApprovalUnavailable is returned when no deletion batch store is configured.
+	ErrApprovalUnavailable = errors.New("deletion approval storage is not configured")
+	// ErrBatchNotFound is returned when no batch has the requested ID.
+	ErrBatchNotFound = errors.New("deletion batch not found")
+	// ErrBatchNotPending is returned when the batch was already fully decided.
+	ErrBatchNotPending = errors.New("deletion batch is no longer pending")
+	// ErrBatchExpired is returned when the batch expired before it was approved.
+	ErrBatchExpired = errors.New("deletion batch has expired")
+	// ErrBatchItemNotPending is returned when a selected item is not in the
+	// batch or was already decided.
+	ErrBatchItemNotPending = errors.New("item is not pending in this batch")
+	// ErrDeletionDisabled is returned when approving while enable_deletion is
+	// off or dry_run is on; the config changed since the batch was proposed.
+	ErrDeletionDisabled = errors.New("deletion is disabled (enable_deletion=false or dry_run=true)")
+	// ErrApprovalRequired is returned for a manual deletion while approval
+	// mode is on; deletions then only run from approved batches.
+	ErrApprovalRequired = errors.New("approval mode is enabled: deletions run only from approved batches")
+)
+
+// SetDeletionBatches injects the store used for two-step deletion approval.
+// Without it, approval mode skips deletions entirely (fail-safe).
+func (e *SyncEngine) SetDeletionBatches(batches *storage.DeletionBatchesFile) {
+	e.deletionBatches = batches
+}
+
+// approvalModeEnabled reports whether full syncs should propose deletions as
+// a batch instead of executing them.
+func (e *SyncEngine) approvalModeEnabled() bool {
+	return e.config.App.EnableDeletion && !e.config.App.DryRun && e.config.App.Approval.Enabled
+}
+
+// approvalExpiry returns how long a newly proposed batch stays approvable.
+func (e *SyncEngine) approvalExpiry() time.Duration {
+	expiry, err := rules.ParseDuration(e.config.App.Approval.Expiry)
+	if err != nil || expiry <= 0 {
+		return defaultApprovalExpiry
+	}
+	return expiry
+}
+
+// proposeDeletionBatch stores the candidates of a full sync as a pending batch.
+// Items still pending in an earlier batch are left there rather than proposed
+// twice. Returns nil when every candidate is already awaiting approval.
+func (e *SyncEngine) proposeDeletionBatch(jobID string, candidates []map[string]interface{}) (*storage.DeletionBatch, error) {
+	if e.deletionBatches == nil {
+		return nil, ErrApprovalUnavailable
+	}
+
+	now := time.Now()
+	if _, err := e.deletionBatches.ExpireStale(now); err != nil {
+		log.Warn().Err(err).Msg("Failed to expire stale deletion batches")
+	}
+	alreadyPending := e.deletionBatches.PendingMediaIDs()
+
+	items := make([]storage.DeletionBatchItem, 0, len(candidates))
+	for _, candidate := range candidates {
+		mediaID, _ := candidate["id"].(string)
+		if mediaID == "" || alreadyPending[mediaID] {
+			continue
+		}
+		item := storage.DeletionBatchItem{
+			MediaID: mediaID,
+			Status:  storage.ItemStatusPending,
+		}
+		item.Title, _ = candidate["title"].(string)
+		item.Year, _ = candidate["year"].(int)
+		item.FileSize, _ = candidate["file_size"].(int64)
+		item.DeleteAfter, _ = candidate["delete_after"].(time.Time)
+		item.Reason, _ = candidate["reason"].(string)
+		if mediaType, ok := candidate["type"].(models.MediaType); ok {
+			item.Type = string(mediaType)
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	batch := storage.DeletionBatch{
+		ID:        uuid.New().String(),
+		JobID:     jobID,
+		Status:    storage.BatchStatusPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(e.approvalExpiry()),
+		Items:     items,
+	}
+	if err := e.deletionBatches.Add(batch); err != nil {
+		return nil, fmt.Errorf("storing deletion batch: %w", err)
+	}
+
+	log.Info().
+		Str("batch_id", batch.ID).
+		Int("items", len(items)).
+		Time("expires_at", batch.ExpiresAt).
+		Msg("Deletion batch proposed, awaiting approval")
+
+	return &batch, nil
+}
+
+// ListDeletionBatches returns all deletion batches, most recent first,
+// expiring stale ones first so their status is current.
+func (e *SyncEngine) ListDeletionBatches() ([]storage.DeletionBatch, error) {
+	if e.deletionBatches == nil {
+		return nil, ErrApprovalUnavailable
+	}
+	if _, err := e.deletionBatches.ExpireStale(time.Now()); err != nil {
+		log.Warn().Err(err).Msg("Failed to expire stale deletion batches")
+	}
+	return e.deletionBatches.GetAll(), nil
+}
+
+// GetDeletionBatch returns a single deletion batch by ID.
+func (e *SyncEngine) GetDeletionBatch(id string) (storage.DeletionBatch, error) {
+	if e.deletionBatches == nil {
+		return storage.DeletionBatch{}, ErrApprovalUnavailable
+	}
+	if _, err := e.deletionBatches.ExpireStale(time.Now()); err != nil {
+		log.Warn().Err(err).Msg("Failed to expire stale deletion batches")
+	}
+	batch, found := e.deletionBatches.Get(id)
+	if !found {
+		return storage.DeletionBatch{}, ErrBatchNotFound
+	}
+	return batch, nil
+}
+
+// ApproveDeletionBatch approves the given items of a pending batch (all
+// pending items when mediaIDs is empty) and deletes them. Each item is
+// re-evaluated against the current rules first: anything that became
+// protected or is no longer due since the proposal is skipped, and
+// ExecuteDeletions' pre-deletion watch-state check still applies.
+//
+// Approved items are deleted as a deletion job (trigger "approval"). It does
+// not wait for a running sync and returns ErrSyncInProgress instead.
+func (e *SyncEngine) ApproveDeletionBatch(ctx context.Context, batchID string, mediaIDs []string, actor string) (storage.DeletionBatch, error) {
+	if e.deletionBatches == nil {
+		return storage.DeletionBatch{}, ErrApprovalUnavailable
+	}
+	if e.config.App.DryRun || !e.config.App.EnableDeletion {
+		return storage.DeletionBatch{}, ErrDeletionDisabled
+	}
+
+	if !e.syncRunMu.TryLock() {
+		return storage.DeletionBatch{}, ErrSyncInProgress
+	}
+	defer e.syncRunMu.Unlock()
+
+	e.batchDecisionMu.Lock()
+	defer e.batchDecisionMu.Unlock()
+
+	batch, selected, err := e.pendingBatchSelection(batchID, mediaIDs)
+	if err != nil {
+		return storage.DeletionBatch{}, err
+	}
+
+	now := time.Now()
+	candidates := make([]map[string]interface{}, 0, len(selected))
+	for _, i := range selected {
+		item := &batch.Items[i]
+		item.DecidedAt = &now
+		item.DecidedBy = actor
+
+		media, found := e.GetMediaByID(item.MediaID)
+		if !found {
+			item.Status = storage.ItemStatusSkipped
+			item.Message = "no longer in library"
+			continue
+		}
+
+		// A manual leaving-soon flag sets DeleteAfter outside the rules, so the
+		// flag keeps the item due unless it has since been excluded.
+		verdict := e.rules.Evaluate(ctx, &media)
+		manualDue := media.IsManualLeavingSoon && now.After(media.DeleteAfter)
+		switch {
+		case verdict.IsProtected && (verdict.ProtectionReason == rules.ProtectedExcluded || !manualDue):
+			item.Status = storage.ItemStatusSkipped
+			item.Message = "now protected: " + FormatDeletionReason(verdict, &media)
+			continue
+		case !manualDue && !verdict.ShouldDelete() && !verdict.HasEpisodeDeletions():
+			item.Status = storage.ItemStatusSkipped
+			item.Message = "no longer due"
+			if !verdict.DeleteAfter.IsZero() {
+				item.Message = "no longer due; now scheduled for " + verdict.DeleteAfter.Format("2006-01-02")
+			}
+			continue
+		}
+
+		candidates = append(candidates, deletionCandidate(media, now))
+	}
+
+	var report DeletionReport
+	if len(candidates) > 0 {
+		job := newDeletionJob(DeletionTriggerApproval)
+		job.Summary["batch_id"] = batch.ID
+		job.Summary["actor"] = actor
+		_, report = e.startDeletionJobLocked(ctx, job, candidates)
+	}
+	results := make(map[string]storage.DeletionResult, len(report.Items))
+	for _, result := range report.Items {
+		results[result.MediaID] = result
+	}
+	for _, i := range selected {
+		item := &batch.Items[i]
+		result, ok := results[item.MediaID]
+		if !ok {
+			continue // skipped by the re-check above
+		}
+		switch result.Outcome {
+		case storage.DeletionOutcomeDeleted, storage.DeletionOutcomeEpisodesDeleted:
+			item.Status = storage.ItemStatusDeleted
+		case storage.DeletionOutcomeProtected:
+			item.Status = storage.ItemStatusSkipped
+		default:
+			item.Status = storage.ItemStatusFailed
+		}
+		item.Message = result.Message
+	}
+
+	finalizeBatchStatus(&batch)
+	if _, err := e.deletionBatches.Update(batch); err != nil {
+		return batch, fmt.Errorf("saving deletion batch: %w", err)
+	}
+
+	log.Info().
+		Str("batch_id", batch.ID).
+		Str("actor", actor).
+		Int("approved", len(selected)).
+		Int("deleted", report.Deleted).
+		Int("episode_files_deleted", report.EpisodeFilesDeleted).
+		Int("protected", report.Protected).
+		Int("failed", report.Failed).
+		Msg("Deletion batch approved")
+
+	return batch, nil
+}
+
+// RejectDeletionBatch rejects the given items of a pending batch (all pending
+// items when mediaIDs is empty). Rejected items are not excluded: if they are
+// still overdue, the next full sync proposes them again.
+func (e *SyncEngine) RejectDeletionBatch(batchID string, mediaIDs []string, actor string) (storage.DeletionBatch, error) {
+	if e.deletionBatches == nil {
+		return storage.DeletionBatch{}, ErrApprovalUnavailable
+	}
+
+	e.batchDecisionMu.Lock()
+	defer e.batchDecisionMu.Unlock()
+
+	batch, selected, err := e.pendingBatchSelection(batchID, mediaIDs)
+	if err != nil {
+		return storage.DeletionBatch{}, err
+	}
+
+	now := time.Now()
+	for _, i := range selected {
+		batch.Items[i].Status = storage.ItemStatusRejected
+		batch.Items[i].DecidedAt = &now
+		batch.Items[i].DecidedBy = actor
+	}
+
+	finalizeBatchStatus(&batch)
+	if _, err := e.deletionBatches.Update(batch); err != nil {
+		return batch, fmt.Errorf("saving deletion batch: %w", err)
+	}
+
+	log.Info().
+		Str("batch_id", batch.ID).
+		Str("actor", actor).
+		Int("rejected", len(selected)).
+		Msg("Deletion batch items rejected")
+
+	return batch, nil
+}
+
+// pendingBatchSelection loads a pending, unexpired batch and resolves the
+// selected media IDs to item indexes (all pending items when empty). Callers
+// hold batchDecisionMu until the decisions are stored.
+func (e *SyncEngine) pendingBatchSelection(batchID string, mediaIDs []string) (storage.DeletionBatch, []int, error) {
+	if _, err := e.deletionBatches.ExpireStale(time.Now()); err != nil {
+		log.Warn().Err(err).Msg("Failed to expire stale deletion batches")
+	}
+
+	batch, found := e.deletionBatches.Get(batchID)
+	if !found {
+		return batch, nil, ErrBatchNotFound
+	}
+	switch batch.Status {
+	case storage.BatchStatusPending:
+	case storage.BatchStatusExpired:
+		return batch, nil, ErrBatchExpired
+	default:
+		return batch, nil, ErrBatchNotPending
+	}
+
+	pendingIndex := make(map[string]int, len(batch.Items))
+	for i, item := range batch.Items {
+		if item.Status == storage.ItemStatusPending {
+			pendingIndex[item.MediaID] = i
+		}
+	}
+
+	if len(mediaIDs) == 0 {
+		selected := make([]int, 0, len(pendingIndex))
+		for i, item := range batch.Items {
+			if item.Status == storage.ItemStatusPending {
+				selected = append(selected, i)
+			}
+		}
+		return batch, selected, nil
+	}
+
+	selected := make([]int, 0, len(mediaIDs))
+	seen := make(map[string]bool, len(mediaIDs))
+	for _, id := range mediaIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		i, ok := pendingIndex[id]
+		if !ok {
+			return batch, nil, fmt.Errorf("%w: %s", ErrBatchItemNotPending, id)
+		}
+		selected = append(selected, i)
+	}
+	return batch, selected, nil
+}
+
+// finalizeBatchStatus closes a batch once no item is pending: rejected if
+// every item was rejected, completed otherwise.
+func finalizeBatchStatus(batch *storage.DeletionBatch) {
+	if batch.PendingCount() > 0 {
+		return
+	}
+	for _, item := range batch.Items {
+		if item.Status != storage.ItemStatusRejected {
+			batch.Status = storage.BatchStatusCompleted
+			return
+		}
+	}
+	batch.Status = storage.BatchStatusRejected
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/cache"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newApprovalTestEngine returns an engine in approval mode with two overdue
+// movies (added 100 days ago under a 90d retention). The movies have no
+// Radarr ID, so "deleting" them only removes them from the library.
+func newApprovalTestEngine(t *testing.T) (*SyncEngine, *storage.JobsFile, *storage.ExclusionsFile, *storage.DeletionBatchesFile) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		App: config.AppConfig{
+			DryRun:         false,
+			EnableDeletion: true,
+			Approval:       config.ApprovalConfig{Enabled: true, Expiry: "7d"},
+		},
+		Sync: config.SyncConfig{
+			FullInterval:        60,
+			IncrementalInterval: 5,
+		},
+		Rules: config.RulesConfig{
+			MovieRetention: "90d",
+			TVRetention:    "120d",
+		},
+	}
+	config.SetTestConfig(cfg)
+
+	jobs, err := storage.NewJobsFile(tmpDir, 50)
+	require.NoError(t, err)
+	exclusions, err := storage.NewExclusionsFile(tmpDir)
+	require.NoError(t, err)
+	manualLS, err := storage.NewManualLeavingSoonFile(tmpDir)
+	require.NoError(t, err)
+	batches, err := storage.NewDeletionBatchesFile(tmpDir, 0)
+	require.NoError(t, err)
+
+	rulesEngine := rules.NewRulesEngine(exclusions, nil)
+	engine := NewSyncEngine(cfg, cache.New(), jobs, exclusions, manualLS, rulesEngine)
+	engine.SetDeletionBatches(batches)
+
+	added := time.Now().AddDate(0, 0, -100)
+	engine.mediaLibrary["movie-1"] = models.Media{ID: "movie-1", Type: models.MediaTypeMovie, Title: "Movie One", AddedAt: added}
+	engine.mediaLibrary["movie-2"] = models.Media{ID: "movie-2", Type: models.MediaTypeMovie, Title: "Movie Two", AddedAt: added}
+
+	return engine, jobs, exclusions, batches
+}
+
+func TestSyncEngine_FullSync_ApprovalModeProposesBatch(t *testing.T) {
+	engine, jobs, _, batches := newApprovalTestEngine(t)
+	ctx := context.Background()
+
+	require.NoError(t, engine.FullSync(ctx))
+
+	// Nothing deleted until approval
+	assert.Equal(t, 2, engine.GetMediaCount())
+
+	all := batches.GetAll()
+	require.Len(t, all, 1)
+	batch := all[0]
+	assert.Equal(t, storage.BatchStatusPending, batch.Status)
+	assert.Len(t, batch.Items, 2)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), batch.ExpiresAt, time.Minute)
+
+	latestJob, found := jobs.GetLatest()
+	require.True(t, found)
+	assert.Equal(t, true, latestJob.Summary["approval_required"])
+	assert.Equal(t, batch.ID, latestJob.Summary["pending_batch_id"])
+	assert.Equal(t, batch.JobID, latestJob.ID)
+	assert.Nil(t, latestJob.Summary["deleted_count"])
+
+	// A second sync must not re-propose items that are still pending
+	require.NoError(t, engine.FullSync(ctx))
+	assert.Len(t, batches.GetAll(), 1)
+}
+
+func TestSyncEngine_ApproveDeletionBatch(t *testing.T) {
+	t.Run("approves selected items only", func(t *testing.T) {
+		engine, jobs, _, batches := newApprovalTestEngine(t)
+		ctx := context.Background()
+		require.NoError(t, engine.FullSync(ctx))
+		batchID := batches.GetAll()[0].ID
+
+		batch, err := engine.ApproveDeletionBatch(ctx, batchID, []string{"movie-1"}, "admin")
+		require.NoError(t, err)
+
+		_, found := engine.GetMediaByID("movie-1")
+		assert.False(t, found, "approved item must be deleted")
+		_, found = engine.GetMediaByID("movie-2")
+		assert.True(t, found, "unselected item must be kept")
+
+		assert.Equal(t, storage.BatchStatusPending, batch.Status, "batch stays pending while items remain")
+		statuses := map[string]storage.DeletionItemStatus{}
+		for _, item := range batch.Items {
+			statuses[item.MediaID] = item.Status
+		}
+		assert.Equal(t, storage.ItemStatusDeleted, statuses["movie-1"])
+		assert.Equal(t, storage.ItemStatusPending, statuses["movie-2"])
+
+		deletionJobs := jobsOfType(jobs, storage.JobTypeDeletion)
+		require.Len(t, deletionJobs, 1)
+		assert.Equal(t, DeletionTriggerApproval, deletionJobs[0].Summary["trigger"])
+		assert.Equal(t, batchID, deletionJobs[0].Summary["batch_id"])
+		assert.Len(t, deletionJobs[0].Results, 1)
+
+		// Approving the rest closes the batch
+		batch, err = engine.ApproveDeletionBatch(ctx, batchID, nil, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, storage.BatchStatusCompleted, batch.Status)
+		assert.Zero(t, engine.GetMediaCount())
+
+		_, err = engine.ApproveDeletionBatch(ctx, batchID, nil, "admin")
+		assert.ErrorIs(t, err, ErrBatchNotPending)
+	})
+
+	t.Run("re-checks items that became protected", func(t *testing.T) {
+		engine, _, exclusions, batches := newApprovalTestEngine(t)
+		ctx := context.Background()
+		require.NoError(t, engine.FullSync(ctx))
+		batchID := batches.GetAll()[0].ID
+
+		// Excluded after proposal
+		require.NoError(t, exclusions.Add(storage.ExclusionItem{ExternalID: "movie-1", ExternalType: "unknown", MediaType: "movie", Title: "Movie One"}))
+		// Watched after proposal: no longer overdue
+		media := engine.mediaLibrary["movie-2"]
+		media.LastWatched = time.Now()
+		media.WatchCount = 1
+		engine.mediaLibrary["movie-2"] = media
+
+		batch, err := engine.ApproveDeletionBatch(ctx, batchID, nil, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, 2, engine.GetMediaCount(), "nothing may be deleted")
+		for _, item := range batch.Items {
+			assert.Equal(t, storage.ItemStatusSkipped, item.Status, item.MediaID)
+			assert.NotEmpty(t, item.Message)
+			assert.Equal(t, "admin", item.DecidedBy)
+		}
+		assert.Equal(t, storage.BatchStatusCompleted, batch.Status)
+	})
+
+	t.Run("rejects expired batches", func(t *testing.T) {
+		engine, _, _, batches := newApprovalTestEngine(t)
+		require.NoError(t, batches.Add(storage.DeletionBatch{
+			ID:        "stale",
+			Status:    storage.BatchStatusPending,
+			CreatedAt: time.Now().Add(-8 * 24 * time.Hour),
+			ExpiresAt: time.Now().Add(-24 * time.Hour),
+			Items:     []storage.DeletionBatchItem{{MediaID: "movie-1", Status: storage.ItemStatusPending}},
+		}))
+
+		_, err := engine.ApproveDeletionBatch(context.Background(), "stale", nil, "admin")
+		assert.ErrorIs(t, err, ErrBatchExpired)
+		assert.Equal(t, 2, engine.GetMediaCount())
+	})
+
+	t.Run("validates batch and item IDs", func(t *testing.T) {
+		engine, _, _, batches := newApprovalTestEngine(t)
+		ctx := context.Background()
+		require.NoError(t, engine.FullSync(ctx))
+		batchID := batches.GetAll()[0].ID
+
+		_, err := engine.ApproveDeletionBatch(ctx, "missing", nil, "admin")
+		assert.ErrorIs(t, err, ErrBatchNotFound)
+
+		_, err = engine.ApproveDeletionBatch(ctx, batchID, []string{"movie-9"}, "admin")
+		assert.ErrorIs(t, err, ErrBatchItemNotPending)
+		assert.Equal(t, 2, engine.GetMediaCount())
+	})
+
+	t.Run("refuses while deletion is disabled", func(t *testing.T) {
+		engine, _, _, batches := newApprovalTestEngine(t)
+		ctx := context.Background()
+		require.NoError(t, engine.FullSync(ctx))
+		batchID := batches.GetAll()[0].ID
+
+		engine.config.App.DryRun = true
+		_, err := engine.ApproveDeletionBatch(ctx, batchID, nil, "admin")
+		assert.ErrorIs(t, err, ErrDeletionDisabled)
+	})
+
+	t.Run("returns busy while a sync runs", func(t *testing.T) {
+		engine, _, _, _ := newApprovalTestEngine(t)
+		engine.syncRunMu.Lock()
+		defer engine.syncRunMu.Unlock()
+
+		_, err := engine.ApproveDeletionBatch(context.Background(), "any", nil, "admin")
+		assert.ErrorIs(t, err, ErrSyncInProgress)
+	})
+}
+
+func TestSyncEngine_RejectDeletionBatch(t *testing.T) {
+	engine, _, _, batches := newApprovalTestEngine(t)
+	ctx := context.Background()
+	require.NoError(t, engine.FullSync(ctx))
+	batchID := batches.GetAll()[0].ID
+
+	batch, err := engine.RejectDeletionBatch(batchID, nil, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, storage.BatchStatusRejected, batch.Status)
+	assert.Equal(t, 2, engine.GetMediaCount())
+
+	// Rejected items are still overdue, so the next sync proposes them again
+	require.NoError(t, engine.FullSync(ctx))
+	all := batches.GetAll()
+	require.Len(t, all, 2)
+	assert.Equal(t, storage.BatchStatusPending, all[0].Status)
+}
+
+func TestSyncEngine_BatchDecisionsAreSerialized(t *testing.T) {
+	engine, _, _, batches := newApprovalTestEngine(t)
+	ctx := context.Background()
+	require.NoError(t, engine.FullSync(ctx))
+	batchID := batches.GetAll()[0].ID
+
+	// Stand in for an approval that is still deciding the batch
+	engine.batchDecisionMu.Lock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.RejectDeletionBatch(batchID, []string{"movie-1"}, "bob")
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("reject must wait for the decision in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The in-flight decision approves movie-1; the waiting reject then finds
+	// it decided instead of overwriting it
+	stored, found := batches.Get(batchID)
+	require.True(t, found)
+	for i := range stored.Items {
+		if stored.Items[i].MediaID == "movie-1" {
+			stored.Items[i].Status = storage.ItemStatusDeleted
+			stored.Items[i].DecidedBy = "alice"
+		}
+	}
+	_, err := batches.Update(stored)
+	require.NoError(t, err)
+	engine.batchDecisionMu.Unlock()
+
+	assert.ErrorIs(t, <-done, ErrBatchItemNotPending)
+	stored, _ = batches.Get(batchID)
+	for _, item := range stored.Items {
+		if item.MediaID == "movie-1" {
+			assert.Equal(t, storage.ItemStatusDeleted, item.Status)
+			assert.Equal(t, "alice", item.DecidedBy)
+		}
+	}
+}
+
+func TestSyncEngine_FullSync_ApprovalModeWithoutStoreSkipsDeletion(t *testing.T) {
+	engine, _, _, _ := newApprovalTestEngine(t)
+	engine.SetDeletionBatches(nil)
+
+	require.NoError(t, engine.FullSync(context.Background()))
+	assert.Equal(t, 2, engine.GetMediaCount(), "approval mode must fail safe without a batch store")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Deletion job triggers, recorded as "trigger" in the job summary
+const (
+	DeletionTriggerSync     = "sync"     // end of a full sync
+	DeletionTriggerSchedule = "schedule" // sync.deletion_cron
+	DeletionTriggerDeferred = "deferred" // end of a deletion blackout window
+	DeletionTriggerManual   = "manual"   // POST /api/deletions/execute
+	DeletionTriggerApproval = "approval" // approved deletion batch
+)
+
+// newDeletionJob returns a deletion job record for enqueueJob
+func newDeletionJob(trigger string) storage.Job {
+	return storage.Job{
+		ID:      uuid.New().String(),
+		Type:    storage.JobTypeDeletion,
+		Summary: map[string]any{"trigger": trigger},
+	}
+}
+
+// runDeletionJob deletes the candidates under the given queued job and stores
+// the per-item results on it. The caller must hold syncRunMu.
+func (e *SyncEngine) runDeletionJob(run *jobRun, candidates []map[string]interface{}) (storage.Job, DeletionReport) {
+	run.begin()
+	run.job.Summary["candidates"] = len(candidates)
+
+	run.startPhase(PhaseDeletions, len(candidates))
+	report := e.executeDeletions(run.ctx, candidates)
+	run.endPhase(PhaseDeletions, -1, nil)
+	if report.Deleted > 0 || report.EpisodeFilesDeleted > 0 {
+		e.cache.Clear()
+	}
+
+	job := &run.job
+	job.Summary["deleted_count"] = report.Deleted
+	job.Summary["episode_items_processed"] = report.EpisodeItemsProcessed
+	job.Summary["episode_files_deleted"] = report.EpisodeFilesDeleted
+	if report.EpisodesUnmonitored > 0 {
+		job.Summary["episodes_unmonitored"] = report.EpisodesUnmonitored
+	}
+	job.Summary["protected_count"] = report.Protected
+	job.Summary["failed_count"] = report.Failed
+	if len(report.DeletedItems) > 0 {
+		job.Summary["deleted_items"] = report.DeletedItems
+	}
+	job.Results = report.Items
+
+	var err error
+	if report.Failed > 0 {
+		err = fmt.Errorf("%d of %d item(s) failed", report.Failed, len(candidates))
+	}
+	finished := run.finish(err)
+
+	log.Info().
+		Str("job_id", finished.ID).
+		Interface("trigger", finished.Summary["trigger"]).
+		Str("status", string(finished.Status)).
+		Int("deleted", report.Deleted).
+		Int("episode_files_deleted", report.EpisodeFilesDeleted).
+		Int("protected", report.Protected).
+		Int("failed", report.Failed).
+		Int64("duration_ms", finished.DurationMs).
+		Msg("Deletion job completed")
+
+	return finished, report
+}
+
+// startDeletionJobLocked queues a deletion job and runs it right away. The
+// caller must hold syncRunMu.
+func (e *SyncEngine) startDeletionJobLocked(ctx context.Context, job storage.Job, candidates []map[string]interface{}) (storage.Job, DeletionReport) {
+	run, _ := e.enqueueJob(ctx, job)
+	return e.runDeletionJob(run, candidates)
+}
+
+// StartDeletionJob queues a manual deletion job and returns it immediately.
+// In the background the job waits for any running sync, then deletes whatever
+// is overdue at that point; poll the job for progress and per-item results.
+// As an explicit admin action it ignores deletion blackout windows, but not
+// approval mode: then it returns ErrApprovalRequired and deletes nothing.
+func (e *SyncEngine) StartDeletionJob() (storage.Job, error) {
+	if e.approvalModeEnabled() {
+		return storage.Job{}, ErrApprovalRequired
+	}
+
+	run, err := e.enqueueJob(context.Background(), newDeletionJob(DeletionTriggerManual))
+	if err != nil {
+		run.finish(err)
+		return storage.Job{}, fmt.Errorf("creating deletion job: %w", err)
+	}
+
+	queued := run.snapshot()
+	goRecover(func() {
+		if err := e.lockSyncRun(run.ctx); err != nil {
+			run.finish(err)
+			return
+		}
+		defer e.syncRunMu.Unlock()
+
+		_, candidates := e.CalculateDeletionInfo()
+		e.runDeletionJob(run, candidates)
+	})
+
+	return queued, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jobsOfType returns the recorded jobs of one type, most recent first
+func jobsOfType(jobs *storage.JobsFile, jobType storage.JobType) []storage.Job {
+	var out []storage.Job
+	for _, job := range jobs.GetAll() {
+		if job.Type == jobType {
+			out = append(out, job)
+		}
+	}
+	return out
+}
+
+func TestSyncEngine_FullSync_RunsDeletionAsSeparateJob(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+
+	require.NoError(t, engine.FullSync(context.Background()))
+	assert.Zero(t, engine.GetMediaCount())
+
+	syncJobs := jobsOfType(jobs, storage.JobTypeFullSync)
+	deletionJobs := jobsOfType(jobs, storage.JobTypeDeletion)
+	require.Len(t, syncJobs, 1)
+	require.Len(t, deletionJobs, 1)
+
+	syncJob, deletionJob := syncJobs[0], deletionJobs[0]
+	assert.Equal(t, deletionJob.ID, syncJob.Summary["deletion_job_id"])
+	assert.Nil(t, syncJob.Summary["deleted_count"], "deletion accounting lives on the deletion job")
+
+	assert.Equal(t, storage.JobStatusCompleted, deletionJob.Status)
+	assert.Equal(t, DeletionTriggerSync, deletionJob.Summary["trigger"])
+	assert.Equal(t, syncJob.ID, deletionJob.Summary["sync_job_id"])
+	assert.Equal(t, 2, deletionJob.Summary["deleted_count"])
+	require.Len(t, deletionJob.Results, 2)
+	for _, result := range deletionJob.Results {
+		assert.Equal(t, storage.DeletionOutcomeDeleted, result.Outcome)
+	}
+	assert.False(t, engine.GetStatus().LastDeletion.IsZero())
+}
+
+func TestSyncEngine_StartDeletionJob(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+	engine.ReapplyRetentionRules()
+
+	// Hold the sync lock so the job stays queued
+	engine.syncRunMu.Lock()
+	job, err := engine.StartDeletionJob()
+	require.NoError(t, err)
+	assert.Equal(t, storage.JobStatusPending, job.Status)
+
+	queued, found := jobs.Get(job.ID)
+	require.True(t, found)
+	assert.Equal(t, storage.JobStatusPending, queued.Status)
+	assert.Equal(t, 2, engine.GetMediaCount())
+	engine.syncRunMu.Unlock()
+
+	require.Eventually(t, func() bool {
+		current, _ := jobs.Get(job.ID)
+		return current.CompletedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	done, _ := jobs.Get(job.ID)
+	assert.Equal(t, storage.JobStatusCompleted, done.Status)
+	assert.Equal(t, DeletionTriggerManual, done.Summary["trigger"])
+	assert.Len(t, done.Results, 2)
+	assert.Zero(t, engine.GetMediaCount())
+}
+
+func TestSyncEngine_StartDeletionJob_ApprovalRequired(t *testing.T) {
+	engine, jobs, _, batches := newApprovalTestEngine(t)
+	engine.ReapplyRetentionRules()
+
+	_, err := engine.StartDeletionJob()
+	assert.ErrorIs(t, err, ErrApprovalRequired)
+	assert.Empty(t, jobsOfType(jobs, storage.JobTypeDeletion))
+	assert.Empty(t, batches.GetAll())
+	assert.Equal(t, 2, engine.GetMediaCount(), "nothing is deleted without an approved batch")
+}
+
+func TestSyncEngine_RunDeletionJob_RecordsFailures(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+
+	candidates := []map[string]interface{}{
+		deletionCandidate(engine.mediaLibrary["movie-1"], time.Now()),
+		{"id": "movie-404", "title": "Gone"},
+	}
+	engine.syncRunMu.Lock()
+	job, report := engine.startDeletionJobLocked(context.Background(), newDeletionJob(DeletionTriggerManual), candidates)
+	engine.syncRunMu.Unlock()
+
+	assert.Equal(t, 1, report.Deleted)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, storage.JobStatusFailed, job.Status)
+	assert.Equal(t, "1 of 2 item(s) failed", job.Error)
+
+	stored, found := jobs.Get(job.ID)
+	require.True(t, found)
+	require.Len(t, stored.Results, 2)
+	assert.Equal(t, storage.DeletionOutcomeDeleted, stored.Results[0].Outcome)
+	assert.Equal(t, storage.DeletionOutcomeFailed, stored.Results[1].Outcome)
+	assert.Equal(t, "media not found in library", stored.Results[1].Message)
+
+	require.Len(t, stored.Phases, 1)
+	assert.Equal(t, PhaseDeletions, stored.Phases[0].Name)
+	assert.Equal(t, 2, stored.Phases[0].Total)
+	assert.Equal(t, 2, stored.Phases[0].Processed)
+}
+
+func TestSyncEngine_RunDeletionJob_UnmonitorsAndRemonitors(t *testing.T) {
+	engine, jobs, exclusions := newTestSyncEngine(t)
+	cfg := config.Get()
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "trim", Type: "episode", Enabled: true, MaxEpisodes: 2, UnmonitorDeleted: true, UnmonitorPastSeasons: true},
+	}
+	engine.rules = rules.NewRulesEngine(exclusions, nil)
+
+	var monitorCalls []clients.SonarrEpisodesMonitoredRequest
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var body clients.SonarrEpisodesMonitoredRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			monitorCalls = append(monitorCalls, body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sonarrServer.Close()
+	engine.sonarrClient = clients.NewSonarrClient(config.SonarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: sonarrServer.URL, APIKey: "key"},
+	})
+
+	now := time.Now()
+	engine.mediaLibrary["sonarr-1"] = models.Media{
+		ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Show", SonarrID: 1, AddedAt: now,
+		Episodes: []models.Episode{
+			// S1E2 has no file but is still monitored: past seasons unmonitor it too
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -40)},
+			{ID: 2, SeasonNumber: 1, EpisodeNumber: 2, Monitored: true, AirDate: now.AddDate(0, 0, -35)},
+			{ID: 3, EpisodeFileID: 103, SeasonNumber: 2, EpisodeNumber: 1, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -30)},
+			{ID: 4, EpisodeFileID: 104, SeasonNumber: 2, EpisodeNumber: 2, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -20)},
+			{ID: 5, EpisodeFileID: 105, SeasonNumber: 2, EpisodeNumber: 3, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -10)},
+		},
+	}
+
+	candidates := []map[string]interface{}{{"id": "sonarr-1", "title": "Show", "type": models.MediaTypeTVShow}}
+	engine.syncRunMu.Lock()
+	job, report := engine.startDeletionJobLocked(context.Background(), newDeletionJob(DeletionTriggerManual), candidates)
+	engine.syncRunMu.Unlock()
+
+	assert.Equal(t, 2, report.EpisodeFilesDeleted)
+	assert.Equal(t, 3, job.Summary["episodes_unmonitored"])
+	require.Len(t, job.Results, 1)
+	assert.Equal(t, []int{1, 2, 3}, job.Results[0].EpisodesUnmonitored)
+	require.Len(t, monitorCalls, 1)
+	assert.False(t, monitorCalls[0].Monitored)
+
+	show, _ := engine.GetMediaByID("sonarr-1")
+	assert.False(t, show.Episodes[1].Monitored)
+	assert.True(t, show.Episodes[3].Monitored)
+
+	remonitored, err := engine.RemonitorJobEpisodes(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, remonitored)
+	require.Len(t, monitorCalls, 2)
+	assert.Equal(t, clients.SonarrEpisodesMonitoredRequest{EpisodeIDs: []int{1, 2, 3}, Monitored: true}, monitorCalls[1])
+
+	show, _ = engine.GetMediaByID("sonarr-1")
+	assert.True(t, show.Episodes[1].Monitored)
+	assert.False(t, show.Episodes[0].HasFile, "re-monitoring does not restore deleted files")
+
+	stored, _ := jobs.Get(job.ID)
+	assert.True(t, stored.Results[0].Remonitored)
+	assert.Equal(t, 3, stored.Summary["episodes_remonitored"])
+
+	_, err = engine.RemonitorJobEpisodes(context.Background(), job.ID)
+	assert.ErrorIs(t, err, ErrNothingToRemonitor)
+}
+
+func TestSyncEngine_RunDeletionJob_KeepsAllEpisodeFailures(t *testing.T) {
+	engine, _, exclusions := newTestSyncEngine(t)
+	cfg := config.Get()
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "trim", Type: "episode", Enabled: true, MaxEpisodes: 1, UnmonitorDeleted: true},
+	}
+	engine.rules = rules.NewRulesEngine(exclusions, nil)
+
+	// Deleting file 102 and every monitoring update fail
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut || r.URL.Path == "/api/v3/episodefile/102" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sonarrServer.Close()
+	engine.sonarrClient = clients.NewSonarrClient(config.SonarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: sonarrServer.URL, APIKey: "key"},
+	})
+
+	now := time.Now()
+	engine.mediaLibrary["sonarr-1"] = models.Media{
+		ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Show", SonarrID: 1, AddedAt: now,
+		Episodes: []models.Episode{
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -30)},
+			{ID: 2, EpisodeFileID: 102, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -20)},
+			{ID: 3, EpisodeFileID: 103, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, Monitored: true, AirDate: now.AddDate(0, 0, -10)},
+		},
+	}
+
+	candidates := []map[string]interface{}{{"id": "sonarr-1", "title": "Show", "type": models.MediaTypeTVShow}}
+	engine.syncRunMu.Lock()
+	job, report := engine.startDeletionJobLocked(context.Background(), newDeletionJob(DeletionTriggerManual), candidates)
+	engine.syncRunMu.Unlock()
+
+	assert.Equal(t, 1, report.EpisodeFilesDeleted)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, job.Results, 1)
+	assert.Equal(t, storage.DeletionOutcomeFailed, job.Results[0].Outcome)
+	assert.Contains(t, job.Results[0].Message, "unmonitoring failed: ")
+	assert.Contains(t, job.Results[0].Message, "1 episode file deletion(s) failed")
+}
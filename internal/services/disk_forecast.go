@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+const (
+	// forecastLookback bounds how far back readings are used for the growth
+	// trend, so an old one-off import doesn't dominate today's growth rate.
+	forecastLookback = 30 * 24 * time.Hour
+
+	// forecastMinSpan is the minimum time between the first and last reading
+	// before a trend is fitted; shorter spans are too noisy to extrapolate.
+	forecastMinSpan = time.Hour
+
+	bytesPerGB = 1024 * 1024 * 1024
+)
+
+// ErrDiskForecastUnavailable is returned when no disk monitor is configured
+// or it has not recorded a reading yet.
+var ErrDiskForecastUnavailable = errors.New("disk forecast unavailable: disk threshold monitoring is disabled or has no readings yet")
+
+// DiskForecastPoint is the projected disk state at the end of one day.
+type DiskForecastPoint struct {
+	Date                      string  `json:"date"` // YYYY-MM-DD
+	ProjectedFreeGB           float64 `json:"projected_free_gb"`
+	ProjectedFreeNoDeletionGB float64 `json:"projected_free_without_deletions_gb"`
+	ReclaimedGB               float64 `json:"reclaimed_gb"` // cumulative, from scheduled deletions
+	BelowThreshold            bool    `json:"below_threshold"`
+	// ProjectedFreeIfApprovedGB is the free space if every scheduled deletion
+	// is approved as it comes due; only set in approval mode.
+	ProjectedFreeIfApprovedGB *float64 `json:"projected_free_if_approved_gb,omitempty"`
+}
+
+// DiskForecast is the response of GET /api/system/disk/forecast.
+type DiskForecast struct {
+	GeneratedAt      time.Time           `json:"generated_at"`
+	Days             int                 `json:"days"`
+	CheckSource      string              `json:"check_source"`
+	CurrentFreeGB    float64             `json:"current_free_gb"`
+	TotalSpaceGB     float64             `json:"total_space_gb"`
+	ThresholdGB      int                 `json:"threshold_gb"`
+	GrowthGBPerDay   float64             `json:"growth_gb_per_day"`
+	TrendAvailable   bool                `json:"trend_available"`
+	SampleCount      int                 `json:"sample_count"`
+	DeletionsEnabled bool                `json:"deletions_enabled"`
+	ApprovalRequired bool                `json:"approval_required"`
+	BreachDate       *string             `json:"breach_date,omitempty"`
+	DaysUntilBreach  *int                `json:"days_until_breach,omitempty"`
+	Milestones       map[string]float64  `json:"milestones"` // "30"/"60"/"90" -> projected free GB
+	Points           []DiskForecastPoint `json:"points"`
+}
+
+// forecastReclaim is how scheduled deletions free space in a forecast.
+type forecastReclaim int
+
+const (
+	// reclaimNone: deletion is disabled or dry run is on
+	reclaimNone forecastReclaim = iota
+	// reclaimAutomatic: due items are deleted by the deletion passes
+	reclaimAutomatic
+	// reclaimOnApproval: due items wait for a deletion batch to be approved,
+	// so nothing is reclaimed automatically
+	reclaimOnApproval
+)
+
+// scheduledReclaim is one entry of the deletion timeline used by the forecast.
+type scheduledReclaim struct {
+	At    time.Time
+	Bytes int64
+}
+
+// ForecastDisk projects free disk space for the next `days` days by fitting a
+// linear growth trend to the recorded disk readings, then adding back the
+// space reclaimed by the current deletion schedule as each item comes due
+// (deferred to the end of any deletion blackout it falls in). In approval
+// mode nothing is deleted until a batch is approved, so that reclaim is
+// reported as a separate series instead.
+func (e *SyncEngine) ForecastDisk(days int) (*DiskForecast, error) {
+	if e.diskMonitor == nil {
+		return nil, ErrDiskForecastUnavailable
+	}
+	status := e.diskMonitor.GetStatus()
+	if status == nil {
+		return nil, ErrDiskForecastUnavailable
+	}
+
+	now := time.Now()
+	var samples []storage.DiskSample
+	if history := e.diskMonitor.GetHistory(); history != nil {
+		samples = history.Since(now.Add(-forecastLookback))
+	}
+	if len(samples) == 0 {
+		return nil, ErrDiskForecastUnavailable
+	}
+
+	cfg := config.Get()
+	reclaim := reclaimNone
+	switch {
+	case !cfg.App.EnableDeletion || cfg.App.DryRun:
+	case cfg.App.Approval.Enabled:
+		reclaim = reclaimOnApproval
+	default:
+		reclaim = reclaimAutomatic
+	}
+
+	timeline := deletionTimeline(e.GetMediaList(), deletionBlackouts())
+	forecast := buildDiskForecast(samples, timeline, status.ThresholdGB, days, now, reclaim)
+	forecast.CheckSource = status.CheckSource
+	return forecast, nil
+}
+
+// deletionTimeline returns the reclaimable bytes per scheduled deletion.
+// Deletions due inside a blackout window happen when the window ends.
+// Episode-level verdicts are skipped: the show's FileSize covers every episode,
+// not just the files the rule targets, so counting it would overstate reclaim.
+func deletionTimeline(media []models.Media, blackouts []utils.TimeWindow) []scheduledReclaim {
+	timeline := make([]scheduledReclaim, 0)
+	for _, m := range media {
+		if m.IsExcluded || m.DeleteAfter.IsZero() || len(m.EpisodeFileIDs) > 0 || m.FileSize <= 0 {
+			continue
+		}
+		timeline = append(timeline, scheduledReclaim{At: utils.NextOutside(blackouts, m.DeleteAfter), Bytes: m.FileSize})
+	}
+	return timeline
+}
+
+// buildDiskForecast is the pure projection behind ForecastDisk.
+// samples must be ordered oldest first; the last sample is the current state.
+func buildDiskForecast(samples []storage.DiskSample, timeline []scheduledReclaim, thresholdGB, days int, now time.Time, reclaim forecastReclaim) *DiskForecast {
+	latest := samples[len(samples)-1]
+	growthPerDay, trendOK := fitUsageGrowth(samples)
+
+	forecast := &DiskForecast{
+		GeneratedAt:      now,
+		Days:             days,
+		CurrentFreeGB:    roundGB(float64(latest.FreeBytes)),
+		TotalSpaceGB:     roundGB(float64(latest.TotalBytes)),
+		ThresholdGB:      thresholdGB,
+		GrowthGBPerDay:   roundGB(growthPerDay),
+		TrendAvailable:   trendOK,
+		SampleCount:      len(samples),
+		DeletionsEnabled: reclaim != reclaimNone,
+		ApprovalRequired: reclaim == reclaimOnApproval,
+		Milestones:       make(map[string]float64),
+		Points:           make([]DiskForecastPoint, 0, days),
+	}
+
+	thresholdBytes := float64(thresholdGB) * bytesPerGB
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	for d := 1; d <= days; d++ {
+		dayEnd := startOfToday.AddDate(0, 0, d)
+		elapsedDays := dayEnd.Sub(now).Hours() / 24
+
+		var reclaimed int64
+		for _, item := range timeline {
+			if !item.At.After(dayEnd) {
+				reclaimed += item.Bytes
+			}
+		}
+
+		noDeletion := float64(latest.FreeBytes) - growthPerDay*elapsedDays
+		withDeletions := math.Min(noDeletion+float64(reclaimed), float64(latest.TotalBytes))
+		noDeletion = math.Min(noDeletion, float64(latest.TotalBytes))
+		projected := noDeletion
+		if reclaim == reclaimAutomatic {
+			projected = withDeletions
+		}
+
+		point := DiskForecastPoint{
+			Date:                      dayEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+			ProjectedFreeGB:           roundGB(math.Max(projected, 0)),
+			ProjectedFreeNoDeletionGB: roundGB(math.Max(noDeletion, 0)),
+			ReclaimedGB:               roundGB(float64(reclaimed)),
+			BelowThreshold:            thresholdGB > 0 && projected < thresholdBytes,
+		}
+		if reclaim == reclaimOnApproval {
+			ifApproved := roundGB(math.Max(withDeletions, 0))
+			point.ProjectedFreeIfApprovedGB = &ifApproved
+		}
+		forecast.Points = append(forecast.Points, point)
+
+		if point.BelowThreshold && forecast.BreachDate == nil {
+			date := point.Date
+			daysUntil := d
+			forecast.BreachDate = &date
+			forecast.DaysUntilBreach = &daysUntil
+		}
+
+		switch d {
+		case 30, 60, 90:
+			forecast.Milestones[strconv.Itoa(d)] = point.ProjectedFreeGB
+		}
+	}
+
+	return forecast
+}
+
+// fitUsageGrowth fits a least-squares line to used space over time and returns
+// the growth in bytes per day. Negative growth (usage shrinking) is clamped to
+// zero: past deletions are already reflected in the readings and must not be
+// extrapolated as future free space on top of the deletion schedule.
+func fitUsageGrowth(samples []storage.DiskSample) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first := samples[0].Timestamp
+	if samples[len(samples)-1].Timestamp.Sub(first) < forecastMinSpan {
+		return 0, false
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(samples))
+	for _, s := range samples {
+		x := s.Timestamp.Sub(first).Hours() / 24
+		y := float64(s.TotalBytes - s.FreeBytes)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	if slope < 0 {
+		slope = 0
+	}
+	return slope, true
+}
+
+// roundGB converts bytes to GB rounded to two decimals.
+func roundGB(bytes float64) float64 {
+	return math.Round(bytes/bytesPerGB*100) / 100
+}
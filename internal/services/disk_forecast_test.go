@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gb = int64(bytesPerGB)
+
+func TestFitUsageGrowth(t *testing.T) {
+	now := time.Now()
+
+	t.Run("steady growth", func(t *testing.T) {
+		samples := []storage.DiskSample{
+			{Timestamp: now.Add(-48 * time.Hour), FreeBytes: 100 * gb, TotalBytes: 1000 * gb},
+			{Timestamp: now.Add(-24 * time.Hour), FreeBytes: 98 * gb, TotalBytes: 1000 * gb},
+			{Timestamp: now, FreeBytes: 96 * gb, TotalBytes: 1000 * gb},
+		}
+		growth, ok := fitUsageGrowth(samples)
+		require.True(t, ok)
+		assert.InDelta(t, float64(2*gb), growth, float64(gb)/100)
+	})
+
+	t.Run("shrinking usage clamps to zero", func(t *testing.T) {
+		samples := []storage.DiskSample{
+			{Timestamp: now.Add(-24 * time.Hour), FreeBytes: 50 * gb, TotalBytes: 1000 * gb},
+			{Timestamp: now, FreeBytes: 80 * gb, TotalBytes: 1000 * gb},
+		}
+		growth, ok := fitUsageGrowth(samples)
+		require.True(t, ok)
+		assert.Equal(t, 0.0, growth)
+	})
+
+	t.Run("span too short", func(t *testing.T) {
+		samples := []storage.DiskSample{
+			{Timestamp: now.Add(-time.Minute), FreeBytes: 100 * gb, TotalBytes: 1000 * gb},
+			{Timestamp: now, FreeBytes: 90 * gb, TotalBytes: 1000 * gb},
+		}
+		_, ok := fitUsageGrowth(samples)
+		assert.False(t, ok)
+	})
+
+	t.Run("single sample", func(t *testing.T) {
+		_, ok := fitUsageGrowth([]storage.DiskSample{{Timestamp: now, FreeBytes: 100 * gb, TotalBytes: 1000 * gb}})
+		assert.False(t, ok)
+	})
+}
+
+func TestBuildDiskForecast_BreachWithoutDeletions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	samples := []storage.DiskSample{
+		{Timestamp: now.Add(-10 * 24 * time.Hour), FreeBytes: 120 * gb, TotalBytes: 1000 * gb},
+		{Timestamp: now, FreeBytes: 100 * gb, TotalBytes: 1000 * gb},
+	}
+
+	forecast := buildDiskForecast(samples, nil, 50, 90, now, reclaimAutomatic)
+
+	assert.True(t, forecast.TrendAvailable)
+	assert.InDelta(t, 2.0, forecast.GrowthGBPerDay, 0.01)
+	assert.Len(t, forecast.Points, 90)
+	require.NotNil(t, forecast.BreachDate)
+	require.NotNil(t, forecast.DaysUntilBreach)
+	// 100GB free, 2GB/day growth: dips below 50GB during day 26 (25.5 days from noon)
+	assert.Equal(t, 26, *forecast.DaysUntilBreach)
+	assert.Equal(t, "2026-01-26", *forecast.BreachDate)
+	assert.Contains(t, forecast.Milestones, "30")
+	assert.Contains(t, forecast.Milestones, "60")
+	assert.Contains(t, forecast.Milestones, "90")
+	assert.Equal(t, 0.0, forecast.Milestones["90"], "projection must not go negative")
+}
+
+func TestBuildDiskForecast_DeletionsPostponeBreach(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	samples := []storage.DiskSample{
+		{Timestamp: now.Add(-10 * 24 * time.Hour), FreeBytes: 120 * gb, TotalBytes: 1000 * gb},
+		{Timestamp: now, FreeBytes: 100 * gb, TotalBytes: 1000 * gb},
+	}
+	timeline := []scheduledReclaim{
+		{At: now.Add(5 * 24 * time.Hour), Bytes: 200 * gb},
+	}
+
+	forecast := buildDiskForecast(samples, timeline, 50, 30, now, reclaimAutomatic)
+	assert.Nil(t, forecast.BreachDate, "reclaimed space should keep disk above threshold for 30 days")
+	assert.InDelta(t, 200.0, forecast.Points[29].ReclaimedGB, 0.01)
+	assert.Greater(t, forecast.Points[29].ProjectedFreeGB, forecast.Points[29].ProjectedFreeNoDeletionGB)
+
+	// Dry run / deletion disabled: schedule reported but not applied
+	disabled := buildDiskForecast(samples, timeline, 50, 30, now, reclaimNone)
+	require.NotNil(t, disabled.BreachDate)
+	assert.Equal(t, disabled.Points[29].ProjectedFreeNoDeletionGB, disabled.Points[29].ProjectedFreeGB)
+	assert.Nil(t, disabled.Points[29].ProjectedFreeIfApprovedGB)
+}
+
+func TestBuildDiskForecast_ApprovalModeReclaimsNothingAutomatically(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	samples := []storage.DiskSample{
+		{Timestamp: now.Add(-10 * 24 * time.Hour), FreeBytes: 120 * gb, TotalBytes: 1000 * gb},
+		{Timestamp: now, FreeBytes: 100 * gb, TotalBytes: 1000 * gb},
+	}
+	timeline := []scheduledReclaim{
+		{At: now.Add(5 * 24 * time.Hour), Bytes: 200 * gb},
+	}
+
+	forecast := buildDiskForecast(samples, timeline, 50, 30, now, reclaimOnApproval)
+	assert.True(t, forecast.DeletionsEnabled)
+	assert.True(t, forecast.ApprovalRequired)
+
+	// Nothing is deleted until a batch is approved, so the breach stays
+	require.NotNil(t, forecast.BreachDate)
+	last := forecast.Points[29]
+	assert.Equal(t, last.ProjectedFreeNoDeletionGB, last.ProjectedFreeGB)
+	assert.InDelta(t, 200.0, last.ReclaimedGB, 0.01)
+
+	// The approved reclaim is reported separately
+	require.NotNil(t, last.ProjectedFreeIfApprovedGB)
+	assert.InDelta(t, last.ProjectedFreeNoDeletionGB+200, *last.ProjectedFreeIfApprovedGB, 0.01)
+}
+
+func TestDeletionTimeline_SkipsNonReclaimable(t *testing.T) {
+	due := time.Now().Add(24 * time.Hour)
+	media := []models.Media{
+		{ID: "a", FileSize: 10 * gb, DeleteAfter: due},
+		{ID: "excluded", FileSize: 10 * gb, DeleteAfter: due, IsExcluded: true},
+		{ID: "unscheduled", FileSize: 10 * gb},
+		{ID: "episodes", FileSize: 10 * gb, DeleteAfter: due, EpisodeFileIDs: []int{1}},
+		{ID: "empty", DeleteAfter: due},
+	}
+
+	timeline := deletionTimeline(media, nil)
+	require.Len(t, timeline, 1)
+	assert.Equal(t, 10*gb, timeline[0].Bytes)
+}
+
+func TestDeletionTimeline_DefersBlackouts(t *testing.T) {
+	window, err := utils.ParseTimeWindow("fri 18:00", "sun 23:00")
+	require.NoError(t, err)
+	saturday := time.Date(2026, 1, 3, 12, 0, 0, 0, time.Local)
+	monday := time.Date(2026, 1, 5, 12, 0, 0, 0, time.Local)
+
+	timeline := deletionTimeline([]models.Media{
+		{ID: "weekend", FileSize: gb, DeleteAfter: saturday},
+		{ID: "weekday", FileSize: gb, DeleteAfter: monday},
+	}, []utils.TimeWindow{window})
+
+	require.Len(t, timeline, 2)
+	assert.Equal(t, time.Date(2026, 1, 4, 23, 0, 0, 0, time.Local), timeline[0].At)
+	assert.Equal(t, monday, timeline[1].At)
+}
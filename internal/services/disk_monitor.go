@@ -4,18 +4,22 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
 	"github.com/rs/zerolog/log"
 )
 
 // DiskMonitor fetches and caches disk space from Radarr/Sonarr.
 // It satisfies the rules.DiskMonitor interface.
 type DiskMonitor struct {
-	radarr *clients.RadarrClient
-	sonarr *clients.SonarrClient
+	radarr  *clients.RadarrClient
+	sonarr  *clients.SonarrClient
+	history *storage.DiskHistoryFile // nil = readings are not recorded
+	events  *EventBus                // nil = transitions are not published
 
 	mu              sync.RWMutex
 	freeSpaceGB     int
@@ -36,6 +40,29 @@ func NewDiskMonitor(radarr *clients.RadarrClient, sonarr *clients.SonarrClient)
 	}
 }
 
+// SetHistory injects the store that Update records each reading into.
+// Called by SyncEngine before the first sync; the recorded time series feeds
+// the disk usage forecast.
+func (m *DiskMonitor) SetHistory(history *storage.DiskHistoryFile) {
+	m.mu.Lock()
+	m.history = history
+	m.mu.Unlock()
+}
+
+// SetEvents injects the bus that threshold transitions are published on.
+func (m *DiskMonitor) SetEvents(events *EventBus) {
+	m.mu.Lock()
+	m.events = events
+	m.mu.Unlock()
+}
+
+// GetHistory returns the disk history store (may be nil if not configured).
+func (m *DiskMonitor) GetHistory() *storage.DiskHistoryFile {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.history
+}
+
 // Update fetches the latest disk space and updates cached state.
 // Called once at the start of each FullSync. Failures are non-fatal —
 // the last known state is retained and a warning is logged.
@@ -66,8 +93,39 @@ func (m *DiskMonitor) Update(ctx context.Context) error {
 	m.totalSpaceGB = totalGB
 	m.thresholdActive = breached
 	m.initialized = true
+	history := m.history
+	events := m.events
 	m.mu.Unlock()
 
+	// Record the raw reading for forecasting. Non-fatal: a failed write only
+	// costs one data point.
+	if history != nil {
+		sample := storage.DiskSample{
+			Timestamp:  time.Now(),
+			FreeBytes:  freeBytes,
+			TotalBytes: totalBytes,
+			Source:     source,
+		}
+		if err := history.Add(sample); err != nil {
+			log.Warn().Err(err).Msg("Failed to record disk history sample")
+		}
+	}
+
+	// Publish transitions; the initial reading counts as one only when breached
+	if breached != prevBreached {
+		state := "breached"
+		if !breached {
+			state = "recovered"
+		}
+		events.Publish(EventDiskThreshold, map[string]any{
+			"state":        state,
+			"free_gb":      freeGB,
+			"total_gb":     totalGB,
+			"threshold_gb": cfg.App.DiskThreshold.FreeSpaceGB,
+			"source":       source,
+		})
+	}
+
 	// Log state transitions
 	if !prevInitialized {
 		if breached {
@@ -35,8 +35,10 @@ func buildDiskSpaceServerAtomic(t *testing.T) (*httptest.Server, func([]clients.
 		case "/api/v3/diskspace":
 			json.NewEncoder(w).Encode(current.Load().([]clients.DiskSpace))
 		case "/api/v3/movie":
-			// Return empty movie list so FullSync can complete without errors.
-			json.NewEncoder(w).Encode([]interface{}{})
+			// A single overdue movie; FullSync rebuilds the library from Radarr.
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 1, "title": "Old Movie", "hasFile": true, "added": timeAgo(120)},
+			})
 		default:
 			http.NotFound(w, r)
 		}
@@ -319,18 +321,11 @@ func TestDiskThreshold_SyncEngine_DiskOK_MediaNotScheduled(t *testing.T) {
 	cfg := diskThresholdCfg(500)
 	syncEngine := newDiskThresholdSyncEngine(t, cfg, srv.URL)
 
-	// Seed an overdue movie directly into the library
-	syncEngine.mediaLibrary["movie-1"] = models.Media{
-		ID:      "movie-1",
-		Type:    models.MediaTypeMovie,
-		Title:   "Old Movie",
-		AddedAt: timeAgo(120),
-	}
-
+	// The Radarr test server serves one overdue movie
 	ctx := context.Background()
 	require.NoError(t, syncEngine.FullSync(ctx))
 
-	media, found := syncEngine.GetMediaByID("movie-1")
+	media, found := syncEngine.GetMediaByID("radarr-1")
 	require.True(t, found)
 	assert.True(t, media.DeleteAfter.IsZero(),
 		"disk OK: overdue movie should not be scheduled for deletion")
@@ -347,17 +342,10 @@ func TestDiskThreshold_SyncEngine_DiskBreached_MediaScheduled(t *testing.T) {
 	cfg := diskThresholdCfg(500)
 	syncEngine := newDiskThresholdSyncEngine(t, cfg, srv.URL)
 
-	syncEngine.mediaLibrary["movie-1"] = models.Media{
-		ID:      "movie-1",
-		Type:    models.MediaTypeMovie,
-		Title:   "Old Movie",
-		AddedAt: timeAgo(120),
-	}
-
 	ctx := context.Background()
 	require.NoError(t, syncEngine.FullSync(ctx))
 
-	media, found := syncEngine.GetMediaByID("movie-1")
+	media, found := syncEngine.GetMediaByID("radarr-1")
 	require.True(t, found)
 	assert.False(t, media.DeleteAfter.IsZero(),
 		"disk breached: overdue movie should be scheduled for deletion")
@@ -325,6 +325,10 @@ func TestDiskMonitor_StateChange_LogsRecovery(t *testing.T) {
 		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: srv.URL, APIKey: "test"},
 	})
 	m := NewDiskMonitor(radarrClient, nil)
+	bus := NewEventBus(0)
+	m.SetEvents(bus)
+	_, events, _, unsubscribe := bus.Subscribe(0)
+	defer unsubscribe()
 
 	// First update — below threshold (breach)
 	if err := m.Update(context.Background()); err != nil {
@@ -341,4 +345,13 @@ func TestDiskMonitor_StateChange_LogsRecovery(t *testing.T) {
 	if m.GetStatus().ThresholdBreached {
 		t.Error("expected threshold not breached after recovery")
 	}
+
+	// Both transitions are published
+	published := drainEvents(events)
+	if len(published) != 2 {
+		t.Fatalf("expected 2 disk threshold events, got %d", len(published))
+	}
+	if published[0].Data["state"] != "breached" || published[1].Data["state"] != "recovered" {
+		t.Errorf("unexpected transition states: %v, %v", published[0].Data["state"], published[1].Data["state"])
+	}
 }
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+// EventType identifies the kind of engine event, sent as the SSE event name
+type EventType string
+
+// Engine event types streamed by GET /api/events
+const (
+	EventSyncStarted      EventType = "sync.started"
+	EventSyncPhase        EventType = "sync.phase"
+	EventSyncCompleted    EventType = "sync.completed"
+	EventItemScheduled    EventType = "item.scheduled"
+	EventItemRescheduled  EventType = "item.rescheduled"
+	EventItemUnscheduled  EventType = "item.unscheduled"
+	EventItemLeavingSoon  EventType = "item.leaving_soon"
+	EventItemRemoved      EventType = "item.removed_externally"
+	EventExclusionAdded   EventType = "exclusion.added"
+	EventExclusionRemoved EventType = "exclusion.removed"
+	EventDeletionExecuted EventType = "deletion.executed"
+	EventDeletionFailed   EventType = "deletion.failed"
+	EventConfigReloaded   EventType = "config.reloaded"
+	EventDiskThreshold    EventType = "disk.threshold"
+	EventKeepRequested    EventType = "keep_request.submitted"
+	EventKeepDecided      EventType = "keep_request.decided"
+)
+
+const (
+	// DefaultEventReplaySize is how many recent events are kept for clients
+	// resuming with Last-Event-ID
+	DefaultEventReplaySize = 500
+
+	// subscriberBufferSize bounds the events queued for one subscriber. A
+	// subscriber that falls this far behind is dropped; it reconnects and
+	// catches up from the replay buffer.
+	subscriberBufferSize = 64
+)
+
+// Event is a single engine event. IDs increase by one per event and restart
+// at 1 when the process restarts.
+type Event struct {
+	ID   uint64         `json:"id"`
+	Type EventType      `json:"type"`
+	Time time.Time      `json:"time"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// EventBus fans engine events out to subscribers and keeps a bounded buffer
+// of recent events for replay
+type EventBus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []Event // ring buffer, oldest at start
+	start       int
+	size        int
+	subscribers map[chan Event]struct{}
+}
+
+// NewEventBus creates an event bus that keeps the last replaySize events
+func NewEventBus(replaySize int) *EventBus {
+	if replaySize <= 0 {
+		replaySize = DefaultEventReplaySize
+	}
+	return &EventBus{
+		buffer:      make([]Event, replaySize),
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish records an event and delivers it to all subscribers without
+// blocking. It is safe to call on a nil bus.
+func (b *EventBus) Publish(eventType EventType, data map[string]any) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Time: time.Now(), Data: data}
+
+	if b.size < len(b.buffer) {
+		b.buffer[(b.start+b.size)%len(b.buffer)] = event
+		b.size++
+	} else {
+		b.buffer[b.start] = event
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Too slow: drop it rather than block the engine
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events after
+// lastEventID (none when lastEventID is 0) followed by a channel of live
+// events. complete is false when events after lastEventID are no longer
+// buffered (or the ID is from before a restart), so the client should reload
+// its state instead of relying on the replay. The channel is closed when the
+// subscriber is dropped or unsubscribe is called.
+func (b *EventBus) Subscribe(lastEventID uint64) (replay []Event, events <-chan Event, complete bool, unsubscribe func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	complete = true
+	if lastEventID > 0 {
+		oldest := b.lastID + 1
+		if b.size > 0 {
+			oldest = b.buffer[b.start].ID
+		}
+		complete = lastEventID <= b.lastID && lastEventID+1 >= oldest
+		for i := 0; i < b.size; i++ {
+			event := b.buffer[(b.start+i)%len(b.buffer)]
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, complete, unsubscribe
+}
+
+// LastEventID returns the ID of the most recent event, or 0
+func (b *EventBus) LastEventID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// scheduleState is the part of a media item tracked for schedule events
+type scheduleState struct {
+	deleteAfter time.Time
+	leavingSoon bool
+}
+
+// Events returns the engine's event bus
+func (e *SyncEngine) Events() *EventBus {
+	return e.events
+}
+
+// publishScheduleChanges compares each item's deletion date and leaving-soon
+// state with what was last announced and publishes the differences. The first
+// call only records the baseline, so a restart does not replay the whole
+// library as newly scheduled.
+func (e *SyncEngine) publishScheduleChanges() {
+	leavingSoonDays := e.config.App.LeavingSoonDays
+	if cfg := config.Get(); cfg != nil {
+		leavingSoonDays = cfg.App.LeavingSoonDays
+	}
+
+	e.mediaLibraryLock.RLock()
+	current := make(map[string]scheduleState, len(e.mediaLibrary))
+	titles := make(map[string]string, len(e.mediaLibrary))
+	for id, media := range e.mediaLibrary {
+		current[id] = scheduleState{
+			deleteAfter: media.DeleteAfter,
+			leavingSoon: !media.IsExcluded && media.DaysUntilDue > 0 && media.DaysUntilDue <= leavingSoonDays,
+		}
+		titles[id] = media.Title
+	}
+	e.mediaLibraryLock.RUnlock()
+
+	e.announcedMu.Lock()
+	defer e.announcedMu.Unlock()
+
+	previous := e.announced
+	e.announced = current
+	if len(previous) == 0 {
+		return
+	}
+
+	for id, state := range current {
+		prev := previous[id]
+		data := map[string]any{"media_id": id, "title": titles[id]}
+		if !state.deleteAfter.IsZero() {
+			data["delete_after"] = state.deleteAfter
+		}
+
+		switch {
+		case state.deleteAfter.Equal(prev.deleteAfter):
+		case prev.deleteAfter.IsZero():
+			e.events.Publish(EventItemScheduled, data)
+		case state.deleteAfter.IsZero():
+			e.events.Publish(EventItemUnscheduled, data)
+		default:
+			data["previous_delete_after"] = prev.deleteAfter
+			e.events.Publish(EventItemRescheduled, data)
+		}
+
+		if state.leavingSoon && !prev.leavingSoon {
+			e.events.Publish(EventItemLeavingSoon, data)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainEvents returns the events already queued on ch
+func drainEvents(ch <-chan Event) []Event {
+	var events []Event
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestEventBus_Replay(t *testing.T) {
+	bus := NewEventBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(EventConfigReloaded, nil)
+	}
+	assert.Equal(t, uint64(5), bus.LastEventID())
+
+	t.Run("new subscriber gets no replay", func(t *testing.T) {
+		replay, _, complete, unsubscribe := bus.Subscribe(0)
+		defer unsubscribe()
+		assert.Empty(t, replay)
+		assert.True(t, complete)
+	})
+
+	t.Run("resumes after a buffered event", func(t *testing.T) {
+		replay, _, complete, unsubscribe := bus.Subscribe(3)
+		defer unsubscribe()
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint64(4), replay[0].ID)
+		assert.Equal(t, uint64(5), replay[1].ID)
+		assert.True(t, complete)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		replay, _, complete, unsubscribe := bus.Subscribe(5)
+		defer unsubscribe()
+		assert.Empty(t, replay)
+		assert.True(t, complete)
+	})
+
+	t.Run("evicted events are reported as incomplete", func(t *testing.T) {
+		replay, _, complete, unsubscribe := bus.Subscribe(1)
+		defer unsubscribe()
+		assert.Len(t, replay, 3, "everything still buffered is replayed")
+		assert.False(t, complete)
+	})
+
+	t.Run("ID from before a restart is incomplete", func(t *testing.T) {
+		replay, _, complete, unsubscribe := bus.Subscribe(42)
+		defer unsubscribe()
+		assert.Empty(t, replay)
+		assert.False(t, complete)
+	})
+}
+
+func TestEventBus_LiveAndSlowSubscribers(t *testing.T) {
+	bus := NewEventBus(0)
+
+	_, live, _, unsubscribe := bus.Subscribe(0)
+	bus.Publish(EventExclusionAdded, map[string]any{"media_id": "movie-1"})
+
+	event := <-live
+	assert.Equal(t, EventExclusionAdded, event.Type)
+	assert.Equal(t, "movie-1", event.Data["media_id"])
+
+	unsubscribe()
+	_, ok := <-live
+	assert.False(t, ok, "unsubscribe closes the channel")
+	unsubscribe() // idempotent
+
+	// A subscriber that never reads is dropped instead of blocking Publish
+	_, slow, _, unsubscribeSlow := bus.Subscribe(0)
+	defer unsubscribeSlow()
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		bus.Publish(EventConfigReloaded, nil)
+	}
+	assert.Len(t, drainEvents(slow), subscriberBufferSize)
+	_, ok = <-slow
+	assert.False(t, ok, "slow subscriber is closed")
+}
+
+func TestEventBus_NilIsNoop(t *testing.T) {
+	var bus *EventBus
+	assert.NotPanics(t, func() { bus.Publish(EventConfigReloaded, nil) })
+}
+
+func TestSyncEngine_PublishesEvents(t *testing.T) {
+	engine, _, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+	engine.config.App.LeavingSoonDays = 14
+	ctx := context.Background()
+
+	_, events, _, unsubscribe := engine.Events().Subscribe(0)
+	defer unsubscribe()
+
+	t.Run("full sync lifecycle and deletions", func(t *testing.T) {
+		require.NoError(t, engine.FullSync(ctx))
+
+		got := drainEvents(events)
+		assert.Equal(t, []EventType{
+			EventSyncStarted,
+			EventSyncPhase,
+			EventSyncCompleted,
+			EventDeletionExecuted,
+			EventDeletionExecuted,
+		}, eventTypes(got))
+		assert.Equal(t, PhaseRules, got[1].Data["phase"])
+		assert.Equal(t, storage.JobStatusCompleted, got[2].Data["status"])
+		assert.NotEmpty(t, got[3].Data["job_id"])
+	})
+
+	t.Run("schedule changes after the baseline", func(t *testing.T) {
+		added := time.Now().AddDate(0, 0, -80)
+		engine.mediaLibrary["movie-3"] = models.Media{ID: "movie-3", Type: models.MediaTypeMovie, Title: "Movie Three", AddedAt: added}
+		engine.ReapplyRetentionRules()
+
+		got := drainEvents(events)
+		assert.Equal(t, []EventType{EventItemScheduled, EventItemLeavingSoon}, eventTypes(got))
+		assert.Equal(t, "movie-3", got[0].Data["media_id"])
+		assert.NotNil(t, got[0].Data["delete_after"])
+
+		media := engine.mediaLibrary["movie-3"]
+		media.AddedAt = added.AddDate(0, 0, 2)
+		engine.mediaLibrary["movie-3"] = media
+		engine.ReapplyRetentionRules()
+
+		got = drainEvents(events)
+		assert.Equal(t, []EventType{EventItemRescheduled}, eventTypes(got))
+		assert.NotNil(t, got[0].Data["previous_delete_after"])
+	})
+
+	t.Run("exclusions", func(t *testing.T) {
+		require.NoError(t, engine.AddExclusion(ctx, "movie-3", "keep", ""))
+		require.NoError(t, engine.RemoveExclusion(ctx, "movie-3"))
+
+		got := drainEvents(events)
+		assert.Equal(t, []EventType{EventExclusionAdded, EventExclusionRemoved}, eventTypes(got))
+		assert.Equal(t, "keep", got[0].Data["reason"])
+	})
+}
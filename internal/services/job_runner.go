@@ -0,0 +1,363 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/metrics"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Job phases, recorded in Job.Phases in the order they started. A full sync's
+// fetch phases run concurrently.
+const (
+	PhaseRadarr     = "radarr"
+	PhaseSonarr     = "sonarr"
+	PhaseJellyfin   = "jellyfin"
+	PhaseStats      = "stats"
+	PhaseJellyseerr = "jellyseerr"
+	PhaseRules      = "rules"
+	PhaseDeletions  = "deletions"
+)
+
+// jobProgressSaveInterval bounds how often progress counters are written to
+// the jobs file while a phase is running. Phase starts and ends always save.
+const jobProgressSaveInterval = time.Second
+
+// syncLockPollInterval is how often a queued job retries the sync lock
+const syncLockPollInterval = 50 * time.Millisecond
+
+var (
+	// ErrJobNotFound is returned when cancelling a job ID that does not exist
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotActive is returned when cancelling a job that already finished
+	ErrJobNotActive = errors.New("job is not queued or running")
+	// ErrNothingToRemonitor is returned when a job has no unmonitored
+	// episodes left to monitor again
+	ErrNothingToRemonitor = errors.New("job has no unmonitored episodes to re-monitor")
+)
+
+// jobRun tracks a queued or running job: its record, phase progress and the
+// cancel function behind POST /api/jobs/{id}/cancel. The goroutine running the
+// job owns the record; the mutex only guards reads from other goroutines.
+type jobRun struct {
+	engine *SyncEngine
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	job      storage.Job
+	lastSave time.Time
+}
+
+type jobRunKey struct{}
+
+// enqueueJob records job as pending (queued) and registers it so it can be
+// cancelled. The run's context is derived from parent. The run is returned
+// even if the record could not be saved, so background work still proceeds.
+func (e *SyncEngine) enqueueJob(parent context.Context, job storage.Job) (*jobRun, error) {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if job.Summary == nil {
+		job.Summary = make(map[string]any)
+	}
+	job.Status = storage.JobStatusPending
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	run := &jobRun{engine: e, cancel: cancel, job: job}
+	run.ctx = context.WithValue(ctx, jobRunKey{}, run)
+
+	e.activeJobsMu.Lock()
+	e.activeJobs[job.ID] = run
+	e.activeJobsMu.Unlock()
+
+	if err := e.jobs.Add(job); err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to create job entry")
+		return run, err
+	}
+	return run, nil
+}
+
+// lockSyncRun acquires syncRunMu like acquireSyncRunLock, but a queued job
+// gives up when its context is cancelled.
+func (e *SyncEngine) lockSyncRun(ctx context.Context) error {
+	if e.syncRunMu.TryLock() {
+		return nil
+	}
+	log.Info().Msg("A sync is already running; this job is queued and will start when it finishes")
+
+	ticker := time.NewTicker(syncLockPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if e.syncRunMu.TryLock() {
+				return nil
+			}
+		}
+	}
+}
+
+// CancelJob cancels a queued or running job. A queued job never starts; a
+// running one stops at the next cancellation point and is recorded as
+// cancelled.
+func (e *SyncEngine) CancelJob(id string) error {
+	e.activeJobsMu.Lock()
+	run, ok := e.activeJobs[id]
+	e.activeJobsMu.Unlock()
+
+	if !ok {
+		if _, found := e.jobs.Get(id); found {
+			return ErrJobNotActive
+		}
+		return ErrJobNotFound
+	}
+
+	log.Info().Str("job_id", id).Msg("Job cancellation requested")
+	run.cancel()
+	return nil
+}
+
+// activeJobCounts returns the number of queued jobs and the ID of the running
+// job, if any
+func (e *SyncEngine) activeJobCounts() (queued int, runningID string) {
+	e.activeJobsMu.Lock()
+	defer e.activeJobsMu.Unlock()
+
+	for id, run := range e.activeJobs {
+		run.mu.Lock()
+		status := run.job.Status
+		run.mu.Unlock()
+		switch status {
+		case storage.JobStatusPending:
+			queued++
+		case storage.JobStatusRunning:
+			runningID = id
+		}
+	}
+	return queued, runningID
+}
+
+// begin marks the job as running once it holds the sync lock
+func (r *jobRun) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.job.Status = storage.JobStatusRunning
+	r.job.StartedAt = time.Now()
+	r.saveLocked()
+	r.publishSyncLocked(EventSyncStarted, map[string]any{})
+}
+
+// snapshot returns a copy of the record that is safe to hand to other goroutines
+func (r *jobRun) snapshot() storage.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := r.job
+	job.Summary = maps.Clone(r.job.Summary)
+	job.Phases = append([]storage.JobPhase(nil), r.job.Phases...)
+	job.Results = append([]storage.DeletionResult(nil), r.job.Results...)
+	return job
+}
+
+// cancelled reports whether the job has been cancelled
+func (r *jobRun) cancelled() bool {
+	return r.ctx.Err() != nil
+}
+
+// startPhase begins a new phase; total is 0 when the amount of work is unknown
+func (r *jobRun) startPhase(name string, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.job.Phases = append(r.job.Phases, storage.JobPhase{
+		Name:      name,
+		Status:    storage.JobStatusRunning,
+		StartedAt: time.Now(),
+		Total:     total,
+	})
+	r.saveLocked()
+}
+
+// currentPhase returns the most recently started running phase, or nil
+func (r *jobRun) currentPhase() *storage.JobPhase {
+	for i := len(r.job.Phases) - 1; i >= 0; i-- {
+		if r.job.Phases[i].Status == storage.JobStatusRunning {
+			return &r.job.Phases[i]
+		}
+	}
+	return nil
+}
+
+// runningPhase returns the running phase called name, or nil
+func (r *jobRun) runningPhase(name string) *storage.JobPhase {
+	for i := len(r.job.Phases) - 1; i >= 0; i-- {
+		if phase := &r.job.Phases[i]; phase.Name == name && phase.Status == storage.JobStatusRunning {
+			return phase
+		}
+	}
+	return nil
+}
+
+// setTotal sets the amount of work in the current phase once it is known
+func (r *jobRun) setTotal(total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if phase := r.currentPhase(); phase != nil {
+		phase.Total = total
+	}
+}
+
+// addProgress adds n processed items to the current phase
+func (r *jobRun) addProgress(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	phase := r.currentPhase()
+	if phase == nil {
+		return
+	}
+	phase.Processed += n
+	if time.Since(r.lastSave) >= jobProgressSaveInterval {
+		r.saveLocked()
+	}
+}
+
+// endPhase completes the running phase called name. processed overrides the
+// counter when non-negative (for phases that only know their result at the end).
+func (r *jobRun) endPhase(name string, processed int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	phase := r.runningPhase(name)
+	if phase == nil {
+		return
+	}
+
+	completedAt := time.Now()
+	phase.CompletedAt = &completedAt
+	phase.DurationMs = completedAt.Sub(phase.StartedAt).Milliseconds()
+	if processed >= 0 {
+		phase.Processed = processed
+	}
+
+	switch {
+	case r.ctx.Err() != nil:
+		phase.Status = storage.JobStatusCancelled
+	case err != nil:
+		phase.Status = storage.JobStatusFailed
+		phase.Error = err.Error()
+	default:
+		phase.Status = storage.JobStatusCompleted
+	}
+	r.saveLocked()
+	metrics.SyncPhaseDuration.WithLabelValues(phase.Name, string(phase.Status)).Observe(completedAt.Sub(phase.StartedAt).Seconds())
+	r.publishSyncLocked(EventSyncPhase, map[string]any{
+		"phase":       phase.Name,
+		"status":      phase.Status,
+		"duration_ms": phase.DurationMs,
+		"processed":   phase.Processed,
+		"total":       phase.Total,
+	})
+}
+
+// finish records the final status of the job and unregisters it. A cancelled
+// job is recorded as cancelled regardless of err.
+func (r *jobRun) finish(err error) storage.Job {
+	r.mu.Lock()
+
+	completedAt := time.Now()
+	r.job.CompletedAt = &completedAt
+	started := r.job.Status == storage.JobStatusRunning
+	if started {
+		r.job.DurationMs = completedAt.Sub(r.job.StartedAt).Milliseconds()
+	}
+
+	switch {
+	case r.ctx.Err() != nil:
+		r.job.Status = storage.JobStatusCancelled
+		r.job.Error = "cancelled"
+	case err != nil:
+		r.job.Status = storage.JobStatusFailed
+		r.job.Error = err.Error()
+	default:
+		r.job.Status = storage.JobStatusCompleted
+	}
+	r.saveLocked()
+	if started {
+		metrics.JobDuration.WithLabelValues(string(r.job.Type), string(r.job.Status)).Observe(float64(r.job.DurationMs) / 1000)
+	}
+	data := map[string]any{"status": r.job.Status, "duration_ms": r.job.DurationMs}
+	if r.job.Error != "" {
+		data["error"] = r.job.Error
+	}
+	r.publishSyncLocked(EventSyncCompleted, data)
+	finished := r.job
+	r.mu.Unlock()
+
+	// Unregister without holding r.mu: activeJobCounts takes activeJobsMu
+	// before each run's mu, so holding both here would invert the lock order.
+	r.engine.activeJobsMu.Lock()
+	delete(r.engine.activeJobs, finished.ID)
+	r.engine.activeJobsMu.Unlock()
+	r.cancel()
+
+	return finished
+}
+
+// publishSyncLocked publishes a sync event for full sync jobs. Callers hold r.mu.
+func (r *jobRun) publishSyncLocked(eventType EventType, data map[string]any) {
+	if r.job.Type != storage.JobTypeFullSync {
+		return
+	}
+	data["job_id"] = r.job.ID
+	data["type"] = r.job.Type
+	r.engine.events.Publish(eventType, data)
+}
+
+// saveLocked writes a copy of the record to the jobs file. Callers hold r.mu.
+func (r *jobRun) saveLocked() {
+	saved := r.job
+	saved.Summary = maps.Clone(r.job.Summary)
+	saved.Phases = append([]storage.JobPhase(nil), r.job.Phases...)
+	saved.Results = append([]storage.DeletionResult(nil), r.job.Results...)
+	if err := r.engine.jobs.Update(saved); err != nil {
+		log.Warn().Err(err).Str("job_id", r.job.ID).Msg("Failed to update job")
+	}
+	r.lastSave = time.Now()
+}
+
+// jobRunFromContext returns the job running under ctx, or nil
+func jobRunFromContext(ctx context.Context) *jobRun {
+	run, _ := ctx.Value(jobRunKey{}).(*jobRun)
+	return run
+}
+
+// reportProgress adds n processed items to the current phase of the job
+// running under ctx. It is a no-op outside a job.
+func reportProgress(ctx context.Context, n int) {
+	if run := jobRunFromContext(ctx); run != nil {
+		run.addProgress(n)
+	}
+}
+
+// reportTotal sets the amount of work in the current phase of the job running
+// under ctx. It is a no-op outside a job.
+func reportTotal(ctx context.Context, total int) {
+	if run := jobRunFromContext(ctx); run != nil {
+		run.setTotal(total)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingStatsProvider blocks GetHistory until the context is cancelled
+type blockingStatsProvider struct {
+	started chan struct{}
+}
+
+func (b *blockingStatsProvider) GetHistory(ctx context.Context, itemIDs []string) ([]clients.StatsHistoryItem, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b *blockingStatsProvider) Ping(ctx context.Context) error { return nil }
+
+// waitForJob polls until the job has completed, failed or been cancelled
+func waitForJob(t *testing.T, jobs *storage.JobsFile, id string) storage.Job {
+	t.Helper()
+	var job storage.Job
+	require.Eventually(t, func() bool {
+		job, _ = jobs.Get(id)
+		return job.CompletedAt != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestSyncEngine_FullSync_RecordsPhases(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+
+	require.NoError(t, engine.FullSync(context.Background()))
+
+	syncJob := jobsOfType(jobs, storage.JobTypeFullSync)[0]
+	require.Len(t, syncJob.Phases, 1, "only phases for configured integrations are recorded")
+	rulesPhase := syncJob.Phases[0]
+	assert.Equal(t, PhaseRules, rulesPhase.Name)
+	assert.Equal(t, storage.JobStatusCompleted, rulesPhase.Status)
+	assert.Equal(t, 2, rulesPhase.Total)
+	assert.Equal(t, 2, rulesPhase.Processed)
+	assert.NotNil(t, rulesPhase.CompletedAt)
+
+	deletionJob := jobsOfType(jobs, storage.JobTypeDeletion)[0]
+	require.Len(t, deletionJob.Phases, 1)
+	assert.Equal(t, PhaseDeletions, deletionJob.Phases[0].Name)
+	assert.Equal(t, 2, deletionJob.Phases[0].Processed)
+}
+
+func TestSyncEngine_QueuedJobCanBeCancelled(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+
+	// Hold the sync lock so the job stays queued
+	engine.syncRunMu.Lock()
+	defer engine.syncRunMu.Unlock()
+
+	job, err := engine.StartFullSync()
+	require.NoError(t, err)
+
+	queued, found := jobs.Get(job.ID)
+	require.True(t, found)
+	assert.Equal(t, storage.JobStatusPending, queued.Status)
+	assert.Equal(t, 1, engine.GetStatus().QueuedJobs)
+
+	require.NoError(t, engine.CancelJob(job.ID))
+
+	cancelled := waitForJob(t, jobs, job.ID)
+	assert.Equal(t, storage.JobStatusCancelled, cancelled.Status)
+	assert.Empty(t, cancelled.Phases, "a cancelled queued job never starts")
+	assert.Zero(t, engine.GetStatus().QueuedJobs)
+	assert.Equal(t, 2, engine.GetMediaCount())
+
+	assert.ErrorIs(t, engine.CancelJob(job.ID), ErrJobNotActive)
+}
+
+func TestSyncEngine_RunningSyncCanBeCancelled(t *testing.T) {
+	engine, jobs, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+	stats := &blockingStatsProvider{started: make(chan struct{})}
+	engine.statsClient = stats
+
+	job, err := engine.StartFullSync()
+	require.NoError(t, err)
+
+	select {
+	case <-stats.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stats phase never started")
+	}
+	assert.Equal(t, job.ID, engine.GetStatus().RunningJobID)
+	require.NoError(t, engine.CancelJob(job.ID))
+
+	cancelled := waitForJob(t, jobs, job.ID)
+	assert.Equal(t, storage.JobStatusCancelled, cancelled.Status)
+	require.NotEmpty(t, cancelled.Phases)
+	last := cancelled.Phases[len(cancelled.Phases)-1]
+	assert.Equal(t, PhaseStats, last.Name)
+	assert.Equal(t, storage.JobStatusCancelled, last.Status)
+
+	// Rules and deletions must not run on a partially refreshed library
+	assert.Equal(t, 2, engine.GetMediaCount())
+	assert.Empty(t, jobsOfType(jobs, storage.JobTypeDeletion))
+}
+
+func TestSyncEngine_CancelJob_Unknown(t *testing.T) {
+	engine, _, _, _ := newApprovalTestEngine(t)
+	assert.ErrorIs(t, engine.CancelJob("missing"), ErrJobNotFound)
+}
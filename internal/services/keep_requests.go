@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrKeepRequestsUnavailable is returned when no keep request store is configured.
+	ErrKeepRequestsUnavailable = errors.New("keep request storage is not configured")
+	// ErrKeepRequestNotFound is returned when no keep request has the requested ID.
+	ErrKeepRequestNotFound = errors.New("keep request not found")
+	// ErrKeepRequestNotPending is returned when deciding a request twice.
+	ErrKeepRequestNotPending = errors.New("keep request was already decided")
+	// ErrKeepRequestDuplicate is returned when the same user already has a
+	// pending request for the item.
+	ErrKeepRequestDuplicate = errors.New("a keep request for this item is already pending")
+	// ErrMediaNotFound is returned when the media item is not in the library.
+	ErrMediaNotFound = errors.New("media not found")
+	// ErrAlreadyExcluded is returned when asking to keep an excluded item.
+	ErrAlreadyExcluded = errors.New("media is already excluded from deletion")
+)
+
+// SetKeepRequests injects the store for keep requests. Without it, keep
+// requests are unavailable.
+func (e *SyncEngine) SetKeepRequests(requests *storage.KeepRequestsFile) {
+	e.keepRequests = requests
+}
+
+// SubmitKeepRequest records a request from actor to exclude a media item from
+// deletion. Restricted callers may only ask about items they requested.
+func (e *SyncEngine) SubmitKeepRequest(mediaID, reason, actor string, restricted bool) (storage.KeepRequest, error) {
+	if e.keepRequests == nil {
+		return storage.KeepRequest{}, ErrKeepRequestsUnavailable
+	}
+
+	// Items a restricted user did not request look missing to them
+	media, found := e.GetMediaByID(mediaID)
+	if !found || (restricted && !media.IsRequestedBy(actor)) {
+		return storage.KeepRequest{}, ErrMediaNotFound
+	}
+	if media.IsExcluded {
+		return storage.KeepRequest{}, ErrAlreadyExcluded
+	}
+
+	e.keepRequestsMu.Lock()
+	defer e.keepRequestsMu.Unlock()
+
+	for _, existing := range e.keepRequests.GetAll() {
+		if existing.MediaID == mediaID && existing.RequestedBy == actor && existing.Status == storage.KeepRequestPending {
+			return storage.KeepRequest{}, ErrKeepRequestDuplicate
+		}
+	}
+
+	request := storage.KeepRequest{
+		ID:          uuid.New().String(),
+		MediaID:     mediaID,
+		Title:       media.Title,
+		MediaType:   string(media.Type),
+		RequestedBy: actor,
+		Reason:      reason,
+		Status:      storage.KeepRequestPending,
+		CreatedAt:   time.Now(),
+	}
+	if err := e.keepRequests.Add(request); err != nil {
+		return storage.KeepRequest{}, fmt.Errorf("storing keep request: %w", err)
+	}
+
+	e.events.Publish(EventKeepRequested, map[string]any{
+		"id":           request.ID,
+		"media_id":     mediaID,
+		"title":        media.Title,
+		"requested_by": actor,
+	})
+
+	log.Info().
+		Str("keep_request_id", request.ID).
+		Str("media_id", mediaID).
+		Str("title", media.Title).
+		Str("requested_by", actor).
+		Msg("Keep request submitted")
+
+	return request, nil
+}
+
+// ListKeepRequests returns keep requests, most recent first. A non-empty
+// requestedBy limits the list to that user's requests.
+func (e *SyncEngine) ListKeepRequests(requestedBy string) ([]storage.KeepRequest, error) {
+	if e.keepRequests == nil {
+		return nil, ErrKeepRequestsUnavailable
+	}
+
+	all := e.keepRequests.GetAll()
+	if requestedBy == "" {
+		return all, nil
+	}
+	own := make([]storage.KeepRequest, 0)
+	for _, request := range all {
+		if request.RequestedBy == requestedBy {
+			own = append(own, request)
+		}
+	}
+	return own, nil
+}
+
+// ApproveKeepRequest excludes the requested item from deletion and marks the
+// request approved.
+func (e *SyncEngine) ApproveKeepRequest(ctx context.Context, id, note, actor string) (storage.KeepRequest, error) {
+	return e.decideKeepRequest(id, note, actor, storage.KeepRequestApproved, func(request storage.KeepRequest) error {
+		media, found := e.GetMediaByID(request.MediaID)
+		if !found {
+			return ErrMediaNotFound
+		}
+		if media.IsExcluded {
+			return nil
+		}
+		reason := "Keep request from " + request.RequestedBy
+		if request.Reason != "" {
+			reason += ": " + request.Reason
+		}
+		return e.AddExclusion(ctx, request.MediaID, reason, actor)
+	})
+}
+
+// DenyKeepRequest marks the request denied; the item stays scheduled.
+func (e *SyncEngine) DenyKeepRequest(id, note, actor string) (storage.KeepRequest, error) {
+	return e.decideKeepRequest(id, note, actor, storage.KeepRequestDenied, nil)
+}
+
+// decideKeepRequest moves a pending request to status, running apply first;
+// the request stays pending when apply fails.
+func (e *SyncEngine) decideKeepRequest(id, note, actor string, status storage.KeepRequestStatus, apply func(storage.KeepRequest) error) (storage.KeepRequest, error) {
+	if e.keepRequests == nil {
+		return storage.KeepRequest{}, ErrKeepRequestsUnavailable
+	}
+
+	e.keepRequestsMu.Lock()
+	defer e.keepRequestsMu.Unlock()
+
+	request, found := e.keepRequests.Get(id)
+	if !found {
+		return storage.KeepRequest{}, ErrKeepRequestNotFound
+	}
+	if request.Status != storage.KeepRequestPending {
+		return storage.KeepRequest{}, ErrKeepRequestNotPending
+	}
+
+	if apply != nil {
+		if err := apply(request); err != nil {
+			return storage.KeepRequest{}, err
+		}
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.DecidedAt = &now
+	request.DecidedBy = actor
+	request.Note = note
+	if _, err := e.keepRequests.Update(request); err != nil {
+		return storage.KeepRequest{}, fmt.Errorf("saving keep request: %w", err)
+	}
+
+	e.events.Publish(EventKeepDecided, map[string]any{
+		"id":           request.ID,
+		"media_id":     request.MediaID,
+		"title":        request.Title,
+		"status":       string(status),
+		"requested_by": request.RequestedBy,
+		"decided_by":   actor,
+	})
+
+	log.Info().
+		Str("keep_request_id", request.ID).
+		Str("media_id", request.MediaID).
+		Str("status", string(status)).
+		Str("decided_by", actor).
+		Msg("Keep request decided")
+
+	return request, nil
+}
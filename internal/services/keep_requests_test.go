@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeepRequestTestEngine(t *testing.T) (*SyncEngine, *storage.ExclusionsFile) {
+	t.Helper()
+	engine, _, exclusions := newTestSyncEngine(t)
+	keepRequests, err := storage.NewKeepRequestsFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	engine.SetKeepRequests(keepRequests)
+
+	kid := "Kid"
+	engine.mediaLibrary["movie-1"] = models.Media{ID: "movie-1", Type: models.MediaTypeMovie, Title: "Movie One", RadarrID: 1, RequestedByUsername: &kid}
+	engine.mediaLibrary["movie-2"] = models.Media{ID: "movie-2", Type: models.MediaTypeMovie, Title: "Movie Two", RadarrID: 2}
+	return engine, exclusions
+}
+
+func TestKeepRequests_NoStoreConfigured(t *testing.T) {
+	engine, _, _ := newTestSyncEngine(t)
+
+	_, err := engine.SubmitKeepRequest("movie-1", "", "kid", true)
+	assert.ErrorIs(t, err, ErrKeepRequestsUnavailable)
+	_, err = engine.ListKeepRequests("")
+	assert.ErrorIs(t, err, ErrKeepRequestsUnavailable)
+}
+
+func TestKeepRequests_Submit(t *testing.T) {
+	engine, _ := newKeepRequestTestEngine(t)
+
+	request, err := engine.SubmitKeepRequest("movie-1", "still watching", "kid", true)
+	require.NoError(t, err)
+	assert.Equal(t, storage.KeepRequestPending, request.Status)
+	assert.Equal(t, "Movie One", request.Title)
+
+	_, err = engine.SubmitKeepRequest("movie-1", "again", "kid", true)
+	assert.ErrorIs(t, err, ErrKeepRequestDuplicate)
+
+	// Restricted users cannot see items they did not request
+	_, err = engine.SubmitKeepRequest("movie-2", "", "kid", true)
+	assert.ErrorIs(t, err, ErrMediaNotFound)
+	_, err = engine.SubmitKeepRequest("missing", "", "kid", true)
+	assert.ErrorIs(t, err, ErrMediaNotFound)
+
+	// Unrestricted users can ask about anything
+	_, err = engine.SubmitKeepRequest("movie-2", "", "viewer", false)
+	require.NoError(t, err)
+
+	own, err := engine.ListKeepRequests("kid")
+	require.NoError(t, err)
+	assert.Len(t, own, 1)
+	all, err := engine.ListKeepRequests("")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestKeepRequests_ApproveExcludes(t *testing.T) {
+	engine, exclusions := newKeepRequestTestEngine(t)
+
+	request, err := engine.SubmitKeepRequest("movie-1", "still watching", "kid", true)
+	require.NoError(t, err)
+
+	approved, err := engine.ApproveKeepRequest(context.Background(), request.ID, "ok", "operator")
+	require.NoError(t, err)
+	assert.Equal(t, storage.KeepRequestApproved, approved.Status)
+	assert.Equal(t, "operator", approved.DecidedBy)
+	require.NotNil(t, approved.DecidedAt)
+
+	assert.True(t, exclusions.IsExcluded("radarr-1"))
+	exclusion, _ := exclusions.Get("radarr-1")
+	assert.Equal(t, "Keep request from kid: still watching", exclusion.Reason)
+	media, _ := engine.GetMediaByID("movie-1")
+	assert.True(t, media.IsExcluded)
+
+	_, err = engine.ApproveKeepRequest(context.Background(), request.ID, "", "operator")
+	assert.ErrorIs(t, err, ErrKeepRequestNotPending)
+	_, err = engine.DenyKeepRequest("missing", "", "operator")
+	assert.ErrorIs(t, err, ErrKeepRequestNotFound)
+
+	// Excluded items cannot be asked for again
+	_, err = engine.SubmitKeepRequest("movie-1", "", "kid", true)
+	assert.ErrorIs(t, err, ErrAlreadyExcluded)
+}
+
+func TestKeepRequests_Deny(t *testing.T) {
+	engine, exclusions := newKeepRequestTestEngine(t)
+
+	request, err := engine.SubmitKeepRequest("movie-1", "", "kid", true)
+	require.NoError(t, err)
+
+	denied, err := engine.DenyKeepRequest(request.ID, "space is tight", "operator")
+	require.NoError(t, err)
+	assert.Equal(t, storage.KeepRequestDenied, denied.Status)
+	assert.Equal(t, "space is tight", denied.Note)
+	assert.False(t, exclusions.IsExcluded("radarr-1"))
+
+	// A denied request does not block a new one
+	_, err = engine.SubmitKeepRequest("movie-1", "please", "kid", true)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+)
+
+// Defaults used when admin.login_throttle fields are unset or invalid
+const (
+	defaultLoginMaxFailures = 5
+	defaultLoginWindow      = 15 * time.Minute
+	defaultLoginLockout     = 15 * time.Minute
+
+	// loginThrottlePruneSize is how many tracked keys trigger dropping stale
+	// ones, so a spray of usernames cannot grow the map without bound
+	loginThrottlePruneSize = 10000
+)
+
+// ErrLoginLocked is returned while a username or client IP is locked out
+// after too many failed sign-in attempts. The error is a *LoginLockedError.
+var ErrLoginLocked = errors.New("too many failed sign-in attempts")
+
+// LoginLockedError carries how long a lockout has left
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// loginThrottleLimits is the effective admin.login_throttle config
+type loginThrottleLimits struct {
+	enabled     bool
+	maxFailures int
+	window      time.Duration
+	lockout     time.Duration
+}
+
+func newLoginThrottleLimits(cfg config.LoginThrottleConfig) loginThrottleLimits {
+	limits := loginThrottleLimits{
+		enabled:     !cfg.Disabled,
+		maxFailures: defaultLoginMaxFailures,
+		window:      defaultLoginWindow,
+		lockout:     defaultLoginLockout,
+	}
+	if cfg.MaxFailures > 0 {
+		limits.maxFailures = cfg.MaxFailures
+	}
+	if d, err := rules.ParseDuration(cfg.Window); err == nil && d > 0 {
+		limits.window = d
+	}
+	if d, err := rules.ParseDuration(cfg.Lockout); err == nil && d > 0 {
+		limits.lockout = d
+	}
+	return limits
+}
+
+// loginFailures tracks failed attempts for one username or client IP
+type loginFailures struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// loginThrottle counts failed sign-ins per key ("user:<name>", "ip:<addr>")
+// and locks a key out once it reaches the limit within the window. The zero
+// value is ready to use.
+type loginThrottle struct {
+	mu      sync.Mutex
+	entries map[string]*loginFailures
+}
+
+// loginThrottleKeys returns the keys an attempt counts against. Usernames
+// are keyed case-insensitively, as they are matched. An empty IP (e.g. a
+// caller without a request) is not tracked.
+func loginThrottleKeys(username, ip string) []string {
+	keys := []string{"user:" + strings.ToLower(username)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+// check returns a *LoginLockedError when any key is locked out
+func (t *loginThrottle) check(limits loginThrottleLimits, now time.Time, keys ...string) error {
+	if !limits.enabled {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		if entry, ok := t.entries[key]; ok && now.Before(entry.lockedUntil) {
+			retryAfter = max(retryAfter, entry.lockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// fail records a failed attempt against every key
+func (t *loginThrottle) fail(limits loginThrottleLimits, now time.Time, keys ...string) {
+	if !limits.enabled {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.entries == nil {
+		t.entries = make(map[string]*loginFailures)
+	}
+	if len(t.entries) >= loginThrottlePruneSize {
+		t.prune(limits, now)
+	}
+	for _, key := range keys {
+		entry, ok := t.entries[key]
+		if !ok || now.Sub(entry.windowStart) > limits.window {
+			entry = &loginFailures{windowStart: now}
+			t.entries[key] = entry
+		}
+		entry.count++
+		if entry.count >= limits.maxFailures {
+			entry.lockedUntil = now.Add(limits.lockout)
+			entry.count = 0
+			entry.windowStart = now
+		}
+	}
+}
+
+// reset forgets the failures for key after a successful sign-in
+func (t *loginThrottle) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+}
+
+// prune drops keys that are neither locked nor inside their window.
+// Callers hold t.mu.
+func (t *loginThrottle) prune(limits loginThrottleLimits, now time.Time) {
+	for key, entry := range t.entries {
+		if !now.Before(entry.lockedUntil) && now.Sub(entry.windowStart) > limits.window {
+			delete(t.entries, key)
+		}
+	}
+}
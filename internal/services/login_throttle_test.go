@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	limits := newLoginThrottleLimits(config.LoginThrottleConfig{MaxFailures: 3, Window: "10m", Lockout: "5m"})
+	var throttle loginThrottle
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		throttle.fail(limits, now, "user:alice", "ip:198.51.100.7")
+	}
+	if err := throttle.check(limits, now, "user:alice"); err != nil {
+		t.Fatalf("Expected no lockout below the limit, got %v", err)
+	}
+
+	throttle.fail(limits, now, "user:alice", "ip:198.51.100.7")
+	err := throttle.check(limits, now.Add(time.Minute), "user:alice")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Expected a lockout, got %v", err)
+	}
+	if locked.RetryAfter != 4*time.Minute {
+		t.Errorf("Expected 4m left, got %s", locked.RetryAfter)
+	}
+	if err := throttle.check(limits, now, "user:bob", "ip:198.51.100.7"); err == nil {
+		t.Error("Expected the IP to be locked out for other usernames too")
+	}
+	if err := throttle.check(limits, now.Add(5*time.Minute), "user:alice"); err != nil {
+		t.Errorf("Expected the lockout to end, got %v", err)
+	}
+
+	// Failures outside the window start a new count
+	throttle.fail(limits, now, "user:carol")
+	throttle.fail(limits, now, "user:carol")
+	throttle.fail(limits, now.Add(11*time.Minute), "user:carol")
+	if err := throttle.check(limits, now.Add(11*time.Minute), "user:carol"); err != nil {
+		t.Errorf("Expected old failures to expire, got %v", err)
+	}
+
+	// A successful sign-in clears the count
+	throttle.fail(limits, now, "user:dave")
+	throttle.fail(limits, now, "user:dave")
+	throttle.reset("user:dave")
+	throttle.fail(limits, now, "user:dave")
+	if err := throttle.check(limits, now, "user:dave"); err != nil {
+		t.Errorf("Expected reset to clear failures, got %v", err)
+	}
+
+	disabled := newLoginThrottleLimits(config.LoginThrottleConfig{Disabled: true})
+	if err := throttle.check(disabled, now, "ip:198.51.100.7"); err != nil {
+		t.Errorf("Expected no lockouts when disabled, got %v", err)
+	}
+}
+
+func TestNewLoginThrottleLimits_Defaults(t *testing.T) {
+	limits := newLoginThrottleLimits(config.LoginThrottleConfig{Window: "bogus"})
+	if !limits.enabled || limits.maxFailures != defaultLoginMaxFailures ||
+		limits.window != defaultLoginWindow || limits.lockout != defaultLoginLockout {
+		t.Errorf("Expected defaults, got %+v", limits)
+	}
+}
+
+func TestLogin_LocksOutAfterFailures(t *testing.T) {
+	svc := setupAuthService(t, "testpassword")
+	ctx := context.Background()
+
+	for i := 0; i < defaultLoginMaxFailures; i++ {
+		if _, err := svc.Login(ctx, "admin", "wrong", ClientInfo{IP: "198.51.100.7"}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Attempt %d: expected ErrInvalidCredentials, got %v", i+1, err)
+		}
+	}
+
+	// Even the right password is refused while locked out
+	if _, err := svc.Login(ctx, "admin", "testpassword", ClientInfo{IP: "203.0.113.9"}); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Expected the username to be locked out, got %v", err)
+	}
+	if _, err := svc.Login(ctx, "someone", "whatever", ClientInfo{IP: "198.51.100.7"}); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Expected the IP to be locked out, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+)
+
+var (
+	libraryItemsDesc = prometheus.NewDesc("oxicleanarr_library_items",
+		"Media items in the library.", []string{"type"}, nil)
+	libraryBytesDesc = prometheus.NewDesc("oxicleanarr_library_bytes",
+		"Size on disk of media items in the library.", []string{"type"}, nil)
+	scheduledItemsDesc = prometheus.NewDesc("oxicleanarr_scheduled_items",
+		"Items with a deletion date that are not excluded.", []string{"type"}, nil)
+	leavingSoonItemsDesc = prometheus.NewDesc("oxicleanarr_leaving_soon_items",
+		"Items inside the leaving-soon window.", []string{"type"}, nil)
+	excludedItemsDesc = prometheus.NewDesc("oxicleanarr_excluded_items",
+		"Items excluded from deletion.", []string{"type"}, nil)
+	diskFreeDesc = prometheus.NewDesc("oxicleanarr_disk_free_gigabytes",
+		"Free disk space at the last disk check.", nil, nil)
+	diskTotalDesc = prometheus.NewDesc("oxicleanarr_disk_total_gigabytes",
+		"Total disk space at the last disk check.", nil, nil)
+	diskThresholdDesc = prometheus.NewDesc("oxicleanarr_disk_threshold_gigabytes",
+		"Configured free space threshold (app.disk_threshold.free_space_gb).", nil, nil)
+	diskBreachedDesc = prometheus.NewDesc("oxicleanarr_disk_threshold_breached",
+		"1 when free space is below the threshold and disk-gated rules are active.", nil, nil)
+)
+
+// metricsCollector reports the engine's current library and disk state at
+// scrape time
+type metricsCollector struct {
+	engine *SyncEngine
+}
+
+// NewMetricsCollector returns a Prometheus collector for the library and disk
+// gauges of the engine
+func NewMetricsCollector(engine *SyncEngine) prometheus.Collector {
+	return &metricsCollector{engine: engine}
+}
+
+func (c *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- libraryItemsDesc
+	ch <- libraryBytesDesc
+	ch <- scheduledItemsDesc
+	ch <- leavingSoonItemsDesc
+	ch <- excludedItemsDesc
+	ch <- diskFreeDesc
+	ch <- diskTotalDesc
+	ch <- diskThresholdDesc
+	ch <- diskBreachedDesc
+}
+
+func (c *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	e := c.engine
+
+	leavingSoonDays := e.config.App.LeavingSoonDays
+	if cfg := config.Get(); cfg != nil {
+		leavingSoonDays = cfg.App.LeavingSoonDays
+	}
+
+	type counts struct {
+		items, bytes, scheduled, leavingSoon, excluded float64
+	}
+	byType := map[models.MediaType]*counts{
+		models.MediaTypeMovie:  {},
+		models.MediaTypeTVShow: {},
+	}
+
+	e.mediaLibraryLock.RLock()
+	for _, media := range e.mediaLibrary {
+		t, ok := byType[media.Type]
+		if !ok {
+			t = &counts{}
+			byType[media.Type] = t
+		}
+		t.items++
+		t.bytes += float64(media.FileSize)
+		if media.IsExcluded {
+			t.excluded++
+			continue
+		}
+		if !media.DeleteAfter.IsZero() {
+			t.scheduled++
+		}
+		if media.DaysUntilDue > 0 && media.DaysUntilDue <= leavingSoonDays {
+			t.leavingSoon++
+		}
+	}
+	e.mediaLibraryLock.RUnlock()
+
+	for mediaType, t := range byType {
+		label := string(mediaType)
+		ch <- prometheus.MustNewConstMetric(libraryItemsDesc, prometheus.GaugeValue, t.items, label)
+		ch <- prometheus.MustNewConstMetric(libraryBytesDesc, prometheus.GaugeValue, t.bytes, label)
+		ch <- prometheus.MustNewConstMetric(scheduledItemsDesc, prometheus.GaugeValue, t.scheduled, label)
+		ch <- prometheus.MustNewConstMetric(leavingSoonItemsDesc, prometheus.GaugeValue, t.leavingSoon, label)
+		ch <- prometheus.MustNewConstMetric(excludedItemsDesc, prometheus.GaugeValue, t.excluded, label)
+	}
+
+	// Disk gauges only once the monitor has a reading
+	if e.diskMonitor == nil {
+		return
+	}
+	status := e.diskMonitor.GetStatus()
+	if status == nil || status.TotalSpaceGB == 0 {
+		return
+	}
+	breached := 0.0
+	if status.ThresholdBreached {
+		breached = 1
+	}
+	ch <- prometheus.MustNewConstMetric(diskFreeDesc, prometheus.GaugeValue, float64(status.FreeSpaceGB))
+	ch <- prometheus.MustNewConstMetric(diskTotalDesc, prometheus.GaugeValue, float64(status.TotalSpaceGB))
+	ch <- prometheus.MustNewConstMetric(diskThresholdDesc, prometheus.GaugeValue, float64(status.ThresholdGB))
+	ch <- prometheus.MustNewConstMetric(diskBreachedDesc, prometheus.GaugeValue, breached)
+}
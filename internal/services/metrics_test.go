@@ -0,0 +1,47 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCollector_Library(t *testing.T) {
+	engine, _, _, _ := newApprovalTestEngine(t)
+	engine.config.App.LeavingSoonDays = 14
+
+	engine.mediaLibrary["movie-1"] = models.Media{ID: "movie-1", Type: models.MediaTypeMovie, FileSize: 100,
+		DeleteAfter: time.Now().AddDate(0, 0, 5), DaysUntilDue: 5}
+	engine.mediaLibrary["movie-2"] = models.Media{ID: "movie-2", Type: models.MediaTypeMovie, FileSize: 200, IsExcluded: true}
+	engine.mediaLibrary["show-1"] = models.Media{ID: "show-1", Type: models.MediaTypeTVShow, FileSize: 1000,
+		DeleteAfter: time.Now().AddDate(0, 0, 60), DaysUntilDue: 60}
+
+	expected := `
+# HELP oxicleanarr_excluded_items Items excluded from deletion.
+# TYPE oxicleanarr_excluded_items gauge
+oxicleanarr_excluded_items{type="movie"} 1
+oxicleanarr_excluded_items{type="tv_show"} 0
+# HELP oxicleanarr_leaving_soon_items Items inside the leaving-soon window.
+# TYPE oxicleanarr_leaving_soon_items gauge
+oxicleanarr_leaving_soon_items{type="movie"} 1
+oxicleanarr_leaving_soon_items{type="tv_show"} 0
+# HELP oxicleanarr_library_bytes Size on disk of media items in the library.
+# TYPE oxicleanarr_library_bytes gauge
+oxicleanarr_library_bytes{type="movie"} 300
+oxicleanarr_library_bytes{type="tv_show"} 1000
+# HELP oxicleanarr_library_items Media items in the library.
+# TYPE oxicleanarr_library_items gauge
+oxicleanarr_library_items{type="movie"} 2
+oxicleanarr_library_items{type="tv_show"} 1
+# HELP oxicleanarr_scheduled_items Items with a deletion date that are not excluded.
+# TYPE oxicleanarr_scheduled_items gauge
+oxicleanarr_scheduled_items{type="movie"} 1
+oxicleanarr_scheduled_items{type="tv_show"} 1
+`
+	err := testutil.CollectAndCompare(NewMetricsCollector(engine), strings.NewReader(expected))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrOIDCDisabled is returned when single sign-on is not configured.
+	ErrOIDCDisabled = errors.New("single sign-on is not enabled")
+	// ErrOIDCState is returned when the callback's state is unknown or expired.
+	ErrOIDCState = errors.New("sign-in request is invalid or has expired")
+	// ErrOIDCNoRole is returned when none of the user's groups map to a role
+	// and no default role is configured.
+	ErrOIDCNoRole = errors.New("your account is not allowed to use OxiCleanarr")
+	// ErrOIDCLocalName is returned when the user's name belongs to a local
+	// account, which single sign-on must not act as.
+	ErrOIDCLocalName = errors.New("your username belongs to a local account")
+)
+
+const (
+	// oidcStateTTL bounds how long a user may take at the provider
+	oidcStateTTL = 10 * time.Minute
+	// maxPendingOIDCLogins caps outstanding sign-ins so unauthenticated
+	// callers cannot grow the map without bound
+	maxPendingOIDCLogins = 1000
+)
+
+// defaultOIDCScopes are requested when admin.oidc.scopes is empty
+var defaultOIDCScopes = []string{"openid", "profile", "email", "groups"}
+
+// oidcPendingLogin is what we remember between redirecting to the provider
+// and its callback
+type oidcPendingLogin struct {
+	nonce        string
+	codeVerifier string
+	expires      time.Time
+}
+
+// StartOIDCLogin begins an authorization code flow with PKCE and returns the
+// provider URL to redirect the browser to and the state that the callback
+// must echo.
+func (s *AuthService) StartOIDCLogin(ctx context.Context) (string, string, error) {
+	oidcCfg := s.currentConfig().Admin.OIDC
+	if !oidcCfg.Enabled {
+		return "", "", ErrOIDCDisabled
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	scopes := oidcCfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+	authURL, err := s.oidcClientFor(oidcCfg).AuthCodeURL(ctx, scopes, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		log.Warn().Err(err).Msg("OIDC discovery failed")
+		return "", "", fmt.Errorf("%w: %v", ErrLoginProviderUnavailable, err)
+	}
+
+	s.oidcMu.Lock()
+	defer s.oidcMu.Unlock()
+	now := time.Now()
+	for key, pending := range s.oidcPending {
+		if now.After(pending.expires) {
+			delete(s.oidcPending, key)
+		}
+	}
+	if len(s.oidcPending) >= maxPendingOIDCLogins {
+		return "", "", fmt.Errorf("%w: too many sign-ins in progress", ErrLoginProviderUnavailable)
+	}
+	if s.oidcPending == nil {
+		s.oidcPending = make(map[string]oidcPendingLogin)
+	}
+	s.oidcPending[state] = oidcPendingLogin{nonce: nonce, codeVerifier: verifier, expires: now.Add(oidcStateTTL)}
+
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin redeems the authorization code from the provider's
+// callback, verifies the ID token and maps the user's groups to a role.
+// Users whose name matches a local account are refused. The username is
+// returned with the error when the user has no mapped role or is refused.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, state, code string, clientInfo ClientInfo) (LoginResult, error) {
+	oidcCfg := s.currentConfig().Admin.OIDC
+	if !oidcCfg.Enabled {
+		return LoginResult{}, ErrOIDCDisabled
+	}
+
+	// A state is single-use, whatever the outcome
+	s.oidcMu.Lock()
+	pending, ok := s.oidcPending[state]
+	delete(s.oidcPending, state)
+	s.oidcMu.Unlock()
+	if !ok || state == "" || time.Now().After(pending.expires) {
+		return LoginResult{}, ErrOIDCState
+	}
+	if code == "" {
+		return LoginResult{}, ErrInvalidCredentials
+	}
+
+	client := s.oidcClientFor(oidcCfg)
+	rawIDToken, err := client.Exchange(ctx, code, pending.codeVerifier)
+	if err != nil {
+		log.Warn().Err(err).Msg("OIDC code exchange failed")
+		return LoginResult{}, fmt.Errorf("%w: %v", ErrLoginProviderUnavailable, err)
+	}
+	claims, err := client.VerifyIDToken(ctx, rawIDToken, pending.nonce)
+	if err != nil {
+		log.Warn().Err(err).Msg("OIDC ID token rejected")
+		if errors.Is(err, clients.ErrOIDCTokenInvalid) {
+			return LoginResult{}, ErrInvalidCredentials
+		}
+		return LoginResult{}, fmt.Errorf("%w: %v", ErrLoginProviderUnavailable, err)
+	}
+
+	usernameClaim := oidcCfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	username, _ := claims[usernameClaim].(string)
+	username = strings.TrimSpace(username)
+	if username == "" {
+		log.Warn().Str("claim", usernameClaim).Msg("OIDC ID token has no username claim")
+		return LoginResult{}, ErrInvalidCredentials
+	}
+
+	if s.isLocalName(username) {
+		log.Warn().Str("username", username).Msg("OIDC sign-in refused: the name belongs to a local account")
+		return LoginResult{Username: username}, ErrOIDCLocalName
+	}
+
+	groupsClaim := oidcCfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	role := oidcRole(oidcCfg, claimStrings(claims[groupsClaim]))
+	if role == "" {
+		log.Warn().Str("username", username).Msg("OIDC user has no mapped role")
+		return LoginResult{Username: username}, ErrOIDCNoRole
+	}
+
+	return s.issueToken(username, role, loginMethodOIDC, clientInfo)
+}
+
+// oidcClientFor returns the cached OIDC client, replacing it when the
+// provider settings change on reload
+func (s *AuthService) oidcClientFor(oidcCfg config.OIDCConfig) *clients.OIDCClient {
+	key := strings.Join([]string{oidcCfg.IssuerURL, oidcCfg.ClientID, oidcCfg.ClientSecret, oidcCfg.RedirectURL}, "\x00")
+
+	s.oidcMu.Lock()
+	defer s.oidcMu.Unlock()
+	if s.oidcClient == nil || s.oidcClientKey != key {
+		s.oidcClient = clients.NewOIDCClient(oidcCfg)
+		s.oidcClientKey = key
+	}
+	return s.oidcClient
+}
+
+// oidcRole returns the highest role mapped to any of the groups, or the
+// default role when none match
+func oidcRole(oidcCfg config.OIDCConfig, groups []string) string {
+	best := ""
+	for _, mapping := range oidcCfg.GroupRoles {
+		for _, group := range groups {
+			if group == mapping.Group && (best == "" || utils.RoleAllows(mapping.Role, best)) {
+				best = mapping.Role
+			}
+		}
+	}
+	if best == "" {
+		return oidcCfg.DefaultRole
+	}
+	return best
+}
+
+// claimStrings reads a claim that may be a single string or a list
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// randomToken returns 32 random bytes, base64url-encoded
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients/oidctest"
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+// oidcCode plays the browser's part: it sends the authorization request to
+// the provider and returns the code it redirects back with
+func oidcCode(t *testing.T, provider *oidctest.Provider, authURL string) string {
+	t.Helper()
+	code, err := provider.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorizing: %v", err)
+	}
+	return code
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := oidctest.New()
+	defer provider.Close()
+	svc := setupAuthService(t, "adminpassword")
+
+	config.SetTestConfig(&config.Config{
+		Admin: config.AdminConfig{
+			OIDC: config.OIDCConfig{
+				Enabled:      true,
+				IssuerURL:    provider.Issuer(),
+				ClientID:     oidctest.ClientID,
+				ClientSecret: oidctest.ClientSecret,
+				RedirectURL:  "https://app.example.com/api/auth/oidc/callback",
+				GroupRoles: []config.OIDCGroupRole{
+					{Group: "media-viewers", Role: utils.RoleViewer},
+					{Group: "media-admins", Role: utils.RoleAdmin},
+				},
+			},
+		},
+	})
+	defer config.SetTestConfig(nil)
+	ctx := context.Background()
+
+	t.Run("highest mapped group wins", func(t *testing.T) {
+		authURL, state, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin failed: %v", err)
+		}
+		provider.SetUser("alice", []string{"media-viewers", "media-admins", "other"})
+
+		result, err := svc.CompleteOIDCLogin(ctx, state, oidcCode(t, provider, authURL), ClientInfo{})
+		if err != nil {
+			t.Fatalf("CompleteOIDCLogin failed: %v", err)
+		}
+		if result.Username != "alice" || result.Role != utils.RoleAdmin {
+			t.Errorf("Expected alice as admin, got %q as %q", result.Username, result.Role)
+		}
+		claims, err := svc.ValidateToken(result.Token)
+		if err != nil || claims.Role != utils.RoleAdmin {
+			t.Errorf("Expected a valid admin token, got %v (err %v)", claims, err)
+		}
+
+		// The state is single-use
+		if _, err := svc.CompleteOIDCLogin(ctx, state, oidcCode(t, provider, authURL), ClientInfo{}); !errors.Is(err, ErrOIDCState) {
+			t.Errorf("Expected ErrOIDCState on replay, got %v", err)
+		}
+	})
+
+	t.Run("unmapped user is refused", func(t *testing.T) {
+		authURL, state, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin failed: %v", err)
+		}
+		provider.SetUser("alice", []string{"family"})
+
+		if _, err := svc.CompleteOIDCLogin(ctx, state, oidcCode(t, provider, authURL), ClientInfo{}); !errors.Is(err, ErrOIDCNoRole) {
+			t.Errorf("Expected ErrOIDCNoRole, got %v", err)
+		}
+	})
+
+	t.Run("local account name is refused", func(t *testing.T) {
+		authURL, state, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin failed: %v", err)
+		}
+		provider.SetUser("Admin", []string{"media-admins"})
+
+		if _, err := svc.CompleteOIDCLogin(ctx, state, oidcCode(t, provider, authURL), ClientInfo{}); !errors.Is(err, ErrOIDCLocalName) {
+			t.Errorf("Expected ErrOIDCLocalName, got %v", err)
+		}
+	})
+
+	t.Run("unknown state is rejected", func(t *testing.T) {
+		if _, err := svc.CompleteOIDCLogin(ctx, "forged", "code", ClientInfo{}); !errors.Is(err, ErrOIDCState) {
+			t.Errorf("Expected ErrOIDCState, got %v", err)
+		}
+	})
+
+	t.Run("wrong code verifier fails the exchange", func(t *testing.T) {
+		authURL, state, err := svc.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin failed: %v", err)
+		}
+		// The code is granted for another challenge than the login's verifier
+		parsed, err := url.Parse(authURL)
+		if err != nil {
+			t.Fatalf("parsing authorization URL: %v", err)
+		}
+		query := parsed.Query()
+		query.Set("code_challenge", "not-the-challenge")
+		parsed.RawQuery = query.Encode()
+
+		if _, err := svc.CompleteOIDCLogin(ctx, state, oidcCode(t, provider, parsed.String()), ClientInfo{}); !errors.Is(err, ErrLoginProviderUnavailable) {
+			t.Errorf("Expected ErrLoginProviderUnavailable, got %v", err)
+		}
+	})
+}
+
+func TestOIDCLogin_Disabled(t *testing.T) {
+	svc := setupAuthService(t, "adminpassword")
+
+	if _, _, err := svc.StartOIDCLogin(context.Background()); !errors.Is(err, ErrOIDCDisabled) {
+		t.Errorf("Expected ErrOIDCDisabled, got %v", err)
+	}
+}
+
+func TestOIDCRole(t *testing.T) {
+	cfg := config.OIDCConfig{
+		GroupRoles:  []config.OIDCGroupRole{{Group: "ops", Role: utils.RoleOperator}},
+		DefaultRole: utils.RoleRequester,
+	}
+
+	if role := oidcRole(cfg, claimStrings([]interface{}{"ops"})); role != utils.RoleOperator {
+		t.Errorf("Expected operator, got %q", role)
+	}
+	if role := oidcRole(cfg, claimStrings("ops")); role != utils.RoleOperator {
+		t.Errorf("Expected operator from a single-string claim, got %q", role)
+	}
+	if role := oidcRole(cfg, claimStrings(nil)); role != utils.RoleRequester {
+		t.Errorf("Expected the default role, got %q", role)
+	}
+}
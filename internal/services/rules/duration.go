@@ -8,6 +8,12 @@ import (
 	"time"
 )
 
+// ParseDuration parses a duration in the config format ("90d", "24h", "30m",
+// "60s"; "never"/"0d" yield 0) for callers outside the rules package.
+func ParseDuration(s string) (time.Duration, error) {
+	return parseDuration(s)
+}
+
 // parseDuration parses duration strings like "90d", "24h", "30m", "60s",
 // or special values "never"/"0d" which disable retention (returns 0, nil).
 func parseDuration(s string) (time.Duration, error) {
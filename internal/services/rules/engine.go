@@ -3,7 +3,6 @@ package rules
 import (
 	"context"
 
-	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
@@ -13,8 +12,8 @@ import (
 // RulesEngine orchestrates two-phase rule evaluation.
 // It is safe for concurrent use after full construction.
 // Construction is a two-step process: NewRulesEngine builds the base engine,
-// then SetSonarrClient and SetDiskMonitor inject late-bound dependencies.
-// No mutation occurs after both injections are complete.
+// then SetDiskMonitor injects the late-bound disk monitor.
+// No mutation occurs after the injection is complete.
 type RulesEngine struct {
 	// protectionRules are evaluated in Phase 1 (in order).
 	// First rule returning non-nil ProtectionStatus wins.
@@ -26,20 +25,29 @@ type RulesEngine struct {
 
 	// episodeRules run in a separate chain for TV shows.
 	// They bypass the disk threshold gate.
-	episodeRules []Rule
-
-	// episodeRuleConfigs holds episode rule configs pending Sonarr client injection.
-	// Populated during NewRulesEngine; converted to EpisodeRule instances by SetSonarrClient.
-	episodeRuleConfigs []config.AdvancedRule
+	episodeRules []*EpisodeRule
 
 	diskMonitor DiskMonitor // nil if disk threshold disabled
+
+	config *config.Config // nil: evaluate against the global config
 }
 
 // NewRulesEngine constructs the engine with the canonical rule order.
 // Called once at startup; rules are immutable after construction.
 func NewRulesEngine(exclusions *storage.ExclusionsFile, diskMonitor DiskMonitor) *RulesEngine {
-	cfg := config.Get()
+	return newRulesEngine(config.Get(), exclusions, diskMonitor)
+}
+
+// NewRulesEngineForConfig constructs an engine whose rules and evaluations
+// come from cfg instead of the global config. Used to preview a config
+// before it is applied.
+func NewRulesEngineForConfig(cfg *config.Config, exclusions *storage.ExclusionsFile, diskMonitor DiskMonitor) *RulesEngine {
+	e := newRulesEngine(cfg, exclusions, diskMonitor)
+	e.config = cfg
+	return e
+}
 
+func newRulesEngine(cfg *config.Config, exclusions *storage.ExclusionsFile, diskMonitor DiskMonitor) *RulesEngine {
 	e := &RulesEngine{
 		diskMonitor: diskMonitor,
 	}
@@ -69,10 +77,8 @@ func NewRulesEngine(exclusions *storage.ExclusionsFile, diskMonitor DiskMonitor)
 			e.protectionRules = append(e.protectionRules, wr)
 			e.schedulingRules = append(e.schedulingRules, wr)
 		case "episode":
-			// Episode rules require a Sonarr client, injected later via SetSonarrClient().
-			// Store the config now; EpisodeRule instances are created on injection.
-			e.episodeRuleConfigs = append(e.episodeRuleConfigs, rule)
-			log.Debug().Str("rule", rule.Name).Msg("Episode rule registered (awaiting Sonarr client injection)")
+			e.episodeRules = append(e.episodeRules, NewEpisodeRule(rule))
+			log.Debug().Str("rule", rule.Name).Msg("Episode rule registered")
 		}
 	}
 
@@ -90,7 +96,7 @@ func (e *RulesEngine) Evaluate(ctx context.Context, media *models.Media) RuleVer
 	evalCtx := EvalContext{
 		Ctx:        ctx,
 		Media:      media,
-		Config:     config.Get(),
+		Config:     e.currentConfig(),
 		DiskStatus: e.getDiskStatus(),
 	}
 	return e.evaluateWithContext(evalCtx)
@@ -103,7 +109,7 @@ func (e *RulesEngine) EvaluateForPreview(ctx context.Context, media *models.Medi
 	evalCtx := EvalContext{
 		Ctx:        ctx,
 		Media:      media,
-		Config:     config.Get(),
+		Config:     e.currentConfig(),
 		DiskStatus: nil, // nil = DiskThresholdRule returns nil (no protection)
 	}
 	return e.evaluateWithContext(evalCtx)
@@ -155,6 +161,9 @@ func (e *RulesEngine) evaluateWithContext(ctx EvalContext) RuleVerdict {
 					ScheduleSource: source,
 					SchedulingRule: rule.Name(),
 					EpisodeFileIDs: episodeFileIDs,
+
+					UnmonitorEpisodes:    rule.unmonitorsDeleted(),
+					UnmonitorPastSeasons: rule.unmonitorsPastSeasons(),
 				}
 			}
 		}
@@ -199,13 +208,56 @@ func (e *RulesEngine) SetDiskMonitor(m DiskMonitor) {
 	e.diskMonitor = m
 }
 
-// SetSonarrClient injects a SonarrClient and instantiates any pending episode rules.
-// Called by SyncEngine after it creates the SonarrClient.
-func (e *RulesEngine) SetSonarrClient(sonarr *clients.SonarrClient) {
-	for _, ruleCfg := range e.episodeRuleConfigs {
-		e.episodeRules = append(e.episodeRules, NewEpisodeRule(ruleCfg, sonarr))
-		log.Info().Str("rule", ruleCfg.Name).Msg("Episode rule instantiated with Sonarr client")
+// NeedsEpisodes reports whether any episode rule applies to media, i.e.
+// whether the full sync must fetch the show's episodes from Sonarr.
+func (e *RulesEngine) NeedsEpisodes(media *models.Media) bool {
+	if media.Type != models.MediaTypeTVShow {
+		return false
+	}
+	for _, rule := range e.episodeRules {
+		if rule.showMatchesRule(EvalContext{Media: media}) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsEpisodeWatchState reports whether an episode rule that applies to
+// media decides by watch state, i.e. whether the full sync must also fetch
+// per-episode played state from Jellyfin.
+func (e *RulesEngine) NeedsEpisodeWatchState(media *models.Media) bool {
+	if media.Type != models.MediaTypeTVShow {
+		return false
+	}
+	for _, rule := range e.episodeRules {
+		if rule.usesWatchState() && rule.showMatchesRule(EvalContext{Media: media}) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsPerUserEpisodeWatchState reports whether an episode rule that applies
+// to media tracks watch progress per user, i.e. whether the full sync must
+// fetch each Jellyfin user's played state for the show's episodes.
+func (e *RulesEngine) NeedsPerUserEpisodeWatchState(media *models.Media) bool {
+	if media.Type != models.MediaTypeTVShow {
+		return false
+	}
+	for _, rule := range e.episodeRules {
+		if rule.perUser() && rule.showMatchesRule(EvalContext{Media: media}) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentConfig returns the config rules are evaluated against
+func (e *RulesEngine) currentConfig() *config.Config {
+	if e.config != nil {
+		return e.config
 	}
+	return config.Get()
 }
 
 // getDiskStatus returns the current disk status for use in EvalContext.
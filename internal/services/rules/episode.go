@@ -4,7 +4,6 @@ import (
 	"sort"
 	"time"
 
-	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/rs/zerolog/log"
@@ -14,14 +13,16 @@ import (
 // It lives in the separate episode chain — it never sees the DiskThresholdRule gate.
 // Episode rules populate RuleVerdict.EpisodeFileIDs with specific Sonarr episode file
 // IDs to delete rather than scheduling whole-item deletion.
+//
+// The rule reads the episode snapshot the full sync stored on the media item
+// (Media.Episodes) and never calls Sonarr, so evaluations and previews are cheap.
 type EpisodeRule struct {
-	rule         config.AdvancedRule
-	sonarrClient *clients.SonarrClient
+	rule config.AdvancedRule
 }
 
 // NewEpisodeRule creates an EpisodeRule from an AdvancedRule config entry.
-func NewEpisodeRule(rule config.AdvancedRule, sonarr *clients.SonarrClient) *EpisodeRule {
-	return &EpisodeRule{rule: rule, sonarrClient: sonarr}
+func NewEpisodeRule(rule config.AdvancedRule) *EpisodeRule {
+	return &EpisodeRule{rule: rule}
 }
 
 func (r *EpisodeRule) Name() string     { return r.rule.Name }
@@ -47,24 +48,22 @@ func (r *EpisodeRule) Schedule(ctx EvalContext) (time.Time, ScheduleSource) {
 
 	// Check continuing series protection
 	if r.rule.ExcludeContinuingSeries {
-		series, err := r.sonarrClient.GetSeriesByID(ctx.Ctx, ctx.Media.SonarrID)
-		if err != nil {
-			log.Warn().Err(err).Str("media_id", ctx.Media.ID).
-				Msg("Failed to fetch series status, skipping episode rule for safety")
+		if ctx.Media.SeriesStatus == "" {
+			log.Warn().Str("media_id", ctx.Media.ID).
+				Msg("Series status unknown, skipping episode rule for safety")
 			return time.Time{}, 0
 		}
-		if series.Status == "continuing" {
+		if ctx.Media.SeriesStatus == "continuing" {
 			log.Debug().Str("title", ctx.Media.Title).
 				Msg("Show is continuing, episode rule skipped")
 			return time.Time{}, 0
 		}
 	}
 
-	// Fetch all episodes for this show
-	episodes, err := r.sonarrClient.GetEpisodes(ctx.Ctx, ctx.Media.SonarrID)
-	if err != nil {
-		log.Warn().Err(err).Str("media_id", ctx.Media.ID).
-			Msg("Failed to fetch episodes for rule evaluation")
+	episodes := ctx.Media.Episodes
+	if episodes == nil {
+		log.Debug().Str("media_id", ctx.Media.ID).
+			Msg("No episode snapshot for show, episode rule skipped")
 		return time.Time{}, 0
 	}
 
@@ -77,15 +76,21 @@ func (r *EpisodeRule) Schedule(ctx EvalContext) (time.Time, ScheduleSource) {
 		toDelete = r.applyByAgeStrategy(episodes)
 	case "by_season_age":
 		toDelete = r.applyBySeasonAgeStrategy(episodes)
+	case "rolling":
+		toDelete = r.applyRollingStrategy(episodes)
 	default:
 		// Auto-select strategy based on which criterion is configured
 		if r.rule.MaxEpisodes > 0 {
 			toDelete = r.applyOldestFirstStrategy(episodes)
 		} else if r.rule.MaxAge != "" {
 			toDelete = r.applyByAgeStrategy(episodes)
+		} else if r.rule.WatchedRetention != "" {
+			toDelete = r.applyWatchedOnlyStrategy(episodes)
 		}
 	}
 
+	toDelete = r.applyWatchAwareness(ctx.Media, episodes, toDelete)
+
 	if len(toDelete) == 0 {
 		return time.Time{}, 0
 	}
@@ -116,9 +121,74 @@ func (r *EpisodeRule) showMatchesRule(ctx EvalContext) bool {
 	return false
 }
 
+// usesWatchState reports whether the rule's deletions depend on episode watch data.
+func (r *EpisodeRule) usesWatchState() bool {
+	return r.rule.OnlyWatchedEpisodes || r.rule.KeepNextNUnwatched > 0 || r.rule.WatchedRetention != "" ||
+		r.rule.EpisodeDeleteStrategy == "rolling"
+}
+
+// perUser reports whether the rule tracks watch progress per Jellyfin user.
+func (r *EpisodeRule) perUser() bool {
+	return r.rule.EpisodeDeleteStrategy == "rolling" && r.rule.RollingScope == "user"
+}
+
+// unmonitorsDeleted reports whether episodes whose files the rule deletes are
+// unmonitored in Sonarr. The rolling strategy always does, since a continuing
+// show would otherwise grab the deleted episodes again.
+func (r *EpisodeRule) unmonitorsDeleted() bool {
+	return r.rule.UnmonitorDeleted || r.rule.EpisodeDeleteStrategy == "rolling"
+}
+
+// unmonitorsPastSeasons reports whether a deletion by the rule also
+// unmonitors every episode of the show's seasons before its latest one.
+func (r *EpisodeRule) unmonitorsPastSeasons() bool {
+	return r.unmonitorsDeleted() && r.rule.UnmonitorPastSeasons
+}
+
+// applyRollingStrategy deletes the files of episodes more than rolling_buffer
+// episodes behind the furthest watched one, in season and episode order
+// (specials excluded). With rolling_scope "household" the furthest episode
+// anyone watched counts; with "user" it is the least advanced viewer's
+// furthest episode, so nothing is deleted that a viewer has not reached.
+func (r *EpisodeRule) applyRollingStrategy(episodes []models.Episode) []int {
+	ordered := orderedEpisodes(episodes)
+
+	position := -1
+	if r.perUser() {
+		furthest := make(map[string]int)
+		for i, ep := range ordered {
+			for _, user := range ep.WatchedBy {
+				furthest[user] = i
+			}
+		}
+		for _, i := range furthest {
+			if position == -1 || i < position {
+				position = i
+			}
+		}
+	} else {
+		for i, ep := range ordered {
+			if ep.Watched() {
+				position = i
+			}
+		}
+	}
+
+	var toDelete []int
+	for _, ep := range ordered[:max(position-r.rule.RollingBuffer, 0)] {
+		if ep.HasFile {
+			if len(r.rule.SeasonNumbers) > 0 && !containsInt(r.rule.SeasonNumbers, ep.SeasonNumber) {
+				continue
+			}
+			toDelete = append(toDelete, ep.EpisodeFileID)
+		}
+	}
+	return toDelete
+}
+
 // applyOldestFirstStrategy keeps the newest max_episodes episodes and marks the rest for deletion.
 // Episodes are sorted by air date (newest first); episodes beyond max_episodes are deleted.
-func (r *EpisodeRule) applyOldestFirstStrategy(episodes []clients.SonarrEpisode) []int {
+func (r *EpisodeRule) applyOldestFirstStrategy(episodes []models.Episode) []int {
 	// MaxEpisodes <= 0 means "not configured" — never delete when no keep-limit is set.
 	// Without this guard, an explicit oldest_first strategy with MaxEpisodes left at 0
 	// would delete every episode file.
@@ -138,7 +208,7 @@ func (r *EpisodeRule) applyOldestFirstStrategy(episodes []clients.SonarrEpisode)
 
 	// Sort by air date, newest first
 	sort.Slice(withFiles, func(i, j int) bool {
-		return episodeAirDate(withFiles[i]).After(episodeAirDate(withFiles[j]))
+		return withFiles[i].AirDate.After(withFiles[j].AirDate)
 	})
 
 	var toDelete []int
@@ -149,7 +219,7 @@ func (r *EpisodeRule) applyOldestFirstStrategy(episodes []clients.SonarrEpisode)
 }
 
 // applyByAgeStrategy deletes episodes whose air date is older than max_age.
-func (r *EpisodeRule) applyByAgeStrategy(episodes []clients.SonarrEpisode) []int {
+func (r *EpisodeRule) applyByAgeStrategy(episodes []models.Episode) []int {
 	maxAge, err := parseDuration(r.rule.MaxAge)
 	if err != nil {
 		log.Warn().Err(err).Str("rule", r.rule.Name).Msg("Invalid max_age in episode rule")
@@ -165,7 +235,7 @@ func (r *EpisodeRule) applyByAgeStrategy(episodes []clients.SonarrEpisode) []int
 		if len(r.rule.SeasonNumbers) > 0 && !containsInt(r.rule.SeasonNumbers, ep.SeasonNumber) {
 			continue
 		}
-		airDate := episodeAirDate(ep)
+		airDate := ep.AirDate
 		if airDate.IsZero() {
 			continue // skip episodes with no air date
 		}
@@ -179,7 +249,7 @@ func (r *EpisodeRule) applyByAgeStrategy(episodes []clients.SonarrEpisode) []int
 // applyBySeasonAgeStrategy deletes entire seasons whose oldest episode exceeds max_age.
 // When keep_latest_season is true, the highest-numbered season is always preserved.
 // If season_numbers is configured, only those seasons are considered for deletion.
-func (r *EpisodeRule) applyBySeasonAgeStrategy(episodes []clients.SonarrEpisode) []int {
+func (r *EpisodeRule) applyBySeasonAgeStrategy(episodes []models.Episode) []int {
 	maxAge, err := parseDuration(r.rule.MaxAge)
 	if err != nil {
 		log.Warn().Err(err).Str("rule", r.rule.Name).Msg("Invalid max_age in episode rule")
@@ -217,7 +287,7 @@ func (r *EpisodeRule) applyBySeasonAgeStrategy(episodes []clients.SonarrEpisode)
 		// This is the correct safe default: prefer keeping over deleting on missing data.
 		oldestDate := time.Now()
 		for _, ep := range seasonEpisodes {
-			airDate := episodeAirDate(ep)
+			airDate := ep.AirDate
 			if !airDate.IsZero() && airDate.Before(oldestDate) {
 				oldestDate = airDate
 			}
@@ -235,24 +305,123 @@ func (r *EpisodeRule) applyBySeasonAgeStrategy(episodes []clients.SonarrEpisode)
 	return toDelete
 }
 
-// episodeAirDate returns the best available air date for an episode.
-// Prefers AirDateUTC (full timestamp); falls back to parsing the AirDate string.
-func episodeAirDate(ep clients.SonarrEpisode) time.Time {
-	if !ep.AirDateUTC.IsZero() {
-		return ep.AirDateUTC
+// applyWatchedOnlyStrategy selects every episode file (within season_numbers,
+// when set) so that watched_retention alone decides what is deleted.
+func (r *EpisodeRule) applyWatchedOnlyStrategy(episodes []models.Episode) []int {
+	var toDelete []int
+	for _, ep := range filterHasFile(episodes) {
+		if len(r.rule.SeasonNumbers) > 0 && !containsInt(r.rule.SeasonNumbers, ep.SeasonNumber) {
+			continue
+		}
+		toDelete = append(toDelete, ep.EpisodeFileID)
+	}
+	return toDelete
+}
+
+// applyWatchAwareness drops episode files the strategy selected that are
+// unwatched (only_watched_episodes), watched too recently or at an unknown
+// time (watched_retention), or among the next keep_next_n_unwatched episodes
+// to watch. A file shared by several episodes is kept if any of them is.
+func (r *EpisodeRule) applyWatchAwareness(media *models.Media, episodes []models.Episode, toDelete []int) []int {
+	if len(toDelete) == 0 {
+		return toDelete
 	}
-	if ep.AirDate != "" {
-		t, err := time.Parse("2006-01-02", ep.AirDate)
-		if err == nil {
-			return t
+	if !r.usesWatchState() {
+		return toDelete
+	}
+
+	var cutoff time.Time
+	if r.rule.WatchedRetention != "" {
+		retention, err := parseDuration(r.rule.WatchedRetention)
+		if err != nil {
+			log.Warn().Err(err).Str("rule", r.rule.Name).Msg("Invalid watched_retention in episode rule, skipping")
+			return nil
+		}
+		cutoff = time.Now().Add(-retention)
+	}
+
+	keep := make(map[int]bool)
+	for _, fileID := range nextUnwatched(episodes, r.rule.KeepNextNUnwatched) {
+		keep[fileID] = true
+	}
+	for _, ep := range episodes {
+		if !ep.HasFile {
+			continue
+		}
+		if (r.rule.OnlyWatchedEpisodes || r.rule.WatchedRetention != "") && !ep.Watched() {
+			keep[ep.EpisodeFileID] = true
+		}
+		if r.rule.WatchedRetention != "" && (ep.LastWatched.IsZero() || ep.LastWatched.After(cutoff)) {
+			keep[ep.EpisodeFileID] = true
+		}
+	}
+
+	result := make([]int, 0, len(toDelete))
+	for _, fileID := range toDelete {
+		if !keep[fileID] {
+			result = append(result, fileID)
+		}
+	}
+	if kept := len(toDelete) - len(result); kept > 0 {
+		log.Debug().
+			Str("title", media.Title).
+			Str("rule", r.rule.Name).
+			Int("episode_files_kept", kept).
+			Msg("Episode files kept by watch state")
+	}
+	return result
+}
+
+// nextUnwatched returns the episode files of the first n unwatched episodes
+// after the furthest watched one, in season and episode order. Specials
+// (season 0) are not part of the order. When nothing has been watched the
+// show's first n episodes are returned.
+func nextUnwatched(episodes []models.Episode, n int) []int {
+	if n <= 0 {
+		return nil
+	}
+
+	ordered := orderedEpisodes(episodes)
+	furthest := -1
+	for i, ep := range ordered {
+		if ep.Watched() {
+			furthest = i
+		}
+	}
+
+	var fileIDs []int
+	for _, ep := range ordered[furthest+1:] {
+		if len(fileIDs) == n {
+			break
+		}
+		if ep.HasFile {
+			fileIDs = append(fileIDs, ep.EpisodeFileID)
 		}
 	}
-	return time.Time{}
+	return fileIDs
+}
+
+// orderedEpisodes returns the regular episodes (specials excluded) in season
+// and episode order.
+func orderedEpisodes(episodes []models.Episode) []models.Episode {
+	ordered := make([]models.Episode, 0, len(episodes))
+	for _, ep := range episodes {
+		if ep.SeasonNumber > 0 {
+			ordered = append(ordered, ep)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].SeasonNumber != ordered[j].SeasonNumber {
+			return ordered[i].SeasonNumber < ordered[j].SeasonNumber
+		}
+		return ordered[i].EpisodeNumber < ordered[j].EpisodeNumber
+	})
+	return ordered
 }
 
 // filterHasFile returns only episodes that have a downloaded file.
-func filterHasFile(episodes []clients.SonarrEpisode) []clients.SonarrEpisode {
-	result := make([]clients.SonarrEpisode, 0, len(episodes))
+func filterHasFile(episodes []models.Episode) []models.Episode {
+	result := make([]models.Episode, 0, len(episodes))
 	for _, ep := range episodes {
 		if ep.HasFile {
 			result = append(result, ep)
@@ -262,8 +431,8 @@ func filterHasFile(episodes []clients.SonarrEpisode) []clients.SonarrEpisode {
 }
 
 // filterBySeason returns only episodes belonging to the specified season numbers.
-func filterBySeason(episodes []clients.SonarrEpisode, seasons []int) []clients.SonarrEpisode {
-	result := make([]clients.SonarrEpisode, 0, len(episodes))
+func filterBySeason(episodes []models.Episode, seasons []int) []models.Episode {
+	result := make([]models.Episode, 0, len(episodes))
 	for _, ep := range episodes {
 		if containsInt(seasons, ep.SeasonNumber) {
 			result = append(result, ep)
@@ -273,8 +442,8 @@ func filterBySeason(episodes []clients.SonarrEpisode, seasons []int) []clients.S
 }
 
 // groupBySeason groups episodes by their season number.
-func groupBySeason(episodes []clients.SonarrEpisode) map[int][]clients.SonarrEpisode {
-	m := make(map[int][]clients.SonarrEpisode)
+func groupBySeason(episodes []models.Episode) map[int][]models.Episode {
+	m := make(map[int][]models.Episode)
 	for _, ep := range episodes {
 		m[ep.SeasonNumber] = append(m[ep.SeasonNumber], ep)
 	}
@@ -5,7 +5,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
 	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
@@ -162,12 +161,12 @@ func TestEpisodeRule_OldestFirst_MaxEpisodesZeroDeletesNothing(t *testing.T) {
 		Name:                  "test-episode",
 		EpisodeDeleteStrategy: "oldest_first",
 		MaxEpisodes:           0, // not configured
-	}, nil)
+	})
 
-	episodes := []clients.SonarrEpisode{
-		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -30)},
-		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -20)},
-		{EpisodeFileID: 3, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -10)},
+	episodes := []models.Episode{
+		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: time.Now().AddDate(0, 0, -30)},
+		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDate: time.Now().AddDate(0, 0, -20)},
+		{EpisodeFileID: 3, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDate: time.Now().AddDate(0, 0, -10)},
 	}
 
 	toDelete := rule.applyOldestFirstStrategy(episodes)
@@ -179,18 +178,69 @@ func TestEpisodeRule_OldestFirst_DeletesExcess(t *testing.T) {
 		Name:                  "test-episode",
 		EpisodeDeleteStrategy: "oldest_first",
 		MaxEpisodes:           2,
-	}, nil)
+	})
 
-	episodes := []clients.SonarrEpisode{
-		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -30)},
-		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -20)},
-		{EpisodeFileID: 3, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -10)},
+	episodes := []models.Episode{
+		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: time.Now().AddDate(0, 0, -30)},
+		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDate: time.Now().AddDate(0, 0, -20)},
+		{EpisodeFileID: 3, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDate: time.Now().AddDate(0, 0, -10)},
 	}
 
 	toDelete := rule.applyOldestFirstStrategy(episodes)
 	assert.ElementsMatch(t, []int{1}, toDelete) // only the oldest (1) is deleted, keeping 2 newest
 }
 
+func TestEpisodeRule_Schedule_UsesSnapshot(t *testing.T) {
+	rule := NewEpisodeRule(config.AdvancedRule{
+		Name:                    "test-episode",
+		EpisodeDeleteStrategy:   "oldest_first",
+		MaxEpisodes:             1,
+		ExcludeContinuingSeries: true,
+	})
+	episodes := []models.Episode{
+		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: time.Now().AddDate(0, 0, -30)},
+		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDate: time.Now().AddDate(0, 0, -20)},
+	}
+
+	t.Run("schedules from the snapshot", func(t *testing.T) {
+		media := models.Media{ID: "sonarr-1", Type: models.MediaTypeTVShow, SonarrID: 1, SeriesStatus: "ended", Episodes: episodes}
+		deleteAfter, source := rule.Schedule(EvalContext{Ctx: context.Background(), Media: &media})
+		assert.False(t, deleteAfter.IsZero())
+		assert.Equal(t, SourceEpisodeRule, source)
+		assert.Equal(t, []int{1}, media.EpisodeFileIDs)
+	})
+
+	t.Run("skips shows without a snapshot", func(t *testing.T) {
+		media := models.Media{ID: "sonarr-1", Type: models.MediaTypeTVShow, SonarrID: 1, SeriesStatus: "ended"}
+		deleteAfter, _ := rule.Schedule(EvalContext{Ctx: context.Background(), Media: &media})
+		assert.True(t, deleteAfter.IsZero())
+		assert.Empty(t, media.EpisodeFileIDs)
+	})
+
+	t.Run("skips continuing and unknown series", func(t *testing.T) {
+		for _, status := range []string{"continuing", ""} {
+			media := models.Media{ID: "sonarr-1", Type: models.MediaTypeTVShow, SonarrID: 1, SeriesStatus: status, Episodes: episodes}
+			deleteAfter, _ := rule.Schedule(EvalContext{Ctx: context.Background(), Media: &media})
+			assert.True(t, deleteAfter.IsZero(), "status %q", status)
+		}
+	})
+}
+
+func TestRulesEngine_NeedsEpisodes(t *testing.T) {
+	cfg := mockConfig("90d", "120d", 14)
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "kids", Type: "episode", Enabled: true, Tag: "kids", MaxEpisodes: 5},
+	}
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	engine := NewRulesEngine(mockExclusions(), nil)
+
+	assert.True(t, engine.NeedsEpisodes(&models.Media{Type: models.MediaTypeTVShow, Tags: []string{"Kids"}}))
+	assert.False(t, engine.NeedsEpisodes(&models.Media{Type: models.MediaTypeTVShow, Tags: []string{"anime"}}))
+	assert.False(t, engine.NeedsEpisodes(&models.Media{Type: models.MediaTypeMovie, Tags: []string{"kids"}}))
+}
+
 // ── parseDuration ─────────────────────────────────────────────────────────────
 
 func TestParseDuration(t *testing.T) {
@@ -2153,3 +2203,176 @@ func TestEngine_PerRule_TagRule_RetentionBaseOverride(t *testing.T) {
 	assert.False(t, verdict.ShouldDelete(), "premium tag rule: watched 1d ago with 90d retention should not be overdue")
 	assert.Equal(t, SourceTagRule, verdict.ScheduleSource)
 }
+
+func TestEpisodeRule_Schedule_WatchAwareness(t *testing.T) {
+	now := time.Now()
+	// S1E1-E3 watched long ago, S1E4 watched yesterday (rewatch in progress),
+	// S1E5-E8 unwatched
+	episodes := []models.Episode{
+		{EpisodeFileID: 1, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: now.AddDate(0, 0, -80), Played: true, LastWatched: now.AddDate(0, 0, -60)},
+		{EpisodeFileID: 2, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDate: now.AddDate(0, 0, -70), Played: true, LastWatched: now.AddDate(0, 0, -60)},
+		{EpisodeFileID: 3, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDate: now.AddDate(0, 0, -60), Played: true},
+		{EpisodeFileID: 4, SeasonNumber: 1, EpisodeNumber: 4, HasFile: true, AirDate: now.AddDate(0, 0, -50), WatchCount: 2, LastWatched: now.AddDate(0, 0, -1)},
+		{EpisodeFileID: 5, SeasonNumber: 1, EpisodeNumber: 5, HasFile: true, AirDate: now.AddDate(0, 0, -40)},
+		{EpisodeFileID: 6, SeasonNumber: 1, EpisodeNumber: 6, HasFile: true, AirDate: now.AddDate(0, 0, -30)},
+		{EpisodeFileID: 7, SeasonNumber: 1, EpisodeNumber: 7, HasFile: true, AirDate: now.AddDate(0, 0, -20)},
+		{EpisodeFileID: 8, SeasonNumber: 1, EpisodeNumber: 8, HasFile: true, AirDate: now.AddDate(0, 0, -10)},
+	}
+
+	schedule := func(rule config.AdvancedRule) []int {
+		rule.Name = "watch-aware"
+		media := models.Media{ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Show", Episodes: episodes}
+		NewEpisodeRule(rule).Schedule(EvalContext{Ctx: context.Background(), Media: &media})
+		return media.EpisodeFileIDs
+	}
+
+	tests := []struct {
+		name string
+		rule config.AdvancedRule
+		want []int
+	}{
+		{
+			name: "without watch options the strategy decides alone",
+			rule: config.AdvancedRule{MaxAge: "15d"},
+			want: []int{1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name: "only_watched_episodes keeps unwatched episodes",
+			rule: config.AdvancedRule{MaxAge: "15d", OnlyWatchedEpisodes: true},
+			want: []int{1, 2, 3, 4},
+		},
+		{
+			name: "watched_retention keeps recent and undated watches",
+			rule: config.AdvancedRule{MaxAge: "15d", WatchedRetention: "30d"},
+			want: []int{1, 2},
+		},
+		{
+			name: "watched_retention alone selects every episode",
+			rule: config.AdvancedRule{WatchedRetention: "30d"},
+			want: []int{1, 2},
+		},
+		{
+			name: "keep_next_n_unwatched keeps the next episodes to watch",
+			rule: config.AdvancedRule{MaxAge: "15d", KeepNextNUnwatched: 2},
+			want: []int{1, 2, 3, 4, 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, schedule(tt.rule))
+		})
+	}
+
+	t.Run("keep_next_n_unwatched starts at the first episode when nothing was watched", func(t *testing.T) {
+		unwatched := []models.Episode{
+			{EpisodeFileID: 12, SeasonNumber: 2, EpisodeNumber: 1, HasFile: true},
+			{EpisodeFileID: 11, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true},
+			{EpisodeFileID: 10, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true},
+			{EpisodeFileID: 99, SeasonNumber: 0, EpisodeNumber: 1, HasFile: true},
+		}
+		assert.Equal(t, []int{10, 11}, nextUnwatched(unwatched, 2))
+	})
+}
+
+func TestRulesEngine_NeedsEpisodeWatchState(t *testing.T) {
+	cfg := mockConfig("90d", "120d", 14)
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "kids", Type: "episode", Enabled: true, Tag: "kids", MaxEpisodes: 5},
+		{Name: "anime", Type: "episode", Enabled: true, Tag: "anime", MaxEpisodes: 5, KeepNextNUnwatched: 3},
+	}
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	engine := NewRulesEngine(mockExclusions(), nil)
+
+	assert.True(t, engine.NeedsEpisodeWatchState(&models.Media{Type: models.MediaTypeTVShow, Tags: []string{"anime"}}))
+	assert.False(t, engine.NeedsEpisodeWatchState(&models.Media{Type: models.MediaTypeTVShow, Tags: []string{"kids"}}))
+}
+
+func TestEpisodeRule_Schedule_Rolling(t *testing.T) {
+	// Ten episodes of a daily show. Alice is at S1E8, Bob at S1E5.
+	var episodes []models.Episode
+	for n := 1; n <= 10; n++ {
+		ep := models.Episode{ID: n, EpisodeFileID: 100 + n, SeasonNumber: 1, EpisodeNumber: n, HasFile: true}
+		if n <= 8 {
+			ep.WatchedBy = append(ep.WatchedBy, "alice")
+		}
+		if n <= 5 {
+			ep.WatchedBy = append(ep.WatchedBy, "bob")
+		}
+		episodes = append(episodes, ep)
+	}
+	special := models.Episode{ID: 99, EpisodeFileID: 199, SeasonNumber: 0, EpisodeNumber: 1, HasFile: true}
+	episodes = append(episodes, special)
+
+	schedule := func(rule config.AdvancedRule, episodes []models.Episode) []int {
+		rule.Name = "rolling"
+		rule.EpisodeDeleteStrategy = "rolling"
+		media := models.Media{ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Daily", Episodes: episodes}
+		NewEpisodeRule(rule).Schedule(EvalContext{Ctx: context.Background(), Media: &media})
+		return media.EpisodeFileIDs
+	}
+
+	t.Run("household follows the furthest viewer", func(t *testing.T) {
+		assert.Equal(t, []int{101, 102, 103, 104, 105, 106, 107}, schedule(config.AdvancedRule{}, episodes))
+	})
+
+	t.Run("household keeps a buffer", func(t *testing.T) {
+		assert.Equal(t, []int{101, 102, 103, 104, 105}, schedule(config.AdvancedRule{RollingBuffer: 2}, episodes))
+	})
+
+	t.Run("user follows the least advanced viewer", func(t *testing.T) {
+		assert.Equal(t, []int{101, 102, 103}, schedule(config.AdvancedRule{RollingScope: "user", RollingBuffer: 1}, episodes))
+	})
+
+	t.Run("nothing watched deletes nothing", func(t *testing.T) {
+		unwatched := []models.Episode{
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true},
+			{ID: 2, EpisodeFileID: 102, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true},
+		}
+		assert.Empty(t, schedule(config.AdvancedRule{}, unwatched))
+		assert.Empty(t, schedule(config.AdvancedRule{RollingScope: "user"}, unwatched))
+	})
+}
+
+func TestRulesEngine_RollingVerdictUnmonitors(t *testing.T) {
+	cfg := mockConfig("90d", "120d", 14)
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "daily", Type: "episode", Enabled: true, Tag: "daily", EpisodeDeleteStrategy: "rolling", RollingScope: "user"},
+	}
+	config.SetTestConfig(cfg)
+	defer config.SetTestConfig(nil)
+
+	engine := NewRulesEngine(mockExclusions(), nil)
+	media := models.Media{
+		ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Daily", Tags: []string{"daily"}, AddedAt: time.Now(),
+		Episodes: []models.Episode{
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, WatchedBy: []string{"alice"}},
+			{ID: 2, EpisodeFileID: 102, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, WatchedBy: []string{"alice"}},
+		},
+	}
+
+	verdict := engine.Evaluate(context.Background(), &media)
+
+	assert.Equal(t, []int{101}, verdict.EpisodeFileIDs)
+	assert.True(t, verdict.UnmonitorEpisodes)
+	assert.True(t, engine.NeedsPerUserEpisodeWatchState(&media))
+}
+
+func TestNewRulesEngineForConfig_IgnoresGlobalConfig(t *testing.T) {
+	config.SetTestConfig(mockConfig("90d", "120d", 14))
+	defer config.SetTestConfig(nil)
+
+	pinned := mockConfig("30d", "120d", 14)
+	pinned.AdvancedRules = []config.AdvancedRule{{Name: "keep", Type: "tag", Tag: "keep", Retention: "never", Enabled: true}}
+	engine := NewRulesEngineForConfig(pinned, mockExclusions(), nil)
+
+	media := mockMedia("m1", models.MediaTypeMovie, 60, -1, false)
+	verdict := engine.Evaluate(context.Background(), &media)
+	require.False(t, verdict.IsProtected)
+	assert.WithinDuration(t, media.AddedAt.AddDate(0, 0, 30), verdict.DeleteAfter, time.Second)
+
+	media.Tags = []string{"keep"}
+	verdict = engine.Evaluate(context.Background(), &media)
+	assert.True(t, verdict.IsProtected, "advanced rules come from the pinned config")
+}
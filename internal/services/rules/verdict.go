@@ -48,6 +48,12 @@ type RuleVerdict struct {
 	// Empty slice = delete the whole item (standard behavior).
 	// Non-empty = delete only these specific episode files.
 	EpisodeFileIDs []int
+	// UnmonitorEpisodes asks for the episodes whose files are deleted to be
+	// unmonitored in Sonarr so they are not grabbed again.
+	UnmonitorEpisodes bool
+	// UnmonitorPastSeasons additionally asks for every episode of the seasons
+	// before the show's latest one to be unmonitored.
+	UnmonitorPastSeasons bool
 }
 
 // ShouldDelete returns true if the item is overdue for deletion right now.
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+)
+
+// Schedule change kinds reported by PreviewRetentionRules
+const (
+	ScheduleChangeScheduled   = "scheduled"
+	ScheduleChangeUnscheduled = "unscheduled"
+	ScheduleChangeRescheduled = "rescheduled"
+)
+
+// scheduleTolerance is how far apart two deletion dates may be and still count
+// as the same. Some rules schedule relative to the time of evaluation.
+const scheduleTolerance = time.Minute
+
+// ScheduleChange describes how applying a config would change the deletion
+// schedule of one media item
+type ScheduleChange struct {
+	MediaID           string           `json:"media_id"`
+	Title             string           `json:"title"`
+	Type              models.MediaType `json:"type"`
+	Change            string           `json:"change"`
+	DeleteAfterBefore *time.Time       `json:"delete_after_before,omitempty"`
+	DeleteAfterAfter  *time.Time       `json:"delete_after_after,omitempty"`
+	ReasonBefore      string           `json:"reason_before,omitempty"`
+	ReasonAfter       string           `json:"reason_after,omitempty"`
+	// Due is set when the item was not overdue before and is after, so the
+	// next deletion pass would delete it
+	Due bool `json:"due"`
+}
+
+// SchedulePreview summarizes how re-applying retention rules under a new
+// config would change the deletion schedule
+type SchedulePreview struct {
+	Evaluated   int              `json:"evaluated"`
+	Scheduled   int              `json:"scheduled"`
+	Unscheduled int              `json:"unscheduled"`
+	Rescheduled int              `json:"rescheduled"`
+	Due         int              `json:"due"`
+	Changes     []ScheduleChange `json:"changes"`
+}
+
+// PreviewRetentionRules evaluates the cached media library against the
+// current config and against cfg, and reports the items whose deletion
+// schedule would change. Nothing is modified and no external API is called.
+// Both sides use rules engines built from their config, so advanced rule
+// changes are included; disk pressure comes from the running monitor.
+func (e *SyncEngine) PreviewRetentionRules(ctx context.Context, cfg *config.Config) SchedulePreview {
+	var diskMonitor rules.DiskMonitor
+	if e.diskMonitor != nil {
+		diskMonitor = e.diskMonitor
+	}
+	before := rules.NewRulesEngineForConfig(config.Get(), e.exclusions, diskMonitor)
+	after := rules.NewRulesEngineForConfig(cfg, e.exclusions, diskMonitor)
+
+	e.mediaLibraryLock.RLock()
+	library := make([]models.Media, 0, len(e.mediaLibrary))
+	for _, media := range e.mediaLibrary {
+		library = append(library, media)
+	}
+	e.mediaLibraryLock.RUnlock()
+
+	now := time.Now()
+	preview := SchedulePreview{Evaluated: len(library), Changes: []ScheduleChange{}}
+	for _, media := range library {
+		prevAfter, prevReason := evaluateSchedule(ctx, before, media)
+		nextAfter, nextReason := evaluateSchedule(ctx, after, media)
+
+		change := ScheduleChange{
+			MediaID:      media.ID,
+			Title:        media.Title,
+			Type:         media.Type,
+			ReasonBefore: prevReason,
+			ReasonAfter:  nextReason,
+		}
+		switch {
+		case prevAfter.IsZero() && nextAfter.IsZero():
+			continue
+		case prevAfter.IsZero():
+			change.Change = ScheduleChangeScheduled
+			preview.Scheduled++
+		case nextAfter.IsZero():
+			change.Change = ScheduleChangeUnscheduled
+			preview.Unscheduled++
+		default:
+			if diff := nextAfter.Sub(prevAfter); diff < scheduleTolerance && diff > -scheduleTolerance {
+				continue
+			}
+			change.Change = ScheduleChangeRescheduled
+			preview.Rescheduled++
+		}
+
+		if !prevAfter.IsZero() {
+			change.DeleteAfterBefore = &prevAfter
+		}
+		if !nextAfter.IsZero() {
+			change.DeleteAfterAfter = &nextAfter
+			change.Due = now.After(nextAfter) && (prevAfter.IsZero() || !now.After(prevAfter))
+		}
+		if change.Due {
+			preview.Due++
+		}
+		preview.Changes = append(preview.Changes, change)
+	}
+
+	// Items the next deletion pass would delete first, then by title
+	sort.Slice(preview.Changes, func(i, j int) bool {
+		a, b := preview.Changes[i], preview.Changes[j]
+		if a.Due != b.Due {
+			return a.Due
+		}
+		return a.Title < b.Title
+	})
+	return preview
+}
+
+// evaluateSchedule returns the deletion date and reason engine gives media,
+// the way applyRetentionRules records them
+func evaluateSchedule(ctx context.Context, engine *rules.RulesEngine, media models.Media) (time.Time, string) {
+	verdict := engine.Evaluate(ctx, &media)
+	if verdict.DeleteAfter.IsZero() {
+		return time.Time{}, ""
+	}
+	return verdict.DeleteAfter, FormatDeletionReason(verdict, &media)
+}
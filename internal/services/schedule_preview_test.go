@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncEngine_PreviewRetentionRules(t *testing.T) {
+	engine, _, _ := newTestSyncEngine(t)
+	defer config.SetTestConfig(nil)
+
+	now := time.Now()
+	engine.mediaLibrary["recent"] = models.Media{ID: "recent", Title: "Recent", Type: models.MediaTypeMovie, AddedAt: now.AddDate(0, 0, -60)}
+	engine.mediaLibrary["old"] = models.Media{ID: "old", Title: "Old", Type: models.MediaTypeMovie, AddedAt: now.AddDate(0, 0, -200)}
+	engine.mediaLibrary["show"] = models.Media{ID: "show", Title: "Show", Type: models.MediaTypeTVShow, AddedAt: now.AddDate(0, 0, -10)}
+
+	unchanged := *config.Get()
+	preview := engine.PreviewRetentionRules(context.Background(), &unchanged)
+	assert.Equal(t, 3, preview.Evaluated)
+	assert.Empty(t, preview.Changes, "the current config changes nothing")
+
+	candidate := *config.Get()
+	candidate.Rules.MovieRetention = "30d"
+	candidate.Rules.TVRetention = "never"
+	preview = engine.PreviewRetentionRules(context.Background(), &candidate)
+
+	assert.Equal(t, 0, preview.Scheduled)
+	assert.Equal(t, 1, preview.Unscheduled)
+	assert.Equal(t, 2, preview.Rescheduled)
+	assert.Equal(t, 1, preview.Due)
+	require.Len(t, preview.Changes, 3)
+
+	recent := preview.Changes[0]
+	assert.Equal(t, "recent", recent.MediaID, "items that become due are listed first")
+	assert.Equal(t, ScheduleChangeRescheduled, recent.Change)
+	assert.True(t, recent.Due)
+	require.NotNil(t, recent.DeleteAfterAfter)
+	assert.True(t, recent.DeleteAfterAfter.Before(now))
+
+	old := preview.Changes[1]
+	assert.Equal(t, ScheduleChangeRescheduled, old.Change)
+	assert.False(t, old.Due, "already overdue before the change")
+
+	show := preview.Changes[2]
+	assert.Equal(t, ScheduleChangeUnscheduled, show.Change)
+	assert.NotNil(t, show.DeleteAfterBefore)
+	assert.Nil(t, show.DeleteAfterAfter)
+	assert.Empty(t, show.ReasonAfter)
+
+	// The live schedule is left alone
+	assert.True(t, engine.mediaLibrary["recent"].DeleteAfter.IsZero())
+}
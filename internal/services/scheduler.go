@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// Scheduled job names, used as keys for next-run tracking and in logs
+const (
+	scheduleFullSync        = "full"
+	scheduleIncrementalSync = "incremental"
+	scheduleDeletion        = "deletion"
+)
+
+// buildSchedule returns the cron schedule when expr is set, otherwise a fixed
+// interval of intervalMinutes.
+func buildSchedule(expr string, intervalMinutes int) (cron.Schedule, error) {
+	if expr != "" {
+		return utils.ParseCron(expr)
+	}
+	if intervalMinutes <= 0 {
+		return nil, fmt.Errorf("interval must be positive (got %d)", intervalMinutes)
+	}
+	return cron.Every(time.Duration(intervalMinutes) * time.Minute), nil
+}
+
+// startScheduleLoop records the first run time synchronously (so status is
+// accurate as soon as Start returns) and runs fn on schedule until stop is
+// closed. The next run is computed after each run finishes, so a long sync
+// never triggers a burst of catch-up runs.
+func (e *SyncEngine) startScheduleLoop(name string, schedule cron.Schedule, stop <-chan struct{}, fn func() error) {
+	next := schedule.Next(time.Now())
+	e.setNextRun(name, next)
+
+	goRecover(func() {
+		for {
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-timer.C:
+				runSyncSafe(name, fn)
+				next = schedule.Next(time.Now())
+				e.setNextRun(name, next)
+			case <-stop:
+				timer.Stop()
+				return
+			}
+		}
+	})
+}
+
+func (e *SyncEngine) setNextRun(name string, next time.Time) {
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+	e.nextRuns[name] = next
+}
+
+// nextRun returns the next scheduled run of a job, or nil if it is not scheduled
+func (e *SyncEngine) nextRun(name string) *time.Time {
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+	next, ok := e.nextRuns[name]
+	if !ok {
+		return nil
+	}
+	return &next
+}
+
+// deletionBlackouts parses the configured deletion blackout windows. Invalid
+// windows are rejected by config validation; any that slip through are skipped.
+func deletionBlackouts() []utils.TimeWindow {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	windows := make([]utils.TimeWindow, 0, len(cfg.Sync.DeletionBlackouts))
+	for _, bw := range cfg.Sync.DeletionBlackouts {
+		w, err := utils.ParseTimeWindow(bw.Start, bw.End)
+		if err != nil {
+			log.Warn().Err(err).Str("name", bw.Name).Msg("Ignoring invalid deletion blackout window")
+			continue
+		}
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+// deletionsAllowedAt reports whether automatic deletions may run at t, and
+// if not, when the blackout covering t ends.
+func deletionsAllowedAt(t time.Time) (bool, time.Time) {
+	next := utils.NextOutside(deletionBlackouts(), t)
+	return next.Equal(t), next
+}
+
+// deferDeletionPass schedules a one-off deletion pass at `at` (the end of a
+// blackout). An already-deferred pass at or before `at` is kept instead.
+func (e *SyncEngine) deferDeletionPass(at time.Time) {
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+
+	if e.deferredDeletion != nil {
+		if !e.deferredDeletionAt.After(at) {
+			return
+		}
+		e.deferredDeletion.Stop()
+	}
+
+	e.deferredDeletionAt = at
+	e.deferredDeletion = time.AfterFunc(time.Until(at), func() {
+		e.scheduleMu.Lock()
+		e.deferredDeletion = nil
+		e.deferredDeletionAt = time.Time{}
+		e.scheduleMu.Unlock()
+
+		runSyncSafe("deferred deletion", func() error {
+			return e.runDeletionPass(context.Background(), DeletionTriggerDeferred)
+		})
+	})
+
+	log.Info().Time("run_at", at).Msg("Deletions deferred until the blackout window ends")
+}
+
+// deferredDeletionTime returns when a deferred deletion pass will run, or nil
+func (e *SyncEngine) deferredDeletionTime() *time.Time {
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+	if e.deferredDeletion == nil {
+		return nil
+	}
+	at := e.deferredDeletionAt
+	return &at
+}
+
+// RunDeletionPass computes the current deletion candidates and deletes them
+// as a deletion job, independent of a full sync. It is driven by
+// sync.deletion_cron. Like FullSync, it proposes a batch instead when approval
+// mode is on, and defers when in a blackout.
+func (e *SyncEngine) RunDeletionPass(ctx context.Context) error {
+	return e.runDeletionPass(ctx, DeletionTriggerSchedule)
+}
+
+func (e *SyncEngine) runDeletionPass(ctx context.Context, trigger string) error {
+	e.acquireSyncRunLock()
+	defer e.syncRunMu.Unlock()
+
+	if !e.config.App.EnableDeletion || e.config.App.DryRun {
+		log.Debug().Msg("Deletion pass skipped: deletion disabled or dry-run")
+		return nil
+	}
+
+	_, candidates := e.CalculateDeletionInfo()
+	if len(candidates) == 0 {
+		log.Info().Msg("Deletion pass: no items overdue")
+		return nil
+	}
+
+	if e.approvalModeEnabled() {
+		_, err := e.proposeDeletionBatch("", candidates)
+		return err
+	}
+
+	if allowed, next := deletionsAllowedAt(time.Now()); !allowed {
+		e.deferDeletionPass(next)
+		return nil
+	}
+
+	job, _ := e.startDeletionJobLocked(ctx, newDeletionJob(trigger), candidates)
+	if job.Status == storage.JobStatusFailed {
+		return fmt.Errorf("deletion job %s: %s", job.ID, job.Error)
+	}
+	return nil
+}
+
+// applyScheduleStatus fills the schedule fields of a SyncStatus
+func (e *SyncEngine) applyScheduleStatus(status *SyncStatus) {
+	cfg := config.Get()
+	if cfg == nil {
+		cfg = e.config
+	}
+	status.FullCron = cfg.Sync.FullCron
+	status.IncrCron = cfg.Sync.IncrementalCron
+	status.DeletionCron = cfg.Sync.DeletionCron
+	status.NextFullSync = e.nextRun(scheduleFullSync)
+	status.NextIncrSync = e.nextRun(scheduleIncrementalSync)
+	status.DeletionsDeferredUntil = e.deferredDeletionTime()
+
+	now := time.Now()
+	allowed, _ := deletionsAllowedAt(now)
+	status.DeletionBlackoutActive = !allowed
+
+	if !e.config.App.EnableDeletion || e.config.App.DryRun {
+		return
+	}
+
+	// Deletions run on their own schedule, or at the end of each full sync
+	next := e.nextRun(scheduleDeletion)
+	if cfg.Sync.DeletionCron == "" {
+		next = status.NextFullSync
+	}
+	if next != nil {
+		_, adjusted := deletionsAllowedAt(*next)
+		next = &adjusted
+	}
+	if deferred := status.DeletionsDeferredUntil; deferred != nil && (next == nil || deferred.Before(*next)) {
+		next = deferred
+	}
+	status.NextDeletion = next
+}
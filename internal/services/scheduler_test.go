@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScheduleTestEngine returns an engine with two overdue movies and
+// immediate deletion (approval mode off).
+func newScheduleTestEngine(t *testing.T) *SyncEngine {
+	engine, _, _, _ := newApprovalTestEngine(t)
+	engine.config.App.Approval.Enabled = false
+	t.Cleanup(func() {
+		engine.scheduleMu.Lock()
+		if engine.deferredDeletion != nil {
+			engine.deferredDeletion.Stop()
+		}
+		engine.scheduleMu.Unlock()
+	})
+	return engine
+}
+
+// blackoutAroundNow returns a daily window covering the current time
+func blackoutAroundNow() config.BlackoutWindow {
+	now := time.Now()
+	return config.BlackoutWindow{
+		Name:  "test",
+		Start: now.Add(-time.Hour).Format("15:04"),
+		End:   now.Add(time.Hour).Format("15:04"),
+	}
+}
+
+func TestSyncEngine_FullSync_DefersDeletionsInBlackout(t *testing.T) {
+	engine := newScheduleTestEngine(t)
+	config.Get().Sync.DeletionBlackouts = []config.BlackoutWindow{blackoutAroundNow()}
+
+	require.NoError(t, engine.FullSync(context.Background()))
+	assert.Equal(t, 2, engine.GetMediaCount(), "nothing may be deleted during a blackout")
+
+	deferred := engine.deferredDeletionTime()
+	require.NotNil(t, deferred)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *deferred, 2*time.Minute)
+
+	latestJob, found := engine.jobs.GetLatest()
+	require.True(t, found)
+	assert.NotNil(t, latestJob.Summary["deletions_deferred_until"])
+
+	status := engine.GetStatus()
+	assert.True(t, status.DeletionBlackoutActive)
+	require.NotNil(t, status.NextDeletion)
+	assert.Equal(t, *deferred, *status.NextDeletion)
+}
+
+func TestSyncEngine_FullSync_LeavesDeletionsToDeletionCron(t *testing.T) {
+	engine := newScheduleTestEngine(t)
+	config.Get().Sync.DeletionCron = "0 3 * * *"
+
+	require.NoError(t, engine.FullSync(context.Background()))
+	assert.Equal(t, 2, engine.GetMediaCount(), "deletions wait for the deletion schedule")
+	assert.Nil(t, engine.deferredDeletionTime())
+
+	require.NoError(t, engine.RunDeletionPass(context.Background()))
+	assert.Zero(t, engine.GetMediaCount())
+}
+
+func TestSyncEngine_RunDeletionPass(t *testing.T) {
+	t.Run("defers during a blackout", func(t *testing.T) {
+		engine := newScheduleTestEngine(t)
+		config.Get().Sync.DeletionBlackouts = []config.BlackoutWindow{blackoutAroundNow()}
+		engine.ReapplyRetentionRules()
+
+		require.NoError(t, engine.RunDeletionPass(context.Background()))
+		assert.Equal(t, 2, engine.GetMediaCount())
+		assert.NotNil(t, engine.deferredDeletionTime())
+	})
+
+	t.Run("skips when deletion is disabled", func(t *testing.T) {
+		engine := newScheduleTestEngine(t)
+		engine.config.App.DryRun = true
+		engine.ReapplyRetentionRules()
+
+		require.NoError(t, engine.RunDeletionPass(context.Background()))
+		assert.Equal(t, 2, engine.GetMediaCount())
+	})
+}
+
+func TestSyncEngine_DeferDeletionPassKeepsEarliest(t *testing.T) {
+	engine := newScheduleTestEngine(t)
+	early := time.Now().Add(time.Hour)
+
+	engine.deferDeletionPass(early)
+	engine.deferDeletionPass(early.Add(time.Hour))
+	assert.Equal(t, early, *engine.deferredDeletionTime())
+
+	earlier := time.Now().Add(30 * time.Minute)
+	engine.deferDeletionPass(earlier)
+	assert.Equal(t, earlier, *engine.deferredDeletionTime())
+}
+
+func TestSyncEngine_StatusNextRuns(t *testing.T) {
+	engine := newScheduleTestEngine(t)
+	cfg := config.Get()
+	cfg.Sync.FullCron = "0 3 * * *"
+	cfg.Sync.DeletionCron = "30 4 * * *"
+
+	full, err := buildSchedule(cfg.Sync.FullCron, cfg.Sync.FullInterval)
+	require.NoError(t, err)
+	deletion, err := buildSchedule(cfg.Sync.DeletionCron, 0)
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	engine.startScheduleLoop(scheduleFullSync, full, stop, func() error { return nil })
+	engine.startScheduleLoop(scheduleDeletion, deletion, stop, func() error { return nil })
+
+	status := engine.GetStatus()
+	require.NotNil(t, status.NextFullSync)
+	assert.Equal(t, 3, status.NextFullSync.Hour())
+	assert.Nil(t, status.NextIncrSync)
+	require.NotNil(t, status.NextDeletion)
+	assert.Equal(t, 4, status.NextDeletion.Hour())
+	assert.Equal(t, 30, status.NextDeletion.Minute())
+	assert.Equal(t, "30 4 * * *", status.DeletionCron)
+
+	// A blackout covering the deletion run pushes it to the window end
+	cfg.Sync.DeletionBlackouts = []config.BlackoutWindow{{Start: "04:00", End: "05:15"}}
+	status = engine.GetStatus()
+	require.NotNil(t, status.NextDeletion)
+	assert.Equal(t, 5, status.NextDeletion.Hour())
+	assert.Equal(t, 15, status.NextDeletion.Minute())
+}
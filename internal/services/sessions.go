@@ -0,0 +1,339 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ramonskie/oxicleanarr/internal/services/rules"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrSessionsUnavailable is returned when no session store is configured.
+	ErrSessionsUnavailable = errors.New("session storage is not configured")
+	// ErrSessionNotFound is returned when no active session has the ID, or
+	// it belongs to someone else.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrInvalidRefreshToken is returned for an unknown, expired or reused
+	// refresh token.
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	// ErrSessionRevoked is returned for an access token whose session has
+	// been revoked or has expired.
+	ErrSessionRevoked = errors.New("session has been revoked or has expired")
+)
+
+const (
+	// defaultAccessTokenTTL is used when admin.sessions.access_token_ttl is
+	// unset or invalid
+	defaultAccessTokenTTL = 15 * time.Minute
+
+	// refreshReuseGrace is how long the replaced refresh token is still
+	// accepted, so two tabs refreshing at once do not sign each other out.
+	// Using it later means it was copied, and ends the session.
+	refreshReuseGrace = 30 * time.Second
+
+	// maxUserAgentLength bounds the user agent stored with a session
+	maxUserAgentLength = 256
+)
+
+// Sign-in methods recorded on sessions
+const (
+	loginMethodPassword = "password"
+	loginMethodJellyfin = "jellyfin"
+	loginMethodTOTP     = "totp"
+	loginMethodOIDC     = "oidc"
+)
+
+// ClientInfo describes where a request comes from. The IP is used for
+// sign-in throttling; both are shown in the session list.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// SetSessions attaches the session store. Without one, sign-ins get a
+// single JWT that is valid until it expires and cannot be revoked.
+func (s *AuthService) SetSessions(sessions *storage.SessionsFile) {
+	s.sessions = sessions
+}
+
+// startSession records a new session and returns its first access and
+// refresh tokens
+func (s *AuthService) startSession(username, role, method string, client ClientInfo) (LoginResult, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	now := time.Now()
+	session := storage.Session{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Role:        role,
+		Method:      method,
+		RefreshHash: hashAPIKey(refreshToken),
+		IP:          client.IP,
+		UserAgent:   truncateUserAgent(client.UserAgent),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(utils.GetJWTExpiry()),
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	if _, err := s.sessions.RemoveWhere(func(existing storage.Session) bool { return existing.Expired(now) }); err != nil {
+		log.Warn().Err(err).Msg("Failed to remove expired sessions")
+	}
+	if err := s.sessions.Put(session); err != nil {
+		return LoginResult{}, fmt.Errorf("storing session: %w", err)
+	}
+	return s.sessionTokens(session, refreshToken, now)
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new
+// refresh token; the old refresh token stops working. The role of local
+// accounts is re-read from the user store.
+func (s *AuthService) RefreshSession(refreshToken string, client ClientInfo) (LoginResult, error) {
+	if s.sessions == nil {
+		return LoginResult{}, ErrSessionsUnavailable
+	}
+	if refreshToken == "" {
+		return LoginResult{}, ErrInvalidRefreshToken
+	}
+	hash := hashAPIKey(refreshToken)
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	now := time.Now()
+	session, ok := s.sessions.GetByRefreshHash(hash)
+	if !ok {
+		return LoginResult{}, ErrInvalidRefreshToken
+	}
+	if session.Expired(now) {
+		s.removeSession(session.ID)
+		return LoginResult{}, ErrInvalidRefreshToken
+	}
+	if hash != session.RefreshHash && now.Sub(session.RotatedAt) > refreshReuseGrace {
+		log.Warn().
+			Str("session_id", session.ID).
+			Str("username", session.Username).
+			Str("ip", client.IP).
+			Msg("Replaced refresh token was used again, revoking the session")
+		s.removeSession(session.ID)
+		return LoginResult{}, ErrInvalidRefreshToken
+	}
+
+	if s.localSession(session) {
+		user, ok := s.users.Get(session.Username)
+		if !ok {
+			s.removeSession(session.ID)
+			return LoginResult{}, ErrInvalidRefreshToken
+		}
+		session.Role = user.Role
+	}
+
+	next, err := randomToken()
+	if err != nil {
+		return LoginResult{}, err
+	}
+	session.PreviousRefreshHash = session.RefreshHash
+	session.RefreshHash = hashAPIKey(next)
+	session.RotatedAt = now
+	session.LastSeenAt = now
+	if client.IP != "" {
+		session.IP = client.IP
+	}
+	if client.UserAgent != "" {
+		session.UserAgent = truncateUserAgent(client.UserAgent)
+	}
+	if err := s.sessions.Put(session); err != nil {
+		return LoginResult{}, fmt.Errorf("storing session: %w", err)
+	}
+	return s.sessionTokens(session, next, now)
+}
+
+// sessionTokens returns a sign-in result with a new access token for
+// session. Access tokens never outlive their session.
+func (s *AuthService) sessionTokens(session storage.Session, refreshToken string, now time.Time) (LoginResult, error) {
+	ttl := min(s.accessTokenTTL(), session.ExpiresAt.Sub(now))
+	token, err := utils.GenerateSessionToken(session.Username, session.Role, session.ID, ttl)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return LoginResult{
+		Token:            token,
+		Username:         session.Username,
+		Role:             session.Role,
+		SessionID:        session.ID,
+		ExpiresAt:        now.Add(ttl),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// SessionActive reports whether the access token's session may still be
+// used, and records the activity. Without a session store every token is
+// accepted; with one, tokens issued without a session must sign in again.
+func (s *AuthService) SessionActive(claims *utils.JWTClaims) bool {
+	if s.sessions == nil {
+		return true
+	}
+	if claims.SessionID == "" {
+		return false
+	}
+
+	session, ok := s.sessions.Get(claims.SessionID)
+	now := time.Now()
+	if !ok || session.Username != claims.Username || session.Expired(now) {
+		return false
+	}
+	// External sessions started before their name was taken by a local
+	// account must not act as it
+	if !s.localSession(session) && s.isLocalName(session.Username) {
+		return false
+	}
+	if err := s.sessions.MarkSeen(session.ID, now); err != nil {
+		log.Warn().Err(err).Msg("Failed to record session activity")
+	}
+	return true
+}
+
+// ListSessions returns the active sessions of username, or of everyone when
+// username is empty, most recently seen first
+func (s *AuthService) ListSessions(username string) ([]storage.Session, error) {
+	if s.sessions == nil {
+		return nil, ErrSessionsUnavailable
+	}
+
+	now := time.Now()
+	var sessions []storage.Session
+	for _, session := range s.sessions.GetAll() {
+		if session.Expired(now) || (username != "" && session.Username != username) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends a session; its access and refresh tokens stop working
+// immediately. A non-empty username limits this to that user's sessions.
+func (s *AuthService) RevokeSession(id, username string) (storage.Session, error) {
+	if s.sessions == nil {
+		return storage.Session{}, ErrSessionsUnavailable
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok || (username != "" && session.Username != username) {
+		return storage.Session{}, ErrSessionNotFound
+	}
+	removed, err := s.sessions.Remove(id)
+	if err != nil {
+		return storage.Session{}, fmt.Errorf("removing session: %w", err)
+	}
+	if !removed {
+		return storage.Session{}, ErrSessionNotFound
+	}
+
+	log.Info().Str("session_id", id).Str("username", session.Username).Msg("Session revoked")
+	return session, nil
+}
+
+// EndSession signs out the session identified by an access token's session
+// ID or, when that is empty, by a refresh token. Unknown sessions are
+// ignored.
+func (s *AuthService) EndSession(sessionID, refreshToken string) error {
+	if s.sessions == nil {
+		return nil
+	}
+	if sessionID == "" && refreshToken != "" {
+		if session, ok := s.sessions.GetByRefreshHash(hashAPIKey(refreshToken)); ok {
+			sessionID = session.ID
+		}
+	}
+	if sessionID == "" {
+		return nil
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	_, err := s.sessions.Remove(sessionID)
+	return err
+}
+
+// RevokeUserSessions ends every session of username, e.g. after a password
+// or role change. Failures are logged; the change that caused them has already been
+// stored.
+func (s *AuthService) RevokeUserSessions(username string) {
+	if s.sessions == nil {
+		return
+	}
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	removed, err := s.sessions.RemoveWhere(func(session storage.Session) bool { return session.Username == username })
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to revoke sessions")
+		return
+	}
+	if removed > 0 {
+		log.Info().Str("username", username).Int("count", removed).Msg("Revoked sessions")
+	}
+}
+
+// removeSession drops a session whose refresh token was rejected. Callers
+// hold sessionsMu.
+func (s *AuthService) removeSession(id string) {
+	if _, err := s.sessions.Remove(id); err != nil {
+		log.Warn().Err(err).Str("session_id", id).Msg("Failed to remove session")
+	}
+}
+
+// localSession reports whether the session belongs to an account in the
+// user store, rather than a Jellyfin or single sign-on account
+func (s *AuthService) localSession(session storage.Session) bool {
+	return (session.Method == loginMethodPassword || session.Method == loginMethodTOTP) && s.hasUsers()
+}
+
+// LocalAccount reports whether the access token was issued to an account in
+// the user store by signing in with its password. Without a session store the
+// sign-in method is not known, so only the user store is consulted.
+func (s *AuthService) LocalAccount(claims *utils.JWTClaims) bool {
+	if !s.hasUsers() {
+		return false
+	}
+	if s.sessions == nil {
+		_, ok := s.users.Get(claims.Username)
+		return ok
+	}
+	session, ok := s.sessions.Get(claims.SessionID)
+	return ok && session.Username == claims.Username && s.localSession(session)
+}
+
+// accessTokenTTL returns the effective admin.sessions.access_token_ttl
+func (s *AuthService) accessTokenTTL() time.Duration {
+	if d, err := rules.ParseDuration(s.currentConfig().Admin.Sessions.AccessTokenTTL); err == nil && d > 0 {
+		return d
+	}
+	return defaultAccessTokenTTL
+}
+
+// truncateUserAgent bounds a client-supplied user agent
+func truncateUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+// setupAuthServiceWithSessions returns a service with a user store holding
+// the migrated admin and a session store
+func setupAuthServiceWithSessions(t *testing.T) *AuthService {
+	t.Helper()
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	sessions, err := storage.NewSessionsFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSessionsFile failed: %v", err)
+	}
+	svc.SetSessions(sessions)
+	return svc
+}
+
+func loginSession(t *testing.T, svc *AuthService, username, password string) LoginResult {
+	t.Helper()
+	result, err := svc.Login(context.Background(), username, password, ClientInfo{IP: "198.51.100.7", UserAgent: "Firefox"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	return result
+}
+
+func TestLogin_StartsSession(t *testing.T) {
+	svc := setupAuthServiceWithSessions(t)
+	svc.cfg.Admin.Sessions.AccessTokenTTL = "5m"
+	config.SetTestConfig(svc.cfg)
+	defer config.SetTestConfig(nil)
+
+	result := loginSession(t, svc, "admin", "adminpassword")
+	if result.SessionID == "" || result.RefreshToken == "" {
+		t.Fatalf("Expected a session and refresh token, got %+v", result)
+	}
+	if ttl := time.Until(result.ExpiresAt); ttl > 5*time.Minute || ttl < 4*time.Minute {
+		t.Errorf("Expected the access token to last about 5m, got %s", ttl)
+	}
+
+	claims, err := svc.ValidateToken(result.Token)
+	if err != nil || claims.SessionID != result.SessionID {
+		t.Fatalf("Expected the token to carry the session, got %+v err=%v", claims, err)
+	}
+
+	sessions, err := svc.ListSessions("admin")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one session, got %d err=%v", len(sessions), err)
+	}
+	if s := sessions[0]; s.IP != "198.51.100.7" || s.UserAgent != "Firefox" || s.Method != loginMethodPassword {
+		t.Errorf("Expected the client to be recorded, got %+v", s)
+	}
+	if s := sessions[0]; s.RefreshHash == result.RefreshToken {
+		t.Error("Refresh tokens must not be stored in plaintext")
+	}
+
+	// Tokens without a session cannot be revoked, so they are refused
+	legacy, _ := utils.GenerateToken("admin", utils.RoleAdmin)
+	if _, err := svc.ValidateToken(legacy); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected a token without a session to be refused, got %v", err)
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	svc := setupAuthServiceWithSessions(t)
+	first := loginSession(t, svc, "admin", "adminpassword")
+
+	second, err := svc.RefreshSession(first.RefreshToken, ClientInfo{IP: "203.0.113.9"})
+	if err != nil {
+		t.Fatalf("RefreshSession failed: %v", err)
+	}
+	if second.SessionID != first.SessionID || second.RefreshToken == first.RefreshToken || second.Token == "" {
+		t.Fatalf("Expected a rotated refresh token for the same session, got %+v", second)
+	}
+	if session, _ := svc.sessions.Get(first.SessionID); session.IP != "203.0.113.9" {
+		t.Errorf("Expected the IP to follow the client, got %q", session.IP)
+	}
+
+	// Two tabs refreshing at once: the replaced token still works briefly
+	third, err := svc.RefreshSession(first.RefreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected the replaced token to work within the grace period, got %v", err)
+	}
+	if third.SessionID != first.SessionID {
+		t.Errorf("Expected the same session, got %q", third.SessionID)
+	}
+
+	// After the grace period, reuse means the token was copied
+	session, _ := svc.sessions.Get(first.SessionID)
+	session.RotatedAt = time.Now().Add(-time.Hour)
+	if err := svc.sessions.Put(session); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, err := svc.RefreshSession(second.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Expected reuse to be refused, got %v", err)
+	}
+	if _, err := svc.RefreshSession(third.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected reuse to end the session, got %v", err)
+	}
+	if _, err := svc.ValidateToken(third.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected access tokens of the session to stop working, got %v", err)
+	}
+
+	if _, err := svc.RefreshSession("", ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected an empty token to be refused, got %v", err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	svc := setupAuthServiceWithSessions(t)
+	if _, err := svc.CreateUser("alice", "alicepassword", utils.RoleViewer); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	admin := loginSession(t, svc, "admin", "adminpassword")
+	alice := loginSession(t, svc, "alice", "alicepassword")
+
+	if _, err := svc.RevokeSession(admin.SessionID, "alice"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected users not to see other sessions, got %v", err)
+	}
+	if all, _ := svc.ListSessions(""); len(all) != 2 {
+		t.Errorf("Expected two sessions in total, got %d", len(all))
+	}
+
+	revoked, err := svc.RevokeSession(alice.SessionID, "alice")
+	if err != nil || revoked.Username != "alice" {
+		t.Fatalf("RevokeSession failed: %+v err=%v", revoked, err)
+	}
+	if _, err := svc.ValidateToken(alice.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected the access token to stop working, got %v", err)
+	}
+	if _, err := svc.RefreshSession(alice.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the refresh token to stop working, got %v", err)
+	}
+
+	// Signing out with only the refresh token ends the session too
+	if err := svc.EndSession("", admin.RefreshToken); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(admin.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected sign-out to end the session, got %v", err)
+	}
+}
+
+func TestPasswordChangeRevokesSessions(t *testing.T) {
+	svc := setupAuthServiceWithSessions(t)
+	if _, err := svc.CreateUser("alice", "alicepassword", utils.RoleViewer); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	first := loginSession(t, svc, "alice", "alicepassword")
+	admin := loginSession(t, svc, "admin", "adminpassword")
+
+	if err := svc.ChangePassword("alice", "alicepassword", "newpassword"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(first.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected the password change to end the session, got %v", err)
+	}
+	if _, err := svc.ValidateToken(admin.Token); err != nil {
+		t.Errorf("Expected other users to stay signed in, got %v", err)
+	}
+
+	second := loginSession(t, svc, "alice", "newpassword")
+	if _, err := svc.UpdateUser("alice", "resetpassword", ""); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(second.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected an admin password reset to end the session, got %v", err)
+	}
+
+	promoted := loginSession(t, svc, "alice", "resetpassword")
+	if _, err := svc.UpdateUser("alice", "", utils.RoleAdmin); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(promoted.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected a role change to end the session, got %v", err)
+	}
+	if _, err := svc.RefreshSession(promoted.RefreshToken, ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the refresh token to stop working after a role change, got %v", err)
+	}
+
+	third := loginSession(t, svc, "alice", "resetpassword")
+	if err := svc.DeleteUser("alice"); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(third.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected deleting the user to end the session, got %v", err)
+	}
+}
+
+func TestLocalAccount(t *testing.T) {
+	svc := setupAuthServiceWithSessions(t)
+
+	local := loginSession(t, svc, "admin", "adminpassword")
+	if claims, err := svc.ValidateToken(local.Token); err != nil || !svc.LocalAccount(claims) {
+		t.Errorf("Expected a password sign-in to be local, got err=%v", err)
+	}
+
+	external, err := svc.startSession("kid", utils.RoleRequester, loginMethodJellyfin, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	claims, err := svc.ValidateToken(external.Token)
+	if err != nil {
+		t.Fatalf("Expected the Jellyfin session to be valid, got %v", err)
+	}
+	if svc.LocalAccount(claims) {
+		t.Error("Expected a Jellyfin sign-in not to be local")
+	}
+
+	// A single sign-on session named like a local account cannot act as it
+	collision, err := svc.startSession("admin", utils.RoleAdmin, loginMethodOIDC, ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(collision.Token); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("Expected the colliding session to be refused, got %v", err)
+	}
+}
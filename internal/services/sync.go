@@ -4,21 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/ramonskie/oxicleanarr/internal/cache"
 	"github.com/ramonskie/oxicleanarr/internal/clients"
 	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/ramonskie/oxicleanarr/internal/metrics"
 	"github.com/ramonskie/oxicleanarr/internal/models"
 	"github.com/ramonskie/oxicleanarr/internal/services/rules"
 	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/tracing"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// episodeFetchConcurrency bounds how many shows have their episodes fetched
+// from Sonarr at once during a full sync
+const episodeFetchConcurrency = 4
+
 // SyncEngine handles media synchronization and cleanup operations
 type SyncEngine struct {
 	config            *config.Config
@@ -28,6 +41,14 @@ type SyncEngine struct {
 	manualLeavingSoon *storage.ManualLeavingSoonFile
 	rules             *rules.RulesEngine
 	diskMonitor       *DiskMonitor
+	deletionBatches   *storage.DeletionBatchesFile
+	keepRequests      *storage.KeepRequestsFile
+	// keepRequestsMu serializes keep request submissions and decisions so
+	// the pending checks and the writes they guard cannot interleave
+	keepRequestsMu sync.Mutex
+	// batchDecisionMu serializes deletion batch approvals and rejections, from
+	// selecting the pending items to storing the decisions
+	batchDecisionMu sync.Mutex
 
 	jellyfinClient   *clients.JellyfinClient
 	radarrClient     *clients.RadarrClient
@@ -37,12 +58,30 @@ type SyncEngine struct {
 
 	mediaLibrary     map[string]models.Media
 	mediaLibraryLock sync.RWMutex
-
-	fullSyncTicker *time.Ticker
-	incrSyncTicker *time.Ticker
-	stopChan       chan struct{}
-	running        bool
-	runningLock    sync.Mutex
+	// libraryGeneration counts the libraries swapped in by full syncs; guarded
+	// by mediaLibraryLock
+	libraryGeneration uint64
+
+	stopChan    chan struct{}
+	running     bool
+	runningLock sync.Mutex
+
+	// events streams engine events to GET /api/events; announced is the
+	// schedule state last published, diffed by publishScheduleChanges
+	events      *EventBus
+	announcedMu sync.Mutex
+	announced   map[string]scheduleState
+
+	// activeJobs holds queued and running jobs so they can be cancelled
+	activeJobsMu sync.Mutex
+	activeJobs   map[string]*jobRun
+
+	// scheduleMu guards the scheduler's next-run bookkeeping and the one-off
+	// deletion pass deferred out of a blackout window.
+	scheduleMu         sync.Mutex
+	nextRuns           map[string]time.Time
+	deferredDeletion   *time.Timer
+	deferredDeletionAt time.Time
 
 	// syncRunMu serializes manual/scheduled sync invocations so a full and an
 	// incremental sync (or two fulls) can't overlap and race the media library.
@@ -67,6 +106,9 @@ func NewSyncEngine(
 		rules:             rulesEngine,
 		mediaLibrary:      make(map[string]models.Media),
 		stopChan:          make(chan struct{}),
+		nextRuns:          make(map[string]time.Time),
+		activeJobs:        make(map[string]*jobRun),
+		events:            NewEventBus(DefaultEventReplaySize),
 	}
 
 	// Initialize clients based on config
@@ -78,8 +120,6 @@ func NewSyncEngine(
 	}
 	if cfg.Integrations.Sonarr.Enabled {
 		engine.sonarrClient = clients.NewSonarrClient(cfg.Integrations.Sonarr)
-		// Inject Sonarr client into rules engine so episode rules can make API calls.
-		rulesEngine.SetSonarrClient(engine.sonarrClient)
 	}
 	if cfg.Integrations.Jellyseerr.Enabled {
 		engine.jellyseerrClient = clients.NewJellyseerrClient(cfg.Integrations.Jellyseerr)
@@ -95,6 +135,7 @@ func NewSyncEngine(
 	// Inject it into the rules engine so that Evaluate() can gate on real disk status.
 	if cfg.App.DiskThreshold.Enabled {
 		engine.diskMonitor = NewDiskMonitor(engine.radarrClient, engine.sonarrClient)
+		engine.diskMonitor.SetEvents(engine.events)
 		rulesEngine.SetDiskMonitor(engine.diskMonitor)
 		log.Info().Msg("Disk monitor initialized")
 	}
@@ -115,16 +156,26 @@ func (e *SyncEngine) Start() error {
 
 	// Always read current config values (supports hot-reload)
 	cfg := config.Get()
-	fullInterval := time.Duration(cfg.Sync.FullInterval) * time.Minute
-	incrInterval := time.Duration(cfg.Sync.IncrementalInterval) * time.Minute
 
 	// Only start sync scheduler if auto-start is enabled
 	if cfg.Sync.AutoStart {
-		// Start full sync ticker
-		e.fullSyncTicker = time.NewTicker(fullInterval)
-
-		// Start incremental sync ticker
-		e.incrSyncTicker = time.NewTicker(incrInterval)
+		fullSchedule, err := buildSchedule(cfg.Sync.FullCron, cfg.Sync.FullInterval)
+		if err != nil {
+			e.running = false
+			return fmt.Errorf("invalid full sync schedule: %w", err)
+		}
+		incrSchedule, err := buildSchedule(cfg.Sync.IncrementalCron, cfg.Sync.IncrementalInterval)
+		if err != nil {
+			e.running = false
+			return fmt.Errorf("invalid incremental sync schedule: %w", err)
+		}
+		var deletionSchedule cron.Schedule
+		if cfg.Sync.DeletionCron != "" {
+			if deletionSchedule, err = utils.ParseCron(cfg.Sync.DeletionCron); err != nil {
+				e.running = false
+				return fmt.Errorf("invalid deletion schedule: %w", err)
+			}
+		}
 
 		// Run initial full sync immediately
 		goRecover(func() {
@@ -134,13 +185,26 @@ func (e *SyncEngine) Start() error {
 			}
 		})
 
-		// Start ticker goroutines
-		goRecover(e.runFullSyncLoop)
-		goRecover(e.runIncrementalSyncLoop)
+		// Start scheduler goroutines
+		stop := e.stopChan
+		e.startScheduleLoop(scheduleFullSync, fullSchedule, stop, func() error {
+			return e.FullSync(context.Background())
+		})
+		e.startScheduleLoop(scheduleIncrementalSync, incrSchedule, stop, func() error {
+			return e.IncrementalSync(context.Background())
+		})
+		if deletionSchedule != nil {
+			e.startScheduleLoop(scheduleDeletion, deletionSchedule, stop, func() error {
+				return e.RunDeletionPass(context.Background())
+			})
+		}
 
 		log.Info().
-			Dur("full_interval", fullInterval).
-			Dur("incr_interval", incrInterval).
+			Int("full_interval", cfg.Sync.FullInterval).
+			Int("incr_interval", cfg.Sync.IncrementalInterval).
+			Str("full_cron", cfg.Sync.FullCron).
+			Str("incr_cron", cfg.Sync.IncrementalCron).
+			Str("deletion_cron", cfg.Sync.DeletionCron).
 			Bool("auto_start", true).
 			Msg("Sync engine started with automatic scheduling")
 	} else {
@@ -164,20 +228,19 @@ func (e *SyncEngine) Stop() {
 	e.running = false
 	close(e.stopChan)
 
-	if e.fullSyncTicker != nil {
-		e.fullSyncTicker.Stop()
-	}
-	if e.incrSyncTicker != nil {
-		e.incrSyncTicker.Stop()
-	}
+	// A pass deferred out of a blackout is deliberately kept: it is owed
+	// work, not part of the recurring schedule.
+	e.scheduleMu.Lock()
+	e.nextRuns = make(map[string]time.Time)
+	e.scheduleMu.Unlock()
 
 	log.Info().Msg("Sync engine stopped")
 }
 
-// RestartScheduler restarts the sync scheduler with updated intervals from config
+// RestartScheduler restarts the sync scheduler with updated intervals and cron schedules from config
 // This is useful when config changes require updating the sync intervals without restarting the application
 func (e *SyncEngine) RestartScheduler() error {
-	log.Info().Msg("Restarting sync scheduler with updated intervals")
+	log.Info().Msg("Restarting sync scheduler with updated schedules")
 
 	e.runningLock.Lock()
 	wasRunning := e.running
@@ -213,36 +276,6 @@ func (e *SyncEngine) RestartScheduler() error {
 	return nil
 }
 
-// runFullSyncLoop runs full sync on schedule
-func (e *SyncEngine) runFullSyncLoop() {
-	for {
-		select {
-		case <-e.fullSyncTicker.C:
-			runSyncSafe("full", func() error {
-				ctx := context.Background()
-				return e.FullSync(ctx)
-			})
-		case <-e.stopChan:
-			return
-		}
-	}
-}
-
-// runIncrementalSyncLoop runs incremental sync on schedule
-func (e *SyncEngine) runIncrementalSyncLoop() {
-	for {
-		select {
-		case <-e.incrSyncTicker.C:
-			runSyncSafe("incremental", func() error {
-				ctx := context.Background()
-				return e.IncrementalSync(ctx)
-			})
-		case <-e.stopChan:
-			return
-		}
-	}
-}
-
 // acquireSyncRunLock serializes sync runs on syncRunMu. If another sync holds
 // the lock, it waits (the scheduler and manual triggers queue behind a running
 // sync) and logs once so the queued wait is visible in the server log.
@@ -254,81 +287,55 @@ func (e *SyncEngine) acquireSyncRunLock() {
 	e.syncRunMu.Lock()
 }
 
-// FullSync performs a complete sync of all media
+// FullSync performs a complete sync of all media. The job is recorded as
+// queued until the sync lock is free; cancelling ctx (or the job via
+// CancelJob) stops it without applying partial results.
 func (e *SyncEngine) FullSync(ctx context.Context) error {
-	// Only one sync run at a time: a manual full sync while a scheduled one is
-	// running would otherwise race the media library and rules evaluation.
-	e.acquireSyncRunLock()
-	defer e.syncRunMu.Unlock()
-
-	jobID := uuid.New().String()
-	startTime := time.Now()
-
-	log.Info().Str("job_id", jobID).Msg("Starting full sync")
-
-	// Create job entry
-	job := storage.Job{
-		ID:        jobID,
-		Type:      storage.JobTypeFullSync,
-		Status:    storage.JobStatusRunning,
-		StartedAt: startTime,
-		Summary:   make(map[string]any),
-	}
+	run, _ := e.enqueueJob(ctx, storage.Job{Type: storage.JobTypeFullSync})
+	return e.runFullSync(run)
+}
 
-	if err := e.jobs.Add(job); err != nil {
-		log.Warn().Err(err).Msg("Failed to create job entry")
+// StartFullSync queues a full sync in the background and returns its job
+// record immediately.
+func (e *SyncEngine) StartFullSync() (storage.Job, error) {
+	run, err := e.enqueueJob(context.Background(), storage.Job{Type: storage.JobTypeFullSync})
+	if err != nil {
+		run.finish(err)
+		return storage.Job{}, fmt.Errorf("creating sync job: %w", err)
 	}
+	queued := run.snapshot()
+	goRecover(func() {
+		if err := e.runFullSync(run); err != nil {
+			log.Error().Err(err).Msg("Manual full sync failed")
+		}
+	})
+	return queued, nil
+}
 
-	// Sync all services
-	movieCount := 0
-	tvShowCount := 0
-	var syncErrs []error
+// runFullSync runs a queued full sync job
+func (e *SyncEngine) runFullSync(run *jobRun) (err error) {
+	ctx := run.ctx
+	jobID := run.job.ID
 
-	// Sync movies from Radarr
-	if e.radarrClient != nil {
-		movies, err := e.syncRadarr(ctx)
-		if err != nil {
-			syncErrs = append(syncErrs, err)
-			log.Error().Err(err).Msg("Failed to sync Radarr")
-		} else {
-			movieCount = len(movies)
-		}
+	// Only one sync run at a time: a manual full sync while a scheduled one is
+	// running would otherwise race the media library and rules evaluation.
+	if err := e.lockSyncRun(ctx); err != nil {
+		run.finish(err)
+		log.Info().Str("job_id", jobID).Msg("Queued full sync cancelled")
+		return err
 	}
+	defer e.syncRunMu.Unlock()
 
-	// Sync TV shows from Sonarr
-	if e.sonarrClient != nil {
-		shows, err := e.syncSonarr(ctx)
-		if err != nil {
-			syncErrs = append(syncErrs, err)
-			log.Error().Err(err).Msg("Failed to sync Sonarr")
-		} else {
-			tvShowCount = len(shows)
-		}
-	}
+	run.begin()
+	job := &run.job
+	startTime := job.StartedAt
 
-	// Sync Jellyfin watch data
-	if e.jellyfinClient != nil {
-		if err := e.syncJellyfin(ctx); err != nil {
-			syncErrs = append(syncErrs, err)
-			log.Error().Err(err).Msg("Failed to sync Jellyfin")
-		}
-	}
+	ctx, span := tracing.Start(ctx, "sync.full", attribute.String("job.id", jobID))
+	defer func() { tracing.End(span, err) }()
 
-	// Sync detailed watch history from the active stats provider (Jellystat or Streamystats)
-	if e.statsClient != nil {
-		if err := e.syncStats(ctx); err != nil {
-			syncErrs = append(syncErrs, err)
-			log.Error().Err(err).Msg("Failed to sync stats provider")
-		}
-	}
+	log.Info().Str("job_id", jobID).Msg("Starting full sync")
 
-	// Sync requested items from Jellyseerr
-	if e.jellyseerrClient != nil {
-		if err := e.syncJellyseerr(ctx); err != nil {
-			syncErrs = append(syncErrs, err)
-			log.Error().Err(err).Msg("Failed to sync Jellyseerr")
-		}
-	} else {
+	if e.jellyseerrClient == nil {
 		// Check if user-based rules are configured but Jellyseerr is disabled
 		cfg := config.Get()
 		hasUserRules := false
@@ -344,8 +351,18 @@ func (e *SyncEngine) FullSync(ctx context.Context) error {
 		}
 	}
 
-	// Apply exclusions from file
-	e.applyExclusions()
+	// Fetch from all integrations concurrently and build the new library off
+	// the lock. Readers keep seeing the previous library until it is swapped
+	// in below, once rules have been applied to it.
+	library, removed, staged := e.stageLibrary(ctx, run)
+	syncErrs := staged.errs
+	if run.cancelled() {
+		return e.cancelFullSync(run)
+	}
+	movieCount := len(staged.movies)
+	tvShowCount := len(staged.shows)
+
+	run.startPhase(PhaseRules, 0)
 
 	// Update disk status before applying retention rules (non-fatal on failure)
 	if e.diskMonitor != nil {
@@ -354,11 +371,16 @@ func (e *SyncEngine) FullSync(ctx context.Context) error {
 		}
 	}
 
-	// Apply retention rules to all media
-	e.applyRetentionRules(ctx)
+	// Apply exclusions, then retention rules to all media
+	e.applyExclusions(library)
+	e.applyRetentionRules(ctx, library)
 
-	// Apply manual leaving soon overrides (fixed DeleteAfter, set at flag time)
-	e.applyManualLeavingSoon()
+	// Swap in the new library; exclusions changed since, and manual leaving
+	// soon overrides (fixed DeleteAfter, set at flag time), are applied as
+	// part of the swap
+	generation := e.swapLibrary(ctx, library)
+	e.publishRemovedExternally(removed)
+	e.publishScheduleChanges()
 
 	// Count items in the leaving-soon window for the job summary (used by the UI).
 	// Matches what the UI's leaving-soon page shows (GET /api/media/leaving-soon/list):
@@ -378,30 +400,52 @@ func (e *SyncEngine) FullSync(ctx context.Context) error {
 
 	// Calculate scheduled deletions and dry-run preview
 	scheduledCount, wouldDelete := e.CalculateDeletionInfo()
-
-	// Execute deletions if enabled and not in dry-run mode
-	deletedCount := 0
-	episodeFilesDeleted := 0
-	protectedCount := 0
-	failedCount := 0
-	deletedItems := make([]map[string]interface{}, 0)
-	if e.config.App.EnableDeletion && !e.config.App.DryRun && len(wouldDelete) > 0 {
-		deletedCount, _, episodeFilesDeleted, protectedCount, failedCount, deletedItems = e.ExecuteDeletions(ctx, wouldDelete)
+	run.endPhase(PhaseRules, -1, nil)
+
+	// Queue a deletion job if enabled and not in dry-run mode; it runs as its
+	// own job record once this sync's record is finalized.
+	approvalMode := e.approvalModeEnabled()
+	// With sync.deletion_cron set, deletions run on their own schedule instead.
+	deleteInline := config.Get().Sync.DeletionCron == ""
+	var proposedBatch *storage.DeletionBatch
+	var deferredUntil time.Time
+	var deletionRun *jobRun
+	if e.config.App.EnableDeletion && !e.config.App.DryRun && deleteInline && len(wouldDelete) > 0 {
+		if approvalMode {
+			// Two-step mode: propose instead of deleting; an admin approves later.
+			batch, err := e.proposeDeletionBatch(jobID, wouldDelete)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to propose deletion batch, no deletions performed")
+			}
+			proposedBatch = batch
+		} else if allowed, next := deletionsAllowedAt(time.Now()); !allowed {
+			deferredUntil = next
+			e.deferDeletionPass(next)
+		} else {
+			queued := newDeletionJob(DeletionTriggerSync)
+			queued.Summary["sync_job_id"] = jobID
+			deletionRun, _ = e.enqueueJob(context.WithoutCancel(ctx), queued)
+		}
 	}
 
 	// Update job
-	completedAt := time.Now()
-	duration := completedAt.Sub(startTime)
-
-	job.CompletedAt = &completedAt
-	job.DurationMs = duration.Milliseconds()
 	job.Summary["movies"] = movieCount
 	job.Summary["tv_shows"] = tvShowCount
 	job.Summary["total_media"] = e.GetMediaCount()
+	job.Summary["library_generation"] = generation
+	job.Summary["removed_externally"] = len(removed)
 	job.Summary["scheduled_deletions"] = scheduledCount
 	job.Summary["leaving_soon_count"] = leavingSoonCount
 	job.Summary["dry_run"] = e.config.App.DryRun
 	job.Summary["enable_deletion"] = e.config.App.EnableDeletion
+	job.Summary["approval_required"] = approvalMode
+	if proposedBatch != nil {
+		job.Summary["pending_batch_id"] = proposedBatch.ID
+		job.Summary["pending_approval_count"] = len(proposedBatch.Items)
+	}
+	if !deferredUntil.IsZero() {
+		job.Summary["deletions_deferred_until"] = deferredUntil
+	}
 
 	// Always add deletion candidates to job summary for UI display
 	// In dry-run mode, these are candidates that would be deleted
@@ -410,31 +454,11 @@ func (e *SyncEngine) FullSync(ctx context.Context) error {
 		job.Summary["would_delete"] = wouldDelete
 	}
 
-	// Add actual deletions when executed
-	if deletedCount > 0 {
-		job.Summary["deleted_count"] = deletedCount
-		job.Summary["deleted_items"] = deletedItems
-	}
-	if episodeFilesDeleted > 0 {
-		job.Summary["episode_files_deleted"] = episodeFilesDeleted
-	}
-	if failedCount > 0 {
-		job.Summary["failed_count"] = failedCount
-	}
-	if protectedCount > 0 {
-		job.Summary["protected_count"] = protectedCount
-	}
-
-	if len(syncErrs) > 0 {
-		job.Status = storage.JobStatusFailed
-		job.Error = errors.Join(syncErrs...).Error()
-	} else {
-		job.Status = storage.JobStatusCompleted
+	if deletionRun != nil {
+		job.Summary["deletion_job_id"] = deletionRun.job.ID
 	}
 
-	if err := e.jobs.Update(job); err != nil {
-		log.Warn().Err(err).Msg("Failed to update job")
-	}
+	run.finish(errors.Join(syncErrs...))
 
 	// Clear cache after full sync
 	e.cache.Clear()
@@ -444,17 +468,28 @@ func (e *SyncEngine) FullSync(ctx context.Context) error {
 		Int("movies", movieCount).
 		Int("tv_shows", tvShowCount).
 		Int("scheduled_deletions", scheduledCount).
-		Int("deleted_count", deletedCount).
 		Bool("dry_run", e.config.App.DryRun).
 		Bool("enable_deletion", e.config.App.EnableDeletion).
-		Dur("duration", duration).
+		Dur("duration", time.Since(startTime)).
 		Msg("Full sync completed")
 
+	if deletionRun != nil {
+		e.runDeletionJob(deletionRun, wouldDelete)
+	}
+
 	return errors.Join(syncErrs...)
 }
 
+// cancelFullSync records a cancelled full sync. The staged library is
+// discarded, so the live library, rules and deletions are left untouched.
+func (e *SyncEngine) cancelFullSync(run *jobRun) error {
+	run.finish(run.ctx.Err())
+	log.Warn().Str("job_id", run.job.ID).Msg("Full sync cancelled")
+	return run.ctx.Err()
+}
+
 // IncrementalSync performs a quick update of watch history
-func (e *SyncEngine) IncrementalSync(ctx context.Context) error {
+func (e *SyncEngine) IncrementalSync(ctx context.Context) (err error) {
 	// Serialize with full syncs (and other incremental syncs) so they can't
 	// overlap and race the media library.
 	e.acquireSyncRunLock()
@@ -463,16 +498,32 @@ func (e *SyncEngine) IncrementalSync(ctx context.Context) error {
 	jobID := uuid.New().String()
 	startTime := time.Now()
 
+	ctx, span := tracing.Start(ctx, "sync.incremental", attribute.String("job.id", jobID))
+	defer func() { tracing.End(span, err) }()
+
 	log.Debug().Str("job_id", jobID).Msg("Starting incremental sync")
+	e.events.Publish(EventSyncStarted, map[string]any{"job_id": jobID, "type": storage.JobTypeIncrementalSync})
 
 	// Just update watch data from Jellyfin
 	if e.jellyfinClient != nil {
 		if err := e.syncJellyfin(ctx); err != nil {
+			e.events.Publish(EventSyncCompleted, map[string]any{
+				"job_id": jobID,
+				"type":   storage.JobTypeIncrementalSync,
+				"status": storage.JobStatusFailed,
+				"error":  err.Error(),
+			})
 			return fmt.Errorf("failed to sync Jellyfin: %w", err)
 		}
 	}
 
 	duration := time.Since(startTime)
+	e.events.Publish(EventSyncCompleted, map[string]any{
+		"job_id":      jobID,
+		"type":        storage.JobTypeIncrementalSync,
+		"status":      storage.JobStatusCompleted,
+		"duration_ms": duration.Milliseconds(),
+	})
 	log.Debug().
 		Str("job_id", jobID).
 		Dur("duration", duration).
@@ -481,8 +532,232 @@ func (e *SyncEngine) IncrementalSync(ctx context.Context) error {
 	return nil
 }
 
-// syncRadarr syncs movies from Radarr
-func (e *SyncEngine) syncRadarr(ctx context.Context) ([]models.Media, error) {
+// syncStaging holds what a full sync fetched before it is merged into the new
+// library. The ok flags are false when an integration is not configured or
+// its fetch failed; its data is then carried over from the previous library.
+type syncStaging struct {
+	movies   []models.Media
+	moviesOK bool
+	shows    []models.Media
+	showsOK  bool
+
+	jellyfin   jellyfinItems
+	jellyfinOK bool
+
+	requests   []clients.JellyseerrRequest
+	requestsOK bool
+
+	history   []clients.StatsHistoryItem
+	historyOK bool
+
+	errs []error
+}
+
+// jellyfinItems are the Jellyfin library items watch data is matched from
+type jellyfinItems struct {
+	movies []clients.JellyfinItem
+	shows  []clients.JellyfinItem
+}
+
+// stageLibrary fetches from every configured integration and builds a new
+// media library without touching the live one. Radarr, Sonarr, Jellyfin and
+// Jellyseerr are fetched concurrently, each as its own job phase. The stats
+// provider is queried once the others have been merged, because it is scoped
+// to the Jellyfin IDs of library items; Jellyseerr keeps running meanwhile.
+func (e *SyncEngine) stageLibrary(ctx context.Context, run *jobRun) (map[string]models.Media, []models.Media, *syncStaging) {
+	staged := &syncStaging{}
+	var errsMu sync.Mutex
+
+	// fetch runs one integration's fetch as a job phase on its own goroutine
+	fetch := func(wg *sync.WaitGroup, phase, name string, fn func() (int, error)) {
+		wg.Add(1)
+		run.startPhase(phase, 0)
+		goRecover(func() {
+			processed, err := -1, fmt.Errorf("%s fetch panicked", name)
+			defer func() {
+				run.endPhase(phase, processed, err)
+				if err != nil {
+					log.Error().Err(err).Msgf("Failed to sync %s", name)
+					errsMu.Lock()
+					staged.errs = append(staged.errs, err)
+					errsMu.Unlock()
+				}
+				wg.Done()
+			}()
+			processed, err = fn()
+		})
+	}
+
+	var sources, requests sync.WaitGroup
+	if e.radarrClient != nil {
+		fetch(&sources, PhaseRadarr, "Radarr", func() (int, error) {
+			movies, err := e.fetchRadarr(ctx)
+			staged.movies, staged.moviesOK = movies, err == nil
+			return len(movies), err
+		})
+	}
+	if e.sonarrClient != nil {
+		fetch(&sources, PhaseSonarr, "Sonarr", func() (int, error) {
+			shows, err := e.fetchSonarr(ctx)
+			if err == nil {
+				e.fetchEpisodes(ctx, shows)
+			}
+			staged.shows, staged.showsOK = shows, err == nil
+			return len(shows), err
+		})
+	}
+	if e.jellyfinClient != nil {
+		fetch(&sources, PhaseJellyfin, "Jellyfin", func() (int, error) {
+			items, err := e.fetchJellyfin(ctx)
+			staged.jellyfin, staged.jellyfinOK = items, err == nil
+			return -1, err
+		})
+	}
+	if e.jellyseerrClient != nil {
+		fetch(&requests, PhaseJellyseerr, "Jellyseerr", func() (int, error) {
+			reqs, err := e.fetchJellyseerr(ctx)
+			staged.requests, staged.requestsOK = reqs, err == nil
+			return -1, err
+		})
+	}
+	sources.Wait()
+
+	e.mediaLibraryLock.RLock()
+	library, removed := mergeLibrary(e.mediaLibrary, staged)
+	e.mediaLibraryLock.RUnlock()
+	if staged.jellyfinOK {
+		matchJellyfin(library, staged.jellyfin)
+		e.fetchEpisodeWatchState(ctx, library)
+	}
+
+	// Sync detailed watch history from the active stats provider (Jellystat or Streamystats)
+	if e.statsClient != nil && !run.cancelled() {
+		var stats sync.WaitGroup
+		fetch(&stats, PhaseStats, "stats provider", func() (int, error) {
+			history, err := e.fetchStats(ctx, libraryJellyfinIDs(library))
+			staged.history, staged.historyOK = history, err == nil
+			return -1, err
+		})
+		stats.Wait()
+	}
+	requests.Wait()
+
+	if staged.historyOK {
+		applyStatsHistory(library, staged.history)
+	}
+	if staged.requestsOK {
+		applyJellyseerrRequests(library, staged.requests)
+	}
+	return library, removed, staged
+}
+
+// mergeLibrary builds a new library from the staged Radarr and Sonarr items
+// and returns it with the previous items that are no longer in Radarr or
+// Sonarr. Items of a type whose fetch did not succeed are carried over as they
+// were. Fresh items keep their exclusion and manual leaving soon flags, and
+// keep their Jellyfin and Jellyseerr fields when those fetches did not
+// succeed, so a failed fetch never looks like unwatched or unrequested media
+// to the rules.
+func mergeLibrary(previous map[string]models.Media, staged *syncStaging) (map[string]models.Media, []models.Media) {
+	fresh := make([]models.Media, 0, len(staged.movies)+len(staged.shows))
+	if staged.moviesOK {
+		fresh = append(fresh, staged.movies...)
+	}
+	if staged.showsOK {
+		fresh = append(fresh, staged.shows...)
+	}
+
+	library := make(map[string]models.Media, len(fresh)+len(previous))
+	for _, media := range fresh {
+		if prev, found := previous[media.ID]; found {
+			media.IsExcluded = prev.IsExcluded
+			media.IsManualLeavingSoon = prev.IsManualLeavingSoon
+			if !staged.jellyfinOK {
+				media.JellyfinID = prev.JellyfinID
+				media.WatchCount = prev.WatchCount
+				media.LastWatched = prev.LastWatched
+				media.HasPoster = prev.HasPoster
+				media.JellyfinMatchStatus = prev.JellyfinMatchStatus
+				media.JellyfinMismatchInfo = prev.JellyfinMismatchInfo
+			}
+			if !staged.requestsOK {
+				media.IsRequested = prev.IsRequested
+				media.RequestedByUserID = prev.RequestedByUserID
+				media.RequestedByUsername = prev.RequestedByUsername
+				media.RequestedByEmail = prev.RequestedByEmail
+			}
+		}
+		library[media.ID] = media
+	}
+
+	var removed []models.Media
+	for id, media := range previous {
+		if _, found := library[id]; found {
+			continue
+		}
+		if (media.Type == models.MediaTypeMovie && staged.moviesOK) ||
+			(media.Type == models.MediaTypeTVShow && staged.showsOK) {
+			removed = append(removed, media)
+			continue
+		}
+		library[id] = media
+	}
+	return library, removed
+}
+
+// swapLibrary installs library as the live media library and returns its
+// generation. Exclusions and manual leaving soon flags are applied again
+// under the lock, so ones changed while the sync was running are not lost;
+// items whose exclusion changed have their retention re-evaluated.
+func (e *SyncEngine) swapLibrary(ctx context.Context, library map[string]models.Media) uint64 {
+	e.mediaLibraryLock.Lock()
+	defer e.mediaLibraryLock.Unlock()
+
+	for _, id := range e.applyExclusions(library) {
+		library[id] = e.evaluateRetention(ctx, library[id])
+	}
+	e.applyManualLeavingSoon(library)
+	e.mediaLibrary = library
+	e.libraryGeneration++
+	return e.libraryGeneration
+}
+
+// publishRemovedExternally records items that disappeared from Radarr or
+// Sonarr since the previous sync, e.g. because they were deleted there directly
+func (e *SyncEngine) publishRemovedExternally(removed []models.Media) {
+	for _, media := range removed {
+		source := "radarr"
+		if media.Type == models.MediaTypeTVShow {
+			source = "sonarr"
+		}
+		log.Info().
+			Str("media_id", media.ID).
+			Str("title", media.Title).
+			Str("source", source).
+			Msg("Media removed externally")
+		e.events.Publish(EventItemRemoved, map[string]any{
+			"media_id":   media.ID,
+			"title":      media.Title,
+			"media_type": media.Type,
+			"source":     source,
+		})
+	}
+}
+
+// LibraryGeneration returns the generation of the live media library. It
+// starts at 0 and increases each time a full sync swaps in a new library.
+func (e *SyncEngine) LibraryGeneration() uint64 {
+	e.mediaLibraryLock.RLock()
+	defer e.mediaLibraryLock.RUnlock()
+
+	return e.libraryGeneration
+}
+
+// fetchRadarr fetches movies with files from Radarr
+func (e *SyncEngine) fetchRadarr(ctx context.Context) (_ []models.Media, err error) {
+	ctx, span := tracing.Start(ctx, "sync.radarr")
+	defer func() { tracing.End(span, err) }()
+
 	radarrMovies, err := e.radarrClient.GetMovies(ctx)
 	if err != nil {
 		return nil, err
@@ -503,9 +778,6 @@ func (e *SyncEngine) syncRadarr(ctx context.Context) ([]models.Media, error) {
 
 	mediaItems := make([]models.Media, 0, len(radarrMovies))
 
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
-
 	for _, rm := range radarrMovies {
 		if !rm.HasFile {
 			continue
@@ -542,7 +814,6 @@ func (e *SyncEngine) syncRadarr(ctx context.Context) ([]models.Media, error) {
 			}
 		}
 
-		e.mediaLibrary[mediaID] = media
 		mediaItems = append(mediaItems, media)
 	}
 
@@ -554,7 +825,12 @@ func (e *SyncEngine) syncRadarr(ctx context.Context) ([]models.Media, error) {
 
 	return mediaItems, nil
 }
-func (e *SyncEngine) syncSonarr(ctx context.Context) ([]models.Media, error) {
+
+// fetchSonarr fetches series with episode files from Sonarr
+func (e *SyncEngine) fetchSonarr(ctx context.Context) (_ []models.Media, err error) {
+	ctx, span := tracing.Start(ctx, "sync.sonarr")
+	defer func() { tracing.End(span, err) }()
+
 	sonarrSeries, err := e.sonarrClient.GetSeries(ctx)
 	if err != nil {
 		return nil, err
@@ -575,9 +851,6 @@ func (e *SyncEngine) syncSonarr(ctx context.Context) ([]models.Media, error) {
 
 	mediaItems := make([]models.Media, 0, len(sonarrSeries))
 
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
-
 	for _, ss := range sonarrSeries {
 		if ss.Statistics.EpisodeFileCount == 0 {
 			continue
@@ -594,6 +867,8 @@ func (e *SyncEngine) syncSonarr(ctx context.Context) ([]models.Media, error) {
 			FileSize: ss.Statistics.SizeOnDisk,
 			SonarrID: ss.ID,
 			TVDBID:   ss.TvdbId,
+
+			SeriesStatus: ss.Status,
 		}
 
 		// Convert tag IDs to tag names
@@ -606,7 +881,6 @@ func (e *SyncEngine) syncSonarr(ctx context.Context) ([]models.Media, error) {
 			}
 		}
 
-		e.mediaLibrary[mediaID] = media
 		mediaItems = append(mediaItems, media)
 	}
 
@@ -618,16 +892,258 @@ func (e *SyncEngine) syncSonarr(ctx context.Context) ([]models.Media, error) {
 
 	return mediaItems, nil
 }
+
+// fetchEpisodes stores an episode snapshot on each show an episode rule
+// applies to, so rule evaluation never has to call Sonarr. Shows are fetched
+// with bounded concurrency; a show whose fetch fails is left without a
+// snapshot and its episode rules are skipped until the next full sync.
+func (e *SyncEngine) fetchEpisodes(ctx context.Context, shows []models.Media) {
+	var pending []int
+	for i := range shows {
+		if e.rules.NeedsEpisodes(&shows[i]) {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "sync.sonarr.episodes", attribute.Int("series.count", len(pending)))
+	defer span.End()
+
+	var failed atomic.Int32
+	forEachBounded(ctx, pending, episodeFetchConcurrency, func(i int) {
+		show := &shows[i]
+		episodes, err := e.sonarrClient.GetEpisodes(ctx, show.SonarrID)
+		if err != nil {
+			failed.Add(1)
+			log.Warn().Err(err).Str("media_id", show.ID).
+				Msg("Failed to fetch episodes, episode rules will skip this show")
+			return
+		}
+		show.Episodes = make([]models.Episode, 0, len(episodes))
+		for _, ep := range episodes {
+			show.Episodes = append(show.Episodes, episodeFromSonarr(ep))
+		}
+	})
+
+	log.Info().
+		Int("series", len(pending)).
+		Int("failed", int(failed.Load())).
+		Msg("Sonarr episode snapshot completed")
+}
+
+// forEachBounded calls fn for each index in indexes from at most limit
+// goroutines, and stops handing out indexes once ctx is cancelled
+func forEachBounded(ctx context.Context, indexes []int, limit int, fn func(i int)) {
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < min(limit, len(indexes)); w++ {
+		wg.Add(1)
+		goRecover(func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		})
+	}
+	for _, i := range indexes {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// fetchEpisodeWatchState copies Jellyfin's per-episode played state onto the
+// episode snapshot of each matched show whose episode rules decide by watch
+// state, and for rules that track progress per user, which Jellyfin users
+// played each episode. Episodes are matched by season and episode number. A
+// show whose fetch fails keeps its episodes unwatched, which only ever keeps
+// more.
+func (e *SyncEngine) fetchEpisodeWatchState(ctx context.Context, library map[string]models.Media) {
+	var shows []models.Media
+	perUser := false
+	for _, media := range library {
+		if media.Episodes != nil && media.JellyfinID != "" && e.rules.NeedsEpisodeWatchState(&media) {
+			shows = append(shows, media)
+			perUser = perUser || e.rules.NeedsPerUserEpisodeWatchState(&media)
+		}
+	}
+	if len(shows) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "sync.jellyfin.episodes", attribute.Int("series.count", len(shows)))
+	defer span.End()
+
+	var users []clients.JellyfinUser
+	if perUser {
+		var err error
+		if users, err = e.jellyfinClient.GetUsers(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to fetch Jellyfin users, per-user episode progress unavailable")
+		}
+	}
+
+	indexes := make([]int, len(shows))
+	for i := range shows {
+		indexes[i] = i
+	}
+	var failed atomic.Int32
+	forEachBounded(ctx, indexes, episodeFetchConcurrency, func(i int) {
+		show := &shows[i]
+		items, err := e.jellyfinClient.GetEpisodes(ctx, show.JellyfinID, "")
+		if err != nil {
+			failed.Add(1)
+			log.Warn().Err(err).Str("media_id", show.ID).
+				Msg("Failed to fetch episode watch state, treating episodes as unwatched")
+			return
+		}
+		show.Episodes = withJellyfinEpisodes(show.Episodes, items)
+
+		if !e.rules.NeedsPerUserEpisodeWatchState(show) {
+			return
+		}
+		for _, user := range users {
+			items, err := e.jellyfinClient.GetEpisodes(ctx, show.JellyfinID, user.ID)
+			if err != nil {
+				failed.Add(1)
+				log.Warn().Err(err).Str("media_id", show.ID).Str("user", user.Name).
+					Msg("Failed to fetch user episode watch state, treating episodes as unwatched by user")
+				continue
+			}
+			show.Episodes = withUserPlayedEpisodes(show.Episodes, user.ID, items)
+		}
+	})
+
+	for _, show := range shows {
+		library[show.ID] = show
+	}
+
+	log.Info().
+		Int("series", len(shows)).
+		Int("users", len(users)).
+		Int("failed", int(failed.Load())).
+		Msg("Jellyfin episode watch state completed")
+}
+
+// episodePosition identifies an episode by season and episode number
+type episodePosition struct{ season, episode int }
+
+// jellyfinEpisodesByPosition indexes Jellyfin episode items by position
+func jellyfinEpisodesByPosition(items []clients.JellyfinItem) map[episodePosition]clients.JellyfinItem {
+	byPosition := make(map[episodePosition]clients.JellyfinItem, len(items))
+	for _, item := range items {
+		byPosition[episodePosition{item.ParentIndexNumber, item.IndexNumber}] = item
+	}
+	return byPosition
+}
+
+// withJellyfinEpisodes returns a copy of episodes carrying the Jellyfin ID and
+// played state of the Jellyfin episode at the same season and episode number
+func withJellyfinEpisodes(episodes []models.Episode, items []clients.JellyfinItem) []models.Episode {
+	byPosition := jellyfinEpisodesByPosition(items)
+
+	result := make([]models.Episode, len(episodes))
+	for i, ep := range episodes {
+		if item, found := byPosition[episodePosition{ep.SeasonNumber, ep.EpisodeNumber}]; found {
+			ep.JellyfinID = item.ID
+			ep.Played = item.UserData.Played
+			ep.WatchCount = item.UserData.PlayCount
+			ep.LastWatched = item.UserData.LastPlayedDate
+		}
+		result[i] = ep
+	}
+	return result
+}
+
+// withUserPlayedEpisodes returns a copy of episodes with userID added to
+// WatchedBy of each episode the user has played, per the user's Jellyfin items
+func withUserPlayedEpisodes(episodes []models.Episode, userID string, items []clients.JellyfinItem) []models.Episode {
+	byPosition := jellyfinEpisodesByPosition(items)
+
+	result := make([]models.Episode, len(episodes))
+	for i, ep := range episodes {
+		item, found := byPosition[episodePosition{ep.SeasonNumber, ep.EpisodeNumber}]
+		if found && (item.UserData.Played || item.UserData.PlayCount > 0) {
+			ep.WatchedBy = appendUnique(ep.WatchedBy, userID)
+			if item.UserData.LastPlayedDate.After(ep.LastWatched) {
+				ep.LastWatched = item.UserData.LastPlayedDate
+			}
+		}
+		result[i] = ep
+	}
+	return result
+}
+
+// appendUnique returns a copy of values with value appended unless present
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(slices.Clip(values), value)
+}
+
+// episodeFromSonarr converts a Sonarr episode, preferring its full UTC air
+// timestamp and falling back to the plain air date
+func episodeFromSonarr(ep clients.SonarrEpisode) models.Episode {
+	airDate := ep.AirDateUTC
+	if airDate.IsZero() && ep.AirDate != "" {
+		if t, err := time.Parse("2006-01-02", ep.AirDate); err == nil {
+			airDate = t
+		}
+	}
+	return models.Episode{
+		ID:            ep.ID,
+		EpisodeFileID: ep.EpisodeFileID,
+		SeasonNumber:  ep.SeasonNumber,
+		EpisodeNumber: ep.EpisodeNumber,
+		AirDate:       airDate,
+		HasFile:       ep.HasFile,
+		Monitored:     ep.Monitored,
+	}
+}
+
+// syncJellyfin updates watch data in the live library from Jellyfin
 func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
-	// Get movies
-	jellyfinMovies, err := e.jellyfinClient.GetMovies(ctx)
+	items, err := e.fetchJellyfin(ctx)
 	if err != nil {
-		return fmt.Errorf("fetching movies: %w", err)
+		return err
 	}
 
 	e.mediaLibraryLock.Lock()
 	defer e.mediaLibraryLock.Unlock()
 
+	matchJellyfin(e.mediaLibrary, items)
+	return nil
+}
+
+// fetchJellyfin fetches the Jellyfin movies and TV shows to match watch data from
+func (e *SyncEngine) fetchJellyfin(ctx context.Context) (_ jellyfinItems, err error) {
+	ctx, span := tracing.Start(ctx, "sync.jellyfin")
+	defer func() { tracing.End(span, err) }()
+
+	movies, err := e.jellyfinClient.GetMovies(ctx)
+	if err != nil {
+		return jellyfinItems{}, fmt.Errorf("fetching movies: %w", err)
+	}
+
+	shows, err := e.jellyfinClient.GetTVShows(ctx)
+	if err != nil {
+		return jellyfinItems{}, fmt.Errorf("fetching TV shows: %w", err)
+	}
+
+	return jellyfinItems{movies: movies, shows: shows}, nil
+}
+
+// matchJellyfin matches library items to Jellyfin items by TMDB/TVDB ID and
+// copies their watch data, flagging title-only matches as metadata mismatches
+func matchJellyfin(library map[string]models.Media, items jellyfinItems) {
+	jellyfinMovies := items.movies
+	jellyfinShows := items.shows
+
 	// Track matching statistics
 	movieMatched := 0
 	movieNotFound := 0
@@ -650,7 +1166,7 @@ func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
 	}
 
 	// Update watch data for movies and track mismatches
-	for id, media := range e.mediaLibrary {
+	for id, media := range library {
 		if media.Type != models.MediaTypeMovie {
 			continue
 		}
@@ -689,13 +1205,7 @@ func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
 				movieNotFound++
 			}
 		}
-		e.mediaLibrary[id] = media
-	}
-
-	// Get TV shows
-	jellyfinShows, err := e.jellyfinClient.GetTVShows(ctx)
-	if err != nil {
-		return fmt.Errorf("fetching TV shows: %w", err)
+		library[id] = media
 	}
 
 	// Build a map of Jellyfin TV shows by TVDB ID for quick lookup
@@ -712,7 +1222,7 @@ func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
 	}
 
 	// Update watch data for TV shows and track mismatches
-	for id, media := range e.mediaLibrary {
+	for id, media := range library {
 		if media.Type != models.MediaTypeTVShow {
 			continue
 		}
@@ -751,7 +1261,7 @@ func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
 				showNotFound++
 			}
 		}
-		e.mediaLibrary[id] = media
+		library[id] = media
 	}
 
 	// Log summary of Jellyfin matching results
@@ -781,20 +1291,18 @@ func (e *SyncEngine) syncJellyfin(ctx context.Context) error {
 			Int("shows", showNotFound).
 			Msg("Items not found in Jellyfin - may not be imported yet or different library paths")
 	}
-
-	return nil
 }
 
-// syncJellyseerr syncs requested items from Jellyseerr
-func (e *SyncEngine) syncJellyseerr(ctx context.Context) error {
-	requests, err := e.jellyseerrClient.GetRequests(ctx)
-	if err != nil {
-		return err
-	}
+// fetchJellyseerr fetches media requests from Jellyseerr
+func (e *SyncEngine) fetchJellyseerr(ctx context.Context) (_ []clients.JellyseerrRequest, err error) {
+	ctx, span := tracing.Start(ctx, "sync.jellyseerr")
+	defer func() { tracing.End(span, err) }()
 
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
+	return e.jellyseerrClient.GetRequests(ctx)
+}
 
+// applyJellyseerrRequests marks requested items in library
+func applyJellyseerrRequests(library map[string]models.Media, requests []clients.JellyseerrRequest) {
 	// Mark requested items
 	for _, req := range requests {
 		// Status 2 = approved, 5 = available (approved + downloaded)
@@ -804,7 +1312,7 @@ func (e *SyncEngine) syncJellyseerr(ctx context.Context) error {
 		}
 
 		// Find matching media by TMDB/TVDB ID
-		for id, media := range e.mediaLibrary {
+		for id, media := range library {
 			matched := false
 			if media.Type == models.MediaTypeMovie && media.TMDBID == req.Media.TmdbId {
 				matched = true
@@ -840,7 +1348,7 @@ func (e *SyncEngine) syncJellyseerr(ctx context.Context) error {
 					Str("resolved_username", username).
 					Msg("Matched Jellyseerr request to media")
 
-				e.mediaLibrary[id] = media
+				library[id] = media
 				break
 			}
 		}
@@ -849,45 +1357,56 @@ func (e *SyncEngine) syncJellyseerr(ctx context.Context) error {
 	log.Info().
 		Int("total_requests", len(requests)).
 		Msg("Jellyseerr sync completed")
-
-	return nil
 }
 
-// syncStats syncs detailed watch history from the active stats provider (Jellystat or Streamystats).
-func (e *SyncEngine) syncStats(ctx context.Context) error {
-	// Collect Jellyfin IDs of all known media items so that item-scoped providers
-	// (e.g. Streamystats) can query only the relevant items.
-	e.mediaLibraryLock.RLock()
-	jellyfinIDs := make([]string, 0, len(e.mediaLibrary))
-	for _, media := range e.mediaLibrary {
+// libraryJellyfinIDs returns the Jellyfin IDs of all items in library, so
+// that item-scoped stats providers (e.g. Streamystats) can query only those
+func libraryJellyfinIDs(library map[string]models.Media) []string {
+	jellyfinIDs := make([]string, 0, len(library))
+	for _, media := range library {
 		if media.JellyfinID != "" {
 			jellyfinIDs = append(jellyfinIDs, media.JellyfinID)
 		}
 	}
-	e.mediaLibraryLock.RUnlock()
+	return jellyfinIDs
+}
 
-	history, err := e.statsClient.GetHistory(ctx, jellyfinIDs)
-	if err != nil {
-		return err
-	}
+// fetchStats fetches detailed watch history from the active stats provider (Jellystat or Streamystats).
+func (e *SyncEngine) fetchStats(ctx context.Context, jellyfinIDs []string) (_ []clients.StatsHistoryItem, err error) {
+	ctx, span := tracing.Start(ctx, "sync.stats")
+	defer func() { tracing.End(span, err) }()
 
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
+	return e.statsClient.GetHistory(ctx, jellyfinIDs)
+}
 
+// applyStatsHistory updates watch data in library from stats provider history
+func applyStatsHistory(library map[string]models.Media, history []clients.StatsHistoryItem) {
 	// Build per-item maps: most recent watch timestamp and total watch count.
 	lastWatchedMap := make(map[string]time.Time)
 	watchCountMap := make(map[string]int)
+	episodeLastWatched := make(map[string]time.Time)
+	episodeWatchCount := make(map[string]int)
+	episodeWatchedBy := make(map[string][]string)
 
 	for _, item := range history {
 		if existing, found := lastWatchedMap[item.JellyfinItemID]; !found || item.WatchedAt.After(existing) {
 			lastWatchedMap[item.JellyfinItemID] = item.WatchedAt
 		}
 		watchCountMap[item.JellyfinItemID]++
+		if item.EpisodeID != "" {
+			if existing, found := episodeLastWatched[item.EpisodeID]; !found || item.WatchedAt.After(existing) {
+				episodeLastWatched[item.EpisodeID] = item.WatchedAt
+			}
+			episodeWatchCount[item.EpisodeID]++
+			if item.UserID != "" {
+				episodeWatchedBy[item.EpisodeID] = appendUnique(episodeWatchedBy[item.EpisodeID], item.UserID)
+			}
+		}
 	}
 
 	// Update media library with accurate watch data from the stats provider.
 	updatedCount := 0
-	for id, media := range e.mediaLibrary {
+	for id, media := range library {
 		if media.JellyfinID == "" {
 			continue
 		}
@@ -895,6 +1414,11 @@ func (e *SyncEngine) syncStats(ctx context.Context) error {
 		if lastWatched, found := lastWatchedMap[media.JellyfinID]; found {
 			updated := false
 
+			if len(episodeLastWatched) > 0 && media.Episodes != nil {
+				media.Episodes = withEpisodeHistory(media.Episodes, episodeLastWatched, episodeWatchCount, episodeWatchedBy)
+				updated = true
+			}
+
 			if media.LastWatched.IsZero() || lastWatched.After(media.LastWatched) {
 				media.LastWatched = lastWatched
 				updated = true
@@ -906,7 +1430,7 @@ func (e *SyncEngine) syncStats(ctx context.Context) error {
 			}
 
 			if updated {
-				e.mediaLibrary[id] = media
+				library[id] = media
 				updatedCount++
 			}
 		}
@@ -916,8 +1440,33 @@ func (e *SyncEngine) syncStats(ctx context.Context) error {
 		Int("total_history_items", len(history)).
 		Int("updated_media", updatedCount).
 		Msg("Stats provider sync completed")
+}
 
-	return nil
+// withEpisodeHistory returns a copy of episodes with watch data and viewers
+// from the stats provider's per-episode history, keyed by Jellyfin episode ID
+func withEpisodeHistory(episodes []models.Episode, lastWatched map[string]time.Time, watchCount map[string]int, watchedBy map[string][]string) []models.Episode {
+	result := make([]models.Episode, len(episodes))
+	for i, ep := range episodes {
+		if watchedAt, found := lastWatched[ep.JellyfinID]; found && ep.JellyfinID != "" {
+			if watchedAt.After(ep.LastWatched) {
+				ep.LastWatched = watchedAt
+			}
+			ep.WatchCount = max(ep.WatchCount, watchCount[ep.JellyfinID])
+			for _, userID := range watchedBy[ep.JellyfinID] {
+				ep.WatchedBy = appendUnique(ep.WatchedBy, userID)
+			}
+		}
+		result[i] = ep
+	}
+	return result
+}
+
+// SetDiskHistory injects the store that disk readings are recorded into for
+// forecasting. No-op when the disk threshold feature is disabled.
+func (e *SyncEngine) SetDiskHistory(history *storage.DiskHistoryFile) {
+	if e.diskMonitor != nil {
+		e.diskMonitor.SetHistory(history)
+	}
 }
 
 // GetDiskMonitor returns the disk monitor instance (may be nil if disabled).
@@ -960,52 +1509,62 @@ func (e *SyncEngine) GetMediaCount() int {
 	return len(e.mediaLibrary)
 }
 
-// applyRetentionRules evaluates retention rules for all media items
-func (e *SyncEngine) applyRetentionRules(ctx context.Context) {
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
+// applyRetentionRules evaluates retention rules for all items in library.
+// Callers hold mediaLibraryLock when library is the live library.
+func (e *SyncEngine) applyRetentionRules(ctx context.Context, library map[string]models.Media) {
+	ctx, span := tracing.Start(ctx, "rules.apply", attribute.Int("media.count", len(library)))
+	defer span.End()
 
-	for id, media := range e.mediaLibrary {
-		verdict := e.rules.Evaluate(ctx, &media)
+	reportTotal(ctx, len(library))
+	for id, media := range library {
+		library[id] = e.evaluateRetention(ctx, media)
+		reportProgress(ctx, 1)
+	}
 
-		// Update media with deletion date and human-readable reason from verdict
-		media.DeleteAfter = verdict.DeleteAfter
-		if !verdict.DeleteAfter.IsZero() {
-			media.DaysUntilDue = int(time.Until(verdict.DeleteAfter).Hours() / 24)
-			media.DeletionReason = FormatDeletionReason(verdict, &media)
-		} else {
-			media.DaysUntilDue = 0
-			media.DeletionReason = ""
-		}
+	log.Debug().Int("media_count", len(library)).Msg("Applied retention rules to media")
+}
 
-		e.mediaLibrary[id] = media
+// evaluateRetention returns media with the deletion date and human-readable
+// reason of its retention verdict
+func (e *SyncEngine) evaluateRetention(ctx context.Context, media models.Media) models.Media {
+	verdict := e.rules.Evaluate(ctx, &media)
+	media.DeleteAfter = verdict.DeleteAfter
+	if !verdict.DeleteAfter.IsZero() {
+		media.DaysUntilDue = int(time.Until(verdict.DeleteAfter).Hours() / 24)
+		media.DeletionReason = FormatDeletionReason(verdict, &media)
+	} else {
+		media.DaysUntilDue = 0
+		media.DeletionReason = ""
 	}
-
-	log.Debug().Int("media_count", len(e.mediaLibrary)).Msg("Applied retention rules to media")
+	return media
 }
 
 // ReapplyRetentionRules re-evaluates retention rules for all media items
 // This is useful after config changes to update deletion dates without a full sync
 func (e *SyncEngine) ReapplyRetentionRules() {
 	log.Info().Msg("Reapplying retention rules after config change")
-	e.applyRetentionRules(context.Background())
+	e.mediaLibraryLock.Lock()
+	e.applyRetentionRules(context.Background(), e.mediaLibrary)
+	e.mediaLibraryLock.Unlock()
+	e.publishScheduleChanges()
 	log.Info().Msg("Retention rules reapplied successfully")
 }
 
-// applyExclusions applies exclusions from the exclusions file to all media items
-func (e *SyncEngine) applyExclusions() {
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
-
+// applyExclusions applies exclusions from the exclusions file to all items in
+// library and returns the IDs of the items whose exclusion changed. Callers
+// hold mediaLibraryLock when library is the live library.
+func (e *SyncEngine) applyExclusions(library map[string]models.Media) []string {
+	var changed []string
 	excludedCount := 0
-	for id, media := range e.mediaLibrary {
+	for id, media := range library {
 		// Check if this media ID is in the exclusions list
 		isExcluded := e.exclusions.IsExcluded(id)
 
 		// Update the media's exclusion status
 		if media.IsExcluded != isExcluded {
 			media.IsExcluded = isExcluded
-			e.mediaLibrary[id] = media
+			library[id] = media
+			changed = append(changed, id)
 			if isExcluded {
 				excludedCount++
 			}
@@ -1013,29 +1572,27 @@ func (e *SyncEngine) applyExclusions() {
 	}
 
 	log.Debug().
-		Int("media_count", len(e.mediaLibrary)).
+		Int("media_count", len(library)).
 		Int("excluded_count", excludedCount).
 		Msg("Applied exclusions to media")
+	return changed
 }
 
 // applyManualLeavingSoon applies manual leaving soon flags to all media items.
 // Runs after applyRetentionRules — overrides DeleteAfter with the stored fixed date.
 // Excluded items are never marked as manual leaving soon (exclusion wins).
-func (e *SyncEngine) applyManualLeavingSoon() {
+func (e *SyncEngine) applyManualLeavingSoon(library map[string]models.Media) {
 	if e.manualLeavingSoon == nil {
 		return
 	}
 
-	e.mediaLibraryLock.Lock()
-	defer e.mediaLibraryLock.Unlock()
-
 	flaggedCount := 0
-	for id, media := range e.mediaLibrary {
+	for id, media := range library {
 		// Exclusion takes priority — never apply manual flag to excluded items
 		if media.IsExcluded {
 			if media.IsManualLeavingSoon {
 				media.IsManualLeavingSoon = false
-				e.mediaLibrary[id] = media
+				library[id] = media
 			}
 			continue
 		}
@@ -1047,24 +1604,25 @@ func (e *SyncEngine) applyManualLeavingSoon() {
 			media.DeleteAfter = item.DeleteAfter
 			media.DaysUntilDue = int(time.Until(item.DeleteAfter).Hours() / 24)
 			media.DeletionReason = "Manual leaving soon"
-			e.mediaLibrary[id] = media
+			library[id] = media
 			flaggedCount++
 		} else if media.IsManualLeavingSoon {
 			// Flag was removed — clear it
 			media.IsManualLeavingSoon = false
-			e.mediaLibrary[id] = media
+			library[id] = media
 		}
 	}
 
 	log.Debug().
-		Int("media_count", len(e.mediaLibrary)).
+		Int("media_count", len(library)).
 		Int("flagged_count", flaggedCount).
 		Msg("Applied manual leaving soon flags to media")
 }
 
 // AddManualLeavingSoon flags a media item for leaving soon with a fixed DeleteAfter date.
+// flaggedBy names who flagged it ("api" when empty).
 // Returns 409-style error if the item is currently excluded.
-func (e *SyncEngine) AddManualLeavingSoon(ctx context.Context, mediaID string) error {
+func (e *SyncEngine) AddManualLeavingSoon(ctx context.Context, mediaID, flaggedBy string) error {
 	media, found := e.GetMediaByID(mediaID)
 	if !found {
 		return fmt.Errorf("media not found: %s", mediaID)
@@ -1087,7 +1645,10 @@ func (e *SyncEngine) AddManualLeavingSoon(ctx context.Context, mediaID string) e
 		Title:        media.Title,
 		DeleteAfter:  deleteAfter,
 		FlaggedAt:    time.Now(),
-		FlaggedBy:    "api",
+		FlaggedBy:    flaggedBy,
+	}
+	if item.FlaggedBy == "" {
+		item.FlaggedBy = "api"
 	}
 
 	if media.RadarrID > 0 {
@@ -1110,6 +1671,7 @@ func (e *SyncEngine) AddManualLeavingSoon(ctx context.Context, mediaID string) e
 	media.DeletionReason = "Manual leaving soon"
 	e.mediaLibrary[mediaID] = media
 	e.mediaLibraryLock.Unlock()
+	e.publishScheduleChanges()
 
 	log.Info().
 		Str("media_id", mediaID).
@@ -1146,6 +1708,7 @@ func (e *SyncEngine) RemoveManualLeavingSoon(ctx context.Context, mediaID string
 	media.DeletionReason = ""
 	e.mediaLibrary[mediaID] = media
 	e.mediaLibraryLock.Unlock()
+	e.publishScheduleChanges()
 
 	log.Info().
 		Str("media_id", mediaID).
@@ -1173,32 +1736,214 @@ func (e *SyncEngine) CalculateDeletionInfo() (int, []map[string]interface{}) {
 		// Check if deletion date has passed
 		if !media.DeleteAfter.IsZero() && now.After(media.DeleteAfter) {
 			scheduledCount++
-			daysOverdue := int(now.Sub(media.DeleteAfter).Hours() / 24)
-			candidate := map[string]interface{}{
-				"id":           media.ID,
-				"jellyfin_id":  media.JellyfinID,
-				"title":        media.Title,
-				"year":         media.Year,
-				"type":         media.Type,
-				"file_size":    media.FileSize,
-				"delete_after": media.DeleteAfter,
-				"days_overdue": daysOverdue,
-				"reason":       media.DeletionReason,
-				"last_watched": media.LastWatched,
-				"has_poster":   media.HasPoster,
-				// Requester information
-				"is_requested":          media.IsRequested,
-				"requested_by_user_id":  media.RequestedByUserID,
-				"requested_by_username": media.RequestedByUsername,
-				"requested_by_email":    media.RequestedByEmail,
-			}
-			wouldDelete = append(wouldDelete, candidate)
+			wouldDelete = append(wouldDelete, deletionCandidate(media, now))
 		}
 	}
 
 	return scheduledCount, wouldDelete
 }
 
+// deletionCandidate builds the candidate map consumed by ExecuteDeletions and
+// shown in job summaries for an overdue media item.
+func deletionCandidate(media models.Media, now time.Time) map[string]interface{} {
+	daysOverdue := int(now.Sub(media.DeleteAfter).Hours() / 24)
+	return map[string]interface{}{
+		"id":           media.ID,
+		"jellyfin_id":  media.JellyfinID,
+		"title":        media.Title,
+		"year":         media.Year,
+		"type":         media.Type,
+		"file_size":    media.FileSize,
+		"delete_after": media.DeleteAfter,
+		"days_overdue": daysOverdue,
+		"reason":       media.DeletionReason,
+		"last_watched": media.LastWatched,
+		"has_poster":   media.HasPoster,
+		// Requester information
+		"is_requested":          media.IsRequested,
+		"requested_by_user_id":  media.RequestedByUserID,
+		"requested_by_username": media.RequestedByUsername,
+		"requested_by_email":    media.RequestedByEmail,
+	}
+}
+
+// DeletionReport aggregates the outcome of a deletion pass
+type DeletionReport struct {
+	Deleted               int
+	EpisodeItemsProcessed int
+	EpisodeFilesDeleted   int
+	EpisodesUnmonitored   int
+	Protected             int
+	Failed                int
+	DeletedItems          []map[string]interface{}
+	Items                 []storage.DeletionResult
+}
+
+// recordDeletionResult adds an item outcome to the report and publishes it as
+// a deletion.executed or deletion.failed event (protected items are not
+// published: nothing happened to them)
+func (e *SyncEngine) recordDeletionResult(ctx context.Context, report *DeletionReport, result storage.DeletionResult) {
+	report.Items = append(report.Items, result)
+
+	rule := result.Rule
+	if rule == "" {
+		rule = "unknown"
+	}
+	metrics.Deletions.WithLabelValues(rule, result.Outcome).Inc()
+	trace.SpanFromContext(ctx).AddEvent("deletion.result", trace.WithAttributes(
+		attribute.String("media.id", result.MediaID),
+		attribute.String("deletion.outcome", result.Outcome),
+		attribute.String("deletion.rule", rule),
+	))
+
+	data := map[string]any{"media_id": result.MediaID, "title": result.Title, "outcome": result.Outcome}
+	if run := jobRunFromContext(ctx); run != nil {
+		data["job_id"] = run.job.ID
+	}
+	if result.EpisodeFilesDeleted > 0 {
+		data["episode_files_deleted"] = result.EpisodeFilesDeleted
+	}
+	switch result.Outcome {
+	case storage.DeletionOutcomeDeleted, storage.DeletionOutcomeEpisodesDeleted:
+		e.events.Publish(EventDeletionExecuted, data)
+	case storage.DeletionOutcomeFailed:
+		data["message"] = result.Message
+		e.events.Publish(EventDeletionFailed, data)
+	}
+}
+
+// unmonitorEpisodes unmonitors in Sonarr the episodes of media whose files
+// were deleted, and with pastSeasons every episode of the seasons before the
+// show's latest one, so Sonarr does not grab them again. Only episodes that
+// are monitored are changed, so re-monitoring the returned IDs restores the
+// previous state exactly. A failure leaves the files deleted; the caller
+// records it on the item.
+func (e *SyncEngine) unmonitorEpisodes(ctx context.Context, media models.Media, deletedFileIDs []int, pastSeasons bool) ([]int, error) {
+	latestSeason := 0
+	for _, ep := range media.Episodes {
+		latestSeason = max(latestSeason, ep.SeasonNumber)
+	}
+
+	var episodeIDs []int
+	for _, ep := range media.Episodes {
+		if !ep.Monitored {
+			continue
+		}
+		deleted := ep.EpisodeFileID != 0 && slices.Contains(deletedFileIDs, ep.EpisodeFileID)
+		pastSeason := pastSeasons && ep.SeasonNumber > 0 && ep.SeasonNumber < latestSeason
+		if deleted || pastSeason {
+			episodeIDs = append(episodeIDs, ep.ID)
+		}
+	}
+	if len(episodeIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := e.sonarrClient.SetEpisodesMonitored(ctx, episodeIDs, false); err != nil {
+		log.Error().Err(err).
+			Str("show", media.Title).
+			Ints("episode_ids", episodeIDs).
+			Msg("Failed to unmonitor deleted episodes, Sonarr may grab them again")
+		return nil, err
+	}
+	return episodeIDs, nil
+}
+
+// updateEpisodeSnapshot applies update to each episode in the snapshot of a
+// show in the live library, so that evaluations before the next full sync see
+// deleted files and monitoring changes
+func (e *SyncEngine) updateEpisodeSnapshot(mediaID string, update func(ep *models.Episode)) {
+	e.mediaLibraryLock.Lock()
+	defer e.mediaLibraryLock.Unlock()
+
+	media, found := e.mediaLibrary[mediaID]
+	if !found || media.Episodes == nil {
+		return
+	}
+	episodes := slices.Clone(media.Episodes)
+	for i := range episodes {
+		update(&episodes[i])
+	}
+	media.Episodes = episodes
+	e.mediaLibrary[mediaID] = media
+}
+
+// RemonitorJobEpisodes reverses the Sonarr unmonitoring done by a deletion
+// job: every episode it unmonitored is monitored again. Items already
+// reversed are skipped. Returns the number of episodes monitored again.
+func (e *SyncEngine) RemonitorJobEpisodes(ctx context.Context, jobID string) (int, error) {
+	job, found := e.jobs.Get(jobID)
+	if !found {
+		return 0, ErrJobNotFound
+	}
+	// The stored job shares its results and summary; update copies
+	job.Results = slices.Clone(job.Results)
+	job.Summary = maps.Clone(job.Summary)
+
+	pending := 0
+	for _, result := range job.Results {
+		if len(result.EpisodesUnmonitored) > 0 && !result.Remonitored {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return 0, ErrNothingToRemonitor
+	}
+	if e.sonarrClient == nil {
+		return 0, fmt.Errorf("sonarr is not configured")
+	}
+
+	remonitored, failed := 0, 0
+	for i := range job.Results {
+		result := &job.Results[i]
+		if len(result.EpisodesUnmonitored) == 0 || result.Remonitored {
+			continue
+		}
+		if err := e.sonarrClient.SetEpisodesMonitored(ctx, result.EpisodesUnmonitored, true); err != nil {
+			failed++
+			log.Error().Err(err).
+				Str("job_id", jobID).
+				Str("media_id", result.MediaID).
+				Msg("Failed to monitor episodes again")
+			continue
+		}
+		result.Remonitored = true
+		remonitored += len(result.EpisodesUnmonitored)
+		e.updateEpisodeSnapshot(result.MediaID, func(ep *models.Episode) {
+			if slices.Contains(result.EpisodesUnmonitored, ep.ID) {
+				ep.Monitored = true
+			}
+		})
+	}
+
+	if remonitored > 0 {
+		total := 0
+		for _, result := range job.Results {
+			if result.Remonitored {
+				total += len(result.EpisodesUnmonitored)
+			}
+		}
+		if job.Summary == nil {
+			job.Summary = make(map[string]any)
+		}
+		job.Summary["episodes_remonitored"] = total
+		if err := e.jobs.Update(job); err != nil {
+			log.Error().Err(err).Str("job_id", jobID).Msg("Failed to record re-monitored episodes on job")
+		}
+	}
+
+	log.Info().
+		Str("job_id", jobID).
+		Int("episodes_remonitored", remonitored).
+		Int("failed", failed).
+		Msg("Re-monitored episodes unmonitored by deletion job")
+
+	if failed > 0 {
+		return remonitored, fmt.Errorf("re-monitoring failed for %d of %d item(s)", failed, pending)
+	}
+	return remonitored, nil
+}
+
 // ExecuteDeletions performs actual deletion of overdue media items.
 // Before each whole-item deletion, a pre-deletion safety check refreshes the watch state
 // from Jellystat to catch any watch activity that occurred after the last evaluation.
@@ -1216,12 +1961,28 @@ func (e *SyncEngine) CalculateDeletionInfo() (int, []map[string]interface{}) {
 //     where at least one episode-file deletion failed. An episode candidate can therefore
 //     contribute to both episodeItemsProcessed and failedCount.
 func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[string]interface{}) (int, int, int, int, int, []map[string]interface{}) {
-	deletedCount := 0
-	episodeItemsProcessed := 0 // candidates handled via episode-level deletion (not whole-item)
-	episodeFilesDeleted := 0
-	protectedCount := 0
-	failedCount := 0
-	deletedItems := make([]map[string]interface{}, 0)
+	report := e.executeDeletions(ctx, candidates)
+	return report.Deleted, report.EpisodeItemsProcessed, report.EpisodeFilesDeleted, report.Protected, report.Failed, report.DeletedItems
+}
+
+// executeDeletions is the implementation behind ExecuteDeletions; it also
+// records a per-candidate result so callers can report outcomes item by item.
+func (e *SyncEngine) executeDeletions(ctx context.Context, candidates []map[string]interface{}) DeletionReport {
+	report := DeletionReport{
+		DeletedItems: make([]map[string]interface{}, 0),
+		Items:        make([]storage.DeletionResult, 0, len(candidates)),
+	}
+
+	ctx, span := tracing.Start(ctx, "deletions.execute", attribute.Int("deletions.candidates", len(candidates)))
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("deletions.deleted", report.Deleted),
+			attribute.Int("deletions.episode_files_deleted", report.EpisodeFilesDeleted),
+			attribute.Int("deletions.protected", report.Protected),
+			attribute.Int("deletions.failed", report.Failed),
+		)
+		span.End()
+	}()
 
 	log.Info().
 		Int("candidates", len(candidates)).
@@ -1244,21 +2005,42 @@ func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[stri
 			log.Warn().
 				Err(err).
 				Msg("Pre-deletion safety check failed — skipping all deletions for safety")
-			return 0, 0, 0, 0, len(candidates), deletedItems
+			report.Failed = len(candidates)
+			for _, candidate := range candidates {
+				mediaID, _ := candidate["id"].(string)
+				title, _ := candidate["title"].(string)
+				e.recordDeletionResult(ctx, &report, storage.DeletionResult{
+					MediaID: mediaID,
+					Title:   title,
+					Outcome: storage.DeletionOutcomeFailed,
+					Message: "pre-deletion safety check failed: " + err.Error(),
+				})
+			}
+			return report
 		}
 	}
 
 	for _, candidate := range candidates {
+		// Cancellation point between items: never abandon an item half-deleted
+		if ctx.Err() != nil {
+			log.Warn().Msg("Deletion pass cancelled, remaining candidates skipped")
+			break
+		}
+		reportProgress(ctx, 1)
+
 		mediaID, ok := candidate["id"].(string)
+		title, _ := candidate["title"].(string)
 		if !ok {
-			failedCount++
+			report.Failed++
+			e.recordDeletionResult(ctx, &report, storage.DeletionResult{Title: title, Outcome: storage.DeletionOutcomeFailed, Message: "invalid media ID"})
 			log.Warn().Interface("candidate", candidate).Msg("Invalid media ID in deletion candidate")
 			continue
 		}
 
 		media, found := e.GetMediaByID(mediaID)
 		if !found {
-			failedCount++
+			report.Failed++
+			e.recordDeletionResult(ctx, &report, storage.DeletionResult{MediaID: mediaID, Title: title, Outcome: storage.DeletionOutcomeFailed, Message: "media not found in library"})
 			log.Warn().Str("media_id", mediaID).Msg("Media not found in library, skipping deletion")
 			continue
 		}
@@ -1271,6 +2053,8 @@ func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[stri
 			// Recent show-level watch activity should not protect old episodes
 			// from rolling-window or age-based cleanup.
 			episodeFailures := 0
+			filesDeleted := 0
+			var deletedFileIDs []int
 			for _, episodeFileID := range verdict.EpisodeFileIDs {
 				if e.sonarrClient == nil {
 					log.Warn().Msg("Sonarr client not available for episode file deletion")
@@ -1285,19 +2069,44 @@ func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[stri
 						Msg("Failed to delete episode file")
 					continue
 				}
-				episodeFilesDeleted++
+				filesDeleted++
+				deletedFileIDs = append(deletedFileIDs, episodeFileID)
 				log.Info().
 					Int("episode_file_id", episodeFileID).
 					Str("show", media.Title).
 					Msg("Episode file deleted")
 			}
+			result := storage.DeletionResult{MediaID: mediaID, Title: media.Title, Outcome: storage.DeletionOutcomeEpisodesDeleted, Rule: verdict.SchedulingRule, EpisodeFilesDeleted: filesDeleted}
+			var problems []string
+			if verdict.UnmonitorEpisodes && filesDeleted > 0 {
+				unmonitored, err := e.unmonitorEpisodes(ctx, media, deletedFileIDs, verdict.UnmonitorPastSeasons)
+				if err != nil {
+					problems = append(problems, "unmonitoring failed: "+err.Error())
+				}
+				result.EpisodesUnmonitored = unmonitored
+				report.EpisodesUnmonitored += len(unmonitored)
+			}
+			e.updateEpisodeSnapshot(mediaID, func(ep *models.Episode) {
+				if ep.EpisodeFileID != 0 && slices.Contains(deletedFileIDs, ep.EpisodeFileID) {
+					ep.EpisodeFileID = 0
+					ep.HasFile = false
+				}
+				if slices.Contains(result.EpisodesUnmonitored, ep.ID) {
+					ep.Monitored = false
+				}
+			})
+			report.EpisodeFilesDeleted += filesDeleted
 			if episodeFailures > 0 {
-				failedCount++
+				report.Failed++
+				result.Outcome = storage.DeletionOutcomeFailed
+				problems = append(problems, fmt.Sprintf("%d episode file deletion(s) failed", episodeFailures))
 			}
+			result.Message = strings.Join(problems, "; ")
+			e.recordDeletionResult(ctx, &report, result)
 			// The candidate itself is counted as processed (its episode files
-			// were handled), but failedCount above still reflects any file-level
+			// were handled), but Failed above still reflects any file-level
 			// deletion failures.
-			episodeItemsProcessed++
+			report.EpisodeItemsProcessed++
 			continue
 		}
 
@@ -1317,7 +2126,8 @@ func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[stri
 
 				freshVerdict := e.rules.Evaluate(ctx, &updatedMedia)
 				if freshVerdict.IsProtected || freshVerdict.DeleteAfter.After(time.Now()) {
-					protectedCount++
+					report.Protected++
+					e.recordDeletionResult(ctx, &report, storage.DeletionResult{MediaID: mediaID, Title: media.Title, Outcome: storage.DeletionOutcomeProtected, Message: "watch activity extended retention", Rule: verdict.SchedulingRule})
 					log.Info().
 						Str("media_id", mediaID).
 						Str("title", media.Title).
@@ -1330,59 +2140,45 @@ func (e *SyncEngine) ExecuteDeletions(ctx context.Context, candidates []map[stri
 
 		// Attempt whole-item deletion
 		if err := e.DeleteMedia(ctx, mediaID, false); err != nil {
-			failedCount++
+			report.Failed++
+			e.recordDeletionResult(ctx, &report, storage.DeletionResult{MediaID: mediaID, Title: media.Title, Outcome: storage.DeletionOutcomeFailed, Message: err.Error(), Rule: verdict.SchedulingRule})
 			log.Error().
 				Err(err).
 				Str("media_id", mediaID).
-				Str("title", candidate["title"].(string)).
+				Str("title", media.Title).
 				Msg("Failed to delete media")
 			continue
 		}
 
 		// Track successful deletion
-		deletedCount++
-		deletedItems = append(deletedItems, candidate)
+		metrics.ReclaimedBytes.WithLabelValues(string(media.Type)).Add(float64(media.FileSize))
+		report.Deleted++
+		report.DeletedItems = append(report.DeletedItems, candidate)
+		e.recordDeletionResult(ctx, &report, storage.DeletionResult{MediaID: mediaID, Title: media.Title, Outcome: storage.DeletionOutcomeDeleted, Rule: verdict.SchedulingRule})
 
 		log.Info().
 			Str("media_id", mediaID).
-			Str("title", candidate["title"].(string)).
+			Str("title", media.Title).
 			Msg("Successfully deleted media")
 	}
 
 	log.Info().
-		Int("deleted", deletedCount).
-		Int("episode_files_deleted", episodeFilesDeleted).
-		Int("protected", protectedCount).
-		Int("failed", failedCount).
+		Int("deleted", report.Deleted).
+		Int("episode_files_deleted", report.EpisodeFilesDeleted).
+		Int("protected", report.Protected).
+		Int("failed", report.Failed).
 		Msg("Deletion execution completed")
 
-	return deletedCount, episodeItemsProcessed, episodeFilesDeleted, protectedCount, failedCount, deletedItems
+	return report
 }
 
-// ErrSyncInProgress is returned by ExecuteDeletionsLocked when a full or
-// incremental sync currently holds the serialization lock. Callers should
+// ErrSyncInProgress is returned by operations that refuse to wait while a
+// full or incremental sync holds the serialization lock (e.g. batch approval). Callers should
 // surface it as a transient busy state (e.g. HTTP 409) instead of blocking,
 // since waiting for the lock and then running with an already-expired request
 // context would turn every candidate into a spurious failure.
 var ErrSyncInProgress = errors.New("a sync is currently in progress")
 
-// ExecuteDeletionsLocked runs ExecuteDeletions while holding syncRunMu so a
-// manual deletion pass (POST /api/deletions/execute) cannot overlap a running
-// full or incremental sync. FullSync calls ExecuteDeletions directly while it
-// already holds the lock, so only external callers should use this wrapper.
-//
-// It does not block for the lock: if a sync is running it returns
-// ErrSyncInProgress immediately so the request can be rejected with a retryable
-// status rather than hanging and then executing against a cancelled context.
-func (e *SyncEngine) ExecuteDeletionsLocked(ctx context.Context, candidates []map[string]interface{}) (int, int, int, int, int, []map[string]interface{}, error) {
-	if !e.syncRunMu.TryLock() {
-		return 0, 0, 0, 0, 0, nil, ErrSyncInProgress
-	}
-	defer e.syncRunMu.Unlock()
-	deletedCount, episodeItemsProcessed, episodeFilesDeleted, protectedCount, failedCount, deletedItems := e.ExecuteDeletions(ctx, candidates)
-	return deletedCount, episodeItemsProcessed, episodeFilesDeleted, protectedCount, failedCount, deletedItems, nil
-}
-
 // buildWatchStateMap fetches watch history from the configured stats provider once and returns
 // a map of jellyfinID → latest watch timestamp. This avoids repeated full-history
 // fetches when checking multiple deletion candidates.
@@ -1490,8 +2286,9 @@ func (e *SyncEngine) DeleteMedia(ctx context.Context, mediaID string, dryRun boo
 	return nil
 }
 
-// AddExclusion adds a media item to the exclusion list
-func (e *SyncEngine) AddExclusion(ctx context.Context, mediaID, reason string) error {
+// AddExclusion adds a media item to the exclusion list. excludedBy names who
+// excluded it ("api" when empty).
+func (e *SyncEngine) AddExclusion(ctx context.Context, mediaID, reason, excludedBy string) error {
 	media, found := e.GetMediaByID(mediaID)
 	if !found {
 		return fmt.Errorf("media not found: %s", mediaID)
@@ -1515,9 +2312,12 @@ func (e *SyncEngine) AddExclusion(ctx context.Context, mediaID, reason string) e
 		MediaType:    string(media.Type),
 		Title:        media.Title,
 		ExcludedAt:   time.Now(),
-		ExcludedBy:   "api",
+		ExcludedBy:   excludedBy,
 		Reason:       reason,
 	}
+	if exclusion.ExcludedBy == "" {
+		exclusion.ExcludedBy = "api"
+	}
 
 	if err := e.exclusions.Add(exclusion); err != nil {
 		return fmt.Errorf("adding exclusion: %w", err)
@@ -1529,6 +2329,8 @@ func (e *SyncEngine) AddExclusion(ctx context.Context, mediaID, reason string) e
 	e.mediaLibrary[mediaID] = media
 	e.mediaLibraryLock.Unlock()
 
+	e.events.Publish(EventExclusionAdded, map[string]any{"media_id": mediaID, "title": media.Title, "reason": reason})
+
 	log.Info().
 		Str("media_id", mediaID).
 		Str("title", media.Title).
@@ -1563,6 +2365,8 @@ func (e *SyncEngine) RemoveExclusion(ctx context.Context, mediaID string) error
 	e.mediaLibrary[mediaID] = media
 	e.mediaLibraryLock.Unlock()
 
+	e.events.Publish(EventExclusionRemoved, map[string]any{"media_id": mediaID, "title": media.Title})
+
 	log.Info().
 		Str("media_id", mediaID).
 		Str("title", media.Title).
@@ -1578,11 +2382,27 @@ type SyncStatus struct {
 	MediaCount    int       `json:"media_count"`
 	LastFullSync  time.Time `json:"last_full_sync,omitempty"`
 	LastIncrSync  time.Time `json:"last_incr_sync,omitempty"`
+	LastDeletion  time.Time `json:"last_deletion,omitempty"`
+	RunningJobID  string    `json:"running_job_id,omitempty"`
+	QueuedJobs    int       `json:"queued_jobs"`
 	FullInterval  int       `json:"full_interval_minutes"`
 	IncrInterval  int       `json:"incr_interval_minutes"`
 	MoviesCount   int       `json:"movies_count"`
 	TVShowsCount  int       `json:"tv_shows_count"`
 	ExcludedCount int       `json:"excluded_count"`
+
+	// LibraryGeneration increases each time a full sync swaps in a new library
+	LibraryGeneration uint64 `json:"library_generation"`
+
+	// Schedule: next run per job (nil when not scheduled, e.g. auto_start off)
+	FullCron               string     `json:"full_cron,omitempty"`
+	IncrCron               string     `json:"incr_cron,omitempty"`
+	DeletionCron           string     `json:"deletion_cron,omitempty"`
+	NextFullSync           *time.Time `json:"next_full_sync,omitempty"`
+	NextIncrSync           *time.Time `json:"next_incr_sync,omitempty"`
+	NextDeletion           *time.Time `json:"next_deletion,omitempty"` // adjusted for blackout windows
+	DeletionBlackoutActive bool       `json:"deletion_blackout_active"`
+	DeletionsDeferredUntil *time.Time `json:"deletions_deferred_until,omitempty"`
 }
 
 // GetStatus returns the current sync engine status
@@ -1608,6 +2428,8 @@ func (e *SyncEngine) GetStatus() SyncStatus {
 		MediaCount:   len(e.mediaLibrary),
 		FullInterval: e.config.Sync.FullInterval,
 		IncrInterval: e.config.Sync.IncrementalInterval,
+
+		LibraryGeneration: e.libraryGeneration,
 	}
 
 	// Count movies, TV shows, and excluded items
@@ -1622,6 +2444,9 @@ func (e *SyncEngine) GetStatus() SyncStatus {
 		}
 	}
 
+	e.applyScheduleStatus(&status)
+	status.QueuedJobs, status.RunningJobID = e.activeJobCounts()
+
 	// Get last sync times from jobs
 	// Note: This is a simple implementation; you might want to cache these values
 	jobs := e.jobs.GetRecent(10)
@@ -1634,6 +2459,10 @@ func (e *SyncEngine) GetStatus() SyncStatus {
 			if status.LastIncrSync.IsZero() || job.CompletedAt.After(status.LastIncrSync) {
 				status.LastIncrSync = *job.CompletedAt
 			}
+		} else if job.Type == storage.JobTypeDeletion && job.CompletedAt != nil {
+			if status.LastDeletion.IsZero() || job.CompletedAt.After(status.LastDeletion) {
+				status.LastDeletion = *job.CompletedAt
+			}
 		}
 	}
 
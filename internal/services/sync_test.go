@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -128,9 +129,10 @@ func TestSyncEngine_StartStop(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, engine.running)
 
-		// Tickers should be nil when AutoStart is false
-		assert.Nil(t, engine.fullSyncTicker)
-		assert.Nil(t, engine.incrSyncTicker)
+		// Nothing is scheduled when AutoStart is false
+		status := engine.GetStatus()
+		assert.Nil(t, status.NextFullSync)
+		assert.Nil(t, status.NextIncrSync)
 
 		engine.Stop()
 		assert.False(t, engine.running)
@@ -171,9 +173,10 @@ func TestSyncEngine_StartStop(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, engine.running)
 
-		// Tickers should be initialized when AutoStart is true
-		assert.NotNil(t, engine.fullSyncTicker)
-		assert.NotNil(t, engine.incrSyncTicker)
+		// Next runs should be scheduled when AutoStart is true
+		status := engine.GetStatus()
+		assert.NotNil(t, status.NextFullSync)
+		assert.NotNil(t, status.NextIncrSync)
 
 		// Give tickers time to initialize
 		time.Sleep(10 * time.Millisecond)
@@ -234,8 +237,8 @@ func TestSyncEngine_StartStop(t *testing.T) {
 
 		baseline := runtime.NumGoroutine()
 		require.NoError(t, engine.Start())
-		assert.NotNil(t, engine.fullSyncTicker)
-		assert.NotNil(t, engine.incrSyncTicker)
+		assert.NotNil(t, engine.GetStatus().NextFullSync)
+		assert.NotNil(t, engine.GetStatus().NextIncrSync)
 
 		// Wait for the scheduler goroutines to be up (poll rather than a fixed
 		// sleep so timing stays robust on loaded CI). Stop before the 1-minute
@@ -330,12 +333,14 @@ func TestSyncEngine_AddExclusion(t *testing.T) {
 		}
 
 		ctx := context.Background()
-		err := engine.AddExclusion(ctx, "radarr-1", "user favorite")
+		err := engine.AddExclusion(ctx, "radarr-1", "user favorite", "alice")
 
 		require.NoError(t, err)
 
-		// Check exclusion was added
+		// Check exclusion was added, attributed to the caller
 		assert.True(t, exclusions.IsExcluded("radarr-1"))
+		item, _ := exclusions.Get("radarr-1")
+		assert.Equal(t, "alice", item.ExcludedBy)
 
 		// Check media was marked as excluded
 		media, found := engine.GetMediaByID("radarr-1")
@@ -347,7 +352,7 @@ func TestSyncEngine_AddExclusion(t *testing.T) {
 		engine, _, _ := newTestSyncEngine(t)
 
 		ctx := context.Background()
-		err := engine.AddExclusion(ctx, "non-existent", "test")
+		err := engine.AddExclusion(ctx, "non-existent", "test", "")
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "media not found")
@@ -943,22 +948,17 @@ func TestSyncEngine_ExecuteDeletions(t *testing.T) {
 func TestSyncEngine_ExecuteDeletions_EpisodeFailureAccounting(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	// Sonarr stub: serves episodes for the rule, fails all episode-file deletes.
-	var deleteCalls int
+	// Sonarr stub: fails all episode-file deletes. Episodes come from the
+	// library snapshot, so the rule must never fetch them.
+	var deleteCalls, getCalls int
 	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodDelete {
 			deleteCalls++
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		// GET /api/v3/episode?seriesId=N
-		episodes := []clients.SonarrEpisode{
-			{ID: 1, SeriesID: 1, EpisodeFileID: 101, EpisodeNumber: 1, SeasonNumber: 1, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -30)},
-			{ID: 2, SeriesID: 1, EpisodeFileID: 102, EpisodeNumber: 2, SeasonNumber: 1, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -20)},
-			{ID: 3, SeriesID: 1, EpisodeFileID: 103, EpisodeNumber: 3, SeasonNumber: 1, HasFile: true, AirDateUTC: time.Now().AddDate(0, 0, -10)},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(episodes)
+		getCalls++
+		w.WriteHeader(http.StatusNotFound)
 	}))
 	defer sonarrServer.Close()
 
@@ -1013,6 +1013,11 @@ func TestSyncEngine_ExecuteDeletions_EpisodeFailureAccounting(t *testing.T) {
 		Type:     models.MediaTypeTVShow,
 		Title:    "Test Show",
 		SonarrID: 1,
+		Episodes: []models.Episode{
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: time.Now().AddDate(0, 0, -30)},
+			{ID: 2, EpisodeFileID: 102, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDate: time.Now().AddDate(0, 0, -20)},
+			{ID: 3, EpisodeFileID: 103, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, AirDate: time.Now().AddDate(0, 0, -10)},
+		},
 	}
 
 	ctx := context.Background()
@@ -1030,6 +1035,7 @@ func TestSyncEngine_ExecuteDeletions_EpisodeFailureAccounting(t *testing.T) {
 	assert.GreaterOrEqual(t, deleteCalls, 1, "episode file delete should have been attempted")
 	assert.Equal(t, 0, episodeFilesDeleted)
 	assert.Equal(t, 1, failedCount, "candidate with failed episode-file deletion must count as failed")
+	assert.Zero(t, getCalls, "episode rules must read the library snapshot")
 }
 
 func TestSyncEngine_FullSync_EnableDeletion(t *testing.T) {
@@ -1129,31 +1135,6 @@ func TestSyncEngine_FullSync_EnableDeletion(t *testing.T) {
 	})
 }
 
-func TestSyncEngine_ExecuteDeletionsLocked_BusyReturnsErrSyncInProgress(t *testing.T) {
-	engine, _, _ := newTestSyncEngine(t)
-
-	// Simulate a running sync holding the serialization lock.
-	engine.syncRunMu.Lock()
-	defer engine.syncRunMu.Unlock()
-
-	_, _, _, _, _, _, err := engine.ExecuteDeletionsLocked(context.Background(), []map[string]interface{}{{}})
-	require.ErrorIs(t, err, ErrSyncInProgress)
-}
-
-func TestSyncEngine_ExecuteDeletionsLocked_RunsWhenLockFree(t *testing.T) {
-	engine, _, _ := newTestSyncEngine(t)
-
-	// Lock is free: the wrapper must acquire it and delegate without error.
-	deletedCount, episodeItemsProcessed, episodeFilesDeleted, protectedCount, failedCount, deletedItems, err := engine.ExecuteDeletionsLocked(context.Background(), nil)
-	require.NoError(t, err)
-	assert.Zero(t, deletedCount)
-	assert.Zero(t, episodeItemsProcessed)
-	assert.Zero(t, episodeFilesDeleted)
-	assert.Zero(t, protectedCount)
-	assert.Zero(t, failedCount)
-	assert.Empty(t, deletedItems)
-}
-
 type stubStatsProvider struct {
 	history []clients.StatsHistoryItem
 }
@@ -1224,3 +1205,377 @@ func TestSyncEngine_SyncQueuesBehindRunningSync(t *testing.T) {
 		t.Fatal("queued sync did not run after lock release")
 	}
 }
+
+func TestSyncEngine_FullSync_StagesAndSwapsLibrary(t *testing.T) {
+	engine, jobs, _ := newTestSyncEngine(t)
+
+	// A movie that has since been removed from Radarr
+	engine.mediaLibrary["radarr-9"] = models.Media{ID: "radarr-9", Type: models.MediaTypeMovie, Title: "Removed Movie", RadarrID: 9}
+
+	radarrBlocked := make(chan struct{})
+	release := make(chan struct{})
+	radarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/movie":
+			close(radarrBlocked)
+			<-release
+			json.NewEncoder(w).Encode([]clients.RadarrMovie{{ID: 1, Title: "Movie", HasFile: true, Added: time.Now()}})
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer radarrServer.Close()
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/series":
+			json.NewEncoder(w).Encode([]clients.SonarrSeries{{ID: 1, Title: "Show", Added: time.Now(), Statistics: clients.SonarrStats{EpisodeFileCount: 1}}})
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	defer sonarrServer.Close()
+
+	engine.radarrClient = clients.NewRadarrClient(config.RadarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: radarrServer.URL, APIKey: "key"},
+	})
+	engine.sonarrClient = clients.NewSonarrClient(config.SonarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: sonarrServer.URL, APIKey: "key"},
+	})
+
+	_, events, _, unsubscribe := engine.Events().Subscribe(0)
+	defer unsubscribe()
+
+	job, err := engine.StartFullSync()
+	require.NoError(t, err)
+	<-radarrBlocked
+
+	// Sonarr completes while Radarr is still fetching
+	require.Eventually(t, func() bool {
+		running, _ := jobs.Get(job.ID)
+		for _, phase := range running.Phases {
+			if phase.Name == PhaseSonarr {
+				return phase.Status == storage.JobStatusCompleted
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Readers still see the previous library
+	media := engine.GetMediaList()
+	require.Len(t, media, 1)
+	assert.Equal(t, "radarr-9", media[0].ID)
+	assert.Zero(t, engine.GetStatus().LibraryGeneration)
+
+	close(release)
+	finished := waitForJob(t, jobs, job.ID)
+	assert.Equal(t, storage.JobStatusCompleted, finished.Status)
+	assert.Equal(t, uint64(1), engine.GetStatus().LibraryGeneration)
+	assert.EqualValues(t, 1, finished.Summary["removed_externally"])
+
+	_, found := engine.GetMediaByID("radarr-9")
+	assert.False(t, found, "items no longer in Radarr are dropped")
+	var removed []Event
+	for _, event := range drainEvents(events) {
+		if event.Type == EventItemRemoved {
+			removed = append(removed, event)
+		}
+	}
+	require.Len(t, removed, 1)
+	assert.Equal(t, "radarr-9", removed[0].Data["media_id"])
+	assert.Equal(t, "radarr", removed[0].Data["source"])
+	_, found = engine.GetMediaByID("radarr-1")
+	assert.True(t, found)
+	_, found = engine.GetMediaByID("sonarr-1")
+	assert.True(t, found)
+}
+
+func TestSyncEngine_SwapLibrary_ReevaluatesExclusionsChangedDuringSync(t *testing.T) {
+	engine, _, exclusions := newTestSyncEngine(t)
+	ctx := context.Background()
+
+	added := time.Now().AddDate(0, 0, -100)
+	require.NoError(t, exclusions.Add(storage.ExclusionItem{ExternalID: "radarr-1", ExternalType: "radarr"}))
+	staged := map[string]models.Media{
+		"radarr-1": {ID: "radarr-1", Type: models.MediaTypeMovie, Title: "Was Excluded", RadarrID: 1, AddedAt: added},
+		"radarr-2": {ID: "radarr-2", Type: models.MediaTypeMovie, Title: "Gets Excluded", RadarrID: 2, AddedAt: added},
+	}
+
+	// Exclusions are applied before the rules, as a full sync does
+	assert.Equal(t, []string{"radarr-1"}, engine.applyExclusions(staged))
+	engine.applyRetentionRules(ctx, staged)
+	require.True(t, staged["radarr-1"].IsExcluded)
+	require.True(t, staged["radarr-1"].DeleteAfter.IsZero())
+	require.False(t, staged["radarr-2"].DeleteAfter.IsZero())
+
+	// Exclusions change while the sync is running
+	require.NoError(t, exclusions.Remove("radarr-1"))
+	require.NoError(t, exclusions.Add(storage.ExclusionItem{ExternalID: "radarr-2", ExternalType: "radarr"}))
+	engine.swapLibrary(ctx, staged)
+
+	unexcluded, found := engine.GetMediaByID("radarr-1")
+	require.True(t, found)
+	assert.False(t, unexcluded.IsExcluded)
+	assert.False(t, unexcluded.DeleteAfter.IsZero(), "retention is re-evaluated once the exclusion is gone")
+	assert.NotEmpty(t, unexcluded.DeletionReason)
+
+	excluded, found := engine.GetMediaByID("radarr-2")
+	require.True(t, found)
+	assert.True(t, excluded.IsExcluded)
+	assert.True(t, excluded.DeleteAfter.IsZero(), "newly excluded items are no longer scheduled")
+}
+
+func TestMergeLibrary(t *testing.T) {
+	watched := time.Now().AddDate(0, 0, -3)
+	user := "alice"
+	previous := map[string]models.Media{
+		"radarr-1": {
+			ID: "radarr-1", Type: models.MediaTypeMovie, Title: "Old Title", RadarrID: 1,
+			JellyfinID: "jf-1", WatchCount: 2, LastWatched: watched, JellyfinMatchStatus: "matched",
+			IsRequested: true, RequestedByUsername: &user,
+		},
+		"radarr-2": {ID: "radarr-2", Type: models.MediaTypeMovie, RadarrID: 2, IsExcluded: true},
+		"sonarr-1": {ID: "sonarr-1", Type: models.MediaTypeTVShow, SonarrID: 1, JellyfinID: "jf-2"},
+	}
+
+	t.Run("failed fetches keep previous data", func(t *testing.T) {
+		staged := &syncStaging{
+			movies:   []models.Media{{ID: "radarr-1", Type: models.MediaTypeMovie, Title: "New Title", RadarrID: 1}},
+			moviesOK: true,
+		}
+
+		library, removed := mergeLibrary(previous, staged)
+
+		require.Len(t, library, 2)
+		require.Len(t, removed, 1)
+		assert.Equal(t, "radarr-2", removed[0].ID, "movies missing from Radarr are reported as removed")
+		movie := library["radarr-1"]
+		assert.Equal(t, "New Title", movie.Title)
+		assert.Equal(t, "jf-1", movie.JellyfinID, "Jellyfin fields carried over when Jellyfin was not fetched")
+		assert.Equal(t, 2, movie.WatchCount)
+		assert.Equal(t, watched, movie.LastWatched)
+		assert.True(t, movie.IsRequested, "request fields carried over when Jellyseerr was not fetched")
+		assert.Equal(t, previous["sonarr-1"], library["sonarr-1"], "shows carried over when Sonarr was not fetched")
+	})
+
+	t.Run("successful fetches start from fresh items", func(t *testing.T) {
+		staged := &syncStaging{
+			movies:     []models.Media{{ID: "radarr-2", Type: models.MediaTypeMovie, RadarrID: 2}, {ID: "radarr-1", Type: models.MediaTypeMovie, RadarrID: 1}},
+			moviesOK:   true,
+			showsOK:    true,
+			jellyfinOK: true,
+			requestsOK: true,
+		}
+
+		library, removed := mergeLibrary(previous, staged)
+
+		require.Len(t, library, 2)
+		require.Len(t, removed, 1)
+		assert.Equal(t, "sonarr-1", removed[0].ID)
+		assert.True(t, library["radarr-2"].IsExcluded, "exclusion flag carried over")
+		movie := library["radarr-1"]
+		assert.Empty(t, movie.JellyfinID)
+		assert.Zero(t, movie.WatchCount)
+		assert.False(t, movie.IsRequested)
+	})
+}
+
+func TestSyncEngine_FetchEpisodes(t *testing.T) {
+	engine, _, exclusions := newTestSyncEngine(t)
+	cfg := config.Get()
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "trim", Type: "episode", Enabled: true, Tag: "trim", MaxEpisodes: 1},
+	}
+	engine.rules = rules.NewRulesEngine(exclusions, nil)
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	requested := map[string]int{}
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seriesID := r.URL.Query().Get("seriesId")
+		mu.Lock()
+		requested[seriesID]++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		if seriesID == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]clients.SonarrEpisode{
+			{ID: 1, EpisodeFileID: 11, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, AirDate: "2024-01-05"},
+			{ID: 2, EpisodeFileID: 12, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, AirDateUTC: time.Date(2024, 1, 12, 20, 0, 0, 0, time.UTC)},
+		})
+	}))
+	defer sonarrServer.Close()
+	engine.sonarrClient = clients.NewSonarrClient(config.SonarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: sonarrServer.URL, APIKey: "key"},
+	})
+
+	var shows []models.Media
+	for id := 1; id <= 10; id++ {
+		show := models.Media{ID: fmt.Sprintf("sonarr-%d", id), Type: models.MediaTypeTVShow, SonarrID: id, Tags: []string{"trim"}}
+		if id == 10 {
+			show.Tags = nil // no episode rule applies
+		}
+		shows = append(shows, show)
+	}
+
+	engine.fetchEpisodes(context.Background(), shows)
+
+	assert.Len(t, requested, 9, "only shows matched by an episode rule are fetched")
+	for seriesID, calls := range requested {
+		assert.Equal(t, 1, calls, "series %s fetched more than once", seriesID)
+	}
+	assert.LessOrEqual(t, maxInFlight, episodeFetchConcurrency)
+
+	require.Len(t, shows[0].Episodes, 2)
+	assert.Equal(t, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), shows[0].Episodes[0].AirDate, "falls back to the plain air date")
+	assert.Equal(t, 12, shows[0].Episodes[1].EpisodeFileID)
+	assert.Nil(t, shows[2].Episodes, "a failed fetch leaves no snapshot")
+	assert.Nil(t, shows[9].Episodes)
+}
+
+func TestSyncEngine_EpisodeWatchState(t *testing.T) {
+	engine, _, exclusions := newTestSyncEngine(t)
+	cfg := config.Get()
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "rewatch", Type: "episode", Enabled: true, Tag: "rewatch", WatchedRetention: "30d"},
+	}
+	engine.rules = rules.NewRulesEngine(exclusions, nil)
+
+	lastPlayed := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	var requested []string
+	jellyfinServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(clients.JellyfinItemsResponse{Items: []clients.JellyfinItem{
+			{ID: "jf-ep-1", ParentIndexNumber: 1, IndexNumber: 1, UserData: clients.JellyfinUserData{Played: true, PlayCount: 1, LastPlayedDate: lastPlayed}},
+			{ID: "jf-ep-2", ParentIndexNumber: 1, IndexNumber: 2},
+		}})
+	}))
+	defer jellyfinServer.Close()
+	engine.jellyfinClient = clients.NewJellyfinClient(config.JellyfinConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: jellyfinServer.URL, APIKey: "key"},
+	})
+
+	episodes := []models.Episode{
+		{ID: 1, EpisodeFileID: 11, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true},
+		{ID: 2, EpisodeFileID: 12, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true},
+	}
+	library := map[string]models.Media{
+		"sonarr-1": {ID: "sonarr-1", Type: models.MediaTypeTVShow, JellyfinID: "jf-1", Tags: []string{"rewatch"}, Episodes: episodes},
+		"sonarr-2": {ID: "sonarr-2", Type: models.MediaTypeTVShow, JellyfinID: "jf-2", Episodes: episodes},
+	}
+
+	engine.fetchEpisodeWatchState(context.Background(), library)
+
+	assert.Equal(t, []string{"/Shows/jf-1/Episodes"}, requested, "only shows with watch-aware episode rules are fetched")
+	show := library["sonarr-1"]
+	require.Len(t, show.Episodes, 2)
+	assert.Equal(t, "jf-ep-1", show.Episodes[0].JellyfinID)
+	assert.True(t, show.Episodes[0].Played)
+	assert.Equal(t, lastPlayed, show.Episodes[0].LastWatched)
+	assert.False(t, show.Episodes[1].Watched())
+	assert.Empty(t, episodes[0].JellyfinID, "the snapshot is copied, not modified in place")
+
+	t.Run("stats history adds per-episode watches", func(t *testing.T) {
+		watchedAt := lastPlayed.AddDate(0, 1, 0)
+		applyStatsHistory(library, []clients.StatsHistoryItem{
+			{JellyfinItemID: "jf-1", EpisodeID: "jf-ep-2", WatchedAt: watchedAt},
+			{JellyfinItemID: "jf-1", EpisodeID: "jf-ep-2", WatchedAt: watchedAt.AddDate(0, 0, -1)},
+		})
+
+		show := library["sonarr-1"]
+		assert.Equal(t, watchedAt, show.Episodes[1].LastWatched)
+		assert.Equal(t, 2, show.Episodes[1].WatchCount)
+		assert.Equal(t, lastPlayed, show.Episodes[0].LastWatched, "episodes without history are unchanged")
+	})
+}
+
+func TestSyncEngine_ExecuteDeletions_RollingUnmonitorsEpisodes(t *testing.T) {
+	engine, _, exclusions := newTestSyncEngine(t)
+	cfg := config.Get()
+	cfg.AdvancedRules = []config.AdvancedRule{
+		{Name: "daily", Type: "episode", Enabled: true, EpisodeDeleteStrategy: "rolling"},
+	}
+	engine.rules = rules.NewRulesEngine(exclusions, nil)
+
+	var deleted []string
+	var monitor clients.SonarrEpisodesMonitoredRequest
+	sonarrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v3/episode/monitor":
+			json.NewDecoder(r.Body).Decode(&monitor)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer sonarrServer.Close()
+	engine.sonarrClient = clients.NewSonarrClient(config.SonarrConfig{
+		BaseIntegrationConfig: config.BaseIntegrationConfig{URL: sonarrServer.URL, APIKey: "key"},
+	})
+
+	engine.mediaLibrary["sonarr-1"] = models.Media{
+		ID: "sonarr-1", Type: models.MediaTypeTVShow, Title: "Daily Show", SonarrID: 1, AddedAt: time.Now(),
+		Episodes: []models.Episode{
+			{ID: 1, EpisodeFileID: 101, SeasonNumber: 1, EpisodeNumber: 1, HasFile: true, Monitored: true, Played: true},
+			{ID: 2, EpisodeFileID: 102, SeasonNumber: 1, EpisodeNumber: 2, HasFile: true, Monitored: true, Played: true},
+			{ID: 3, EpisodeFileID: 103, SeasonNumber: 1, EpisodeNumber: 3, HasFile: true, Monitored: true, Played: true},
+			{ID: 4, EpisodeFileID: 104, SeasonNumber: 1, EpisodeNumber: 4, HasFile: true, Monitored: true},
+		},
+	}
+
+	_, processed, filesDeleted, _, failed, _ := engine.ExecuteDeletions(context.Background(), []map[string]interface{}{
+		{"id": "sonarr-1", "title": "Daily Show", "type": models.MediaTypeTVShow},
+	})
+
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, 2, filesDeleted)
+	assert.Zero(t, failed)
+	assert.Equal(t, []string{"/api/v3/episodefile/101", "/api/v3/episodefile/102"}, deleted)
+	assert.Equal(t, []int{1, 2}, monitor.EpisodeIDs)
+	assert.False(t, monitor.Monitored)
+
+	show, _ := engine.GetMediaByID("sonarr-1")
+	assert.False(t, show.Episodes[0].HasFile, "the snapshot reflects deleted files")
+	assert.False(t, show.Episodes[0].Monitored)
+	assert.True(t, show.Episodes[2].HasFile)
+	assert.True(t, show.Episodes[2].Monitored)
+
+	verdict := engine.rules.Evaluate(context.Background(), &show)
+	assert.False(t, verdict.HasEpisodeDeletions(), "deleted files are not selected again")
+}
+
+func TestWithUserPlayedEpisodes(t *testing.T) {
+	played := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	episodes := []models.Episode{
+		{ID: 1, SeasonNumber: 1, EpisodeNumber: 1, WatchedBy: []string{"alice"}},
+		{ID: 2, SeasonNumber: 1, EpisodeNumber: 2},
+	}
+	items := []clients.JellyfinItem{
+		{ParentIndexNumber: 1, IndexNumber: 1, UserData: clients.JellyfinUserData{Played: true}},
+		{ParentIndexNumber: 1, IndexNumber: 2, UserData: clients.JellyfinUserData{PlayCount: 1, LastPlayedDate: played}},
+	}
+
+	result := withUserPlayedEpisodes(episodes, "bob", items)
+	result = withUserPlayedEpisodes(result, "bob", items)
+
+	assert.Equal(t, []string{"alice", "bob"}, result[0].WatchedBy)
+	assert.Equal(t, []string{"bob"}, result[1].WatchedBy)
+	assert.Equal(t, played, result[1].LastWatched)
+	assert.Equal(t, []string{"alice"}, episodes[0].WatchedBy, "the snapshot is copied, not modified in place")
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSyncEngine_FullSync_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	radarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer radarr.Close()
+
+	cfg := diskThresholdCfg(0)
+	cfg.App.DiskThreshold.Enabled = false
+	// Created after the provider is installed so its transport records spans
+	engine := newDiskThresholdSyncEngine(t, cfg, radarr.URL)
+
+	require.NoError(t, engine.FullSync(context.Background()))
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	require.Len(t, spans["sync.full"], 1)
+	require.Len(t, spans["sync.radarr"], 1)
+	require.Len(t, spans["rules.apply"], 1)
+	require.NotEmpty(t, spans["radarr GET"])
+
+	root := spans["sync.full"][0]
+	assert.False(t, root.Parent().IsValid(), "full sync is a root span")
+	assert.Equal(t, root.SpanContext().SpanID(), spans["sync.radarr"][0].Parent().SpanID())
+	assert.Equal(t, root.SpanContext().SpanID(), spans["rules.apply"][0].Parent().SpanID())
+	for _, request := range spans["radarr GET"] {
+		assert.Equal(t, spans["sync.radarr"][0].SpanContext().SpanID(), request.Parent().SpanID(),
+			"upstream requests are children of the sync step")
+	}
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/storage"
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrTwoFactorUnavailable is returned for accounts without a local user
+	// record (Jellyfin and single sign-on users, or no user store).
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication is only available for local accounts")
+	// ErrTwoFactorEnabled is returned when enrolling an account that already uses it.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnabled is returned when an account has no second factor to manage.
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorNotEnrolling is returned when confirming without starting enrollment.
+	ErrTwoFactorNotEnrolling = errors.New("no two-factor enrollment is in progress")
+	// ErrTwoFactorRequired is returned when disabling a second factor the role requires.
+	ErrTwoFactorRequired = errors.New("two-factor authentication is required for your role")
+	// ErrInvalidTwoFactorCode is returned for a wrong, expired or reused code.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrLoginChallenge is returned when a sign-in challenge is unknown or expired.
+	ErrLoginChallenge = errors.New("sign-in has expired, sign in again")
+)
+
+const (
+	// twoFactorChallengeTTL bounds how long the second step may take
+	twoFactorChallengeTTL = 5 * time.Minute
+	// maxTwoFactorChallengeFailures is how many wrong codes end a challenge
+	maxTwoFactorChallengeFailures = 5
+	// maxPendingTwoFactorLogins caps challenges so the map cannot grow without bound
+	maxPendingTwoFactorLogins = 1000
+	// recoveryCodeCount is how many recovery codes are issued at a time
+	recoveryCodeCount = 10
+	// defaultTwoFactorIssuer is shown in authenticator apps when admin.two_factor.issuer is empty
+	defaultTwoFactorIssuer = "OxiCleanarr"
+)
+
+// TOTPEnrollment is a new TOTP secret and the otpauth:// URI to show as a QR code
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorStatus describes an account's second factor
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	Enrolling              bool `json:"enrolling"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// pendingTwoFactorLogin is a sign-in that passed the password check and
+// waits for its second factor
+type pendingTwoFactorLogin struct {
+	username string
+	expires  time.Time
+	failures int
+}
+
+// startTwoFactorLogin issues the challenge for the second sign-in step. An
+// account that must use two-factor authentication but has not enrolled yet
+// gets an enrollment, which the second step confirms.
+func (s *AuthService) startTwoFactorLogin(user storage.User) (LoginResult, error) {
+	result := LoginResult{Username: user.Username, Role: user.Role}
+	if !user.TOTPEnabled() {
+		enrollment, err := s.beginTOTPEnrollment(user.Username, true)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		result.Enrollment = &enrollment
+	}
+
+	challenge, err := randomToken()
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	s.twoFactorMu.Lock()
+	defer s.twoFactorMu.Unlock()
+	now := time.Now()
+	for key, pending := range s.twoFactorPending {
+		if now.After(pending.expires) {
+			delete(s.twoFactorPending, key)
+		}
+	}
+	if len(s.twoFactorPending) >= maxPendingTwoFactorLogins {
+		return LoginResult{}, fmt.Errorf("%w: too many sign-ins in progress", ErrLoginProviderUnavailable)
+	}
+	if s.twoFactorPending == nil {
+		s.twoFactorPending = make(map[string]pendingTwoFactorLogin)
+	}
+	s.twoFactorPending[challenge] = pendingTwoFactorLogin{username: user.Username, expires: now.Add(twoFactorChallengeTTL)}
+
+	result.Challenge = challenge
+	return result, nil
+}
+
+// CompleteTwoFactorLogin finishes a sign-in with a TOTP or recovery code for
+// the challenge that Login returned. When the challenge came with an
+// enrollment, the code confirms it and the result carries the new recovery
+// codes. The username is returned with the error for wrong codes so failures
+// can be attributed.
+func (s *AuthService) CompleteTwoFactorLogin(challenge, code string, client ClientInfo) (LoginResult, error) {
+	now := time.Now()
+	s.twoFactorMu.Lock()
+	pending, ok := s.twoFactorPending[challenge]
+	s.twoFactorMu.Unlock()
+	if !ok || challenge == "" || now.After(pending.expires) {
+		return LoginResult{}, ErrLoginChallenge
+	}
+
+	result := LoginResult{Username: pending.username}
+	limits := newLoginThrottleLimits(s.currentConfig().Admin.LoginThrottle)
+	keys := loginThrottleKeys(pending.username, client.IP)
+	if err := s.throttle.check(limits, now, keys...); err != nil {
+		return result, err
+	}
+
+	user, recoveryCodes, err := s.verifyLoginCode(pending.username, code, now)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		s.throttle.fail(limits, now, keys...)
+		s.twoFactorMu.Lock()
+		if p, ok := s.twoFactorPending[challenge]; ok {
+			p.failures++
+			if p.failures >= maxTwoFactorChallengeFailures {
+				delete(s.twoFactorPending, challenge)
+			} else {
+				s.twoFactorPending[challenge] = p
+			}
+		}
+		s.twoFactorMu.Unlock()
+		return result, err
+	}
+	if err != nil {
+		return result, err
+	}
+
+	s.twoFactorMu.Lock()
+	delete(s.twoFactorPending, challenge)
+	s.twoFactorMu.Unlock()
+	s.throttle.reset(keys[0])
+
+	result, err = s.issueToken(user.Username, user.Role, loginMethodTOTP, client)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result.RecoveryCodes = recoveryCodes
+	return result, nil
+}
+
+// verifyLoginCode checks the second factor of a sign-in, or confirms the
+// enrollment started by it. Returns the new recovery codes on enrollment.
+func (s *AuthService) verifyLoginCode(username, code string, now time.Time) (storage.User, []string, error) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, ok := s.users.Get(username)
+	if !ok {
+		return storage.User{}, nil, ErrLoginChallenge
+	}
+
+	var recoveryCodes []string
+	if user.TOTPEnabled() {
+		if !checkSecondFactor(&user, code, now) {
+			return storage.User{}, nil, ErrInvalidTwoFactorCode
+		}
+	} else {
+		var err error
+		if recoveryCodes, err = enableTOTP(&user, code, now); err != nil {
+			return storage.User{}, nil, err
+		}
+		log.Info().Str("username", username).Msg("Two-factor authentication enabled at sign-in")
+	}
+
+	if err := s.users.Put(user); err != nil {
+		return storage.User{}, nil, err
+	}
+	return user, recoveryCodes, nil
+}
+
+// TwoFactorStatus returns the second-factor state of a local account
+func (s *AuthService) TwoFactorStatus(username string) (TwoFactorStatus, error) {
+	user, err := s.localUser(username)
+	if err != nil {
+		return TwoFactorStatus{}, err
+	}
+	return TwoFactorStatus{
+		Enabled:                user.TOTPEnabled(),
+		Required:               s.twoFactorRequired(user.Role),
+		Enrolling:              !user.TOTPEnabled() && user.TOTPPendingSecret != "",
+		RecoveryCodesRemaining: len(user.RecoveryCodes),
+	}, nil
+}
+
+// BeginTOTPEnrollment generates a new TOTP secret for a local account. It
+// takes effect once ConfirmTOTPEnrollment accepts a code for it.
+func (s *AuthService) BeginTOTPEnrollment(username string) (TOTPEnrollment, error) {
+	return s.beginTOTPEnrollment(username, false)
+}
+
+// beginTOTPEnrollment stores a pending secret. With reuse, a pending secret
+// from an earlier attempt is kept, so a QR code scanned during an abandoned
+// sign-in still works.
+func (s *AuthService) beginTOTPEnrollment(username string, reuse bool) (TOTPEnrollment, error) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.localUser(username)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if user.TOTPEnabled() {
+		return TOTPEnrollment{}, ErrTwoFactorEnabled
+	}
+
+	if !reuse || user.TOTPPendingSecret == "" {
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return TOTPEnrollment{}, err
+		}
+		user.TOTPPendingSecret = secret
+		user.UpdatedAt = time.Now()
+		if err := s.users.Put(user); err != nil {
+			return TOTPEnrollment{}, err
+		}
+	}
+
+	return TOTPEnrollment{
+		Secret: user.TOTPPendingSecret,
+		URI:    utils.TOTPProvisioningURI(s.twoFactorIssuer(), user.Username, user.TOTPPendingSecret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication once the code
+// matches the pending secret. Returns the recovery codes, which are not
+// retrievable later.
+func (s *AuthService) ConfirmTOTPEnrollment(username, code string) ([]string, error) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.localUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	recoveryCodes, err := enableTOTP(&user, code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Put(user); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("username", username).Msg("Two-factor authentication enabled")
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current
+// TOTP or recovery code. Accounts whose role requires it cannot turn it off.
+func (s *AuthService) DisableTOTP(username, code string) error {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.localUser(username)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if s.twoFactorRequired(user.Role) {
+		return ErrTwoFactorRequired
+	}
+	if !checkSecondFactor(&user, code, time.Now()) {
+		return ErrInvalidTwoFactorCode
+	}
+
+	clearTOTP(&user)
+	if err := s.users.Put(user); err != nil {
+		return err
+	}
+	log.Info().Str("username", username).Msg("Two-factor authentication disabled")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a
+// current TOTP or recovery code
+func (s *AuthService) RegenerateRecoveryCodes(username, code string) ([]string, error) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, err := s.localUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if !checkSecondFactor(&user, code, time.Now()) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodes = hashes
+	user.UpdatedAt = time.Now()
+	if err := s.users.Put(user); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// ResetTOTP removes a user's second factor, e.g. after a lost phone. If the
+// role requires one, the user enrolls again at the next sign-in.
+func (s *AuthService) ResetTOTP(username string) error {
+	if s.users == nil {
+		return ErrUsersUnavailable
+	}
+
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	user, ok := s.users.Get(username)
+	if !ok {
+		return ErrUserNotFound
+	}
+	if !user.TOTPEnabled() && user.TOTPPendingSecret == "" {
+		return ErrTwoFactorNotEnabled
+	}
+
+	clearTOTP(&user)
+	if err := s.users.Put(user); err != nil {
+		return err
+	}
+	log.Info().Str("username", username).Msg("Two-factor authentication reset")
+	return nil
+}
+
+// localUser returns the user record for username, or ErrTwoFactorUnavailable
+// for accounts that only exist at Jellyfin or the single sign-on provider
+func (s *AuthService) localUser(username string) (storage.User, error) {
+	if s.users == nil {
+		return storage.User{}, ErrTwoFactorUnavailable
+	}
+	user, ok := s.users.Get(username)
+	if !ok {
+		return storage.User{}, ErrTwoFactorUnavailable
+	}
+	return user, nil
+}
+
+// twoFactorRequired reports whether admin.two_factor.required_roles lists role
+func (s *AuthService) twoFactorRequired(role string) bool {
+	return slices.Contains(s.currentConfig().Admin.TwoFactor.RequiredRoles, role)
+}
+
+// twoFactorIssuer returns the name authenticator apps show for accounts
+func (s *AuthService) twoFactorIssuer() string {
+	if issuer := s.currentConfig().Admin.TwoFactor.Issuer; issuer != "" {
+		return issuer
+	}
+	return defaultTwoFactorIssuer
+}
+
+// enableTOTP promotes the pending secret once code matches it and issues
+// recovery codes
+func enableTOTP(user *storage.User, code string, now time.Time) ([]string, error) {
+	if user.TOTPPendingSecret == "" {
+		return nil, ErrTwoFactorNotEnrolling
+	}
+	if !acceptTOTP(user, user.TOTPPendingSecret, code, now) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = user.TOTPPendingSecret
+	user.TOTPPendingSecret = ""
+	user.RecoveryCodes = hashes
+	user.UpdatedAt = now
+	return recoveryCodes, nil
+}
+
+// clearTOTP removes every trace of a second factor from user
+func clearTOTP(user *storage.User) {
+	user.TOTPSecret = ""
+	user.TOTPPendingSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+	user.UpdatedAt = time.Now()
+}
+
+// checkSecondFactor accepts a TOTP code for the user's secret or one of the
+// user's recovery codes, which is then used up
+func checkSecondFactor(user *storage.User, code string, now time.Time) bool {
+	if acceptTOTP(user, user.TOTPSecret, code, now) {
+		return true
+	}
+	return useRecoveryCode(user, code)
+}
+
+// acceptTOTP checks code against secret and refuses codes at or before the
+// last accepted time step, so an observed code cannot be replayed
+func acceptTOTP(user *storage.User, secret, code string, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	step, ok := utils.ValidateTOTP(secret, code, now)
+	if !ok || step <= user.TOTPLastStep {
+		return false
+	}
+	user.TOTPLastStep = step
+	return true
+}
+
+// useRecoveryCode removes the matching recovery code from user
+func useRecoveryCode(user *storage.User, code string) bool {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false
+	}
+	hash := hashRecoveryCode(normalized)
+
+	for i, stored := range user.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			user.RecoveryCodes = slices.Delete(slices.Clone(user.RecoveryCodes), i, i+1)
+			log.Info().Str("username", user.Username).Int("remaining", len(user.RecoveryCodes)).Msg("Recovery code used")
+			return true
+		}
+	}
+	return false
+}
+
+// recoveryCodeEncoding spells recovery codes in lowercase base32
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns fresh recovery codes formatted for display
+// ("xxxxx-xxxxx", 50 random bits each) and their hashes for storage
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(buf)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separator and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// hashRecoveryCode returns the hex SHA-256 of a normalized recovery code.
+// Codes are random, so a fast hash is enough to make the stored form useless.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ramonskie/oxicleanarr/internal/utils"
+)
+
+// currentTOTPStep returns the current TOTP time step. Tests use codes from
+// the steps before and after it, which only all match during this step, so
+// it first waits for the next step when fewer than 10 seconds are left.
+func currentTOTPStep(t *testing.T) int64 {
+	t.Helper()
+	now := time.Now()
+	next := now.Truncate(utils.TOTPPeriod).Add(utils.TOTPPeriod)
+	if remaining := next.Sub(now); remaining < 10*time.Second {
+		time.Sleep(remaining)
+	}
+	return utils.TOTPStep(time.Now())
+}
+
+// totpCodeAt returns the code for secret at the given time step. Tests fix
+// the step up front so crossing a period boundary mid-test cannot turn a
+// replayed code into a fresh one.
+func totpCodeAt(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatalf("TOTPCode failed: %v", err)
+	}
+	return code
+}
+
+// enrollTOTP enables two-factor authentication for username with the code
+// from the step before step, and returns the secret and recovery codes
+func enrollTOTP(t *testing.T, svc *AuthService, username string, step int64) (string, []string) {
+	t.Helper()
+	enrollment, err := svc.BeginTOTPEnrollment(username)
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment failed: %v", err)
+	}
+	recoveryCodes, err := svc.ConfirmTOTPEnrollment(username, totpCodeAt(t, enrollment.Secret, step-1))
+	if err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment failed: %v", err)
+	}
+	return enrollment.Secret, recoveryCodes
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	step := currentTOTPStep(t)
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+
+	if _, err := svc.ConfirmTOTPEnrollment("admin", "123456"); !errors.Is(err, ErrTwoFactorNotEnrolling) {
+		t.Errorf("Expected ErrTwoFactorNotEnrolling before enrolling, got %v", err)
+	}
+
+	enrollment, err := svc.BeginTOTPEnrollment("admin")
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment failed: %v", err)
+	}
+	if enrollment.Secret == "" || enrollment.URI == "" {
+		t.Fatalf("Expected a secret and URI, got %+v", enrollment)
+	}
+	if status, _ := svc.TwoFactorStatus("admin"); status.Enabled || !status.Enrolling {
+		t.Errorf("Expected a pending enrollment, got %+v", status)
+	}
+
+	if _, err := svc.ConfirmTOTPEnrollment("admin", "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode for a wrong code, got %v", err)
+	}
+	recoveryCodes, err := svc.ConfirmTOTPEnrollment("admin", totpCodeAt(t, enrollment.Secret, step))
+	if err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment failed: %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Errorf("Expected %d recovery codes, got %d", recoveryCodeCount, len(recoveryCodes))
+	}
+
+	status, err := svc.TwoFactorStatus("admin")
+	if err != nil || !status.Enabled || status.Enrolling || status.RecoveryCodesRemaining != recoveryCodeCount {
+		t.Errorf("Expected two-factor enabled with all recovery codes, got %+v err=%v", status, err)
+	}
+	if _, err := svc.BeginTOTPEnrollment("admin"); !errors.Is(err, ErrTwoFactorEnabled) {
+		t.Errorf("Expected ErrTwoFactorEnabled when enrolling twice, got %v", err)
+	}
+
+	// Only the hashes of recovery codes are stored
+	user, _ := svc.users.Get("admin")
+	for _, stored := range user.RecoveryCodes {
+		for _, code := range recoveryCodes {
+			if stored == code {
+				t.Fatal("Recovery codes must not be stored in plaintext")
+			}
+		}
+	}
+
+	if _, err := svc.TwoFactorStatus("jellyfin-user"); !errors.Is(err, ErrTwoFactorUnavailable) {
+		t.Errorf("Expected ErrTwoFactorUnavailable for an account without a local record, got %v", err)
+	}
+}
+
+func TestLogin_TwoFactor(t *testing.T) {
+	step := currentTOTPStep(t)
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	secret, recoveryCodes := enrollTOTP(t, svc, "admin", step)
+	ctx := context.Background()
+
+	login := func(t *testing.T) string {
+		t.Helper()
+		result, err := svc.Login(ctx, "admin", "adminpassword", ClientInfo{IP: "198.51.100.7"})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		if result.Token != "" || result.Challenge == "" || result.Enrollment != nil {
+			t.Fatalf("Expected a challenge and no token, got %+v", result)
+		}
+		return result.Challenge
+	}
+
+	t.Run("totp code completes sign-in once", func(t *testing.T) {
+		challenge := login(t)
+		result, err := svc.CompleteTwoFactorLogin(challenge, totpCodeAt(t, secret, step), ClientInfo{IP: "198.51.100.7"})
+		if err != nil {
+			t.Fatalf("CompleteTwoFactorLogin failed: %v", err)
+		}
+		if result.Token == "" || result.Role != utils.RoleAdmin {
+			t.Errorf("Expected an admin token, got %+v", result)
+		}
+		if _, err := svc.CompleteTwoFactorLogin(challenge, totpCodeAt(t, secret, step+1), ClientInfo{}); !errors.Is(err, ErrLoginChallenge) {
+			t.Errorf("Expected the challenge to be single-use, got %v", err)
+		}
+	})
+
+	t.Run("used code is not accepted again", func(t *testing.T) {
+		challenge := login(t)
+		_, err := svc.CompleteTwoFactorLogin(challenge, totpCodeAt(t, secret, step), ClientInfo{IP: "198.51.100.7"})
+		if !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected a replayed code to be rejected, got %v", err)
+		}
+		if _, err := svc.CompleteTwoFactorLogin(challenge, totpCodeAt(t, secret, step+1), ClientInfo{IP: "198.51.100.7"}); err != nil {
+			t.Errorf("Expected the next code to work on the same challenge, got %v", err)
+		}
+	})
+
+	t.Run("recovery code works once", func(t *testing.T) {
+		if _, err := svc.CompleteTwoFactorLogin(login(t), recoveryCodes[0], ClientInfo{}); err != nil {
+			t.Fatalf("Recovery code rejected: %v", err)
+		}
+		if _, err := svc.CompleteTwoFactorLogin(login(t), recoveryCodes[0], ClientInfo{}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("Expected a used recovery code to be rejected, got %v", err)
+		}
+		// Separators and case do not matter
+		loose := "  " + recoveryCodes[1][:5] + recoveryCodes[1][6:] + " "
+		if _, err := svc.CompleteTwoFactorLogin(login(t), loose, ClientInfo{}); err != nil {
+			t.Errorf("Expected a recovery code without separator to work, got %v", err)
+		}
+		if status, _ := svc.TwoFactorStatus("admin"); status.RecoveryCodesRemaining != recoveryCodeCount-2 {
+			t.Errorf("Expected %d recovery codes left, got %d", recoveryCodeCount-2, status.RecoveryCodesRemaining)
+		}
+	})
+
+	t.Run("wrong codes end the challenge", func(t *testing.T) {
+		svc.cfg.Admin.LoginThrottle.Disabled = true
+		defer func() { svc.cfg.Admin.LoginThrottle.Disabled = false }()
+
+		challenge := login(t)
+		for i := 0; i < maxTwoFactorChallengeFailures; i++ {
+			if _, err := svc.CompleteTwoFactorLogin(challenge, "000000", ClientInfo{}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+				t.Fatalf("Attempt %d: expected ErrInvalidTwoFactorCode, got %v", i+1, err)
+			}
+		}
+		if _, err := svc.CompleteTwoFactorLogin(challenge, totpCodeAt(t, secret, step+1), ClientInfo{}); !errors.Is(err, ErrLoginChallenge) {
+			t.Errorf("Expected the challenge to be gone, got %v", err)
+		}
+	})
+}
+
+func TestLogin_TwoFactorRequiredByRole(t *testing.T) {
+	step := currentTOTPStep(t)
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	if _, err := svc.CreateUser("viewer1", "viewerpassword", utils.RoleViewer); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	svc.cfg.Admin.TwoFactor.RequiredRoles = []string{utils.RoleAdmin}
+	ctx := context.Background()
+
+	// Roles that do not require it sign in as before
+	if result, err := svc.Login(ctx, "viewer1", "viewerpassword", ClientInfo{}); err != nil || result.Token == "" {
+		t.Fatalf("Expected viewer to get a token, got %+v err=%v", result, err)
+	}
+
+	result, err := svc.Login(ctx, "admin", "adminpassword", ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if result.Token != "" || result.Challenge == "" || result.Enrollment == nil {
+		t.Fatalf("Expected an enrollment challenge, got %+v", result)
+	}
+
+	// Signing in again keeps the secret that may already have been scanned
+	again, err := svc.Login(ctx, "admin", "adminpassword", ClientInfo{})
+	if err != nil || again.Enrollment == nil || again.Enrollment.Secret != result.Enrollment.Secret {
+		t.Fatalf("Expected the same pending secret, got %+v err=%v", again, err)
+	}
+
+	done, err := svc.CompleteTwoFactorLogin(again.Challenge, totpCodeAt(t, again.Enrollment.Secret, step), ClientInfo{})
+	if err != nil {
+		t.Fatalf("CompleteTwoFactorLogin failed: %v", err)
+	}
+	if done.Token == "" || len(done.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("Expected a token and recovery codes, got %+v", done)
+	}
+
+	code := totpCodeAt(t, again.Enrollment.Secret, step+1)
+	if err := svc.DisableTOTP("admin", code); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Errorf("Expected ErrTwoFactorRequired, got %v", err)
+	}
+
+	// An admin reset makes the user enroll again at the next sign-in
+	if err := svc.ResetTOTP("admin"); err != nil {
+		t.Fatalf("ResetTOTP failed: %v", err)
+	}
+	if result, err := svc.Login(ctx, "admin", "adminpassword", ClientInfo{}); err != nil || result.Enrollment == nil {
+		t.Errorf("Expected enrollment again after reset, got %+v err=%v", result, err)
+	}
+	if err := svc.ResetTOTP("nobody"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestDisableTOTPAndRegenerateRecoveryCodes(t *testing.T) {
+	step := currentTOTPStep(t)
+	svc := setupAuthServiceWithUsers(t, "adminpassword")
+	if _, err := svc.MigrateAdmin(); err != nil {
+		t.Fatalf("MigrateAdmin failed: %v", err)
+	}
+	secret, oldCodes := enrollTOTP(t, svc, "admin", step)
+
+	newCodes, err := svc.RegenerateRecoveryCodes("admin", totpCodeAt(t, secret, step))
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes failed: %v", err)
+	}
+	if err := svc.DisableTOTP("admin", oldCodes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected old recovery codes to stop working, got %v", err)
+	}
+	if err := svc.DisableTOTP("admin", newCodes[0]); err != nil {
+		t.Fatalf("DisableTOTP failed: %v", err)
+	}
+
+	if result, err := svc.Login(context.Background(), "admin", "adminpassword", ClientInfo{}); err != nil || result.Token == "" {
+		t.Errorf("Expected a token without a second factor, got %+v err=%v", result, err)
+	}
+	if err := svc.DisableTOTP("admin", "123456"); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Errorf("Expected ErrTwoFactorNotEnabled, got %v", err)
+	}
+}
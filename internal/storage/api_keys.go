@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// apiKeyUsageFlushInterval bounds how often last-used updates are written, so
+// a polling client does not cause a disk write per request
+const apiKeyUsageFlushInterval = time.Minute
+
+// APIKey is a named, scoped key for machine clients. Only a SHA-256 hash of
+// the secret is stored; the secret itself is shown once at creation.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the secret, to tell keys apart
+	Hash       string     `json:"hash"`   // hex SHA-256 of the secret
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Expired reports whether the key has an expiry in the past
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// APIKeysFile represents the api_keys.json structure
+type APIKeysFile struct {
+	Version   string            `json:"version"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Keys      map[string]APIKey `json:"keys"`
+	mu        sync.RWMutex      `json:"-"`
+	filePath  string            `json:"-"`
+	byHash    map[string]string `json:"-"` // hash -> ID
+	flushedAt time.Time         `json:"-"` // last write of usage updates
+}
+
+// NewAPIKeysFile creates or loads an API keys file. A corrupt file is backed
+// up and replaced with an empty one, which revokes every key.
+func NewAPIKeysFile(dataPath string) (*APIKeysFile, error) {
+	filePath := filepath.Join(dataPath, "api_keys.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	kf := &APIKeysFile{
+		Version:  "1.0",
+		Keys:     make(map[string]APIKey),
+		filePath: filePath,
+		byHash:   make(map[string]string),
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := kf.load(); err != nil {
+			backup, backupErr := backupCorruptFile(filePath)
+			if backupErr != nil {
+				return nil, fmt.Errorf("failed to load API keys file: %w (and backing it up failed: %v)", err, backupErr)
+			}
+			log.Warn().Err(err).Str("backup", backup).Msg("API keys file is corrupt, starting with no keys")
+			kf.Keys = make(map[string]APIKey)
+		}
+	}
+	kf.reindex()
+
+	return kf, nil
+}
+
+// Put creates or replaces a key
+func (kf *APIKeysFile) Put(key APIKey) error {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	next := make(map[string]APIKey, len(kf.Keys)+1)
+	for id, existing := range kf.Keys {
+		next[id] = existing
+	}
+	next[key.ID] = key
+	now := time.Now()
+
+	if err := kf.persist(next, now); err != nil {
+		return err
+	}
+
+	kf.Keys = next
+	kf.UpdatedAt = now
+	kf.reindex()
+	return nil
+}
+
+// Remove deletes a key. Returns false when no key has the ID.
+func (kf *APIKeysFile) Remove(id string) (bool, error) {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	if _, exists := kf.Keys[id]; !exists {
+		return false, nil
+	}
+
+	next := make(map[string]APIKey, len(kf.Keys)-1)
+	for keyID, existing := range kf.Keys {
+		if keyID != id {
+			next[keyID] = existing
+		}
+	}
+	now := time.Now()
+
+	if err := kf.persist(next, now); err != nil {
+		return false, err
+	}
+
+	kf.Keys = next
+	kf.UpdatedAt = now
+	kf.reindex()
+	return true, nil
+}
+
+// Get retrieves a key by ID
+func (kf *APIKeysFile) Get(id string) (APIKey, bool) {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+
+	key, exists := kf.Keys[id]
+	return key, exists
+}
+
+// GetByHash retrieves a key by the hash of its secret
+func (kf *APIKeysFile) GetByHash(hash string) (APIKey, bool) {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+
+	id, exists := kf.byHash[hash]
+	if !exists {
+		return APIKey{}, false
+	}
+	return kf.Keys[id], true
+}
+
+// GetAll returns all keys, oldest first
+func (kf *APIKeysFile) GetAll() []APIKey {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(kf.Keys))
+	for _, key := range kf.Keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// MarkUsed records that a key was used at the given time. The update is kept
+// in memory and written at most once per apiKeyUsageFlushInterval.
+func (kf *APIKeysFile) MarkUsed(id string, at time.Time) error {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	key, exists := kf.Keys[id]
+	if !exists {
+		return nil
+	}
+	key.LastUsedAt = &at
+	kf.Keys[id] = key
+
+	if at.Sub(kf.flushedAt) < apiKeyUsageFlushInterval {
+		return nil
+	}
+	if err := kf.persist(kf.Keys, kf.UpdatedAt); err != nil {
+		return err
+	}
+	kf.flushedAt = at
+	return nil
+}
+
+// Flush writes pending last-used updates to disk
+func (kf *APIKeysFile) Flush() error {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	if err := kf.persist(kf.Keys, kf.UpdatedAt); err != nil {
+		return err
+	}
+	kf.flushedAt = time.Now()
+	return nil
+}
+
+// reindex rebuilds the hash lookup. Callers hold kf.mu (or own kf).
+func (kf *APIKeysFile) reindex() {
+	kf.byHash = make(map[string]string, len(kf.Keys))
+	for id, key := range kf.Keys {
+		kf.byHash[key.Hash] = id
+	}
+}
+
+// load reads the API keys file from disk
+func (kf *APIKeysFile) load() error {
+	data, err := os.ReadFile(kf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var loaded APIKeysFile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	kf.Version = loaded.Version
+	kf.UpdatedAt = loaded.UpdatedAt
+	kf.Keys = loaded.Keys
+	if kf.Keys == nil {
+		kf.Keys = make(map[string]APIKey)
+	}
+
+	log.Info().Int("count", len(kf.Keys)).Msg("Loaded API keys from file")
+	return nil
+}
+
+// persist atomically writes the given state to disk. Callers hold kf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (kf *APIKeysFile) persist(keys map[string]APIKey, updatedAt time.Time) error {
+	if kf.filePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(&APIKeysFile{
+		Version:   kf.Version,
+		UpdatedAt: updatedAt,
+		Keys:      keys,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(kf.filePath, data, 0600); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(keys)).Msg("Saved API keys to file")
+	return nil
+}
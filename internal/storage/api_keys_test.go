@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeysFile_PutGetRemoveReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	kf, err := NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+
+	require.NoError(t, kf.Put(APIKey{ID: "k1", Name: "plugin", Hash: "hash-1", Scopes: []string{"leaving-soon:read"}, CreatedAt: time.Now()}))
+	require.NoError(t, kf.Put(APIKey{ID: "k2", Name: "script", Hash: "hash-2", CreatedAt: time.Now().Add(time.Second)}))
+
+	key, ok := kf.GetByHash("hash-1")
+	require.True(t, ok)
+	assert.Equal(t, "plugin", key.Name)
+	all := kf.GetAll()
+	require.Len(t, all, 2)
+	assert.Equal(t, "k1", all[0].ID, "oldest first")
+
+	info, err := os.Stat(filepath.Join(tmpDir, "api_keys.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	removed, err := kf.Remove("k2")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	_, ok = kf.GetByHash("hash-2")
+	assert.False(t, ok, "removed keys no longer match")
+	removed, err = kf.Remove("k2")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	reloaded, err := NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+	key, ok = reloaded.GetByHash("hash-1")
+	require.True(t, ok)
+	assert.Equal(t, []string{"leaving-soon:read"}, key.Scopes)
+}
+
+func TestAPIKeysFile_MarkUsedIsThrottled(t *testing.T) {
+	tmpDir := t.TempDir()
+	kf, err := NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, kf.Put(APIKey{ID: "k1", Hash: "h", CreatedAt: time.Now()}))
+
+	first := time.Now()
+	require.NoError(t, kf.MarkUsed("k1", first))
+	second := first.Add(10 * time.Second)
+	require.NoError(t, kf.MarkUsed("k1", second))
+
+	key, _ := kf.Get("k1")
+	require.NotNil(t, key.LastUsedAt)
+	assert.True(t, key.LastUsedAt.Equal(second), "memory always has the latest use")
+
+	reloaded, err := NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+	key, _ = reloaded.Get("k1")
+	require.NotNil(t, key.LastUsedAt)
+	assert.True(t, key.LastUsedAt.Equal(first), "the second use within a minute is not written")
+
+	require.NoError(t, kf.Flush())
+	reloaded, err = NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+	key, _ = reloaded.Get("k1")
+	assert.True(t, key.LastUsedAt.Equal(second))
+}
+
+func TestAPIKeysFile_CorruptFileStartsEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "api_keys.json"), []byte("{not json"), 0600))
+
+	kf, err := NewAPIKeysFile(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, kf.GetAll())
+}
+
+func TestAPIKey_Expired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, APIKey{}.Expired(now))
+	assert.True(t, APIKey{ExpiresAt: &past}.Expired(now))
+	assert.False(t, APIKey{ExpiresAt: &future}.Expired(now))
+}
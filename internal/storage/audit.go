@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditChange is one changed field of an audited action. Field is a dotted
+// path into the target (e.g. "rules.movie_retention", "advanced_rules[1].enabled");
+// Before is absent for additions and After for removals.
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// AuditEntry records one mutating action: who did what to which target, from
+// where, and what changed
+type AuditEntry struct {
+	ID        string        `json:"id"`
+	Timestamp time.Time     `json:"timestamp"`
+	Actor     string        `json:"actor"` // username, "api_key:<name>" or "api_key"
+	IP        string        `json:"ip,omitempty"`
+	Action    string        `json:"action"` // e.g. "media.exclude", "rule.update", "auth.login"
+	Target    string        `json:"target,omitempty"`
+	Outcome   string        `json:"outcome"`
+	Message   string        `json:"message,omitempty"`
+	Changes   []AuditChange `json:"changes,omitempty"`
+}
+
+// auditCompactThreshold is how many dropped entries the file may still hold
+// before it is rewritten with only the kept ones
+const auditCompactThreshold = 1000
+
+// AuditLogFile represents audit.jsonl, an append-only log with one JSON entry
+// per line, oldest first. Entries dropped by retention stay in the file until
+// enough have accumulated to rewrite it, so recording an action costs one
+// appended line rather than a rewrite of the whole log.
+type AuditLogFile struct {
+	// Entries are kept most recent first
+	Entries  []AuditEntry
+	mu       sync.RWMutex
+	filePath string
+	// fileEntries is how many entries the file holds, including dropped ones
+	// not yet compacted away
+	fileEntries int
+}
+
+// NewAuditLogFile creates or loads an audit log file
+func NewAuditLogFile(dataPath string) (*AuditLogFile, error) {
+	filePath := filepath.Join(dataPath, "audit.jsonl")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	af := &AuditLogFile{
+		Entries:  make([]AuditEntry, 0),
+		filePath: filePath,
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := af.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return af, nil
+}
+
+// Add records an entry and drops entries older than cutoff or beyond the
+// newest maxEntries. A zero cutoff or maxEntries disables that limit.
+func (af *AuditLogFile) Add(entry AuditEntry, cutoff time.Time, maxEntries int) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	if err := af.append(entry); err != nil {
+		return err
+	}
+
+	entries := make([]AuditEntry, 0, len(af.Entries)+1)
+	entries = append(entries, entry)
+	af.Entries = pruneAuditEntries(append(entries, af.Entries...), cutoff, maxEntries)
+
+	if af.fileEntries-len(af.Entries) >= auditCompactThreshold {
+		if err := af.compact(); err != nil {
+			// The appended entry is safe; compaction is retried on the next Add
+			log.Warn().Err(err).Msg("Failed to compact audit log")
+		}
+	}
+	return nil
+}
+
+// Prune drops entries older than cutoff or beyond the newest maxEntries, and
+// rewrites the file if it still holds dropped entries. It is called at
+// startup, so entries dropped before a restart do not reappear.
+func (af *AuditLogFile) Prune(cutoff time.Time, maxEntries int) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	af.Entries = pruneAuditEntries(af.Entries, cutoff, maxEntries)
+	if af.fileEntries == len(af.Entries) {
+		return nil
+	}
+	return af.compact()
+}
+
+// pruneAuditEntries returns the leading entries of entries (most recent
+// first) that are within cutoff and maxEntries
+func pruneAuditEntries(entries []AuditEntry, cutoff time.Time, maxEntries int) []AuditEntry {
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	if cutoff.IsZero() {
+		return entries
+	}
+	for i, entry := range entries {
+		if entry.Timestamp.Before(cutoff) {
+			// Entries are newest first, so everything after is older still
+			return entries[:i]
+		}
+	}
+	return entries
+}
+
+// GetAll returns all entries, most recent first
+func (af *AuditLogFile) GetAll() []AuditEntry {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	entries := make([]AuditEntry, len(af.Entries))
+	copy(entries, af.Entries)
+	return entries
+}
+
+// load reads the audit log file from disk. Lines that do not parse, such as
+// one torn by a crash mid-write, are skipped; the file is then preserved as a
+// backup and rewritten with the entries that did parse.
+func (af *AuditLogFile) load() error {
+	file, err := os.Open(af.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	skipped := 0
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var entry AuditEntry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr != nil {
+				skipped++
+			} else {
+				entries = append(entries, entry)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The file is oldest first
+	af.Entries = make([]AuditEntry, len(entries))
+	for i, entry := range entries {
+		af.Entries[len(entries)-1-i] = entry
+	}
+	af.fileEntries = len(entries)
+
+	if skipped > 0 {
+		file.Close()
+		if backup, err := backupCorruptFile(af.filePath); err != nil {
+			log.Warn().Err(err).Int("skipped", skipped).Msg("Skipped unreadable audit log entries; backing up the file failed")
+		} else {
+			log.Warn().Int("skipped", skipped).Str("backup", backup).Msg("Skipped unreadable audit log entries; original file preserved")
+		}
+		if err := af.compact(); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Int("count", len(af.Entries)).Msg("Loaded audit log from file")
+	return nil
+}
+
+// append writes entry to the end of the file. Callers hold af.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (af *AuditLogFile) append(entry AuditEntry) error {
+	if af.filePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Entries include client IPs and usernames
+	file, err := os.OpenFile(af.filePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	af.fileEntries++
+	return nil
+}
+
+// compact atomically rewrites the file with only the kept entries. Callers
+// hold af.mu.
+func (af *AuditLogFile) compact() error {
+	if af.filePath == "" {
+		af.fileEntries = len(af.Entries)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := len(af.Entries) - 1; i >= 0; i-- {
+		if err := encoder.Encode(af.Entries[i]); err != nil {
+			return err
+		}
+	}
+	if err := writeFileAtomic(af.filePath, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	af.fileEntries = len(af.Entries)
+	log.Debug().Int("count", len(af.Entries)).Msg("Compacted audit log file")
+	return nil
+}
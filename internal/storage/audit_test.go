@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogFile(t *testing.T) {
+	t.Run("adds entries most recent first and persists them", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		af, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+
+		now := time.Now()
+		require.NoError(t, af.Add(AuditEntry{ID: "a1", Timestamp: now.Add(-time.Minute), Action: "rule.create"}, time.Time{}, 0))
+		require.NoError(t, af.Add(AuditEntry{ID: "a2", Timestamp: now, Action: "rule.delete", Changes: []AuditChange{{Field: "enabled", Before: true}}}, time.Time{}, 0))
+
+		entries := af.GetAll()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "a2", entries[0].ID)
+
+		info, err := os.Stat(filepath.Join(tmpDir, "audit.jsonl"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		reloaded, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		require.Len(t, reloaded.GetAll(), 2)
+		assert.Equal(t, "enabled", reloaded.GetAll()[0].Changes[0].Field)
+		assert.Equal(t, true, reloaded.GetAll()[0].Changes[0].Before)
+	})
+
+	t.Run("applies retention and max entries", func(t *testing.T) {
+		af, err := NewAuditLogFile(t.TempDir())
+		require.NoError(t, err)
+
+		now := time.Now()
+		require.NoError(t, af.Add(AuditEntry{ID: "old", Timestamp: now.Add(-48 * time.Hour)}, time.Time{}, 0))
+		for _, id := range []string{"b1", "b2", "b3"} {
+			require.NoError(t, af.Add(AuditEntry{ID: id, Timestamp: now}, now.Add(-24*time.Hour), 0))
+		}
+		ids := func() []string {
+			var out []string
+			for _, e := range af.GetAll() {
+				out = append(out, e.ID)
+			}
+			return out
+		}
+		assert.Equal(t, []string{"b3", "b2", "b1"}, ids())
+
+		require.NoError(t, af.Add(AuditEntry{ID: "b4", Timestamp: now}, time.Time{}, 2))
+		assert.Equal(t, []string{"b4", "b3"}, ids())
+	})
+
+	t.Run("appends each entry without rewriting the file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "audit.jsonl")
+		af, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+
+		require.NoError(t, af.Add(AuditEntry{ID: "c1", Timestamp: time.Now()}, time.Time{}, 0))
+		first, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		require.NoError(t, af.Add(AuditEntry{ID: "c2", Timestamp: time.Now()}, time.Time{}, 0))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, first), "earlier lines should be left as they were")
+		assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+	})
+
+	t.Run("compacts once enough entries are dropped", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "audit.jsonl")
+		af, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+
+		lines := func() int {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			return bytes.Count(data, []byte("\n"))
+		}
+		now := time.Now()
+		for i := 0; i < auditCompactThreshold+9; i++ {
+			require.NoError(t, af.Add(AuditEntry{ID: fmt.Sprint(i), Timestamp: now}, time.Time{}, 10))
+		}
+		assert.Equal(t, auditCompactThreshold+9, lines(), "dropped entries stay until the threshold")
+
+		require.NoError(t, af.Add(AuditEntry{ID: "last", Timestamp: now}, time.Time{}, 10))
+		assert.Equal(t, 10, lines())
+
+		reloaded, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		require.Len(t, reloaded.GetAll(), 10)
+		assert.Equal(t, "last", reloaded.GetAll()[0].ID)
+		assert.Equal(t, af.GetAll()[9].ID, reloaded.GetAll()[9].ID)
+	})
+
+	t.Run("prune drops entries left in the file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		af, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+
+		now := time.Now()
+		for _, id := range []string{"d1", "d2", "d3"} {
+			require.NoError(t, af.Add(AuditEntry{ID: id, Timestamp: now}, time.Time{}, 2))
+		}
+
+		// Before compaction a restart would bring d1 back
+		reloaded, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		require.Len(t, reloaded.GetAll(), 3)
+
+		require.NoError(t, reloaded.Prune(now.Add(-time.Hour), 2))
+		assert.Len(t, reloaded.GetAll(), 2)
+
+		reloaded, err = NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		entries := reloaded.GetAll()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "d3", entries[0].ID)
+		assert.Equal(t, "d2", entries[1].ID)
+	})
+
+	t.Run("skips unreadable lines and keeps a backup", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "audit.jsonl")
+		content := `{"id":"e1","timestamp":"2026-01-01T00:00:00Z"}` + "\n" + `{"id":"e2","timest`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+		af, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		entries := af.GetAll()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "e1", entries[0].ID)
+
+		backups, err := filepath.Glob(path + ".corrupt.*")
+		require.NoError(t, err)
+		assert.Len(t, backups, 1)
+
+		// The torn line is gone, so the next entry starts on a line of its own
+		require.NoError(t, af.Add(AuditEntry{ID: "e3", Timestamp: time.Now()}, time.Time{}, 0))
+		reloaded, err := NewAuditLogFile(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, reloaded.GetAll(), 2)
+	})
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Sources of config versions
+const (
+	ConfigSourceStartup = "startup" // the config file found at startup
+	ConfigSourceAPI     = "api"     // written by the config or rules API
+	ConfigSourceFile    = "file"    // edited outside OxiCleanarr and reloaded
+	ConfigSourceRestore = "restore" // an earlier version restored through the API
+)
+
+// ConfigVersion is a snapshot of config.yaml as it was written or reloaded
+type ConfigVersion struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"`
+	Actor     string    `json:"actor,omitempty"`
+	// RestoredFrom is the ID of the version a restore went back to
+	RestoredFrom string `json:"restored_from,omitempty"`
+	Hash         string `json:"hash"` // SHA-256 of Content, hex encoded
+	Content      string `json:"content"`
+}
+
+// ConfigHistoryFile represents the config_history.json structure. Versions
+// are kept oldest first and bounded by maxVersions. The file holds whatever
+// secrets config.yaml does, so it is only readable by its owner.
+type ConfigHistoryFile struct {
+	Version     string          `json:"version"`
+	Versions    []ConfigVersion `json:"versions"`
+	mu          sync.RWMutex
+	filePath    string
+	maxVersions int
+}
+
+// NewConfigHistoryFile creates or loads a config history file
+func NewConfigHistoryFile(dataPath string, maxVersions int) (*ConfigHistoryFile, error) {
+	filePath := filepath.Join(dataPath, "config_history.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	if maxVersions == 0 {
+		maxVersions = 50
+	}
+
+	hf := &ConfigHistoryFile{
+		Version:     "1.0",
+		Versions:    make([]ConfigVersion, 0),
+		filePath:    filePath,
+		maxVersions: maxVersions,
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := hf.load(); err != nil {
+			// The history is only needed to roll back; start fresh rather than
+			// refuse to start, but keep the bytes around for manual recovery.
+			if backup, backupErr := backupCorruptFile(filePath); backupErr != nil {
+				log.Error().Err(err).Err(backupErr).
+					Msg("Failed to load config history file; corrupt backup also failed, starting fresh")
+			} else {
+				log.Error().Err(err).Str("backup", backup).
+					Msg("Failed to load config history file; corrupt file preserved, starting fresh")
+			}
+		}
+	}
+
+	return hf, nil
+}
+
+// Add appends a version, dropping the oldest versions beyond maxVersions
+func (hf *ConfigHistoryFile) Add(version ConfigVersion) error {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+
+	next := make([]ConfigVersion, 0, len(hf.Versions)+1)
+	next = append(next, hf.Versions...)
+	next = append(next, version)
+	if len(next) > hf.maxVersions {
+		next = next[len(next)-hf.maxVersions:]
+	}
+
+	if err := hf.persist(next); err != nil {
+		return err
+	}
+
+	hf.Versions = next
+	return nil
+}
+
+// Remove deletes the version with the given ID and reports whether it existed
+func (hf *ConfigHistoryFile) Remove(id string) (bool, error) {
+	hf.mu.Lock()
+	defer hf.mu.Unlock()
+
+	next := make([]ConfigVersion, 0, len(hf.Versions))
+	for _, version := range hf.Versions {
+		if version.ID != id {
+			next = append(next, version)
+		}
+	}
+	if len(next) == len(hf.Versions) {
+		return false, nil
+	}
+
+	if err := hf.persist(next); err != nil {
+		return false, err
+	}
+
+	hf.Versions = next
+	return true, nil
+}
+
+// Get returns the version with the given ID
+func (hf *ConfigHistoryFile) Get(id string) (ConfigVersion, bool) {
+	hf.mu.RLock()
+	defer hf.mu.RUnlock()
+
+	for _, version := range hf.Versions {
+		if version.ID == id {
+			return version, true
+		}
+	}
+	return ConfigVersion{}, false
+}
+
+// Latest returns the most recently added version
+func (hf *ConfigHistoryFile) Latest() (ConfigVersion, bool) {
+	hf.mu.RLock()
+	defer hf.mu.RUnlock()
+
+	if len(hf.Versions) == 0 {
+		return ConfigVersion{}, false
+	}
+	return hf.Versions[len(hf.Versions)-1], true
+}
+
+// GetAll returns all versions, oldest first
+func (hf *ConfigHistoryFile) GetAll() []ConfigVersion {
+	hf.mu.RLock()
+	defer hf.mu.RUnlock()
+
+	versions := make([]ConfigVersion, len(hf.Versions))
+	copy(versions, hf.Versions)
+	return versions
+}
+
+// load reads the config history file from disk
+func (hf *ConfigHistoryFile) load() error {
+	data, err := os.ReadFile(hf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var temp struct {
+		Version  string          `json:"version"`
+		Versions []ConfigVersion `json:"versions"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	hf.Version = temp.Version
+	hf.Versions = temp.Versions
+	if hf.Versions == nil {
+		hf.Versions = make([]ConfigVersion, 0)
+	}
+
+	log.Info().Int("count", len(hf.Versions)).Msg("Loaded config history from file")
+	return nil
+}
+
+// persist atomically writes the given versions to disk. Callers hold hf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (hf *ConfigHistoryFile) persist(versions []ConfigVersion) error {
+	if hf.filePath == "" {
+		return nil
+	}
+
+	data := struct {
+		Version  string          `json:"version"`
+		Versions []ConfigVersion `json:"versions"`
+	}{
+		Version:  hf.Version,
+		Versions: versions,
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(hf.filePath, jsonData, 0600); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(versions)).Msg("Saved config history to file")
+	return nil
+}
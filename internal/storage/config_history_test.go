@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHistoryFile_AddAndReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	hf, err := NewConfigHistoryFile(tmpDir, 10)
+	require.NoError(t, err)
+	_, ok := hf.Latest()
+	assert.False(t, ok)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, hf.Add(ConfigVersion{ID: "a", CreatedAt: now.Add(-time.Hour), Source: ConfigSourceStartup, Content: "app: {}\n"}))
+	require.NoError(t, hf.Add(ConfigVersion{ID: "b", CreatedAt: now, Source: ConfigSourceAPI, Actor: "admin", Content: "app:\n  dry_run: false\n"}))
+
+	info, err := os.Stat(filepath.Join(tmpDir, "config_history.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the history holds config secrets")
+
+	reloaded, err := NewConfigHistoryFile(tmpDir, 10)
+	require.NoError(t, err)
+
+	versions := reloaded.GetAll()
+	require.Len(t, versions, 2)
+	assert.Equal(t, "a", versions[0].ID)
+	latest, ok := reloaded.Latest()
+	require.True(t, ok)
+	assert.Equal(t, "b", latest.ID)
+	assert.Equal(t, "admin", latest.Actor)
+
+	version, ok := reloaded.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "app: {}\n", version.Content)
+}
+
+func TestConfigHistoryFile_TrimsOldestBeyondMax(t *testing.T) {
+	hf, err := NewConfigHistoryFile(t.TempDir(), 3)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		require.NoError(t, hf.Add(ConfigVersion{ID: id}))
+	}
+
+	versions := hf.GetAll()
+	require.Len(t, versions, 3)
+	assert.Equal(t, "3", versions[0].ID, "oldest versions should be dropped first")
+	assert.Equal(t, "5", versions[2].ID)
+}
+
+func TestConfigHistoryFile_Remove(t *testing.T) {
+	hf, err := NewConfigHistoryFile(t.TempDir(), 0)
+	require.NoError(t, err)
+	require.NoError(t, hf.Add(ConfigVersion{ID: "a"}))
+	require.NoError(t, hf.Add(ConfigVersion{ID: "b"}))
+
+	removed, err := hf.Remove("b")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	latest, _ := hf.Latest()
+	assert.Equal(t, "a", latest.ID)
+
+	removed, err = hf.Remove("missing")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestConfigHistoryFile_CorruptFileStartsFresh(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "config_history.json")
+	require.NoError(t, os.WriteFile(filePath, []byte("{not json"), 0600))
+
+	hf, err := NewConfigHistoryFile(tmpDir, 0)
+	require.NoError(t, err)
+	assert.Empty(t, hf.GetAll())
+
+	matches, err := filepath.Glob(filePath + ".corrupt.*")
+	require.NoError(t, err)
+	assert.Len(t, matches, 1, "corrupt file should be preserved")
+}
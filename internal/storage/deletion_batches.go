@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeletionBatchStatus represents the lifecycle state of a deletion batch
+type DeletionBatchStatus string
+
+const (
+	BatchStatusPending   DeletionBatchStatus = "pending"   // awaiting approval for at least one item
+	BatchStatusCompleted DeletionBatchStatus = "completed" // every item was decided (approved or rejected)
+	BatchStatusRejected  DeletionBatchStatus = "rejected"  // every item was rejected
+	BatchStatusExpired   DeletionBatchStatus = "expired"   // expiry passed with items still pending
+)
+
+// DeletionItemStatus represents the state of a single item in a deletion batch
+type DeletionItemStatus string
+
+const (
+	ItemStatusPending  DeletionItemStatus = "pending"
+	ItemStatusDeleted  DeletionItemStatus = "deleted"
+	ItemStatusSkipped  DeletionItemStatus = "skipped" // re-check at approval found it no longer due
+	ItemStatusFailed   DeletionItemStatus = "failed"
+	ItemStatusRejected DeletionItemStatus = "rejected"
+	ItemStatusExpired  DeletionItemStatus = "expired"
+)
+
+// DeletionBatchItem is one proposed deletion inside a batch
+type DeletionBatchItem struct {
+	MediaID     string             `json:"media_id"`
+	Title       string             `json:"title"`
+	Year        int                `json:"year,omitempty"`
+	Type        string             `json:"type"`
+	FileSize    int64              `json:"file_size"`
+	DeleteAfter time.Time          `json:"delete_after"`
+	Reason      string             `json:"reason,omitempty"`
+	Status      DeletionItemStatus `json:"status"`
+	Message     string             `json:"message,omitempty"` // why it was skipped or failed
+	DecidedAt   *time.Time         `json:"decided_at,omitempty"`
+	DecidedBy   string             `json:"decided_by,omitempty"`
+}
+
+// DeletionBatch is a set of deletion candidates proposed by a full sync that
+// waits for admin approval before anything is deleted
+type DeletionBatch struct {
+	ID        string              `json:"id"`
+	JobID     string              `json:"job_id,omitempty"` // full sync that proposed the batch
+	Status    DeletionBatchStatus `json:"status"`
+	CreatedAt time.Time           `json:"created_at"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	Items     []DeletionBatchItem `json:"items"`
+}
+
+// PendingCount returns the number of items still awaiting a decision
+func (b DeletionBatch) PendingCount() int {
+	count := 0
+	for _, item := range b.Items {
+		if item.Status == ItemStatusPending {
+			count++
+		}
+	}
+	return count
+}
+
+// DeletionBatchesFile represents the deletion_batches.json structure
+type DeletionBatchesFile struct {
+	Version    string          `json:"version"`
+	Batches    []DeletionBatch `json:"batches"`
+	mu         sync.RWMutex
+	filePath   string
+	maxBatches int
+}
+
+// NewDeletionBatchesFile creates or loads a deletion batches file
+func NewDeletionBatchesFile(dataPath string, maxBatches int) (*DeletionBatchesFile, error) {
+	filePath := filepath.Join(dataPath, "deletion_batches.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	if maxBatches == 0 {
+		maxBatches = 50 // Default to keeping last 50 batches
+	}
+
+	bf := &DeletionBatchesFile{
+		Version:    "1.0",
+		Batches:    make([]DeletionBatch, 0),
+		filePath:   filePath,
+		maxBatches: maxBatches,
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := bf.load(); err != nil {
+			// Losing pending batches only means the next full sync proposes
+			// them again, so start fresh but keep the bytes for recovery.
+			if backup, backupErr := backupCorruptFile(filePath); backupErr != nil {
+				log.Error().Err(err).Err(backupErr).
+					Msg("Failed to load deletion batches file; corrupt backup also failed, starting fresh")
+			} else {
+				log.Error().Err(err).Str("backup", backup).
+					Msg("Failed to load deletion batches file; corrupt file preserved, starting fresh")
+			}
+		}
+	}
+
+	return bf, nil
+}
+
+// Add adds a new batch (most recent first), keeping only maxBatches.
+// Pending batches are never trimmed, so an unapproved batch cannot silently
+// disappear behind a run of newer ones.
+func (bf *DeletionBatchesFile) Add(batch DeletionBatch) error {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	next := make([]DeletionBatch, 0, len(bf.Batches)+1)
+	next = append(next, batch)
+	next = append(next, bf.Batches...)
+	if len(next) > bf.maxBatches {
+		trimmed := make([]DeletionBatch, 0, bf.maxBatches)
+		for i, b := range next {
+			if i < bf.maxBatches || b.Status == BatchStatusPending {
+				trimmed = append(trimmed, b)
+			}
+		}
+		next = trimmed
+	}
+
+	if err := bf.persist(next); err != nil {
+		return err
+	}
+
+	bf.Batches = next
+	return nil
+}
+
+// Update replaces an existing batch. Returns false if no batch has that ID.
+func (bf *DeletionBatchesFile) Update(batch DeletionBatch) (bool, error) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	next := make([]DeletionBatch, len(bf.Batches))
+	copy(next, bf.Batches)
+
+	updated := false
+	for i := range next {
+		if next[i].ID == batch.ID {
+			next[i] = batch
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		return false, nil
+	}
+
+	if err := bf.persist(next); err != nil {
+		return false, err
+	}
+
+	bf.Batches = next
+	return true, nil
+}
+
+// Get retrieves a batch by ID
+func (bf *DeletionBatchesFile) Get(id string) (DeletionBatch, bool) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	for _, batch := range bf.Batches {
+		if batch.ID == id {
+			return copyBatch(batch), true
+		}
+	}
+	return DeletionBatch{}, false
+}
+
+// GetAll returns all batches, most recent first
+func (bf *DeletionBatchesFile) GetAll() []DeletionBatch {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	batches := make([]DeletionBatch, len(bf.Batches))
+	for i, batch := range bf.Batches {
+		batches[i] = copyBatch(batch)
+	}
+	return batches
+}
+
+// PendingMediaIDs returns the media IDs of all items still awaiting a
+// decision in pending batches
+func (bf *DeletionBatchesFile) PendingMediaIDs() map[string]bool {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	ids := make(map[string]bool)
+	for _, batch := range bf.Batches {
+		if batch.Status != BatchStatusPending {
+			continue
+		}
+		for _, item := range batch.Items {
+			if item.Status == ItemStatusPending {
+				ids[item.MediaID] = true
+			}
+		}
+	}
+	return ids
+}
+
+// ExpireStale marks pending batches whose expiry has passed as expired, along
+// with their still-pending items. Returns the number of batches expired.
+func (bf *DeletionBatchesFile) ExpireStale(now time.Time) (int, error) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	next := make([]DeletionBatch, len(bf.Batches))
+	expired := 0
+	for i, batch := range bf.Batches {
+		if batch.Status != BatchStatusPending || now.Before(batch.ExpiresAt) {
+			next[i] = batch
+			continue
+		}
+		batch = copyBatch(batch)
+		batch.Status = BatchStatusExpired
+		for j := range batch.Items {
+			if batch.Items[j].Status == ItemStatusPending {
+				batch.Items[j].Status = ItemStatusExpired
+			}
+		}
+		next[i] = batch
+		expired++
+	}
+
+	if expired == 0 {
+		return 0, nil
+	}
+
+	if err := bf.persist(next); err != nil {
+		return 0, err
+	}
+
+	bf.Batches = next
+	return expired, nil
+}
+
+// copyBatch returns a batch with its own Items slice so callers can mutate
+// items without touching the stored state
+func copyBatch(batch DeletionBatch) DeletionBatch {
+	items := make([]DeletionBatchItem, len(batch.Items))
+	copy(items, batch.Items)
+	batch.Items = items
+	return batch
+}
+
+// load reads the deletion batches file from disk
+func (bf *DeletionBatchesFile) load() error {
+	data, err := os.ReadFile(bf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var temp struct {
+		Version string          `json:"version"`
+		Batches []DeletionBatch `json:"batches"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	bf.Version = temp.Version
+	bf.Batches = temp.Batches
+	if bf.Batches == nil {
+		bf.Batches = make([]DeletionBatch, 0)
+	}
+
+	log.Info().Int("count", len(bf.Batches)).Msg("Loaded deletion batches from file")
+	return nil
+}
+
+// persist atomically writes the given batches to disk. Callers hold bf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (bf *DeletionBatchesFile) persist(batches []DeletionBatch) error {
+	if bf.filePath == "" {
+		return nil
+	}
+
+	data := struct {
+		Version string          `json:"version"`
+		Batches []DeletionBatch `json:"batches"`
+	}{
+		Version: bf.Version,
+		Batches: batches,
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(bf.filePath, jsonData, 0644); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(batches)).Msg("Saved deletion batches to file")
+	return nil
+}
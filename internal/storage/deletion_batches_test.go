@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBatch(id string, status DeletionBatchStatus, expiresAt time.Time, mediaIDs ...string) DeletionBatch {
+	items := make([]DeletionBatchItem, 0, len(mediaIDs))
+	for _, mediaID := range mediaIDs {
+		items = append(items, DeletionBatchItem{MediaID: mediaID, Title: mediaID, Status: ItemStatusPending})
+	}
+	return DeletionBatch{
+		ID:        id,
+		Status:    status,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+		Items:     items,
+	}
+}
+
+func TestDeletionBatchesFile_AddGetUpdateReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bf, err := NewDeletionBatchesFile(tmpDir, 10)
+	require.NoError(t, err)
+
+	batch := newTestBatch("b1", BatchStatusPending, time.Now().Add(time.Hour), "movie-1", "movie-2")
+	require.NoError(t, bf.Add(batch))
+
+	got, found := bf.Get("b1")
+	require.True(t, found)
+	assert.Equal(t, 2, got.PendingCount())
+
+	// Mutating the returned copy must not leak into the store
+	got.Items[0].Status = ItemStatusDeleted
+	again, _ := bf.Get("b1")
+	assert.Equal(t, ItemStatusPending, again.Items[0].Status)
+
+	ok, err := bf.Update(got)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = bf.Update(DeletionBatch{ID: "missing"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	reloaded, err := NewDeletionBatchesFile(tmpDir, 10)
+	require.NoError(t, err)
+	got, found = reloaded.Get("b1")
+	require.True(t, found)
+	assert.Equal(t, ItemStatusDeleted, got.Items[0].Status)
+	assert.Equal(t, 1, got.PendingCount())
+}
+
+func TestDeletionBatchesFile_TrimKeepsPending(t *testing.T) {
+	bf, err := NewDeletionBatchesFile(t.TempDir(), 2)
+	require.NoError(t, err)
+
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, bf.Add(newTestBatch("old-pending", BatchStatusPending, future, "a")))
+	require.NoError(t, bf.Add(newTestBatch("done-1", BatchStatusCompleted, future, "b")))
+	require.NoError(t, bf.Add(newTestBatch("done-2", BatchStatusCompleted, future, "c")))
+
+	all := bf.GetAll()
+	ids := make([]string, 0, len(all))
+	for _, b := range all {
+		ids = append(ids, b.ID)
+	}
+	assert.Equal(t, []string{"done-2", "done-1", "old-pending"}, ids, "pending batches must survive trimming")
+
+	require.NoError(t, bf.Add(newTestBatch("done-3", BatchStatusCompleted, future, "d")))
+	all = bf.GetAll()
+	ids = ids[:0]
+	for _, b := range all {
+		ids = append(ids, b.ID)
+	}
+	assert.Equal(t, []string{"done-3", "done-2", "old-pending"}, ids)
+}
+
+func TestDeletionBatchesFile_ExpireStale(t *testing.T) {
+	bf, err := NewDeletionBatchesFile(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	now := time.Now()
+	stale := newTestBatch("stale", BatchStatusPending, now.Add(-time.Minute), "movie-1", "movie-2")
+	stale.Items[1].Status = ItemStatusDeleted
+	require.NoError(t, bf.Add(stale))
+	require.NoError(t, bf.Add(newTestBatch("fresh", BatchStatusPending, now.Add(time.Hour), "movie-3")))
+
+	assert.Equal(t, map[string]bool{"movie-1": true, "movie-3": true}, bf.PendingMediaIDs())
+
+	expired, err := bf.ExpireStale(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	got, _ := bf.Get("stale")
+	assert.Equal(t, BatchStatusExpired, got.Status)
+	assert.Equal(t, ItemStatusExpired, got.Items[0].Status)
+	assert.Equal(t, ItemStatusDeleted, got.Items[1].Status, "decided items keep their status")
+
+	assert.Equal(t, map[string]bool{"movie-3": true}, bf.PendingMediaIDs())
+
+	expired, err = bf.ExpireStale(now)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DiskSample is a single disk space reading recorded by the disk monitor
+type DiskSample struct {
+	Timestamp  time.Time `json:"timestamp"`
+	FreeBytes  int64     `json:"free_bytes"`
+	TotalBytes int64     `json:"total_bytes"`
+	Source     string    `json:"source,omitempty"` // "radarr", "sonarr", "lowest"
+}
+
+// DiskHistoryFile represents the disk_history.json structure.
+// Samples are kept oldest first and bounded by maxSamples.
+type DiskHistoryFile struct {
+	Version    string       `json:"version"`
+	Samples    []DiskSample `json:"samples"`
+	mu         sync.RWMutex
+	filePath   string
+	maxSamples int
+}
+
+// NewDiskHistoryFile creates or loads a disk history file
+func NewDiskHistoryFile(dataPath string, maxSamples int) (*DiskHistoryFile, error) {
+	filePath := filepath.Join(dataPath, "disk_history.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	if maxSamples == 0 {
+		maxSamples = 4320 // ~180 days of hourly full syncs
+	}
+
+	df := &DiskHistoryFile{
+		Version:    "1.0",
+		Samples:    make([]DiskSample, 0),
+		filePath:   filePath,
+		maxSamples: maxSamples,
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := df.load(); err != nil {
+			// History only feeds the forecast; losing it is not worth refusing
+			// to start, but keep the bytes around for manual recovery.
+			if backup, backupErr := backupCorruptFile(filePath); backupErr != nil {
+				log.Error().Err(err).Err(backupErr).
+					Msg("Failed to load disk history file; corrupt backup also failed, starting fresh")
+			} else {
+				log.Error().Err(err).Str("backup", backup).
+					Msg("Failed to load disk history file; corrupt file preserved, starting fresh")
+			}
+		}
+	}
+
+	return df, nil
+}
+
+// Add appends a sample, dropping the oldest samples beyond maxSamples
+func (df *DiskHistoryFile) Add(sample DiskSample) error {
+	df.mu.Lock()
+	defer df.mu.Unlock()
+
+	next := make([]DiskSample, 0, len(df.Samples)+1)
+	next = append(next, df.Samples...)
+	next = append(next, sample)
+	if len(next) > df.maxSamples {
+		next = next[len(next)-df.maxSamples:]
+	}
+
+	if err := df.persist(next); err != nil {
+		return err
+	}
+
+	df.Samples = next
+	return nil
+}
+
+// GetAll returns all samples, oldest first
+func (df *DiskHistoryFile) GetAll() []DiskSample {
+	df.mu.RLock()
+	defer df.mu.RUnlock()
+
+	samples := make([]DiskSample, len(df.Samples))
+	copy(samples, df.Samples)
+	return samples
+}
+
+// Since returns the samples recorded at or after t, oldest first
+func (df *DiskHistoryFile) Since(t time.Time) []DiskSample {
+	df.mu.RLock()
+	defer df.mu.RUnlock()
+
+	samples := make([]DiskSample, 0, len(df.Samples))
+	for _, s := range df.Samples {
+		if !s.Timestamp.Before(t) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// load reads the disk history file from disk
+func (df *DiskHistoryFile) load() error {
+	data, err := os.ReadFile(df.filePath)
+	if err != nil {
+		return err
+	}
+
+	var temp struct {
+		Version string       `json:"version"`
+		Samples []DiskSample `json:"samples"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	df.Version = temp.Version
+	df.Samples = temp.Samples
+	if df.Samples == nil {
+		df.Samples = make([]DiskSample, 0)
+	}
+
+	log.Info().Int("count", len(df.Samples)).Msg("Loaded disk history from file")
+	return nil
+}
+
+// persist atomically writes the given samples to disk. Callers hold df.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (df *DiskHistoryFile) persist(samples []DiskSample) error {
+	if df.filePath == "" {
+		return nil
+	}
+
+	data := struct {
+		Version string       `json:"version"`
+		Samples []DiskSample `json:"samples"`
+	}{
+		Version: df.Version,
+		Samples: samples,
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(df.filePath, jsonData, 0644); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(samples)).Msg("Saved disk history to file")
+	return nil
+}
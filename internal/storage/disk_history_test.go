@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskHistoryFile_AddAndReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	df, err := NewDiskHistoryFile(tmpDir, 10)
+	require.NoError(t, err)
+	assert.Empty(t, df.GetAll())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, df.Add(DiskSample{Timestamp: now.Add(-time.Hour), FreeBytes: 200, TotalBytes: 1000, Source: "radarr"}))
+	require.NoError(t, df.Add(DiskSample{Timestamp: now, FreeBytes: 150, TotalBytes: 1000, Source: "radarr"}))
+
+	reloaded, err := NewDiskHistoryFile(tmpDir, 10)
+	require.NoError(t, err)
+
+	samples := reloaded.GetAll()
+	require.Len(t, samples, 2)
+	assert.Equal(t, int64(200), samples[0].FreeBytes)
+	assert.Equal(t, int64(150), samples[1].FreeBytes)
+	assert.True(t, samples[1].Timestamp.Equal(now))
+}
+
+func TestDiskHistoryFile_TrimsOldestBeyondMax(t *testing.T) {
+	df, err := NewDiskHistoryFile(t.TempDir(), 3)
+	require.NoError(t, err)
+
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, df.Add(DiskSample{Timestamp: base.Add(time.Duration(i) * time.Hour), FreeBytes: int64(i)}))
+	}
+
+	samples := df.GetAll()
+	require.Len(t, samples, 3)
+	assert.Equal(t, int64(2), samples[0].FreeBytes, "oldest samples should be dropped first")
+	assert.Equal(t, int64(4), samples[2].FreeBytes)
+}
+
+func TestDiskHistoryFile_Since(t *testing.T) {
+	df, err := NewDiskHistoryFile(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	base := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, df.Add(DiskSample{Timestamp: base.Add(time.Duration(i) * 24 * time.Hour), FreeBytes: int64(i)}))
+	}
+
+	recent := df.Since(base.Add(48 * time.Hour))
+	require.Len(t, recent, 2)
+	assert.Equal(t, int64(2), recent[0].FreeBytes)
+}
+
+func TestDiskHistoryFile_CorruptFileStartsFresh(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "disk_history.json")
+	require.NoError(t, os.WriteFile(filePath, []byte("{not json"), 0644))
+
+	df, err := NewDiskHistoryFile(tmpDir, 0)
+	require.NoError(t, err)
+	assert.Empty(t, df.GetAll())
+
+	matches, err := filepath.Glob(filePath + ".corrupt.*")
+	require.NoError(t, err)
+	assert.Len(t, matches, 1, "corrupt file should be preserved")
+}
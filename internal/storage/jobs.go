@@ -18,6 +18,7 @@ const (
 	JobStatusRunning   JobStatus = "running"
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
 )
 
 // JobType represents the type of sync job
@@ -26,8 +27,32 @@ type JobType string
 const (
 	JobTypeFullSync        JobType = "full_sync"
 	JobTypeIncrementalSync JobType = "incremental_sync"
+	JobTypeDeletion        JobType = "deletion"
 )
 
+// Deletion outcomes recorded per item in a deletion job
+const (
+	DeletionOutcomeDeleted         = "deleted"          // whole item deleted
+	DeletionOutcomeEpisodesDeleted = "episodes_deleted" // episode-level deletion handled
+	DeletionOutcomeProtected       = "protected"        // skipped by the pre-deletion safety check
+	DeletionOutcomeFailed          = "failed"
+)
+
+// DeletionResult is the outcome of one item in a deletion job
+type DeletionResult struct {
+	MediaID             string `json:"media_id"`
+	Title               string `json:"title"`
+	Outcome             string `json:"outcome"`
+	Rule                string `json:"rule,omitempty"` // rule that scheduled the deletion
+	Message             string `json:"message,omitempty"`
+	EpisodeFilesDeleted int    `json:"episode_files_deleted,omitempty"`
+
+	// EpisodesUnmonitored lists the Sonarr episode IDs unmonitored after the
+	// deletion; Remonitored is set once they have been monitored again
+	EpisodesUnmonitored []int `json:"episodes_unmonitored,omitempty"`
+	Remonitored         bool  `json:"remonitored,omitempty"`
+}
+
 // Job represents a sync job
 type Job struct {
 	ID          string         `json:"id"`
@@ -38,6 +63,24 @@ type Job struct {
 	DurationMs  int64          `json:"duration_ms"`
 	Summary     map[string]any `json:"summary,omitempty"`
 	Error       string         `json:"error,omitempty"`
+
+	// Phases records per-phase progress and timings, updated while running
+	Phases []JobPhase `json:"phases,omitempty"`
+
+	// Results lists per-item outcomes (deletion jobs only)
+	Results []DeletionResult `json:"results,omitempty"`
+}
+
+// JobPhase records the progress and timing of one phase of a job
+type JobPhase struct {
+	Name        string     `json:"name"`
+	Status      JobStatus  `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DurationMs  int64      `json:"duration_ms"`
+	Processed   int        `json:"processed"`
+	Total       int        `json:"total,omitempty"` // 0 when unknown
+	Error       string     `json:"error,omitempty"`
 }
 
 // JobsFile represents the jobs.json structure
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KeepRequestStatus represents the lifecycle state of a keep request
+type KeepRequestStatus string
+
+const (
+	KeepRequestPending  KeepRequestStatus = "pending"
+	KeepRequestApproved KeepRequestStatus = "approved" // the item was excluded from deletion
+	KeepRequestDenied   KeepRequestStatus = "denied"
+)
+
+// KeepRequest asks an operator to exclude a media item from deletion
+type KeepRequest struct {
+	ID          string            `json:"id"`
+	MediaID     string            `json:"media_id"`
+	Title       string            `json:"title"`
+	MediaType   string            `json:"media_type"`
+	RequestedBy string            `json:"requested_by"`
+	Reason      string            `json:"reason,omitempty"`
+	Status      KeepRequestStatus `json:"status"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DecidedAt   *time.Time        `json:"decided_at,omitempty"`
+	DecidedBy   string            `json:"decided_by,omitempty"`
+	Note        string            `json:"note,omitempty"` // optional message from whoever decided
+}
+
+// KeepRequestsFile represents the keep_requests.json structure
+type KeepRequestsFile struct {
+	Version     string        `json:"version"`
+	Requests    []KeepRequest `json:"requests"`
+	mu          sync.RWMutex
+	filePath    string
+	maxRequests int
+}
+
+// NewKeepRequestsFile creates or loads a keep requests file
+func NewKeepRequestsFile(dataPath string, maxRequests int) (*KeepRequestsFile, error) {
+	filePath := filepath.Join(dataPath, "keep_requests.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	if maxRequests == 0 {
+		maxRequests = 500 // Default to keeping the last 500 requests
+	}
+
+	kf := &KeepRequestsFile{
+		Version:     "1.0",
+		Requests:    make([]KeepRequest, 0),
+		filePath:    filePath,
+		maxRequests: maxRequests,
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := kf.load(); err != nil {
+			// Losing keep requests only means users have to ask again, so
+			// start fresh but keep the bytes for recovery.
+			if backup, backupErr := backupCorruptFile(filePath); backupErr != nil {
+				log.Error().Err(err).Err(backupErr).
+					Msg("Failed to load keep requests file; corrupt backup also failed, starting fresh")
+			} else {
+				log.Error().Err(err).Str("backup", backup).
+					Msg("Failed to load keep requests file; corrupt file preserved, starting fresh")
+			}
+		}
+	}
+
+	return kf, nil
+}
+
+// Add adds a new request (most recent first), keeping only maxRequests.
+// Pending requests are never trimmed.
+func (kf *KeepRequestsFile) Add(request KeepRequest) error {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	next := make([]KeepRequest, 0, len(kf.Requests)+1)
+	next = append(next, request)
+	next = append(next, kf.Requests...)
+	if len(next) > kf.maxRequests {
+		trimmed := make([]KeepRequest, 0, kf.maxRequests)
+		for i, r := range next {
+			if i < kf.maxRequests || r.Status == KeepRequestPending {
+				trimmed = append(trimmed, r)
+			}
+		}
+		next = trimmed
+	}
+
+	if err := kf.persist(next); err != nil {
+		return err
+	}
+
+	kf.Requests = next
+	return nil
+}
+
+// Update replaces an existing request. Returns false if no request has that ID.
+func (kf *KeepRequestsFile) Update(request KeepRequest) (bool, error) {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	next := make([]KeepRequest, len(kf.Requests))
+	copy(next, kf.Requests)
+
+	updated := false
+	for i := range next {
+		if next[i].ID == request.ID {
+			next[i] = request
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		return false, nil
+	}
+
+	if err := kf.persist(next); err != nil {
+		return false, err
+	}
+
+	kf.Requests = next
+	return true, nil
+}
+
+// Get retrieves a request by ID
+func (kf *KeepRequestsFile) Get(id string) (KeepRequest, bool) {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+
+	for _, request := range kf.Requests {
+		if request.ID == id {
+			return request, true
+		}
+	}
+	return KeepRequest{}, false
+}
+
+// GetAll returns all requests, most recent first
+func (kf *KeepRequestsFile) GetAll() []KeepRequest {
+	kf.mu.RLock()
+	defer kf.mu.RUnlock()
+
+	requests := make([]KeepRequest, len(kf.Requests))
+	copy(requests, kf.Requests)
+	return requests
+}
+
+// load reads the keep requests file from disk
+func (kf *KeepRequestsFile) load() error {
+	data, err := os.ReadFile(kf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var loaded struct {
+		Version  string        `json:"version"`
+		Requests []KeepRequest `json:"requests"`
+	}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	kf.Version = loaded.Version
+	kf.Requests = loaded.Requests
+	if kf.Requests == nil {
+		kf.Requests = make([]KeepRequest, 0)
+	}
+
+	log.Info().Int("count", len(kf.Requests)).Msg("Loaded keep requests from file")
+	return nil
+}
+
+// persist atomically writes the given state to disk. Callers hold kf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (kf *KeepRequestsFile) persist(requests []KeepRequest) error {
+	if kf.filePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Version  string        `json:"version"`
+		Requests []KeepRequest `json:"requests"`
+	}{
+		Version:  kf.Version,
+		Requests: requests,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(kf.filePath, data, 0644); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(requests)).Msg("Saved keep requests to file")
+	return nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepRequestsFile_AddUpdateReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	kf, err := NewKeepRequestsFile(tmpDir, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, kf.Add(KeepRequest{ID: "k1", MediaID: "m1", RequestedBy: "kid", Status: KeepRequestPending, CreatedAt: time.Now()}))
+	require.NoError(t, kf.Add(KeepRequest{ID: "k2", MediaID: "m2", RequestedBy: "kid", Status: KeepRequestPending, CreatedAt: time.Now()}))
+
+	all := kf.GetAll()
+	require.Len(t, all, 2)
+	assert.Equal(t, "k2", all[0].ID, "most recent first")
+
+	request, ok := kf.Get("k1")
+	require.True(t, ok)
+	request.Status = KeepRequestApproved
+	updated, err := kf.Update(request)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = kf.Update(KeepRequest{ID: "missing"})
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	reloaded, err := NewKeepRequestsFile(tmpDir, 0)
+	require.NoError(t, err)
+	request, ok = reloaded.Get("k1")
+	require.True(t, ok)
+	assert.Equal(t, KeepRequestApproved, request.Status)
+}
+
+func TestKeepRequestsFile_TrimKeepsPending(t *testing.T) {
+	kf, err := NewKeepRequestsFile(t.TempDir(), 2)
+	require.NoError(t, err)
+
+	require.NoError(t, kf.Add(KeepRequest{ID: "pending", Status: KeepRequestPending}))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, kf.Add(KeepRequest{ID: fmt.Sprintf("denied-%d", i), Status: KeepRequestDenied}))
+	}
+
+	all := kf.GetAll()
+	assert.Len(t, all, 3, "two newest plus the pending request")
+	_, ok := kf.Get("pending")
+	assert.True(t, ok, "pending requests are never trimmed")
+}
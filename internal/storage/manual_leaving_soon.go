@@ -18,7 +18,7 @@ type ManualLeavingSoonItem struct {
 	Title        string    `json:"title"`
 	DeleteAfter  time.Time `json:"delete_after"`
 	FlaggedAt    time.Time `json:"flagged_at"`
-	FlaggedBy    string    `json:"flagged_by"` // "api"
+	FlaggedBy    string    `json:"flagged_by"` // username, API key, or "api"
 }
 
 // ManualLeavingSoonFile represents the manual_leaving_soon.json structure
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sessionActivityFlushInterval bounds how often last-seen updates are
+// written, so an active browser does not cause a disk write per request
+const sessionActivityFlushInterval = time.Minute
+
+// Session is a server-side sign-in. Access tokens carry the session ID, so
+// removing the session revokes them. Only SHA-256 hashes of refresh tokens
+// are stored.
+type Session struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// Method is how the user signed in: password, jellyfin, totp or oidc
+	Method      string `json:"method,omitempty"`
+	RefreshHash string `json:"refresh_hash"`
+	// PreviousRefreshHash is the refresh token replaced at RotatedAt. Seeing
+	// it again after a short grace period means the token was copied.
+	PreviousRefreshHash string    `json:"previous_refresh_hash,omitempty"`
+	RotatedAt           time.Time `json:"rotated_at,omitempty"`
+	IP                  string    `json:"ip,omitempty"`
+	UserAgent           string    `json:"user_agent,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	LastSeenAt          time.Time `json:"last_seen_at"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the session has run out
+func (s Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionsFile represents the sessions.json structure
+type SessionsFile struct {
+	Version   string             `json:"version"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Sessions  map[string]Session `json:"sessions"`
+	mu        sync.RWMutex       `json:"-"`
+	filePath  string             `json:"-"`
+	byRefresh map[string]string  `json:"-"` // current or previous refresh hash -> ID
+	flushedAt time.Time          `json:"-"` // last write of activity updates
+}
+
+// NewSessionsFile creates or loads a sessions file. A corrupt file is backed
+// up and replaced with an empty one, which signs everyone out.
+func NewSessionsFile(dataPath string) (*SessionsFile, error) {
+	filePath := filepath.Join(dataPath, "sessions.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	sf := &SessionsFile{
+		Version:   "1.0",
+		Sessions:  make(map[string]Session),
+		filePath:  filePath,
+		byRefresh: make(map[string]string),
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		if err := sf.load(); err != nil {
+			backup, backupErr := backupCorruptFile(filePath)
+			if backupErr != nil {
+				return nil, fmt.Errorf("failed to load sessions file: %w (and backing it up failed: %v)", err, backupErr)
+			}
+			log.Warn().Err(err).Str("backup", backup).Msg("Sessions file is corrupt, starting with no sessions")
+			sf.Sessions = make(map[string]Session)
+		}
+	}
+	sf.reindex()
+
+	return sf, nil
+}
+
+// Put creates or replaces a session
+func (sf *SessionsFile) Put(session Session) error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	next := make(map[string]Session, len(sf.Sessions)+1)
+	for id, existing := range sf.Sessions {
+		next[id] = existing
+	}
+	next[session.ID] = session
+	now := time.Now()
+
+	if err := sf.persist(next, now); err != nil {
+		return err
+	}
+
+	sf.Sessions = next
+	sf.UpdatedAt = now
+	sf.reindex()
+	return nil
+}
+
+// Remove deletes a session. Returns false when no session has the ID.
+func (sf *SessionsFile) Remove(id string) (bool, error) {
+	removed, err := sf.RemoveWhere(func(session Session) bool { return session.ID == id })
+	return removed > 0, err
+}
+
+// RemoveWhere deletes every session match returns true for and returns how
+// many were removed. Nothing is written when none match.
+func (sf *SessionsFile) RemoveWhere(match func(Session) bool) (int, error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	next := make(map[string]Session, len(sf.Sessions))
+	for id, existing := range sf.Sessions {
+		if !match(existing) {
+			next[id] = existing
+		}
+	}
+	removed := len(sf.Sessions) - len(next)
+	if removed == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+
+	if err := sf.persist(next, now); err != nil {
+		return 0, err
+	}
+
+	sf.Sessions = next
+	sf.UpdatedAt = now
+	sf.reindex()
+	return removed, nil
+}
+
+// Get retrieves a session by ID
+func (sf *SessionsFile) Get(id string) (Session, bool) {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	session, exists := sf.Sessions[id]
+	return session, exists
+}
+
+// GetByRefreshHash retrieves the session whose current or previous refresh
+// token has the given hash
+func (sf *SessionsFile) GetByRefreshHash(hash string) (Session, bool) {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	id, exists := sf.byRefresh[hash]
+	if !exists {
+		return Session{}, false
+	}
+	return sf.Sessions[id], true
+}
+
+// GetAll returns all sessions, most recently seen first
+func (sf *SessionsFile) GetAll() []Session {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+
+	sessions := make([]Session, 0, len(sf.Sessions))
+	for _, session := range sf.Sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].ID < sessions[j].ID
+		}
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions
+}
+
+// MarkSeen records activity on a session at the given time. The update is
+// kept in memory and written at most once per sessionActivityFlushInterval.
+func (sf *SessionsFile) MarkSeen(id string, at time.Time) error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	session, exists := sf.Sessions[id]
+	if !exists || !at.After(session.LastSeenAt) {
+		return nil
+	}
+	session.LastSeenAt = at
+	sf.Sessions[id] = session
+
+	if at.Sub(sf.flushedAt) < sessionActivityFlushInterval {
+		return nil
+	}
+	if err := sf.persist(sf.Sessions, sf.UpdatedAt); err != nil {
+		return err
+	}
+	sf.flushedAt = at
+	return nil
+}
+
+// Flush writes pending last-seen updates to disk
+func (sf *SessionsFile) Flush() error {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	if err := sf.persist(sf.Sessions, sf.UpdatedAt); err != nil {
+		return err
+	}
+	sf.flushedAt = time.Now()
+	return nil
+}
+
+// reindex rebuilds the refresh hash lookup. Callers hold sf.mu (or own sf).
+func (sf *SessionsFile) reindex() {
+	sf.byRefresh = make(map[string]string, len(sf.Sessions))
+	for id, session := range sf.Sessions {
+		if session.PreviousRefreshHash != "" {
+			sf.byRefresh[session.PreviousRefreshHash] = id
+		}
+		sf.byRefresh[session.RefreshHash] = id
+	}
+}
+
+// load reads the sessions file from disk
+func (sf *SessionsFile) load() error {
+	data, err := os.ReadFile(sf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var loaded SessionsFile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	sf.Version = loaded.Version
+	sf.UpdatedAt = loaded.UpdatedAt
+	sf.Sessions = loaded.Sessions
+	if sf.Sessions == nil {
+		sf.Sessions = make(map[string]Session)
+	}
+
+	log.Info().Int("count", len(sf.Sessions)).Msg("Loaded sessions from file")
+	return nil
+}
+
+// persist atomically writes the given state to disk. Callers hold sf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+func (sf *SessionsFile) persist(sessions map[string]Session, updatedAt time.Time) error {
+	if sf.filePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(&SessionsFile{
+		Version:   sf.Version,
+		UpdatedAt: updatedAt,
+		Sessions:  sessions,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(sf.filePath, data, 0600); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(sessions)).Msg("Saved sessions to file")
+	return nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionsFile_PutGetRemoveReload(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sf, err := NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, sf.Put(Session{ID: "s1", Username: "alice", RefreshHash: "r1", PreviousRefreshHash: "r0", LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, sf.Put(Session{ID: "s2", Username: "bob", RefreshHash: "r2", LastSeenAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)}))
+
+	session, ok := sf.GetByRefreshHash("r1")
+	require.True(t, ok)
+	assert.Equal(t, "alice", session.Username)
+	session, ok = sf.GetByRefreshHash("r0")
+	require.True(t, ok, "the previous refresh token still resolves, for reuse detection")
+	assert.Equal(t, "s1", session.ID)
+
+	all := sf.GetAll()
+	require.Len(t, all, 2)
+	assert.Equal(t, "s2", all[0].ID, "most recently seen first")
+
+	info, err := os.Stat(filepath.Join(tmpDir, "sessions.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	removed, err := sf.Remove("s2")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	_, ok = sf.GetByRefreshHash("r2")
+	assert.False(t, ok)
+	removed, err = sf.Remove("s2")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	reloaded, err := NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+	session, ok = reloaded.Get("s1")
+	require.True(t, ok)
+	assert.Equal(t, "alice", session.Username)
+}
+
+func TestSessionsFile_RemoveWhere(t *testing.T) {
+	sf, err := NewSessionsFile(t.TempDir())
+	require.NoError(t, err)
+
+	for _, s := range []Session{{ID: "a1", Username: "alice"}, {ID: "a2", Username: "alice"}, {ID: "b1", Username: "bob"}} {
+		require.NoError(t, sf.Put(s))
+	}
+
+	removed, err := sf.RemoveWhere(func(s Session) bool { return s.Username == "alice" })
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.Len(t, sf.GetAll(), 1)
+
+	removed, err = sf.RemoveWhere(func(s Session) bool { return s.Username == "carol" })
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+func TestSessionsFile_MarkSeenIsThrottled(t *testing.T) {
+	tmpDir := t.TempDir()
+	sf, err := NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+	start := time.Now()
+	require.NoError(t, sf.Put(Session{ID: "s1", LastSeenAt: start}))
+
+	first := start.Add(time.Second)
+	require.NoError(t, sf.MarkSeen("s1", first))
+	second := first.Add(10 * time.Second)
+	require.NoError(t, sf.MarkSeen("s1", second))
+
+	session, _ := sf.Get("s1")
+	assert.True(t, session.LastSeenAt.Equal(second), "in-memory value is always current")
+
+	reloaded, err := NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+	session, _ = reloaded.Get("s1")
+	assert.True(t, session.LastSeenAt.Equal(first), "second update within the interval is not written yet")
+
+	require.NoError(t, sf.Flush())
+	reloaded, err = NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+	session, _ = reloaded.Get("s1")
+	assert.True(t, session.LastSeenAt.Equal(second))
+}
+
+func TestSessionsFile_CorruptFileStartsEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "sessions.json"), []byte("{not json"), 0600))
+
+	sf, err := NewSessionsFile(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, sf.GetAll())
+}
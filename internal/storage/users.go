@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// User represents a local account that can sign in to the web UI and API
+type User struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"` // bcrypt
+	Role         string    `json:"role"`          // "admin" | "operator" | "viewer"
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// TOTPSecret is the base32 TOTP secret; set once two-factor sign-in is enabled
+	TOTPSecret string `json:"totp_secret,omitempty"`
+	// TOTPPendingSecret is a secret awaiting its first code during enrollment
+	TOTPPendingSecret string `json:"totp_pending_secret,omitempty"`
+	// TOTPLastStep is the time step of the last accepted code, so no code is accepted twice
+	TOTPLastStep int64 `json:"totp_last_step,omitempty"`
+	// RecoveryCodes holds hex SHA-256 hashes of the unused recovery codes
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// TOTPEnabled reports whether the user signs in with a second factor
+func (u User) TOTPEnabled() bool {
+	return u.TOTPSecret != ""
+}
+
+// UsersFile represents the users.json structure
+type UsersFile struct {
+	Version   string          `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Users     map[string]User `json:"users"`
+	mu        sync.RWMutex    `json:"-"`
+	filePath  string          `json:"-"`
+}
+
+// NewUsersFile creates or loads a users file
+func NewUsersFile(dataPath string) (*UsersFile, error) {
+	filePath := filepath.Join(dataPath, "users.json")
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	uf := &UsersFile{
+		Version:  "1.0",
+		Users:    make(map[string]User),
+		filePath: filePath,
+	}
+
+	// Fail closed on a corrupt file: starting with no users would re-run the
+	// admin migration and silently drop every other account.
+	if _, err := os.Stat(filePath); err == nil {
+		if err := uf.load(); err != nil {
+			backup, backupErr := backupCorruptFile(filePath)
+			if backupErr != nil {
+				return nil, fmt.Errorf("failed to load users file: %w (and backing it up failed: %v)", err, backupErr)
+			}
+			return nil, fmt.Errorf("failed to load users file %s: %w (corrupt file preserved at %s)", filePath, err, backup)
+		}
+	}
+
+	return uf, nil
+}
+
+// Put creates or replaces a user
+func (uf *UsersFile) Put(user User) error {
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+
+	next := make(map[string]User, len(uf.Users)+1)
+	for name, existing := range uf.Users {
+		next[name] = existing
+	}
+	next[user.Username] = user
+	now := time.Now()
+
+	if err := uf.persist(next, now); err != nil {
+		return err
+	}
+
+	uf.Users = next
+	uf.UpdatedAt = now
+	return nil
+}
+
+// Remove deletes a user. Removing an unknown user is a no-op.
+func (uf *UsersFile) Remove(username string) error {
+	uf.mu.Lock()
+	defer uf.mu.Unlock()
+
+	if _, exists := uf.Users[username]; !exists {
+		return nil
+	}
+
+	next := make(map[string]User, len(uf.Users)-1)
+	for name, existing := range uf.Users {
+		if name != username {
+			next[name] = existing
+		}
+	}
+	now := time.Now()
+
+	if err := uf.persist(next, now); err != nil {
+		return err
+	}
+
+	uf.Users = next
+	uf.UpdatedAt = now
+	return nil
+}
+
+// Get retrieves a user by username
+func (uf *UsersFile) Get(username string) (User, bool) {
+	uf.mu.RLock()
+	defer uf.mu.RUnlock()
+
+	user, exists := uf.Users[username]
+	return user, exists
+}
+
+// GetAll returns all users sorted by username
+func (uf *UsersFile) GetAll() []User {
+	uf.mu.RLock()
+	defer uf.mu.RUnlock()
+
+	users := make([]User, 0, len(uf.Users))
+	for _, user := range uf.Users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+// Count returns the number of users
+func (uf *UsersFile) Count() int {
+	uf.mu.RLock()
+	defer uf.mu.RUnlock()
+
+	return len(uf.Users)
+}
+
+// load reads the users file from disk
+func (uf *UsersFile) load() error {
+	data, err := os.ReadFile(uf.filePath)
+	if err != nil {
+		return err
+	}
+
+	var loaded UsersFile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	uf.Version = loaded.Version
+	uf.UpdatedAt = loaded.UpdatedAt
+	uf.Users = loaded.Users
+	if uf.Users == nil {
+		uf.Users = make(map[string]User)
+	}
+
+	log.Info().Int("count", len(uf.Users)).Msg("Loaded users from file")
+	return nil
+}
+
+// persist atomically writes the given state to disk. Callers hold uf.mu.
+// A struct constructed without a file path (e.g. in tests) is in-memory only.
+// The file holds password hashes, so it is only readable by the owner.
+func (uf *UsersFile) persist(users map[string]User, updatedAt time.Time) error {
+	if uf.filePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(&UsersFile{
+		Version:   uf.Version,
+		UpdatedAt: updatedAt,
+		Users:     users,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(uf.filePath, data, 0600); err != nil {
+		return err
+	}
+
+	log.Debug().Int("count", len(users)).Msg("Saved users to file")
+	return nil
+}
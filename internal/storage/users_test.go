@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsersFile_PutGetRemove(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	uf, err := NewUsersFile(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, uf.Count())
+
+	now := time.Now()
+	require.NoError(t, uf.Put(User{Username: "bob", PasswordHash: "$2a$hash", Role: "viewer", CreatedAt: now}))
+	require.NoError(t, uf.Put(User{Username: "alice", PasswordHash: "$2a$hash", Role: "admin", CreatedAt: now}))
+
+	user, ok := uf.Get("alice")
+	require.True(t, ok)
+	assert.Equal(t, "admin", user.Role)
+
+	all := uf.GetAll()
+	require.Len(t, all, 2)
+	assert.Equal(t, "alice", all[0].Username, "users are sorted by username")
+
+	// Password hashes must not be world-readable
+	info, err := os.Stat(filepath.Join(tmpDir, "users.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, uf.Remove("bob"))
+	require.NoError(t, uf.Remove("nobody"))
+	_, ok = uf.Get("bob")
+	assert.False(t, ok)
+
+	// Reload from disk
+	reloaded, err := NewUsersFile(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.Count())
+	user, ok = reloaded.Get("alice")
+	require.True(t, ok)
+	assert.Equal(t, "$2a$hash", user.PasswordHash)
+}
+
+func TestUsersFile_CorruptFileFailsClosed(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "users.json")
+	require.NoError(t, os.WriteFile(filePath, []byte("{not json"), 0600))
+
+	_, err := NewUsersFile(tmpDir)
+	require.Error(t, err)
+
+	matches, _ := filepath.Glob(filePath + ".corrupt.*")
+	assert.Len(t, matches, 1, "corrupt file is preserved for recovery")
+}
@@ -0,0 +1,139 @@
+// Package tracing sets up OpenTelemetry tracing for sync runs, rule
+// evaluation, deletions and upstream HTTP requests.
+//
+// Until Setup installs a provider the global tracer is a no-op, so Start and
+// InstrumentTransport are cheap to call unconditionally.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName identifies spans created by OxiCleanarr itself
+	instrumentationName = "github.com/ramonskie/oxicleanarr"
+
+	serviceName = "oxicleanarr"
+
+	// defaultTracesPath is appended to endpoints given without a path
+	defaultTracesPath = "/v1/traces"
+)
+
+// Setup installs the global tracer provider described by cfg and returns a
+// function that flushes and stops it. When tracing is disabled nothing is
+// installed and the returned function is a no-op.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// newExporter creates the span exporter selected by cfg.Exporter
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "", "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			endpoint, err := tracesURL(cfg.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("creating stdout exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// tracesURL returns endpoint with the OTLP traces path added when it has
+// no path, so "http://collector:4318" works like it does for the SDK's
+// OTEL_EXPORTER_OTLP_ENDPOINT.
+func tracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid tracing endpoint %q: %w", endpoint, err)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultTracesPath
+	}
+	return u.String(), nil
+}
+
+// Start starts a span as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InstrumentTransport wraps next so every request gets a client span named
+// after the integration (e.g. "radarr GET"), parented to the span in the
+// request's context. A nil next uses http.DefaultTransport.
+func InstrumentTransport(client string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(next,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return client + " " + r.Method
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("peer.service", client))),
+	)
+}
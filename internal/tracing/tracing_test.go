@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// useRecorder installs a tracer provider that records ended spans
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("disabled installs nothing", func(t *testing.T) {
+		before := otel.GetTracerProvider()
+		shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "stdout"})
+		require.NoError(t, err)
+		assert.Equal(t, before, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("stdout exporter", func(t *testing.T) {
+		t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+		shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: true, Exporter: "stdout"})
+		require.NoError(t, err)
+		_, isSDK := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+		assert.True(t, isSDK)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("otlp exporter", func(t *testing.T) {
+		t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+		shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: true, Endpoint: "http://127.0.0.1:1"})
+		require.NoError(t, err)
+		// Nothing was recorded, so shutdown doesn't need the collector
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), config.TracingConfig{Enabled: true, Exporter: "zipkin"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"http://collector:4318", "http://collector:4318/v1/traces"},
+		{"http://collector:4318/", "http://collector:4318/v1/traces"},
+		{"https://otel.example.com/custom/traces", "https://otel.example.com/custom/traces"},
+	}
+	for _, tt := range tests {
+		got, err := tracesURL(tt.endpoint)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.endpoint)
+	}
+}
+
+func TestStartEnd(t *testing.T) {
+	recorder := useRecorder(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInstrumentTransport(t *testing.T) {
+	recorder := useRecorder(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: InstrumentTransport("sonarr", nil)}
+	ctx, parent := Start(context.Background(), "sync.sonarr")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v3/series", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	request := spans[0]
+	assert.Equal(t, "sonarr GET", request.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), request.Parent().SpanID())
+	assert.Equal(t, codes.Error, request.Status().Code, "4xx responses mark client spans as errors")
+	assert.Contains(t, traceparent, parent.SpanContext().TraceID().String(), "trace context is propagated upstream")
+}
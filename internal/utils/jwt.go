@@ -16,9 +16,12 @@ var (
 // ErrJWTNotInitialized is returned when token operations are attempted before InitJWT
 var ErrJWTNotInitialized = errors.New("JWT not initialized")
 
-// JWTClaims represents the JWT claims
+// JWTClaims represents the JWT claims. SessionID ties the token to a
+// server-side session, which can be revoked before the token expires.
 type JWTClaims struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -47,16 +50,24 @@ func GetJWTExpiry() time.Duration {
 	return jwtExpiry
 }
 
-// GenerateToken generates a new JWT token for a user
-func GenerateToken(username string) (string, error) {
+// GenerateToken generates a new JWT token for a user with the given role
+func GenerateToken(username, role string) (string, error) {
+	return GenerateSessionToken(username, role, "", jwtExpiry)
+}
+
+// GenerateSessionToken generates a JWT for the given session that expires
+// after ttl
+func GenerateSessionToken(username, role, sessionID string, ttl time.Duration) (string, error) {
 	if jwtSecret == nil {
 		return "", ErrJWTNotInitialized
 	}
 
 	claims := JWTClaims{
-		Username: username,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtExpiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -31,7 +31,7 @@ func TestInitJWT_RequiresSecret(t *testing.T) {
 func TestGenerateToken_NotInitialized(t *testing.T) {
 	// Reset global state by leaving it uninitialized
 	jwtSecret = nil
-	if _, err := GenerateToken("admin"); err != ErrJWTNotInitialized {
+	if _, err := GenerateToken("admin", RoleAdmin); err != ErrJWTNotInitialized {
 		t.Errorf("Expected ErrJWTNotInitialized, got %v", err)
 	}
 }
@@ -48,7 +48,7 @@ func TestGenerateAndValidateToken(t *testing.T) {
 		t.Fatalf("InitJWT failed: %v", err)
 	}
 
-	token, err := GenerateToken("admin")
+	token, err := GenerateToken("admin", RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -60,13 +60,41 @@ func TestGenerateAndValidateToken(t *testing.T) {
 	if claims.Username != "admin" {
 		t.Errorf("Expected username 'admin', got %q", claims.Username)
 	}
+	if claims.Role != RoleAdmin {
+		t.Errorf("Expected role %q, got %q", RoleAdmin, claims.Role)
+	}
+	if claims.SessionID != "" {
+		t.Errorf("Expected no session ID, got %q", claims.SessionID)
+	}
+}
+
+func TestGenerateSessionToken(t *testing.T) {
+	if err := InitJWT("session-secret-at-least-32-chars-long!!", 24*time.Hour); err != nil {
+		t.Fatalf("InitJWT failed: %v", err)
+	}
+
+	token, err := GenerateSessionToken("alice", RoleViewer, "session-1", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateSessionToken failed: %v", err)
+	}
+
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.SessionID != "session-1" {
+		t.Errorf("Expected session ID session-1, got %q", claims.SessionID)
+	}
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 5*time.Minute || ttl < 4*time.Minute {
+		t.Errorf("Expected the token to expire in about 5m, got %s", ttl)
+	}
 }
 
 func TestValidateToken_WrongSecret(t *testing.T) {
 	if err := InitJWT("secret-a-for-testing-at-least-32-chars", time.Hour); err != nil {
 		t.Fatalf("InitJWT failed: %v", err)
 	}
-	token, err := GenerateToken("admin")
+	token, err := GenerateToken("admin", RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -84,7 +112,7 @@ func TestValidateToken_Expired(t *testing.T) {
 	if err := InitJWT("expiry-secret-at-least-32-chars-long!!", -time.Minute); err != nil {
 		t.Fatalf("InitJWT failed: %v", err)
 	}
-	token, err := GenerateToken("admin")
+	token, err := GenerateToken("admin", RoleAdmin)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
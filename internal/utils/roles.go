@@ -0,0 +1,33 @@
+package utils
+
+// User roles, from least to most privileged. Each role includes every
+// permission of the roles below it. Requesters are restricted users (e.g.
+// signed in through Jellyfin) who only see the media they requested.
+const (
+	RoleRequester = "requester"
+	RoleViewer    = "viewer"
+	RoleOperator  = "operator"
+	RoleAdmin     = "admin"
+)
+
+// roleRanks orders the known roles; unknown roles have rank 0 and are denied
+// everything.
+var roleRanks = map[string]int{
+	RoleRequester: 1,
+	RoleViewer:    2,
+	RoleOperator:  3,
+	RoleAdmin:     4,
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether a caller holding role may perform an action that
+// requires the given minimum role.
+func RoleAllows(role, required string) bool {
+	rank := roleRanks[role]
+	return rank > 0 && rank >= roleRanks[required]
+}
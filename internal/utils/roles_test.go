@@ -0,0 +1,60 @@
+package utils
+
+import "testing"
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{RoleRequester, RoleRequester, true},
+		{RoleRequester, RoleViewer, false},
+		{RoleViewer, RoleRequester, true},
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleOperator, false},
+		{RoleViewer, RoleAdmin, false},
+		{RoleOperator, RoleViewer, true},
+		{RoleOperator, RoleOperator, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleAdmin, RoleViewer, true},
+		{RoleAdmin, RoleAdmin, true},
+		{"", RoleViewer, false},
+		{"superuser", RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := RoleAllows(tt.role, tt.required); got != tt.want {
+			t.Errorf("RoleAllows(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	for _, role := range []string{RoleRequester, RoleViewer, RoleOperator, RoleAdmin} {
+		if !IsValidRole(role) {
+			t.Errorf("Expected %q to be valid", role)
+		}
+	}
+	if IsValidRole("root") {
+		t.Error("Expected unknown role to be invalid")
+	}
+}
+
+func TestScopes(t *testing.T) {
+	for _, scope := range AllScopes {
+		if !IsValidScope(scope) {
+			t.Errorf("IsValidScope(%q) = false", scope)
+		}
+	}
+	if IsValidScope("media:write") {
+		t.Error("IsValidScope accepted an unknown scope")
+	}
+
+	granted := []string{ScopeLeavingSoonRead}
+	if !HasAnyScope(granted, ScopeMediaRead, ScopeLeavingSoonRead) {
+		t.Error("HasAnyScope missed a granted scope")
+	}
+	if HasAnyScope(granted, ScopeMediaRead) || HasAnyScope(nil, ScopeMediaRead) {
+		t.Error("HasAnyScope matched a scope that was not granted")
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+var windowPointRegex = regexp.MustCompile(`^(?:(sun|mon|tue|wed|thu|fri|sat)[a-z]*\s+)?(\d{1,2}):(\d{2})$`)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseCron parses a standard 5-field cron expression or a descriptor such as
+// "@daily" or "@every 30m".
+func ParseCron(expr string) (cron.Schedule, error) {
+	return cron.ParseStandard(expr)
+}
+
+// TimeWindow is a recurring window, either daily ("22:00"-"06:00") or weekly
+// ("fri 18:00"-"sun 23:00"), evaluated in the location of the time passed in.
+// Windows wrap past midnight or the end of the week when End is before Start.
+type TimeWindow struct {
+	Weekly bool
+	Start  int // minutes since midnight (daily) or since Sunday 00:00 (weekly)
+	End    int
+}
+
+// ParseTimeWindow parses the start and end of a recurring window. Both points
+// must use the same form: "HH:MM" for daily windows or "<weekday> HH:MM" for
+// weekly ones.
+func ParseTimeWindow(start, end string) (TimeWindow, error) {
+	startWeekly, startMin, err := parseWindowPoint(start)
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("start: %w", err)
+	}
+	endWeekly, endMin, err := parseWindowPoint(end)
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("end: %w", err)
+	}
+	if startWeekly != endWeekly {
+		return TimeWindow{}, fmt.Errorf("start and end must both include a weekday or both omit it")
+	}
+	if startMin == endMin {
+		return TimeWindow{}, fmt.Errorf("start and end must differ")
+	}
+	return TimeWindow{Weekly: startWeekly, Start: startMin, End: endMin}, nil
+}
+
+func parseWindowPoint(s string) (bool, int, error) {
+	m := windowPointRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return false, 0, fmt.Errorf("invalid time %q (use \"HH:MM\" or \"<weekday> HH:MM\", e.g. \"fri 18:00\")", s)
+	}
+	hour, _ := strconv.Atoi(m[2])
+	minute, _ := strconv.Atoi(m[3])
+	if hour > 23 || minute > 59 {
+		return false, 0, fmt.Errorf("invalid time %q", s)
+	}
+	minutes := hour*60 + minute
+	if m[1] == "" {
+		return false, minutes, nil
+	}
+	return true, int(weekdays[m[1]])*minutesPerDay + minutes, nil
+}
+
+// period returns the length of the window's cycle in minutes
+func (w TimeWindow) period() int {
+	if w.Weekly {
+		return minutesPerWeek
+	}
+	return minutesPerDay
+}
+
+// offset returns t's position in the window's cycle in minutes
+func (w TimeWindow) offset(t time.Time) int {
+	m := t.Hour()*60 + t.Minute()
+	if w.Weekly {
+		m += int(t.Weekday()) * minutesPerDay
+	}
+	return m
+}
+
+// Contains reports whether t falls inside the window (start inclusive, end exclusive)
+func (w TimeWindow) Contains(t time.Time) bool {
+	m := w.offset(t)
+	if w.Start < w.End {
+		return m >= w.Start && m < w.End
+	}
+	return m >= w.Start || m < w.End
+}
+
+// EndAfter returns the end of the occurrence of the window containing t.
+// Only meaningful when Contains(t) is true.
+func (w TimeWindow) EndAfter(t time.Time) time.Time {
+	remaining := (w.End - w.offset(t) + w.period()) % w.period()
+	return t.Truncate(time.Minute).Add(time.Duration(remaining) * time.Minute)
+}
+
+// NextOutside returns t if it is outside every window, otherwise the earliest
+// time after t that is outside all of them (chained or overlapping windows are
+// followed through).
+func NextOutside(windows []TimeWindow, t time.Time) time.Time {
+	// Each pass either leaves t unchanged or moves it to a window end; more
+	// passes than windows means the windows cover every minute of the cycle.
+	for i := 0; i <= len(windows); i++ {
+		moved := false
+		for _, w := range windows {
+			if w.Contains(t) {
+				t = w.EndAfter(t)
+				moved = true
+			}
+		}
+		if !moved {
+			return t
+		}
+	}
+	return t
+}
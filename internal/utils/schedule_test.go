@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// 2024-01-05 is a Friday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func mustWindow(t *testing.T, start, end string) TimeWindow {
+	t.Helper()
+	w, err := ParseTimeWindow(start, end)
+	if err != nil {
+		t.Fatalf("ParseTimeWindow(%q, %q): %v", start, end, err)
+	}
+	return w
+}
+
+func TestParseTimeWindow_Errors(t *testing.T) {
+	tests := []struct{ start, end string }{
+		{"", "06:00"},
+		{"25:00", "06:00"},
+		{"22:60", "06:00"},
+		{"fri 18:00", "23:00"},
+		{"noon", "13:00"},
+		{"funday 10:00", "mon 10:00"},
+		{"10:00", "10:00"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseTimeWindow(tt.start, tt.end); err == nil {
+			t.Errorf("ParseTimeWindow(%q, %q): expected error", tt.start, tt.end)
+		}
+	}
+}
+
+func TestTimeWindow_Daily(t *testing.T) {
+	w := mustWindow(t, "22:00", "06:00")
+
+	if !w.Contains(at(5, 23, 0)) || !w.Contains(at(6, 5, 59)) {
+		t.Error("expected window to wrap past midnight")
+	}
+	if w.Contains(at(5, 6, 0)) || w.Contains(at(5, 12, 0)) {
+		t.Error("end is exclusive and midday is outside")
+	}
+	if got := w.EndAfter(at(5, 23, 30)); !got.Equal(at(6, 6, 0)) {
+		t.Errorf("EndAfter = %v, want next morning 06:00", got)
+	}
+}
+
+func TestTimeWindow_Weekly(t *testing.T) {
+	w := mustWindow(t, "Friday 18:00", "mon 02:00")
+
+	if !w.Contains(at(5, 18, 0)) || !w.Contains(at(7, 12, 0)) || !w.Contains(at(8, 1, 0)) {
+		t.Error("expected Friday evening through early Monday to be inside")
+	}
+	if w.Contains(at(5, 17, 59)) || w.Contains(at(8, 2, 0)) || w.Contains(at(10, 12, 0)) {
+		t.Error("expected times outside the weekend to be outside")
+	}
+	if got := w.EndAfter(at(6, 9, 15)); !got.Equal(at(8, 2, 0)) {
+		t.Errorf("EndAfter = %v, want Monday 02:00", got)
+	}
+}
+
+func TestNextOutside(t *testing.T) {
+	windows := []TimeWindow{
+		mustWindow(t, "18:00", "20:00"),
+		mustWindow(t, "19:30", "21:00"), // overlaps the first
+	}
+
+	if got := NextOutside(windows, at(5, 12, 0)); !got.Equal(at(5, 12, 0)) {
+		t.Errorf("time outside all windows must be returned unchanged, got %v", got)
+	}
+	if got := NextOutside(windows, at(5, 18, 30)); !got.Equal(at(5, 21, 0)) {
+		t.Errorf("chained windows: got %v, want 21:00", got)
+	}
+	if got := NextOutside(nil, at(5, 18, 30)); !got.Equal(at(5, 18, 30)) {
+		t.Errorf("no windows: got %v", got)
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	if _, err := ParseCron("0 3 * * *"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ParseCron("@every 30m"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ParseCron("* * *"); err == nil {
+		t.Error("expected error for too few fields")
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+// API key scopes. A named API key may only call the routes that accept one
+// of its scopes; unlike roles, scopes do not include one another.
+const (
+	ScopeLeavingSoonRead  = "leaving-soon:read"
+	ScopeMediaRead        = "media:read"
+	ScopeSyncTrigger      = "sync:trigger"
+	ScopeDeletionsExecute = "deletions:execute"
+	ScopeConfigWrite      = "config:write"
+)
+
+// AllScopes lists the known scopes
+var AllScopes = []string{
+	ScopeLeavingSoonRead,
+	ScopeMediaRead,
+	ScopeSyncTrigger,
+	ScopeDeletionsExecute,
+	ScopeConfigWrite,
+}
+
+// IsValidScope reports whether scope is one of the known scopes
+func IsValidScope(scope string) bool {
+	for _, known := range AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyScope reports whether granted contains at least one of wanted
+func HasAnyScope(granted []string, wanted ...string) bool {
+	for _, g := range granted {
+		for _, w := range wanted {
+			if g == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key from the RFC 6238 test vectors
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B lists 8-digit codes; the 6-digit code is the tail
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode failed: %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("At %d: expected %s, got %s", tt.unix, tt.code, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+
+	current, _ := TOTPCode(rfc6238Secret, step)
+	previous, _ := TOTPCode(rfc6238Secret, step-1)
+	stale, _ := TOTPCode(rfc6238Secret, step-2)
+
+	if got, ok := ValidateTOTP(rfc6238Secret, current, now); !ok || got != step {
+		t.Errorf("Expected current code to match step %d, got %d, %v", step, got, ok)
+	}
+	if got, ok := ValidateTOTP(rfc6238Secret, previous, now); !ok || got != step-1 {
+		t.Errorf("Expected previous code to match within skew, got %d, %v", got, ok)
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Error("Code from two periods ago must be rejected")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, current[:3]+" "+current[3:], now); !ok {
+		t.Error("Spaces inside the code should be ignored")
+	}
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("Expected %q to be rejected", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", current, now); ok {
+		t.Error("Invalid secret must never validate")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	a, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret failed: %v", err)
+	}
+	b, _ := GenerateTOTPSecret()
+	if a == b {
+		t.Error("Secrets must be random")
+	}
+	if len(a) != 32 || strings.Contains(a, "=") {
+		t.Errorf("Expected 32 unpadded base32 characters, got %q", a)
+	}
+	if _, err := TOTPCode(a, 1); err != nil {
+		t.Errorf("Generated secret must be usable: %v", err)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("OxiCleanarr", "jane doe", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("Invalid URI %q: %v", uri, err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("Expected otpauth://totp/, got %q", uri)
+	}
+	if u.Path != "/OxiCleanarr:jane doe" {
+		t.Errorf("Unexpected label %q", u.Path)
+	}
+	query := u.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "OxiCleanarr" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("Unexpected parameters %q", u.RawQuery)
+	}
+}
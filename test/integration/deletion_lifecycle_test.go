@@ -230,6 +230,15 @@ func testDeletionLifecycle(t *testing.T) {
 		require.Equal(t, float64(1), scheduledDeletions, "Expected 1 item scheduled")
 		t.Logf("✅ Job shows scheduled_deletions: 1")
 
+		// Deletions run as a separate deletion job linked from the sync job
+		deletionJobID, ok := summary["deletion_job_id"].(string)
+		require.True(t, ok, "deletion_job_id field should exist")
+		deletionJob, err := client.GetJob(deletionJobID)
+		require.NoError(t, err, "Failed to get deletion job")
+		require.Equal(t, "completed", deletionJob["status"], "Deletion job should complete")
+		summary, ok = deletionJob["summary"].(map[string]interface{})
+		require.True(t, ok, "Deletion job summary not found")
+
 		// Verify deleted_count exists and equals 1
 		deletedCount, ok := summary["deleted_count"].(float64)
 		require.True(t, ok, "deleted_count field should exist")
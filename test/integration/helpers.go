@@ -1351,6 +1351,28 @@ func (tc *TestClient) GetMediaDetails(mediaID string) (map[string]interface{}, e
 	return details, nil
 }
 
+// GetJob retrieves a job by ID from the API
+func (tc *TestClient) GetJob(jobID string) (map[string]interface{}, error) {
+	tc.t.Helper()
+
+	resp, err := tc.Get("/api/jobs/" + jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var job map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return job, nil
+}
+
 // GetLatestJob retrieves the latest job from the API
 func (tc *TestClient) GetLatestJob() (map[string]interface{}, error) {
 	tc.t.Helper()
@@ -1414,6 +1436,13 @@ func (tc *TestClient) WaitForJobCompletion(maxWait time.Duration) (map[string]in
 			// Job is complete if it's not in "running" or "pending" status
 			if status != "running" && status != "pending" {
 				tc.t.Logf("Job %s completed with status: %s", jobID, status)
+				// A full sync queues deletions as a separate job; once that has
+				// finished too, return the sync job that triggered it.
+				if summary, ok := job["summary"].(map[string]interface{}); ok && job["type"] == "deletion" {
+					if syncJobID, ok := summary["sync_job_id"].(string); ok {
+						return tc.GetJob(syncJobID)
+					}
+				}
 				return job, nil
 			}
 		}
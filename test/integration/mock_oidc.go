@@ -0,0 +1,24 @@
+package integration
+
+import (
+	"net"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients/oidctest"
+)
+
+// NewMockOIDCServer starts the shared mock OIDC provider for the Docker tests.
+// Like the other mocks it binds to 0.0.0.0 so OxiCleanarr in Docker can reach
+// it via host.docker.internal. Its issuer is that Docker-facing URL, since the
+// issuer must match what OxiCleanarr fetches discovery from; URL() is the
+// host-facing one.
+func NewMockOIDCServer() *oidctest.Provider {
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		panic("mock_oidc: failed to listen on 0.0.0.0: " + err.Error())
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	provider := oidctest.NewOnListener(listener, "http://host.docker.internal:"+port)
+	provider.Server.URL = "http://127.0.0.1:" + port
+	return provider
+}
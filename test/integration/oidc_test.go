@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ramonskie/oxicleanarr/internal/clients/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// testOIDCLogin runs the single sign-on flow against the mock OIDC provider:
+// OxiCleanarr redirects to the provider, the provider redirects back with a
+// code, and the callback sets the auth cookie with the mapped role.
+func testOIDCLogin(t *testing.T) {
+	t.Logf("=== OIDC Single Sign-On Test ===")
+
+	mockOIDC := NewMockOIDCServer()
+	defer mockOIDC.Close()
+	t.Logf("Started mock OIDC provider at: %s (issuer %s)", mockOIDC.URL(), mockOIDC.Issuer())
+
+	absConfigPath, err := filepath.Abs(ConfigPath)
+	require.NoError(t, err)
+	absComposeFile, err := filepath.Abs(ComposeFile)
+	require.NoError(t, err)
+
+	UpdateConfigForOIDCTest(t, absConfigPath, mockOIDC.Issuer())
+	RestartOxiCleanarr(t, absComposeFile)
+	defer func() {
+		RemoveOIDCConfig(t, absConfigPath)
+		RestartOxiCleanarr(t, absComposeFile)
+	}()
+
+	t.Run("ProvidersAdvertiseOIDC", func(t *testing.T) {
+		resp, data := rawRequest(t, http.MethodGet, OxiCleanarrURL+"/api/auth/providers", "", "", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var providers map[string]bool
+		require.NoError(t, json.Unmarshal(data, &providers))
+		assert.True(t, providers["oidc"])
+	})
+
+	t.Run("GroupMapsToRole", func(t *testing.T) {
+		mockOIDC.SetUser("sso-operator", []string{"media-operators"})
+		location, client := runOIDCFlow(t, mockOIDC)
+		assert.Equal(t, "/", location)
+
+		me := oidcMe(t, client)
+		assert.Equal(t, "sso-operator", me["username"])
+		assert.Equal(t, "operator", me["role"])
+	})
+
+	t.Run("UnmappedUserIsRefused", func(t *testing.T) {
+		mockOIDC.SetUser("stranger", []string{"family"})
+		location, client := runOIDCFlow(t, mockOIDC)
+		assert.True(t, strings.HasPrefix(location, "/login?sso_error="), "expected a login error redirect, got %q", location)
+
+		resp, err := client.Get(OxiCleanarrURL + "/api/auth/me")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// runOIDCFlow plays the browser: it follows the redirects from the login
+// endpoint through the provider back to the callback, and returns the
+// callback's final redirect and the client holding the cookies.
+func runOIDCFlow(t *testing.T, mockOIDC *oidctest.Provider) (string, *http.Client) {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	next := OxiCleanarrURL + "/api/auth/oidc/login"
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(next)
+		require.NoError(t, err)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		require.Equal(t, http.StatusFound, resp.StatusCode, "GET %s", next)
+
+		location := resp.Header.Get("Location")
+		if i == 2 {
+			return location, client
+		}
+		// The provider's URLs are Docker-facing; reach the mock from the host
+		next = strings.Replace(location, mockOIDC.Issuer(), mockOIDC.URL(), 1)
+	}
+	return "", client
+}
+
+// oidcMe returns /api/auth/me for the signed-in client
+func oidcMe(t *testing.T, client *http.Client) map[string]interface{} {
+	t.Helper()
+	resp, err := client.Get(OxiCleanarrURL + "/api/auth/me")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var me map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&me))
+	return me
+}
+
+// UpdateConfigForOIDCTest enables single sign-on against the mock provider
+func UpdateConfigForOIDCTest(t *testing.T, configPath, issuer string) {
+	t.Helper()
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+
+	var config map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &config))
+
+	admin, ok := config["admin"].(map[string]interface{})
+	require.True(t, ok, "admin section not found")
+
+	callback, err := url.JoinPath(OxiCleanarrURL, "/api/auth/oidc/callback")
+	require.NoError(t, err)
+	admin["oidc"] = map[string]interface{}{
+		"enabled":       true,
+		"issuer_url":    issuer,
+		"client_id":     oidctest.ClientID,
+		"client_secret": oidctest.ClientSecret,
+		"redirect_url":  callback,
+		"group_roles": []map[string]interface{}{
+			{"group": "media-admins", "role": "admin"},
+			{"group": "media-operators", "role": "operator"},
+		},
+	}
+
+	newContent, err := yaml.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, newContent, 0644))
+
+	t.Logf("Config updated with mock OIDC provider")
+}
+
+// RemoveOIDCConfig turns single sign-on off again
+func RemoveOIDCConfig(t *testing.T, configPath string) {
+	t.Helper()
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+
+	var config map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &config))
+	if admin, ok := config["admin"].(map[string]interface{}); ok {
+		delete(admin, "oidc")
+	}
+
+	newContent, err := yaml.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, newContent, 0644))
+}
@@ -105,6 +105,16 @@ func TestIntegrationSuite(t *testing.T) {
 		testAuthEndpoints(t)
 	})
 
+	// Run OIDC single sign-on tests against the mock provider
+	t.Run("OIDCLogin", func(t *testing.T) {
+		if !infrastructureReady {
+			t.Log("⚠️  Infrastructure not ready (filtered by -run), setting up now...")
+			testInfrastructureSetup(t)
+			infrastructureReady = true
+		}
+		testOIDCLogin(t)
+	})
+
 	// Run system endpoint tests (non-destructive)
 	t.Run("SystemEndpoints", func(t *testing.T) {
 		if !infrastructureReady {
@@ -1,6 +1,14 @@
 import type { 
+  AuthProviders,
   AuthResponse, 
   LoginRequest, 
+  LoginResponse,
+  SessionListResponse,
+  ConfigHistoryResponse,
+  ConfigVersionDiff,
+  ConfigValidationResult,
+  TOTPEnrollment,
+  TwoFactorStatus,
   MediaListResponse, 
   MediaItem, 
   SyncStatus, 
@@ -18,10 +26,33 @@ import type { ServiceStatusResponse } from './types-services';
 
 const API_BASE = '/api';
 
+// Auth endpoints whose 401 means bad credentials, not an expired access token
+const NO_REFRESH_ENDPOINTS = ['/auth/login', '/auth/login/2fa', '/auth/refresh', '/auth/logout'];
+
 class ApiClient {
+  // Shared so parallel requests that hit an expired token refresh only once
+  private refreshing: Promise<boolean> | null = null;
+
+  // refreshSession trades the refresh cookie for a new access token
+  private refreshSession(): Promise<boolean> {
+    if (!this.refreshing) {
+      this.refreshing = fetch(`${API_BASE}/auth/refresh`, {
+        method: 'POST',
+        credentials: 'same-origin',
+      })
+        .then((response) => response.ok)
+        .catch(() => false)
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
   private async request<T>(
     endpoint: string,
-    options: RequestInit = {}
+    options: RequestInit = {},
+    retried = false
   ): Promise<T> {
     const headers: Record<string, string> = {
       'Content-Type': 'application/json',
@@ -39,6 +70,13 @@ class ApiClient {
       credentials: 'same-origin',
     });
 
+    // Access tokens are short-lived: renew once and retry
+    if (response.status === 401 && !retried && !NO_REFRESH_ENDPOINTS.includes(endpoint)) {
+      if (await this.refreshSession()) {
+        return this.request<T>(endpoint, options, true);
+      }
+    }
+
     if (!response.ok) {
       const error = await response.json().catch(() => ({
         error: 'Unknown error',
@@ -51,17 +89,67 @@ class ApiClient {
   }
 
   // Auth
-  async login(credentials: LoginRequest): Promise<AuthResponse> {
-    return this.request<AuthResponse>('/auth/login', {
+  async login(credentials: LoginRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>('/auth/login', {
       method: 'POST',
       body: JSON.stringify(credentials),
     });
   }
 
+  async loginTwoFactor(challenge: string, code: string): Promise<AuthResponse> {
+    return this.request<AuthResponse>('/auth/login/2fa', {
+      method: 'POST',
+      body: JSON.stringify({ challenge, code }),
+    });
+  }
+
+  async twoFactorStatus(): Promise<TwoFactorStatus> {
+    return this.request<TwoFactorStatus>('/auth/2fa');
+  }
+
+  async enrollTwoFactor(): Promise<TOTPEnrollment> {
+    return this.request<TOTPEnrollment>('/auth/2fa/enroll', { method: 'POST' });
+  }
+
+  async confirmTwoFactor(code: string): Promise<{ recovery_codes: string[] }> {
+    return this.request<{ recovery_codes: string[] }>('/auth/2fa/confirm', {
+      method: 'POST',
+      body: JSON.stringify({ code }),
+    });
+  }
+
+  async disableTwoFactor(code: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>('/auth/2fa/disable', {
+      method: 'POST',
+      body: JSON.stringify({ code }),
+    });
+  }
+
+  async regenerateRecoveryCodes(code: string): Promise<{ recovery_codes: string[] }> {
+    return this.request<{ recovery_codes: string[] }>('/auth/2fa/recovery-codes', {
+      method: 'POST',
+      body: JSON.stringify({ code }),
+    });
+  }
+
+  async authProviders(): Promise<AuthProviders> {
+    return this.request<AuthProviders>('/auth/providers');
+  }
+
   async me(): Promise<AuthResponse> {
     return this.request<AuthResponse>('/auth/me');
   }
 
+  async listSessions(all = false): Promise<SessionListResponse> {
+    return this.request<SessionListResponse>(`/auth/sessions${all ? '?all=true' : ''}`);
+  }
+
+  async revokeSession(id: string): Promise<{ message: string }> {
+    return this.request<{ message: string }>(`/auth/sessions/${encodeURIComponent(id)}`, {
+      method: 'DELETE',
+    });
+  }
+
   async logout(): Promise<{ message: string }> {
     return this.request<{ message: string }>('/auth/logout', {
       method: 'POST',
@@ -219,6 +307,29 @@ class ApiClient {
     });
   }
 
+  // Checks a full config file without applying it
+  async validateConfig(content: string, format: 'yaml' | 'json' = 'yaml'): Promise<ConfigValidationResult> {
+    return this.request<ConfigValidationResult>('/config/validate', {
+      method: 'POST',
+      headers: { 'Content-Type': format === 'json' ? 'application/json' : 'application/yaml' },
+      body: content,
+    });
+  }
+
+  async getConfigHistory(): Promise<ConfigHistoryResponse> {
+    return this.request<ConfigHistoryResponse>('/config/history');
+  }
+
+  async diffConfigVersion(id: string): Promise<ConfigVersionDiff> {
+    return this.request<ConfigVersionDiff>(`/config/history/${encodeURIComponent(id)}/diff`);
+  }
+
+  async restoreConfigVersion(id: string): Promise<{ message: string; id: string }> {
+    return this.request<{ message: string; id: string }>(`/config/history/${encodeURIComponent(id)}/restore`, {
+      method: 'POST',
+    });
+  }
+
   // Rules
   async listRules(): Promise<RulesListResponse> {
     return this.request<RulesListResponse>('/rules');
@@ -4,6 +4,20 @@ export interface ServiceStatus {
   online: boolean;
   error?: string;
   latency?: string;
+  transport?: TransportStats;
+}
+
+export interface TransportStats {
+  requests: number;
+  failures: number;
+  retries: number;
+  rejected: number;
+  in_flight: number;
+  circuit_state: 'closed' | 'open' | 'half_open';
+  consecutive_failures: number;
+  retry_at?: string;
+  last_error?: string;
+  last_failure_at?: string;
 }
 
 export interface ServiceStatusResponse {
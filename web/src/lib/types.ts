@@ -1,6 +1,59 @@
+import type { ServiceStatus } from './types-services';
+
+export type UserRole = 'admin' | 'operator' | 'viewer' | 'requester';
+
 export interface AuthResponse {
   token?: string;
   username?: string;
+  role?: UserRole;
+  expires_in?: number;
+  refresh_token?: string;
+  recovery_codes?: string[];
+}
+
+export interface Session {
+  id: string;
+  username: string;
+  method?: 'password' | 'jellyfin' | 'totp' | 'oidc';
+  ip?: string;
+  user_agent?: string;
+  created_at: string;
+  last_seen_at: string;
+  expires_at: string;
+  current: boolean;
+}
+
+export interface SessionListResponse {
+  sessions: Session[];
+  total: number;
+}
+
+export interface TOTPEnrollment {
+  secret: string;
+  uri: string;
+}
+
+// Returned by /auth/login instead of a token when a second factor is needed
+export interface TwoFactorChallengeResponse {
+  two_factor_required: true;
+  challenge: string;
+  username: string;
+  enrollment?: TOTPEnrollment;
+}
+
+export type LoginResponse = AuthResponse | TwoFactorChallengeResponse;
+
+export interface TwoFactorStatus {
+  enabled: boolean;
+  required: boolean;
+  enrolling: boolean;
+  recovery_codes_remaining: number;
+}
+
+export interface AuthProviders {
+  password: boolean;
+  jellyfin: boolean;
+  oidc: boolean;
 }
 
 export interface LoginRequest {
@@ -76,15 +129,65 @@ export interface JobSummary {
   [key: string]: any; // Allow other summary fields
 }
 
+export interface DeletionResult {
+  media_id: string;
+  title: string;
+  outcome: 'deleted' | 'episodes_deleted' | 'protected' | 'failed';
+  rule?: string;
+  message?: string;
+  episode_files_deleted?: number;
+  episodes_unmonitored?: number[];
+  remonitored?: boolean;
+}
+
+export type JobStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled';
+
+export interface JobPhase {
+  name: 'radarr' | 'sonarr' | 'jellyfin' | 'stats' | 'jellyseerr' | 'rules' | 'deletions';
+  status: JobStatus;
+  started_at: string;
+  completed_at?: string;
+  duration_ms: number;
+  processed: number;
+  total?: number;
+  error?: string;
+}
+
 export interface Job {
   id: string;
-  type: 'full_sync' | 'incremental_sync';
-  status: 'pending' | 'running' | 'completed' | 'failed';
+  type: 'full_sync' | 'incremental_sync' | 'deletion';
+  status: JobStatus;
   started_at: string;
   completed_at?: string;
   duration_ms: number;
   summary?: JobSummary;
   error?: string;
+  results?: DeletionResult[]; // deletion jobs only
+  phases?: JobPhase[];
+}
+
+export type EngineEventType =
+  | 'sync.started'
+  | 'sync.phase'
+  | 'sync.completed'
+  | 'item.scheduled'
+  | 'item.rescheduled'
+  | 'item.unscheduled'
+  | 'item.leaving_soon'
+  | 'item.removed_externally'
+  | 'exclusion.added'
+  | 'exclusion.removed'
+  | 'deletion.executed'
+  | 'deletion.failed'
+  | 'config.reloaded'
+  | 'disk.threshold';
+
+// Event streamed by GET /api/events
+export interface EngineEvent {
+  id: number;
+  type: EngineEventType;
+  time: string;
+  data?: Record<string, any>;
 }
 
 export interface JobListResponse {
@@ -100,14 +203,11 @@ export interface ApiError {
 export interface DeletionExecutionResponse {
   success: boolean;
   scheduled_count: number;
-  deleted_count?: number;
-  episode_files_deleted?: number;
-  protected_count?: number;
-  failed_count?: number;
+  job_id?: string; // set when a deletion job was started
+  status?: Job['status'];
   dry_run?: boolean;
   message: string;
   candidates?: DeletionCandidate[];
-  deleted_items?: DeletionCandidate[];
 }
 
 // Configuration types
@@ -182,6 +282,7 @@ export interface BaseIntegration {
   enabled: boolean;
   url: string;
   has_api_key: boolean;
+  api_key_source?: 'file' | 'env';
   timeout: string;
 }
 
@@ -204,6 +305,14 @@ export interface AdvancedRule {
   max_age?: string;
   require_watched?: boolean;
   users?: UserRule[];
+  episode_delete_strategy?: 'oldest_first' | 'by_age' | 'by_season_age' | 'rolling';
+  rolling_scope?: 'household' | 'user';
+  rolling_buffer?: number;
+  only_watched_episodes?: boolean;
+  keep_next_n_unwatched?: number;
+  watched_retention?: string;
+  unmonitor_deleted?: boolean;
+  unmonitor_past_seasons?: boolean;
 }
 
 export interface UserRule {
@@ -250,3 +359,129 @@ export interface LogsResponse {
   lines: LogLine[];
   total: number;
 }
+
+export type KeepRequestStatus = 'pending' | 'approved' | 'denied';
+
+export interface KeepRequest {
+  id: string;
+  media_id: string;
+  title: string;
+  media_type: string;
+  requested_by: string;
+  reason?: string;
+  status: KeepRequestStatus;
+  created_at: string;
+  decided_at?: string;
+  decided_by?: string;
+  note?: string;
+}
+
+export type ApiKeyScope =
+  | 'leaving-soon:read'
+  | 'media:read'
+  | 'sync:trigger'
+  | 'deletions:execute'
+  | 'config:write';
+
+export interface ApiKey {
+  id: string;
+  name: string;
+  prefix: string;
+  scopes: ApiKeyScope[];
+  created_by?: string;
+  created_at: string;
+  expires_at?: string;
+  last_used_at?: string;
+  expired: boolean;
+}
+
+export interface CreateApiKeyRequest {
+  name: string;
+  scopes: ApiKeyScope[];
+  expires_at?: string;
+}
+
+// Returned once on creation; `key` is the secret and cannot be retrieved later
+export interface CreateApiKeyResponse extends ApiKey {
+  key: string;
+}
+
+export interface AuditChange {
+  field: string;
+  before?: unknown;
+  after?: unknown;
+}
+
+export interface AuditEntry {
+  id: string;
+  timestamp: string;
+  actor: string;
+  ip?: string;
+  action: string;
+  target?: string;
+  outcome: 'success' | 'failure';
+  message?: string;
+  changes?: AuditChange[];
+}
+
+export interface AuditListResponse {
+  entries: AuditEntry[];
+  total: number;
+  limit: number;
+  offset: number;
+}
+
+export interface ConfigVersion {
+  id: string;
+  created_at: string;
+  source: 'startup' | 'api' | 'file' | 'restore';
+  actor?: string;
+  restored_from?: string;
+  current: boolean;
+}
+
+export interface ConfigHistoryResponse {
+  versions: ConfigVersion[];
+  total: number;
+}
+
+// before is the current value, after the value in the version
+export interface ConfigVersionDiff {
+  version: ConfigVersion;
+  changes: AuditChange[];
+  total: number;
+}
+
+export interface ConfigIssue {
+  field: string;
+  message: string;
+}
+
+export interface ScheduleChange {
+  media_id: string;
+  title: string;
+  type: 'movie' | 'tv_show';
+  change: 'scheduled' | 'unscheduled' | 'rescheduled';
+  delete_after_before?: string;
+  delete_after_after?: string;
+  reason_before?: string;
+  reason_after?: string;
+  due: boolean; // the next deletion pass would delete it
+}
+
+export interface SchedulePreview {
+  evaluated: number;
+  scheduled: number;
+  unscheduled: number;
+  rescheduled: number;
+  due: number;
+  changes: ScheduleChange[];
+}
+
+export interface ConfigValidationResult {
+  valid: boolean;
+  errors: ConfigIssue[];
+  warnings: ConfigIssue[];
+  connectivity: ServiceStatus[];
+  schedule?: SchedulePreview;
+}
@@ -19,6 +19,18 @@ import {
   DialogTitle,
 } from '@/components/ui/dialog';
 
+// apiKeyStatus describes the stored API key. Keys from a secret file or
+// environment variable are written back as that reference on save.
+function apiKeyStatus(data?: BaseIntegration): string {
+  switch (data?.api_key_source) {
+    case 'file':
+      return 'API key is read from a secret file (entering a new key replaces it)';
+    case 'env':
+      return 'API key is read from an environment variable (entering a new key replaces it)';
+  }
+  return data?.has_api_key ? 'API key is configured (leave blank to keep current)' : 'No API key configured';
+}
+
 export default function ConfigurationPage() {
   const { section = 'general' } = useParams<{ section: string }>();
   const { toast } = useToast();
@@ -290,7 +302,7 @@ export default function ConfigurationPage() {
         <div>
           <label className="text-sm font-medium">API Key</label>
           <p className="text-sm text-gray-500 mb-2">
-            {data?.has_api_key ? 'API key is configured (leave blank to keep current)' : 'No API key configured'}
+            {apiKeyStatus(data)}
           </p>
           <div className="flex gap-2">
             <Input
@@ -722,9 +734,7 @@ export default function ConfigurationPage() {
               <div>
                 <label className="text-sm font-medium">API Key (Jellyfin API Key)</label>
                 <p className="text-sm text-gray-500 mb-2">
-                  {formData.integrations?.streamystats?.has_api_key
-                    ? 'API key is configured (leave blank to keep current)'
-                    : 'No API key configured'}
+                  {apiKeyStatus(formData.integrations?.streamystats)}
                 </p>
                 <div className="flex gap-2">
                   <Input
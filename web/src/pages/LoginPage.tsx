@@ -1,7 +1,8 @@
 import { useState } from 'react';
-import { useNavigate } from 'react-router-dom';
-import { useMutation } from '@tanstack/react-query';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { useMutation, useQuery } from '@tanstack/react-query';
 import { apiClient } from '@/lib/api';
+import type { AuthResponse, LoginResponse, TwoFactorChallengeResponse } from '@/lib/types';
 import { useAuthStore } from '@/store/auth';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
@@ -10,27 +11,166 @@ import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/com
 export default function LoginPage() {
   const [username, setUsername] = useState('');
   const [password, setPassword] = useState('');
-  const [error, setError] = useState('');
+  const [searchParams] = useSearchParams();
+  const [error, setError] = useState(searchParams.get('sso_error') || '');
+  const [challenge, setChallenge] = useState<TwoFactorChallengeResponse | null>(null);
+  const [code, setCode] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
   const navigate = useNavigate();
   const login = useAuthStore((state) => state.login);
 
+  const { data: providers } = useQuery({
+    queryKey: ['auth-providers'],
+    queryFn: () => apiClient.authProviders(),
+    retry: false,
+  });
+
+  const finishLogin = (response: AuthResponse) => {
+    // Recovery codes are only shown once, so keep the user here until they are saved
+    if (response.recovery_codes?.length) {
+      setRecoveryCodes(response.recovery_codes);
+      return;
+    }
+    login(username);
+    navigate('/');
+  };
+
   const loginMutation = useMutation({
     mutationFn: () => apiClient.login({ username, password }),
-    onSuccess: () => {
-      login(username);
-      navigate('/');
+    onSuccess: (response: LoginResponse) => {
+      if ('two_factor_required' in response) {
+        setChallenge(response);
+        return;
+      }
+      finishLogin(response);
     },
     onError: (error: Error) => {
       setError(error.message || 'Login failed');
     },
   });
 
+  const twoFactorMutation = useMutation({
+    mutationFn: () => apiClient.loginTwoFactor(challenge!.challenge, code),
+    onSuccess: finishLogin,
+    onError: (error: Error) => {
+      setError(error.message || 'Verification failed');
+      setCode('');
+    },
+  });
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     setError('');
-    loginMutation.mutate();
+    if (challenge) {
+      twoFactorMutation.mutate();
+    } else {
+      loginMutation.mutate();
+    }
   };
 
+  const errorMessage = error && (
+    <div className="text-sm text-destructive bg-destructive/10 p-3 rounded-md">
+      {error}
+    </div>
+  );
+
+  if (recoveryCodes.length > 0) {
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-background">
+        <Card className="w-full max-w-md">
+          <CardHeader className="space-y-1">
+            <CardTitle className="text-2xl font-bold text-center">Recovery codes</CardTitle>
+            <CardDescription className="text-center">
+              Save these codes somewhere safe. Each one signs you in once if you lose your
+              authenticator. They will not be shown again.
+            </CardDescription>
+          </CardHeader>
+          <CardContent className="space-y-4">
+            <div className="grid grid-cols-2 gap-2 font-mono text-sm bg-muted p-3 rounded-md">
+              {recoveryCodes.map((recoveryCode) => (
+                <span key={recoveryCode}>{recoveryCode}</span>
+              ))}
+            </div>
+            <Button
+              className="w-full"
+              onClick={() => {
+                login(username);
+                navigate('/');
+              }}
+            >
+              I have saved these codes
+            </Button>
+          </CardContent>
+        </Card>
+      </div>
+    );
+  }
+
+  if (challenge) {
+    const enrollment = challenge.enrollment;
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-background">
+        <Card className="w-full max-w-md">
+          <CardHeader className="space-y-1">
+            <CardTitle className="text-2xl font-bold text-center">Two-factor authentication</CardTitle>
+            <CardDescription className="text-center">
+              {enrollment
+                ? 'Your account requires two-factor authentication. Add it to your authenticator app, then enter the code it shows.'
+                : 'Enter the code from your authenticator app or a recovery code.'}
+            </CardDescription>
+          </CardHeader>
+          <CardContent>
+            <form onSubmit={handleSubmit} className="space-y-4">
+              {enrollment && (
+                <div className="space-y-2 text-sm">
+                  <p>
+                    <a href={enrollment.uri} className="underline">
+                      Open in authenticator app
+                    </a>{' '}
+                    or enter this key manually:
+                  </p>
+                  <code className="block break-all bg-muted p-3 rounded-md">{enrollment.secret}</code>
+                </div>
+              )}
+              <div className="space-y-2">
+                <label htmlFor="code" className="text-sm font-medium">
+                  Code
+                </label>
+                <Input
+                  id="code"
+                  type="text"
+                  inputMode={enrollment ? 'numeric' : 'text'}
+                  autoComplete="one-time-code"
+                  placeholder={enrollment ? '123456' : '123456 or recovery code'}
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  required
+                  autoFocus
+                />
+              </div>
+              {errorMessage}
+              <Button type="submit" className="w-full" disabled={twoFactorMutation.isPending}>
+                {twoFactorMutation.isPending ? 'Verifying...' : 'Verify'}
+              </Button>
+              <Button
+                type="button"
+                variant="outline"
+                className="w-full"
+                onClick={() => {
+                  setChallenge(null);
+                  setCode('');
+                  setError('');
+                }}
+              >
+                Back
+              </Button>
+            </form>
+          </CardContent>
+        </Card>
+      </div>
+    );
+  }
+
   return (
     <div className="min-h-screen flex items-center justify-center bg-background">
       <Card className="w-full max-w-md">
@@ -69,11 +209,7 @@ export default function LoginPage() {
                 required
               />
             </div>
-            {error && (
-              <div className="text-sm text-destructive bg-destructive/10 p-3 rounded-md">
-                {error}
-              </div>
-            )}
+            {errorMessage}
             <Button
               type="submit"
               className="w-full"
@@ -82,6 +218,12 @@ export default function LoginPage() {
               {loginMutation.isPending ? 'Signing in...' : 'Sign in'}
             </Button>
           </form>
+          {providers?.oidc && (
+            <Button asChild variant="outline" className="w-full mt-4">
+              {/* Full page navigation: the provider redirects back to the callback */}
+              <a href="/api/auth/oidc/login">Sign in with SSO</a>
+            </Button>
+          )}
         </CardContent>
       </Card>
     </div>
@@ -62,11 +62,11 @@ export default function ScheduledDeletionsPage() {
   const executeDeletionsMutation = useMutation({
     mutationFn: () => apiClient.executeDeletions(false),
     onSuccess: (data) => {
-      const protectedMsg = data.protected_count ? ` ${data.protected_count} protected.` : '';
       toast({
-        title: 'Deletions Executed',
-        description: `Successfully deleted ${data.deleted_count} items. ${data.failed_count || 0} failed.${protectedMsg}`,
-        variant: data.failed_count && data.failed_count > 0 ? 'destructive' : 'default',
+        title: data.job_id ? 'Deletion Job Started' : 'Nothing to Delete',
+        description: data.job_id
+          ? `Deleting ${data.scheduled_count} items in the background. See Job History for results.`
+          : data.message,
       });
       // Refetch media to update the list
       queryClient.invalidateQueries({ queryKey: ['movies'] });